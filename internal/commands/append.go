@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	appendInvalidLengthErrMsg string = "the APPEND command accepts 3 parameters: APPEND, KEY and VALUE. Received %d parameters instead"
+	stringTooLongErrMsg       string = "string exceeds maximum allowed size (proto-max-bulk-len)"
+	// maxStringSize is the longest string that can be stored, the same default as the
+	// proto-max-bulk-len option of Redis.
+	maxStringSize int = 512 * 1024 * 1024
+)
+
+func init() {
+	appendCmd := appendCommand{"append"}
+	registerCommand(appendCmd)
+}
+
+type appendCommand struct {
+	name string
+}
+
+func (a appendCommand) getName() string {
+	return a.name
+}
+
+// processArguments appends the value to the string stored in the key, creating it if
+// the key doesn't exist, and returns the length of the resulting string.
+func (a appendCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(appendInvalidLengthErrMsg, len(elements)))
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var current string
+	if ok {
+		current = val.String()
+	}
+	suffix := elements[2].String()
+	if len(current)+len(suffix) > maxStringSize {
+		return protocol.NewError(stringTooLongErrMsg)
+	}
+	result := current + suffix
+	datastore.SetKeepTTL(key, protocol.NewBulkString([]byte(result)))
+	return protocol.NewInteger(len(result))
+}
@@ -0,0 +1,289 @@
+package commands
+
+// commandArity holds the number of arguments of each command, counting the command name,
+// with the same convention as Redis: a positive arity is the exact number of arguments
+// and a negative arity is the minimum. Each command validates its own arguments when it
+// runs, so this is only used to reject a command queued in a transaction right away,
+// before EXEC, or called by a script with the wrong number of arguments, the way Redis
+// does.
+var commandArity = map[string]int{
+	"append":           3,
+	"bitcount":         -2,
+	"bitfield":         -2,
+	"bitfield_ro":      -2,
+	"bgsave":           -1,
+	"bitop":            -4,
+	"bitpos":           -3,
+	"blmove":           6,
+	"blmpop":           -5,
+	"blpop":            -3,
+	"brpop":            -3,
+	"brpoplpush":       4,
+	"decr":             2,
+	"decrby":           3,
+	"del":              -2,
+	"discard":          1,
+	"eval":             -3,
+	"evalsha":          -3,
+	"exec":             1,
+	"exists":           -2,
+	"expire":           -3,
+	"expireat":         -3,
+	"expiretime":       2,
+	"fcall":            -3,
+	"fcall_ro":         -3,
+	"function":         -2,
+	"geoadd":           -5,
+	"geodist":          -4,
+	"geohash":          -2,
+	"geopos":           -2,
+	"geosearch":        -7,
+	"geosearchstore":   -8,
+	"get":              2,
+	"getbit":           3,
+	"getdel":           2,
+	"getex":            -2,
+	"getrange":         4,
+	"getset":           3,
+	"hdel":             -3,
+	"hello":            -1,
+	"hexists":          3,
+	"hexpire":          -6,
+	"hexpireat":        -6,
+	"hexpiretime":      -5,
+	"hget":             3,
+	"hgetall":          2,
+	"hincrby":          4,
+	"hincrbyfloat":     4,
+	"hkeys":            2,
+	"hlen":             2,
+	"hmget":            -3,
+	"hmset":            -4,
+	"hpersist":         -5,
+	"hpexpire":         -6,
+	"hpexpireat":       -6,
+	"hpexpiretime":     -5,
+	"hpttl":            -5,
+	"hrandfield":       -2,
+	"hscan":            -3,
+	"hset":             -4,
+	"hsetnx":           4,
+	"hstrlen":          3,
+	"httl":             -5,
+	"hvals":            2,
+	"incr":             2,
+	"incrby":           3,
+	"incrbyfloat":      3,
+	"info":             -1,
+	"lastsave":         1,
+	"lindex":           3,
+	"linsert":          5,
+	"llen":             2,
+	"lmove":            5,
+	"lmpop":            -4,
+	"lpop":             -2,
+	"lpush":            -3,
+	"lpushx":           -3,
+	"lrange":           4,
+	"lrem":             4,
+	"lset":             4,
+	"ltrim":            4,
+	"mget":             -2,
+	"mset":             -3,
+	"msetnx":           -3,
+	"multi":            1,
+	"object":           -2,
+	"persist":          2,
+	"pexpire":          -3,
+	"pexpireat":        -3,
+	"pexpiretime":      2,
+	"pfadd":            -2,
+	"pfcount":          -2,
+	"pfmerge":          -2,
+	"ping":             -1,
+	"psetex":           4,
+	"psubscribe":       -2,
+	"pttl":             2,
+	"publish":          3,
+	"pubsub":           -2,
+	"punsubscribe":     -1,
+	"rpop":             -2,
+	"rpoplpush":        3,
+	"rpush":            -3,
+	"rpushx":           -3,
+	"sadd":             -3,
+	"save":             1,
+	"scard":            2,
+	"script":           -2,
+	"sdiff":            -2,
+	"sdiffstore":       -3,
+	"set":              -3,
+	"setbit":           4,
+	"setex":            4,
+	"setnx":            3,
+	"setrange":         4,
+	"sinter":           -2,
+	"sintercard":       -3,
+	"sinterstore":      -3,
+	"sismember":        3,
+	"smembers":         2,
+	"smismember":       -3,
+	"smove":            4,
+	"spop":             -2,
+	"spublish":         3,
+	"srandmember":      -2,
+	"srem":             -3,
+	"sscan":            -3,
+	"ssubscribe":       -2,
+	"strlen":           2,
+	"subscribe":        -2,
+	"substr":           4,
+	"sunion":           -2,
+	"sunionstore":      -3,
+	"sunsubscribe":     -1,
+	"ttl":              2,
+	"type":             2,
+	"unsubscribe":      -1,
+	"unwatch":          1,
+	"watch":            -2,
+	"xack":             -4,
+	"xadd":             -5,
+	"xautoclaim":       -6,
+	"xclaim":           -6,
+	"xdel":             -3,
+	"xgroup":           -2,
+	"xinfo":            -2,
+	"xlen":             2,
+	"xpending":         -3,
+	"xrange":           -4,
+	"xread":            -4,
+	"xreadgroup":       -7,
+	"xrevrange":        -4,
+	"xtrim":            -4,
+	"zadd":             -4,
+	"zcard":            2,
+	"zcount":           4,
+	"zincrby":          4,
+	"zinterstore":      -4,
+	"zlexcount":        4,
+	"zmscore":          -3,
+	"zpopmax":          -2,
+	"zpopmin":          -2,
+	"zrange":           -4,
+	"zrangebylex":      -4,
+	"zrangebyscore":    -4,
+	"zrangestore":      -5,
+	"zrank":            -3,
+	"zrem":             -3,
+	"zremrangebylex":   4,
+	"zremrangebyrank":  4,
+	"zremrangebyscore": 4,
+	"zrevrange":        -4,
+	"zrevrangebylex":   -4,
+	"zrevrangebyscore": -4,
+	"zrevrank":         -3,
+	"zscore":           3,
+	"zunionstore":      -4,
+}
+
+// validArity tells if the command can be called with that number of arguments, counting
+// the command name.
+func validArity(name string, arguments int) bool {
+	arity, ok := commandArity[name]
+	if !ok {
+		return true
+	}
+	if arity < 0 {
+		return arguments >= -arity
+	}
+	return arguments == arity
+}
+
+// writeCommands are the commands that can modify the datastore, which read-only scripts
+// can't call.
+var writeCommands = map[string]bool{
+	"append":           true,
+	"bitfield":         true,
+	"bitop":            true,
+	"blmove":           true,
+	"blmpop":           true,
+	"blpop":            true,
+	"brpop":            true,
+	"brpoplpush":       true,
+	"decr":             true,
+	"decrby":           true,
+	"del":              true,
+	"expire":           true,
+	"expireat":         true,
+	"geoadd":           true,
+	"geosearchstore":   true,
+	"getdel":           true,
+	"getex":            true,
+	"getset":           true,
+	"hdel":             true,
+	"hexpire":          true,
+	"hexpireat":        true,
+	"hincrby":          true,
+	"hincrbyfloat":     true,
+	"hmset":            true,
+	"hpersist":         true,
+	"hpexpire":         true,
+	"hpexpireat":       true,
+	"hset":             true,
+	"hsetnx":           true,
+	"incr":             true,
+	"incrby":           true,
+	"incrbyfloat":      true,
+	"linsert":          true,
+	"lmove":            true,
+	"lmpop":            true,
+	"lpop":             true,
+	"lpush":            true,
+	"lpushx":           true,
+	"lrem":             true,
+	"lset":             true,
+	"ltrim":            true,
+	"mset":             true,
+	"msetnx":           true,
+	"persist":          true,
+	"pexpire":          true,
+	"pexpireat":        true,
+	"pfadd":            true,
+	"pfmerge":          true,
+	"psetex":           true,
+	"rpop":             true,
+	"rpoplpush":        true,
+	"rpush":            true,
+	"rpushx":           true,
+	"sadd":             true,
+	"sdiffstore":       true,
+	"set":              true,
+	"setbit":           true,
+	"setex":            true,
+	"setnx":            true,
+	"setrange":         true,
+	"sinterstore":      true,
+	"smove":            true,
+	"spop":             true,
+	"srem":             true,
+	"sunionstore":      true,
+	"xack":             true,
+	"xadd":             true,
+	"xautoclaim":       true,
+	"xclaim":           true,
+	"xdel":             true,
+	"xgroup":           true,
+	"xreadgroup":       true,
+	"xtrim":            true,
+	"zadd":             true,
+	"zincrby":          true,
+	"zinterstore":      true,
+	"zpopmax":          true,
+	"zpopmin":          true,
+	"zrangestore":      true,
+	"zrem":             true,
+	"zremrangebylex":   true,
+	"zremrangebyrank":  true,
+	"zremrangebyscore": true,
+	"zunionstore":      true,
+}
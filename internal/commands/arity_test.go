@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestEveryCommandHasArity(t *testing.T) {
+	for name := range registeredCommands {
+		if _, ok := commandArity[name]; !ok {
+			t.Errorf("missing arity for %s", name)
+		}
+	}
+	for name := range registeredClientCommands {
+		if _, ok := commandArity[name]; !ok {
+			t.Errorf("missing arity for %s", name)
+		}
+	}
+	for name := range commandArity {
+		_, ok := registeredCommands[name]
+		_, clientOk := registeredClientCommands[name]
+		if !ok && !clientOk {
+			t.Errorf("arity of %s, which isn't a command", name)
+		}
+	}
+}
+
+func TestWriteCommandsAreCommands(t *testing.T) {
+	for name := range writeCommands {
+		_, ok := registeredCommands[name]
+		_, clientOk := registeredClientCommands[name]
+		if !ok && !clientOk {
+			t.Errorf("%s is a write command, but it isn't a command", name)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	bitcountSyntaxErrMsg string = "invalid arguments for command BITCOUNT. Syntax: BITCOUNT key [start end [BYTE | BIT]]"
+)
+
+func init() {
+	bitcount := bitcountCommand{"bitcount"}
+	registerCommand(bitcount)
+}
+
+type bitcountCommand struct {
+	name string
+}
+
+func (b bitcountCommand) getName() string {
+	return b.name
+}
+
+// processArguments returns how many bits are set in the string stored in the key, or
+// in the range of it from start to end, both inclusive. The range is given in bytes,
+// unless BIT is informed, and negative offsets count from the end of the string.
+func (b bitcountCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 && len(elements) != 4 && len(elements) != 5 {
+		return protocol.NewError(bitcountSyntaxErrMsg)
+	}
+	bitmap, _, err := getBitmap(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	start, end := 0, len(bitmap)*8
+	if len(elements) > 2 {
+		var errReply protocol.DataType
+		start, end, errReply = parseBitRange(elements[2:], len(bitmap), bitcountSyntaxErrMsg)
+		if errReply != nil {
+			return errReply
+		}
+	}
+	return protocol.NewInteger(datastore.BitCount(bitmap, start, end))
+}
+
+// parseBitRange parses the start and end offsets of a range of a bitmap, followed by
+// the optional unit, BYTE or BIT, and returns the range in bit offsets, from start,
+// inclusive, to end, exclusive.
+func parseBitRange(arguments []protocol.DataType, length int, syntaxErrMsg string) (int, int, protocol.DataType) {
+	start, ok := parseInt(arguments[0])
+	if !ok {
+		return 0, 0, protocol.NewError(notIntegerErrMsg)
+	}
+	end, ok := parseInt(arguments[1])
+	if !ok {
+		return 0, 0, protocol.NewError(notIntegerErrMsg)
+	}
+	unit := "BYTE"
+	if len(arguments) > 2 {
+		unit = strings.ToUpper(arguments[2].String())
+	}
+	switch unit {
+	case "BYTE":
+		start, end = stringRange(start, end, length)
+		return start * 8, end * 8, nil
+	case "BIT":
+		start, end = stringRange(start, end, length*8)
+		return start, end, nil
+	}
+	return 0, 0, protocol.NewError(syntaxErrMsg)
+}
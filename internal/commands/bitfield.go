@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	bitfieldSyntaxErrMsg   string = "invalid arguments for command %s. Syntax: %s key [GET encoding offset | [OVERFLOW <WRAP | SAT | FAIL>] <SET encoding offset value | INCRBY encoding offset increment> [GET encoding offset | ...]]"
+	bitfieldTypeErrMsg     string = "Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is."
+	bitfieldOverflowErrMsg string = "Invalid OVERFLOW type specified"
+	bitfieldROErrMsg       string = "BITFIELD_RO only supports the GET subcommand"
+)
+
+var overflowPolicies = map[string]datastore.OverflowPolicy{
+	"WRAP": datastore.OverflowWrap,
+	"SAT":  datastore.OverflowSat,
+	"FAIL": datastore.OverflowFail,
+}
+
+func init() {
+	registerCommand(bitfieldCommand{name: "bitfield"})
+	registerCommand(bitfieldCommand{name: "bitfield_ro", readOnly: true})
+}
+
+// bitfieldCommand implements BITFIELD, which reads and writes integers of arbitrary
+// widths stored in a string, and BITFIELD_RO, which only reads them.
+type bitfieldCommand struct {
+	name     string
+	readOnly bool
+}
+
+// bitfieldOperation is one of the GET, SET or INCRBY subcommands of BITFIELD, with the
+// overflow policy in effect for it.
+type bitfieldOperation struct {
+	subcommand string
+	fieldType  datastore.BitfieldType
+	offset     int
+	value      int64
+	overflow   datastore.OverflowPolicy
+}
+
+func (b bitfieldCommand) getName() string {
+	return b.name
+}
+
+// processArguments runs the subcommands in order, returning the result of each of
+// them: the value read by GET, the previous value for SET and the new value for
+// INCRBY. SET and INCRBY reply nil when the value overflows with the FAIL policy.
+func (b bitfieldCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return b.syntaxError()
+	}
+	operations, errReply := b.parseOperations(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	key := elements[1].String()
+	bitmap, _, err := getBitmap(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	writes := false
+	replies := []protocol.DataType{}
+	for _, operation := range operations {
+		t := operation.fieldType
+		current := datastore.GetBitfield(bitmap, operation.offset, t)
+		switch operation.subcommand {
+		case "GET":
+			replies = append(replies, protocol.NewInteger(int(current)))
+			continue
+		case "SET":
+			value, ok := t.Add(0, operation.value, operation.overflow)
+			if !ok {
+				replies = append(replies, protocol.NewNullBulkString())
+				continue
+			}
+			bitmap = datastore.SetBitfield(bitmap, operation.offset, t, value)
+			replies = append(replies, protocol.NewInteger(int(current)))
+		case "INCRBY":
+			value, ok := t.Add(current, operation.value, operation.overflow)
+			if !ok {
+				replies = append(replies, protocol.NewNullBulkString())
+				continue
+			}
+			bitmap = datastore.SetBitfield(bitmap, operation.offset, t, value)
+			replies = append(replies, protocol.NewInteger(int(value)))
+		}
+		writes = true
+	}
+	if writes {
+		datastore.SetKeepTTL(key, protocol.NewBulkString(bitmap))
+	}
+	return protocol.NewArray(replies...)
+}
+
+func (b bitfieldCommand) parseOperations(arguments []protocol.DataType) ([]bitfieldOperation, protocol.DataType) {
+	operations := []bitfieldOperation{}
+	overflow := datastore.OverflowWrap
+	for i := 0; i < len(arguments); {
+		subcommand := strings.ToUpper(arguments[i].String())
+		if b.readOnly && subcommand != "GET" {
+			return nil, protocol.NewError(bitfieldROErrMsg)
+		}
+		switch subcommand {
+		case "OVERFLOW":
+			if i+1 >= len(arguments) {
+				return nil, b.syntaxError()
+			}
+			policy, ok := overflowPolicies[strings.ToUpper(arguments[i+1].String())]
+			if !ok {
+				return nil, protocol.NewError(bitfieldOverflowErrMsg)
+			}
+			overflow = policy
+			i += 2
+			continue
+		case "GET", "SET", "INCRBY":
+		default:
+			return nil, b.syntaxError()
+		}
+		required := 3
+		if subcommand != "GET" {
+			required = 4
+		}
+		if i+required > len(arguments) {
+			return nil, b.syntaxError()
+		}
+		operation := bitfieldOperation{subcommand: subcommand, overflow: overflow}
+		var ok bool
+		if operation.fieldType, ok = parseBitfieldType(arguments[i+1].String()); !ok {
+			return nil, protocol.NewError(bitfieldTypeErrMsg)
+		}
+		if operation.offset, ok = parseBitfieldOffset(arguments[i+2].String(), operation.fieldType); !ok {
+			return nil, protocol.NewError(bitOffsetErrMsg)
+		}
+		if subcommand != "GET" {
+			value, err := strconv.ParseInt(arguments[i+3].String(), 10, 64)
+			if err != nil {
+				return nil, protocol.NewError(notIntegerErrMsg)
+			}
+			operation.value = value
+		}
+		operations = append(operations, operation)
+		i += required
+	}
+	return operations, nil
+}
+
+func (b bitfieldCommand) syntaxError() protocol.DataType {
+	name := strings.ToUpper(b.name)
+	return protocol.NewError(fmt.Sprintf(bitfieldSyntaxErrMsg, name, name))
+}
+
+// parseBitfieldType parses a type like i8 or u16. Signed types can be up to 64 bits
+// wide and unsigned types up to 63.
+func parseBitfieldType(argument string) (datastore.BitfieldType, bool) {
+	if len(argument) < 2 {
+		return datastore.BitfieldType{}, false
+	}
+	t := datastore.BitfieldType{}
+	maxWidth := 0
+	switch argument[0] {
+	case 'i', 'I':
+		t.Signed = true
+		maxWidth = 64
+	case 'u', 'U':
+		maxWidth = 63
+	default:
+		return t, false
+	}
+	width, err := strconv.Atoi(argument[1:])
+	if err != nil || width < 1 || width > maxWidth {
+		return t, false
+	}
+	t.Width = width
+	return t, true
+}
+
+// parseBitfieldOffset parses the offset of an integer in bits, or in multiples of the
+// width of its type when prefixed by #.
+func parseBitfieldOffset(argument string, t datastore.BitfieldType) (int, bool) {
+	multiplier := 1
+	if strings.HasPrefix(argument, "#") {
+		multiplier = t.Width
+		argument = argument[1:]
+	}
+	offset, err := strconv.Atoi(argument)
+	if err != nil || offset < 0 || offset > (maxStringSize*8-t.Width)/multiplier {
+		return 0, false
+	}
+	return offset * multiplier, true
+}
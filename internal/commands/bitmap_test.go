@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type bitmapTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestBitmapCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	// bits holds the bytes ff f0 00
+	bits := "BITFIELD bitmap-bits SET u8 0 255 SET u8 8 240 SET u8 16 0"
+	btcs := []bitmapTestCase{
+		{
+			name:     "SETBIT",
+			input:    "SETBIT bitmap-setbit 7 1",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:      "SETBIT grows the string",
+			setupCmds: []string{"SETBIT bitmap-setbit-grow 7 1", "SETBIT bitmap-setbit-grow 17 1"},
+			input:     "GET bitmap-setbit-grow",
+			expected:  protocol.NewBulkString([]byte{0x01, 0x00, 0x40}),
+		},
+		{
+			name:      "SETBIT returns the previous bit",
+			setupCmds: []string{"SETBIT bitmap-setbit-previous 3 1"},
+			input:     "SETBIT bitmap-setbit-previous 3 0",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:     "SETBIT with invalid value",
+			input:    "SETBIT bitmap-setbit-invalid 3 2",
+			expected: protocol.NewError(bitValueErrMsg),
+		},
+		{
+			name:     "SETBIT with offset out of range",
+			input:    fmt.Sprintf("SETBIT bitmap-setbit-range %d 1", maxStringSize*8),
+			expected: protocol.NewError(bitOffsetErrMsg),
+		},
+		{
+			name:      "GETBIT",
+			setupCmds: []string{"SET bitmap-getbit a"},
+			input:     "GETBIT bitmap-getbit 1",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "GETBIT past the end",
+			setupCmds: []string{"SET bitmap-getbit-end a"},
+			input:     "GETBIT bitmap-getbit-end 100",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "BITCOUNT",
+			setupCmds: []string{"SET bitmap-count foobar"},
+			input:     "BITCOUNT bitmap-count",
+			expected:  protocol.NewInteger(26),
+		},
+		{
+			name:      "BITCOUNT with byte range",
+			setupCmds: []string{"SET bitmap-count-bytes foobar"},
+			input:     "BITCOUNT bitmap-count-bytes 1 1",
+			expected:  protocol.NewInteger(6),
+		},
+		{
+			name:      "BITCOUNT with bit range",
+			setupCmds: []string{"SET bitmap-count-bits foobar"},
+			input:     "BITCOUNT bitmap-count-bits 5 30 BIT",
+			expected:  protocol.NewInteger(17),
+		},
+		{
+			name:      "BITCOUNT with negative range",
+			setupCmds: []string{"SET bitmap-count-neg foobar"},
+			input:     "BITCOUNT bitmap-count-neg -2 -1",
+			expected:  protocol.NewInteger(7),
+		},
+		{
+			name:     "BITCOUNT with only start",
+			input:    "BITCOUNT bitmap-count-start 1",
+			expected: protocol.NewError(bitcountSyntaxErrMsg),
+		},
+		{
+			name:      "BITPOS",
+			setupCmds: []string{bits},
+			input:     "BITPOS bitmap-bits 0",
+			expected:  protocol.NewInteger(12),
+		},
+		{
+			name:      "BITPOS with byte range",
+			setupCmds: []string{bits},
+			input:     "BITPOS bitmap-bits 1 2",
+			expected:  protocol.NewInteger(-1),
+		},
+		{
+			name:      "BITPOS with bit range",
+			setupCmds: []string{bits},
+			input:     "BITPOS bitmap-bits 1 7 15 BIT",
+			expected:  protocol.NewInteger(7),
+		},
+		{
+			name:      "BITPOS of a clear bit past the end",
+			setupCmds: []string{"SET bitmap-pos-full \xff"},
+			input:     "BITPOS bitmap-pos-full 0",
+			expected:  protocol.NewInteger(8),
+		},
+		{
+			name:      "BITPOS of a clear bit with end",
+			setupCmds: []string{"SET bitmap-pos-full-end \xff"},
+			input:     "BITPOS bitmap-pos-full-end 0 0 -1",
+			expected:  protocol.NewInteger(-1),
+		},
+		{
+			name:     "BITPOS on a missing key",
+			input:    "BITPOS bitmap-pos-missing 0",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:     "BITPOS with invalid bit",
+			input:    "BITPOS bitmap-pos-missing 2",
+			expected: protocol.NewError(bitposBitErrMsg),
+		},
+		{
+			name:      "BITOP AND",
+			setupCmds: []string{"SET bitmap-op-a foobar", "SET bitmap-op-b abcdef", "BITOP AND bitmap-op-and bitmap-op-a bitmap-op-b"},
+			input:     "GET bitmap-op-and",
+			expected:  protocol.NewBulkString([]byte("`bc`ab")),
+		},
+		{
+			name:      "BITOP OR with missing key",
+			setupCmds: []string{"SET bitmap-op-or-a ab"},
+			input:     "BITOP OR bitmap-op-or bitmap-op-or-a bitmap-op-missing",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "BITOP NOT",
+			setupCmds: []string{"SETBIT bitmap-op-not-src 0 1", "BITOP NOT bitmap-op-not bitmap-op-not-src"},
+			input:     "GET bitmap-op-not",
+			expected:  protocol.NewBulkString([]byte{0x7f}),
+		},
+		{
+			name:     "BITOP NOT with two keys",
+			input:    "BITOP NOT bitmap-op-dest bitmap-op-a bitmap-op-b",
+			expected: protocol.NewError(bitopNotErrMsg),
+		},
+		{
+			name:      "BITOP DIFF",
+			setupCmds: []string{"BITFIELD bitmap-op-diff-x SET u8 0 15", "BITFIELD bitmap-op-diff-y SET u8 0 3", "BITOP DIFF bitmap-op-diff bitmap-op-diff-x bitmap-op-diff-y"},
+			input:     "GET bitmap-op-diff",
+			expected:  protocol.NewBulkString([]byte{12}),
+		},
+		{
+			name:     "BITOP DIFF with one key",
+			input:    "BITOP DIFF bitmap-op-dest bitmap-op-a",
+			expected: protocol.NewError(bitopDiffErrMsg),
+		},
+		{
+			name:      "BITOP with empty result",
+			setupCmds: []string{"SET bitmap-op-empty value"},
+			input:     "BITOP AND bitmap-op-empty bitmap-op-missing",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:     "BITFIELD",
+			input:    "BITFIELD bitmap-field INCRBY i5 100 1 GET u4 0",
+			expected: integerArray(1, 0),
+		},
+		{
+			name:      "BITFIELD SET returns the previous value",
+			setupCmds: []string{"BITFIELD bitmap-field-set SET i8 #1 -100"},
+			input:     "BITFIELD bitmap-field-set SET i8 #1 5 GET i8 8",
+			expected:  integerArray(-100, 5),
+		},
+		{
+			name:      "BITFIELD overflow policies",
+			setupCmds: []string{"BITFIELD bitmap-field-overflow SET u2 0 3"},
+			input:     "BITFIELD bitmap-field-overflow INCRBY u2 0 1 OVERFLOW SAT INCRBY u2 0 5 OVERFLOW FAIL INCRBY u2 0 1",
+			expected:  protocol.NewArray(protocol.NewInteger(0), protocol.NewInteger(3), protocol.NewNullBulkString()),
+		},
+		{
+			name:     "BITFIELD with u64",
+			input:    "BITFIELD bitmap-field-u64 GET u64 0",
+			expected: protocol.NewError(bitfieldTypeErrMsg),
+		},
+		{
+			name:     "BITFIELD with invalid overflow",
+			input:    "BITFIELD bitmap-field-invalid OVERFLOW NONE",
+			expected: protocol.NewError(bitfieldOverflowErrMsg),
+		},
+		{
+			name:     "BITFIELD with only GET doesn't create the key",
+			input:    "BITFIELD bitmap-field-get GET i8 0",
+			expected: integerArray(0),
+		},
+		{
+			name:      "BITFIELD_RO",
+			setupCmds: []string{"SET bitmap-field-ro a"},
+			input:     "BITFIELD_RO bitmap-field-ro GET u8 0",
+			expected:  integerArray(97),
+		},
+		{
+			name:     "BITFIELD_RO with SET",
+			input:    "BITFIELD_RO bitmap-field-ro SET u8 0 1",
+			expected: protocol.NewError(bitfieldROErrMsg),
+		},
+		{
+			name:      "Bitmap command on a list",
+			setupCmds: []string{"RPUSH bitmap-list a"},
+			input:     "SETBIT bitmap-list 0 1",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range btcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+	if datastore.Exists("bitmap-field-get") {
+		t.Fatalf("BITFIELD with only GET shouldn't create the key")
+	}
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	bitopInvalidLengthErrMsg string = "invalid arguments for command BITOP. Syntax: BITOP <AND | OR | XOR | NOT | DIFF> destkey key [key ...]"
+	bitopNotErrMsg           string = "BITOP NOT must be called with a single source key."
+	bitopDiffErrMsg          string = "BITOP DIFF must be called with at least two source keys."
+)
+
+var bitOperations = map[string]datastore.BitOperation{
+	"AND":  datastore.BitAnd,
+	"OR":   datastore.BitOr,
+	"XOR":  datastore.BitXor,
+	"NOT":  datastore.BitNot,
+	"DIFF": datastore.BitDiff,
+}
+
+func init() {
+	bitop := bitopCommand{"bitop"}
+	registerCommand(bitop)
+}
+
+type bitopCommand struct {
+	name string
+}
+
+func (b bitopCommand) getName() string {
+	return b.name
+}
+
+// processArguments stores the result of the operation on the strings stored in the
+// keys in the destination key and returns its length. Missing keys are taken as empty
+// strings and shorter strings as padded with zero bytes. An empty result deletes the
+// destination key.
+func (b bitopCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return protocol.NewError(bitopInvalidLengthErrMsg)
+	}
+	name := strings.ToUpper(elements[1].String())
+	operation, ok := bitOperations[name]
+	if !ok {
+		return protocol.NewError(bitopInvalidLengthErrMsg)
+	}
+	keys := elements[3:]
+	if operation == datastore.BitNot && len(keys) != 1 {
+		return protocol.NewError(bitopNotErrMsg)
+	}
+	if operation == datastore.BitDiff && len(keys) < 2 {
+		return protocol.NewError(bitopDiffErrMsg)
+	}
+	bitmaps := [][]byte{}
+	for _, key := range keys {
+		bitmap, _, err := getBitmap(key.String())
+		if err != nil {
+			return errorReply(err)
+		}
+		bitmaps = append(bitmaps, bitmap)
+	}
+	result := datastore.BitOp(operation, bitmaps)
+	destination := elements[2].String()
+	if len(result) == 0 {
+		datastore.Delete(destination)
+		return protocol.NewInteger(0)
+	}
+	datastore.Set(destination, protocol.NewBulkString(result))
+	return protocol.NewInteger(len(result))
+}
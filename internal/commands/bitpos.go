@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	bitposSyntaxErrMsg string = "invalid arguments for command BITPOS. Syntax: BITPOS key bit [start [end [BYTE | BIT]]]"
+	bitposBitErrMsg    string = "The bit argument must be 1 or 0."
+)
+
+func init() {
+	bitpos := bitposCommand{"bitpos"}
+	registerCommand(bitpos)
+}
+
+type bitposCommand struct {
+	name string
+}
+
+func (b bitposCommand) getName() string {
+	return b.name
+}
+
+// processArguments returns the offset of the first bit set to 1 or 0 in the string
+// stored in the key, optionally in a range like the one of BITCOUNT. If no bit is
+// found it returns -1, except when looking for 0 without an end offset, which returns
+// the first bit past the end of the string, since the string is taken as padded with
+// zeros.
+func (b bitposCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 || len(elements) > 6 {
+		return protocol.NewError(bitposSyntaxErrMsg)
+	}
+	bit, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	if bit != 0 && bit != 1 {
+		return protocol.NewError(bitposBitErrMsg)
+	}
+	bitmap, exists, err := getBitmap(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	arguments := elements[3:]
+	endGiven := len(arguments) > 1
+	// A missing start defaults to the first byte and a missing end to the last one
+	switch len(arguments) {
+	case 0:
+		arguments = []protocol.DataType{protocol.NewBulkString([]byte("0")), protocol.NewBulkString([]byte("-1"))}
+	case 1:
+		arguments = append(arguments, protocol.NewBulkString([]byte("-1")))
+	}
+	start, end, errReply := parseBitRange(arguments, len(bitmap), bitposSyntaxErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	if !exists {
+		if bit == 1 {
+			return protocol.NewInteger(-1)
+		}
+		return protocol.NewInteger(0)
+	}
+	pos := datastore.BitPos(bitmap, bit, start, end)
+	if pos == -1 && bit == 0 && !endGiven && start < end {
+		return protocol.NewInteger(end)
+	}
+	return protocol.NewInteger(pos)
+}
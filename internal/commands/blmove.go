@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	blmoveInvalidLengthErrMsg     string = "invalid arguments for command BLMOVE. Syntax: BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout"
+	brpoplpushInvalidLengthErrMsg string = "the BRPOPLPUSH command accepts 4 parameters: BRPOPLPUSH, SOURCE, DESTINATION and TIMEOUT. Received %d parameters instead"
+)
+
+func init() {
+	blmove := blmoveCommand{"blmove"}
+	registerClientCommand(blmove)
+	brpoplpush := brpoplpushCommand{"brpoplpush"}
+	registerClientCommand(brpoplpush)
+}
+
+type blmoveCommand struct {
+	name string
+}
+
+type brpoplpushCommand struct {
+	name string
+}
+
+func (b blmoveCommand) getName() string {
+	return b.name
+}
+
+func (b blmoveCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 6 {
+		return protocol.NewError(blmoveInvalidLengthErrMsg)
+	}
+	fromFront, ok := parseListSide(elements[3])
+	if !ok {
+		return protocol.NewError(blmoveInvalidLengthErrMsg)
+	}
+	toFront, ok := parseListSide(elements[4])
+	if !ok {
+		return protocol.NewError(blmoveInvalidLengthErrMsg)
+	}
+	timeout, errReply := parseBlockingTimeout(elements[5])
+	if errReply != nil {
+		return errReply
+	}
+	return blockingMove(client, elements[1].String(), elements[2].String(), fromFront, toFront, timeout)
+}
+
+func (b brpoplpushCommand) getName() string {
+	return b.name
+}
+
+func (b brpoplpushCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(fmt.Sprintf(brpoplpushInvalidLengthErrMsg, len(elements)))
+	}
+	timeout, errReply := parseBlockingTimeout(elements[3])
+	if errReply != nil {
+		return errReply
+	}
+	return blockingMove(client, elements[1].String(), elements[2].String(), false, true, timeout)
+}
+
+func blockingMove(client *Client, source, destination string, fromFront, toFront bool, timeout time.Duration) protocol.DataType {
+	if _, _, err := datastore.GetList(source); err != nil {
+		return errorReply(err)
+	}
+	serve := func(key string) (protocol.DataType, bool) {
+		if _, ok, err := datastore.GetList(key); err != nil || !ok {
+			return nil, false
+		}
+		// A destination of the wrong type fails the command, like in Redis
+		return moveListElement(source, destination, fromFront, toFront), true
+	}
+	return serveOrBlock(client, []string{source}, timeout, serve, protocol.NewNullBulkString())
+}
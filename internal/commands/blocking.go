@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	timeoutNotFloatErrMsg string = "timeout is not a float or out of range"
+	timeoutNegativeErrMsg string = "timeout is negative"
+)
+
+// blockedClient is a client waiting for data to be pushed to one of its keys.
+type blockedClient struct {
+	client *Client
+	keys   []string
+	// deadline is when the client stops waiting. The zero value means it waits forever.
+	deadline time.Time
+	// serve tries to serve the client with the data in a key that became ready. It
+	// returns false if there is still nothing for the client in that key.
+	serve        func(key string) (protocol.DataType, bool)
+	timeoutReply protocol.DataType
+}
+
+// BlockedReply is the reply to a client that was blocked by a previous command.
+type BlockedReply struct {
+	Client *Client
+	Reply  protocol.DataType
+}
+
+var (
+	// blockedByKey holds, for each key, the clients waiting for it in the order they
+	// blocked, so they are served first come, first served.
+	blockedByKey   = make(map[string][]*blockedClient)
+	blockedClients = make(map[*Client]*blockedClient)
+	// readyKeys are keys with clients waiting that received data since the last time
+	// the blocked clients were served.
+	readyKeys []string
+	readySet  = make(map[string]struct{})
+)
+
+// parseBlockingTimeout parses the timeout of a blocking command, given in seconds with
+// optional decimals. A timeout of 0 means blocking forever.
+func parseBlockingTimeout(argument protocol.DataType) (time.Duration, protocol.DataType) {
+	seconds, err := strconv.ParseFloat(argument.String(), 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds*float64(time.Second) > math.MaxInt64 {
+		return 0, protocol.NewError(timeoutNotFloatErrMsg)
+	}
+	if seconds < 0 {
+		return 0, protocol.NewError(timeoutNegativeErrMsg)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// serveOrBlock tries to serve the client right away from its keys, in order. If none
+// of them has data, the client is blocked and the returned reply is nil, which tells
+// the task loop to hold the reply until ServeBlockedClients delivers it. Inside a
+// transaction or a script the client is never blocked, it gets the timeout reply
+// instead. A freed client is neither served nor blocked, so the data isn't handed to a
+// connection that's gone.
+func serveOrBlock(client *Client, keys []string, timeout time.Duration, serve func(key string) (protocol.DataType, bool), timeoutReply protocol.DataType) protocol.DataType {
+	if client.freed {
+		return timeoutReply
+	}
+	for _, key := range keys {
+		if reply, ok := serve(key); ok {
+			return reply
+		}
+	}
+	if client.nonBlocking {
+		return timeoutReply
+	}
+	blocked := &blockedClient{
+		client:       client,
+		keys:         keys,
+		serve:        serve,
+		timeoutReply: timeoutReply,
+	}
+	if timeout > 0 {
+		blocked.deadline = time.Now().Add(timeout)
+	}
+	for _, key := range keys {
+		if !slices.Contains(blockedByKey[key], blocked) {
+			blockedByKey[key] = append(blockedByKey[key], blocked)
+		}
+	}
+	blockedClients[client] = blocked
+	return nil
+}
+
+// signalKeyAsReady must be called by commands that add data to a key, so the clients
+// blocked on it get a chance to be served.
+func signalKeyAsReady(key string) {
+	if _, ok := blockedByKey[key]; !ok {
+		return
+	}
+	if _, ok := readySet[key]; ok {
+		return
+	}
+	readySet[key] = struct{}{}
+	readyKeys = append(readyKeys, key)
+}
+
+// ServeBlockedClients serves the blocked clients waiting on keys that received data and
+// replies to the ones whose timeout is over. It must be called from the task loop after
+// every command, and when the deadline returned by NextBlockedDeadline is reached.
+func ServeBlockedClients(now time.Time) []BlockedReply {
+	var replies []BlockedReply
+	// Serving a client can make other keys ready, for example with BLMOVE
+	for len(readyKeys) > 0 {
+		key := readyKeys[0]
+		readyKeys = readyKeys[1:]
+		delete(readySet, key)
+		// A client that can't be served doesn't stop the ones after it, since they may
+		// be waiting for different data, like readers of different consumer groups
+		for _, blocked := range slices.Clone(blockedByKey[key]) {
+			reply, ok := blocked.serve(key)
+			if !ok {
+				continue
+			}
+			unblockClient(blocked)
+			replies = append(replies, BlockedReply{blocked.client, protocol.Convert(reply, blocked.client.protocolVersion)})
+		}
+	}
+	for _, blocked := range blockedClients {
+		if !blocked.deadline.IsZero() && !now.Before(blocked.deadline) {
+			unblockClient(blocked)
+			replies = append(replies, BlockedReply{blocked.client, protocol.Convert(blocked.timeoutReply, blocked.client.protocolVersion)})
+		}
+	}
+	return replies
+}
+
+// NextBlockedDeadline returns the earliest deadline of the blocked clients. The bool is
+// false if no client is waiting with a timeout.
+func NextBlockedDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, blocked := range blockedClients {
+		if !blocked.deadline.IsZero() && (next.IsZero() || blocked.deadline.Before(next)) {
+			next = blocked.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func unblockClient(blocked *blockedClient) {
+	delete(blockedClients, blocked.client)
+	for _, key := range blocked.keys {
+		waiting := blockedByKey[key]
+		for i, other := range waiting {
+			if other == blocked {
+				waiting = append(waiting[:i], waiting[i+1:]...)
+				break
+			}
+		}
+		if len(waiting) == 0 {
+			delete(blockedByKey, key)
+		} else {
+			blockedByKey[key] = waiting
+		}
+	}
+}
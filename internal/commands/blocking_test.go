@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func processClientInline(t *testing.T, client *Client, input string) protocol.DataType {
+	t.Helper()
+	return ProcessClientCommand(client, parseInline(t, input))
+}
+
+func TestBlockingPopServedInOrder(t *testing.T) {
+	first, second := NewClient(), NewClient()
+	if reply := processClientInline(t, first, "BLPOP blocking-fifo 0"); reply != nil {
+		t.Fatalf("client should have blocked, got %v", reply)
+	}
+	if reply := processClientInline(t, second, "BRPOP blocking-other blocking-fifo 0"); reply != nil {
+		t.Fatalf("client should have blocked, got %v", reply)
+	}
+	processInline(t, "RPUSH blocking-fifo a")
+	replies := ServeBlockedClients(time.Now())
+	expected := []BlockedReply{{first, bulkStringArray("blocking-fifo", "a")}}
+	if !reflect.DeepEqual(replies, expected) {
+		t.Fatalf("unexpected replies. Expected: %v, Actual: %v", expected, replies)
+	}
+
+	processInline(t, "RPUSH blocking-fifo b c")
+	replies = ServeBlockedClients(time.Now())
+	expected = []BlockedReply{{second, bulkStringArray("blocking-fifo", "c")}}
+	if !reflect.DeepEqual(replies, expected) {
+		t.Fatalf("unexpected replies. Expected: %v, Actual: %v", expected, replies)
+	}
+	if remaining := processInline(t, "LRANGE blocking-fifo 0 -1"); !reflect.DeepEqual(remaining, bulkStringArray("b")) {
+		t.Fatalf("unexpected remaining elements %v", remaining)
+	}
+}
+
+func TestBlockingPopServedRightAway(t *testing.T) {
+	processInline(t, "RPUSH blocking-ready a b")
+	reply := processClientInline(t, NewClient(), "BLPOP blocking-empty blocking-ready 1")
+	if !reflect.DeepEqual(reply, bulkStringArray("blocking-ready", "a")) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+}
+
+func TestFreedClientIsNotServed(t *testing.T) {
+	processInline(t, "RPUSH blocking-freed a")
+	client := NewClient()
+	FreeClient(client)
+	// It gets the timeout reply instead
+	if reply := processClientInline(t, client, "BLPOP blocking-freed 0"); reply != protocol.NewNullArray() {
+		t.Fatalf("unexpected reply to BLPOP %v", reply)
+	}
+	if reply := processClientInline(t, client, "BLMOVE blocking-freed blocking-freed-dst LEFT LEFT 0"); reply != protocol.NewNullBulkString() {
+		t.Fatalf("unexpected reply to BLMOVE %v", reply)
+	}
+	if _, ok := blockedClients[client]; ok {
+		t.Fatalf("a freed client shouldn't be blocked")
+	}
+	if reply := processInline(t, "LLEN blocking-freed"); reply != protocol.NewInteger(1) {
+		t.Fatalf("the element should stay in the list, got %v", reply)
+	}
+}
+
+func TestBlockingTimeout(t *testing.T) {
+	client := NewClient()
+	if reply := processClientInline(t, client, "BLMOVE blocking-timeout blocking-dst LEFT LEFT 0.05"); reply != nil {
+		t.Fatalf("client should have blocked, got %v", reply)
+	}
+	deadline, ok := NextBlockedDeadline()
+	if !ok {
+		t.Fatalf("there should be a deadline")
+	}
+	if replies := ServeBlockedClients(deadline.Add(-time.Millisecond)); len(replies) != 0 {
+		t.Fatalf("client shouldn't have timed out yet: %v", replies)
+	}
+	replies := ServeBlockedClients(deadline)
+	expected := []BlockedReply{{client, protocol.NewNullBulkString()}}
+	if !reflect.DeepEqual(replies, expected) {
+		t.Fatalf("unexpected replies. Expected: %v, Actual: %v", expected, replies)
+	}
+	if _, ok := NextBlockedDeadline(); ok {
+		t.Fatalf("there shouldn't be any client blocked")
+	}
+}
+
+func TestBlockingMoveChain(t *testing.T) {
+	mover, consumer := NewClient(), NewClient()
+	processClientInline(t, mover, "BLMOVE blocking-chain-src blocking-chain-dst RIGHT LEFT 0")
+	processClientInline(t, consumer, "BLMPOP 0 1 blocking-chain-dst LEFT COUNT 2")
+	processInline(t, "LPUSH blocking-chain-src x")
+	replies := ServeBlockedClients(time.Now())
+	expected := []BlockedReply{
+		{mover, protocol.NewBulkString([]byte("x"))},
+		{consumer, protocol.NewArray(protocol.NewBulkString([]byte("blocking-chain-dst")), bulkStringArray("x"))},
+	}
+	if !reflect.DeepEqual(replies, expected) {
+		t.Fatalf("unexpected replies. Expected: %v, Actual: %v", expected, replies)
+	}
+}
+
+func TestBlockingInvalidArguments(t *testing.T) {
+	processInline(t, "SET blocking-string value")
+	tcs := []struct {
+		input    string
+		expected protocol.DataType
+	}{
+		{"BLPOP blocking-invalid -1", protocol.NewError(timeoutNegativeErrMsg)},
+		{"BLPOP blocking-invalid soon", protocol.NewError(timeoutNotFloatErrMsg)},
+		{"BLPOP blocking-string 0", protocol.NewError("WRONGTYPE Operation against a key holding the wrong kind of value")},
+		{"LMPOP 0 blocking-invalid LEFT", protocol.NewError(numkeysErrMsg)},
+		{"LMPOP 1 blocking-invalid LEFT", protocol.NewNullArray()},
+	}
+	for _, tc := range tcs {
+		if actual := processClientInline(t, NewClient(), tc.input); !reflect.DeepEqual(actual, tc.expected) {
+			t.Fatalf("unexpected reply for %s. Expected: %v, Actual: %v", tc.input, tc.expected, actual)
+		}
+	}
+}
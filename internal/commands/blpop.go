@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	bpopInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key [key ...] timeout"
+)
+
+func init() {
+	registerClientCommand(bpopCommand{name: "blpop", front: true})
+	registerClientCommand(bpopCommand{name: "brpop"})
+}
+
+// bpopCommand implements BLPOP and BRPOP, the blocking versions of LPOP and RPOP. The
+// front flag tells if the element is popped from the head of the list.
+type bpopCommand struct {
+	name  string
+	front bool
+}
+
+func (b bpopCommand) getName() string {
+	return b.name
+}
+
+func (b bpopCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		name := strings.ToUpper(b.name)
+		return protocol.NewError(fmt.Sprintf(bpopInvalidLengthErrMsg, name, name))
+	}
+	timeout, errReply := parseBlockingTimeout(elements[len(elements)-1])
+	if errReply != nil {
+		return errReply
+	}
+	keys := []string{}
+	for _, element := range elements[1 : len(elements)-1] {
+		keys = append(keys, element.String())
+	}
+	// Like Redis, the first key holding the wrong type fails the command right away
+	for _, key := range keys {
+		if _, _, err := datastore.GetList(key); err != nil {
+			return errorReply(err)
+		}
+	}
+	serve := func(key string) (protocol.DataType, bool) {
+		list, ok, err := datastore.GetList(key)
+		if err != nil || !ok {
+			return nil, false
+		}
+		value := popListElement(key, list, b.front)
+		return protocol.NewArray(protocol.NewBulkString([]byte(key)), protocol.NewBulkString(value)), true
+	}
+	return serveOrBlock(client, keys, timeout, serve, protocol.NewNullArray())
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"sync/atomic"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+var lastClientID atomic.Int64
+
+// Client holds the state of a single connection that commands like HELLO can read
+// and change. It is only accessed from the task loop, so it doesn't need locking, except
+// for its output, which is also drained by the connection.
+type Client struct {
+	id              int64
+	name            string
+	protocolVersion int
+	// channels, patterns and shardChannels are the subscriptions of the client in
+	// subscribed mode
+	channels      map[string]struct{}
+	patterns      map[string]struct{}
+	shardChannels map[string]struct{}
+	output        *output
+	// multi is the transaction started by MULTI, or nil outside of a transaction
+	multi *transaction
+	// nonBlocking is set while EXEC or a script runs commands for the client, which
+	// can't block, so blocking commands reply as if their timeout was over
+	nonBlocking bool
+	// watched holds the version of each key watched by the client when WATCH ran
+	watched map[string]datastore.KeyVersion
+	// freed is set once the connection was closed and FreeClient released the client
+	freed bool
+}
+
+type clientCommand interface {
+	getName() string
+	processClientArguments(client *Client, data protocol.Array) protocol.DataType
+}
+
+// NewClient creates the state for a new connection. Every connection starts speaking
+// RESP2 until it negotiates a different version with HELLO.
+func NewClient() *Client {
+	return &Client{
+		id:              lastClientID.Add(1),
+		protocolVersion: protocol.RESP2,
+		channels:        make(map[string]struct{}),
+		patterns:        make(map[string]struct{}),
+		shardChannels:   make(map[string]struct{}),
+		output:          newOutput(),
+		watched:         make(map[string]datastore.KeyVersion),
+	}
+}
+
+func (c *Client) ID() int64 {
+	return c.id
+}
+
+func (c *Client) Name() string {
+	return c.name
+}
+
+func (c *Client) ProtocolVersion() int {
+	return c.protocolVersion
+}
+
+// subscriptions returns the number of channels, patterns and sharded channels the client
+// is subscribed to. A client with any subscription is in subscribed mode.
+func (c *Client) subscriptions() int {
+	return len(c.channels) + len(c.patterns) + len(c.shardChannels)
+}
+
+// FreeClient releases everything held by a client whose connection was closed, like its
+// subscriptions and watched keys, and closes its output. It must be called from the
+// task loop.
+func FreeClient(client *Client) {
+	client.freed = true
+	unsubscribeAll(client)
+	unwatch(client)
+	if blocked, ok := blockedClients[client]; ok {
+		unblockClient(blocked)
+	}
+	client.output.close()
+}
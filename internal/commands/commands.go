@@ -7,26 +7,59 @@ package commands
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
-var registeredCommands map[string]command
+const (
+	notIntegerErrMsg  string = "value is not an integer or out of range"
+	notPositiveErrMsg string = "value is out of range, must be positive"
+	// randomCountErrMsg is returned for a negative count of random elements over
+	// maxRandomCount, the number of elements returned.
+	randomCountErrMsg string = "value is out of range, must be at least -%d"
+
+	subscribedModeErrMsg string = "Can't execute '%s': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING are allowed in this context"
+)
+
+// maxRandomCount is the most elements a negative count of HRANDFIELD or SRANDMEMBER can
+// return. Those elements may repeat, so the count isn't bounded by the size of the key.
+const maxRandomCount = 1 << 20
+
+// The registries are initialized on declaration since package variables are initialized
+// before any init function, which is where the commands are registered.
+var (
+	registeredCommands       = make(map[string]command)
+	registeredClientCommands = make(map[string]clientCommand)
+	// subscribedModeCommands are the commands a RESP2 client in subscribed mode can run
+	subscribedModeCommands = map[string]bool{
+		"subscribe":    true,
+		"psubscribe":   true,
+		"unsubscribe":  true,
+		"punsubscribe": true,
+		"ssubscribe":   true,
+		"sunsubscribe": true,
+		"ping":         true,
+	}
+)
+
+// errorReply converts an error returned by the datastore to an error reply. Every
+// command accessing a key of a given type goes through it, so reading a key holding
+// another type always results in the same WRONGTYPE error.
+func errorReply(err error) protocol.Error {
+	return protocol.NewError(err.Error())
+}
 
 type command interface {
 	getName() string
 	processArguments(data protocol.Array) protocol.DataType
 }
 
-func init() {
-	registeredCommands = make(map[string]command)
-}
-
 // ParseCommand parses byte slice buffer input and calls the ParseFrame function to
 // determine if it received a full command. If it did it will process the command returning
-// a Error object if the command is invalid. It always returns the number of processed
-// bytes or -1 if the buffer input doesn't contain a full command.
+// a Error object if the command is invalid, or nil if it's empty. It always returns the
+// number of processed bytes or -1 if the buffer input doesn't contain a full command.
 func ParseCommand(buffer []byte) (protocol.ValidRead, error) {
 	validRead, err := protocol.ParseFrame(buffer)
 	if err != nil {
@@ -34,22 +67,23 @@ func ParseCommand(buffer []byte) (protocol.ValidRead, error) {
 	}
 	data, size := validRead.Unwrap()
 	if size == -1 {
-		return protocol.ValidRead{data, -1}, nil
+		return protocol.ValidRead{Data: data, BytesRead: -1}, nil
 	}
 	switch data.(type) {
 	case protocol.Array:
 		elements := data.(protocol.Array).GetElements()
 		if len(elements) < 1 {
-			return protocol.ValidRead{protocol.NewError("command not informed"), size}, nil
+			// Like Redis, empty commands, such as empty inline lines, are skipped
+			return protocol.ValidRead{Data: nil, BytesRead: size}, nil
 		}
 		switch elements[0].(type) {
 		case protocol.BulkString:
-			return protocol.ValidRead{data.(protocol.Array), size}, nil
+			return protocol.ValidRead{Data: data.(protocol.Array), BytesRead: size}, nil
 		default:
-			return protocol.ValidRead{protocol.NewError(fmt.Sprintf("invalid command of type %T. Commands must be of BulkString type", data)), size}, nil
+			return protocol.ValidRead{Data: protocol.NewError(fmt.Sprintf("invalid command of type %T. Commands must be of BulkString type", data)), BytesRead: size}, nil
 		}
 	default:
-		return protocol.ValidRead{protocol.NewError(fmt.Sprintf("invalid input of type %T. Expected an Array", data)), size}, nil
+		return protocol.ValidRead{Data: protocol.NewError(fmt.Sprintf("invalid input of type %T. Expected an Array", data)), BytesRead: size}, nil
 	}
 }
 
@@ -59,6 +93,50 @@ func registerCommand(cmd command) {
 	registeredCommands[strings.ToLower(cmd.getName())] = cmd
 }
 
+func registerClientCommand(cmd clientCommand) {
+	registeredClientCommands[strings.ToLower(cmd.getName())] = cmd
+}
+
+// ProcessClientCommand processes a command sent through a client connection. Commands
+// that depend on the connection state get access to the client, while every other
+// command goes through ProcessCommand. The reply is converted to the protocol version
+// negotiated by the client. A nil reply means the command blocked the client, and the
+// reply will be returned later by ServeBlockedClients.
+//
+// A RESP2 client in subscribed mode can only run the commands that change its
+// subscriptions and PING, since its connection is used for the messages. A client in a
+// transaction has its commands queued until EXEC.
+func ProcessClientCommand(client *Client, data protocol.Array) protocol.DataType {
+	name := strings.ToLower(data.GetElements()[0].String())
+	var response protocol.DataType
+	switch {
+	case client.protocolVersion == protocol.RESP2 && client.subscriptions() > 0 && !subscribedModeCommands[name]:
+		response = protocol.NewError(fmt.Sprintf(subscribedModeErrMsg, name))
+	case client.multi != nil && !transactionCommands[name]:
+		response = queueCommand(client, name, data)
+	default:
+		response = dispatchCommand(client, data)
+	}
+	if frames, ok := response.(replies); ok {
+		converted := make(replies, len(frames))
+		for i, frame := range frames {
+			converted[i] = protocol.Convert(frame, client.protocolVersion)
+		}
+		return converted
+	}
+	return protocol.Convert(response, client.ProtocolVersion())
+}
+
+// dispatchCommand runs the command, giving it access to the client if it depends on the
+// connection state.
+func dispatchCommand(client *Client, data protocol.Array) protocol.DataType {
+	name := strings.ToLower(data.GetElements()[0].String())
+	if operation, ok := registeredClientCommands[name]; ok {
+		return operation.processClientArguments(client, data)
+	}
+	return ProcessCommand(data)
+}
+
 func ProcessCommand(data protocol.Array) protocol.DataType {
 	command := data.GetElements()[0]
 	name := strings.ToLower(command.String())
@@ -66,6 +144,24 @@ func ProcessCommand(data protocol.Array) protocol.DataType {
 	if ok {
 		return operation.processArguments(data)
 	}
+	if _, ok := registeredClientCommands[name]; ok {
+		return protocol.NewError(fmt.Sprintf("the %s command requires a client connection", strings.ToUpper(name)))
+	}
 	fmt.Printf("invalid command %s", command.String())
 	return protocol.NewError(fmt.Sprintf("invalid command %s", command.String()))
 }
+
+// parseInt parses an integer argument, like an index or a count.
+func parseInt(argument protocol.DataType) (int, bool) {
+	value, err := strconv.Atoi(argument.String())
+	return value, err == nil
+}
+
+// bulkStrings converts raw values, like the entries of a list, to an Array of BulkStrings.
+func bulkStrings(values [][]byte) protocol.Array {
+	elements := make([]protocol.DataType, 0, len(values))
+	for _, value := range values {
+		elements = append(elements, protocol.NewBulkString(value))
+	}
+	return protocol.NewArray(elements...)
+}
@@ -16,11 +16,17 @@ type commandTestCase struct {
 func TestCommandParsing(t *testing.T) {
 	ctc := []commandTestCase{
 		{"Invalid command", "*3\r\n$3\r\nbuy\r\n$3\r\nkey\r\n$3\r\nval\r\n", protocol.NewError("invalid command buy"), 31},
+		{"Missing key", "*2\r\n$3\r\nGET\r\n$7\r\nmissing\r\n", protocol.NewNullBulkString(), 26},
 		{"Invalid GET arguments", "*3\r\n$3\r\nGET\r\n$3\r\nkey\r\n$4\r\nabcd\r\n", protocol.NewError("the GET command accepts 2 parameters: GET and KEY. Received 3 parameters instead"), 32},
+		{"Inline command", "GET key abcd\r\n", protocol.NewError("the GET command accepts 2 parameters: GET and KEY. Received 3 parameters instead"), 14},
 	}
 	for _, tc := range ctc {
 		t.Run(tc.name, func(t *testing.T) {
-			arguments, length := ParseCommand([]byte(tc.input))
+			validRead, err := ParseCommand([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			arguments, length := validRead.Unwrap()
 			actual := ProcessCommand(arguments.(protocol.Array))
 			if actual != tc.expected {
 				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
@@ -31,3 +37,19 @@ func TestCommandParsing(t *testing.T) {
 		})
 	}
 }
+
+// parseInline parses a command written in the inline format, like "SET key value".
+func parseInline(t *testing.T, input string) protocol.Array {
+	t.Helper()
+	validRead, err := ParseCommand([]byte(input + "\r\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	arguments, _ := validRead.Unwrap()
+	return arguments.(protocol.Array)
+}
+
+func processInline(t *testing.T, input string) protocol.DataType {
+	t.Helper()
+	return ProcessCommand(parseInline(t, input))
+}
@@ -29,7 +29,11 @@ func TestDelInvalid(t *testing.T) {
 	}
 	for _, tc := range dtcs {
 		t.Run(tc.name, func(t *testing.T) {
-			arguments, _ := ParseCommand([]byte(tc.input))
+			validRead, err := ParseCommand([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			arguments, _ := validRead.Unwrap()
 			actual := ProcessCommand(arguments.(protocol.Array))
 			if actual != tc.expected {
 				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
@@ -81,7 +85,11 @@ func TestDelValid(t *testing.T) {
 			for _, cmd := range tc.setupCmds {
 				ProcessCommand(cmd)
 			}
-			arguments, _ := ParseCommand([]byte(tc.input))
+			validRead, err := ParseCommand([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			arguments, _ := validRead.Unwrap()
 			actual := ProcessCommand(arguments.(protocol.Array))
 			if actual != tc.expected {
 				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
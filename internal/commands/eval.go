@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	evalInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s numkeys [key [key ...]] [arg [arg ...]]"
+	evalNegativeKeysErrMsg  string = "Number of keys can't be negative"
+	evalTooManyKeysErrMsg   string = "Number of keys can't be greater than number of args"
+	noScriptErrMsg          string = "NOSCRIPT No matching script. Please use EVAL."
+)
+
+func init() {
+	registerClientCommand(evalCommand{name: "eval"})
+	registerClientCommand(evalCommand{name: "evalsha", sha: true})
+}
+
+// evalCommand implements EVAL, which runs a script given its source, and EVALSHA, which
+// runs a script already cached given the SHA1 digest of its source. The script gets the
+// keys in the KEYS table and the other arguments in the ARGV table, and can call
+// commands with redis.call. Scripts run in the task loop, so no other command runs
+// while they do.
+type evalCommand struct {
+	name string
+	sha  bool
+}
+
+func (e evalCommand) getName() string {
+	return e.name
+}
+
+func (e evalCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		syntax := "EVAL script"
+		if e.sha {
+			syntax = "EVALSHA sha1"
+		}
+		return protocol.NewError(fmt.Sprintf(evalInvalidLengthErrMsg, strings.ToUpper(e.name), syntax))
+	}
+	keys, args, errReply := scriptKeysAndArgs(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	var s *script
+	if e.sha {
+		var ok bool
+		if s, ok = scripts[strings.ToLower(elements[1].String())]; !ok {
+			return protocol.NewError(noScriptErrMsg)
+		}
+	} else if s, errReply = loadScript(elements[1].String()); errReply != nil {
+		return errReply
+	}
+	return runScript(client, s, keys, args)
+}
+
+// scriptKeysAndArgs splits the arguments of a script, starting with the number of keys,
+// in the keys and the other arguments.
+func scriptKeysAndArgs(arguments []protocol.DataType) ([]protocol.DataType, []protocol.DataType, protocol.DataType) {
+	numKeys, ok := parseInt(arguments[0])
+	if !ok {
+		return nil, nil, protocol.NewError(notIntegerErrMsg)
+	}
+	if numKeys < 0 {
+		return nil, nil, protocol.NewError(evalNegativeKeysErrMsg)
+	}
+	arguments = arguments[1:]
+	if numKeys > len(arguments) {
+		return nil, nil, protocol.NewError(evalTooManyKeysErrMsg)
+	}
+	return arguments[:numKeys], arguments[numKeys:], nil
+}
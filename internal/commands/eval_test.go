@@ -0,0 +1,214 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type evalTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestScripting(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	global := "return x"
+	loaded := "return 'loaded'"
+	etcs := []evalTestCase{
+		{
+			name:     "EVAL with KEYS and ARGV",
+			input:    `EVAL "return {KEYS[1], ARGV[1], #KEYS, #ARGV}" 1 eval-key a b`,
+			expected: protocol.NewArray(bulkString("eval-key"), bulkString("a"), protocol.NewInteger(1), protocol.NewInteger(2)),
+		},
+		{
+			name:      "redis.call",
+			setupCmds: []string{"SET eval-get value"},
+			input:     `EVAL "return redis.call('GET', KEYS[1])" 1 eval-get`,
+			expected:  bulkString("value"),
+		},
+		{
+			name:     "redis.call writes",
+			input:    `EVAL "redis.call('SET', KEYS[1], ARGV[1]) return redis.call('INCRBY', KEYS[1], 2)" 1 eval-incr 5`,
+			expected: protocol.NewInteger(7),
+		},
+		{
+			name:     "Script values to replies",
+			input:    `EVAL "return {1, 2.9, 'x', true, false, nil, 'after'}" 0`,
+			expected: protocol.NewArray(protocol.NewInteger(1), protocol.NewInteger(2), bulkString("x"), protocol.NewInteger(1), protocol.NewNullBulkString()),
+		},
+		{
+			name:     "Null replies are false",
+			input:    `EVAL "return redis.call('GET', KEYS[1]) == false" 1 eval-missing`,
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "Arrays are tables",
+			setupCmds: []string{"RPUSH eval-list a b c"},
+			input:     `EVAL "local t = redis.call('LRANGE', KEYS[1], 0, -1) return #t .. t[3]" 1 eval-list`,
+			expected:  bulkString("3c"),
+		},
+		{
+			name:     "Status replies",
+			input:    `EVAL "return redis.call('SET', KEYS[1], 'v')" 1 eval-status`,
+			expected: protocol.NewSimpleString("OK"),
+		},
+		{
+			name:     "redis.status_reply and redis.error_reply",
+			input:    `EVAL "if ARGV[1] == 'ok' then return redis.status_reply('FINE') end return redis.error_reply('MYERR failed')" 0 error`,
+			expected: protocol.NewError("MYERR failed"),
+		},
+		{
+			name:      "redis.call raises errors",
+			setupCmds: []string{"SET eval-wrongtype v"},
+			input:     `EVAL "redis.call('LPUSH', KEYS[1], 'a') return 1" 1 eval-wrongtype`,
+			expected:  wrongType,
+		},
+		{
+			name:      "redis.pcall returns errors",
+			setupCmds: []string{"SET eval-pcall v"},
+			input:     `EVAL "local reply = redis.pcall('LPUSH', KEYS[1], 'a') return reply.err" 1 eval-pcall`,
+			expected:  bulkString(datastore.ErrWrongType.Error()),
+		},
+		{
+			name:     "Blocking commands don't block",
+			input:    `EVAL "return redis.call('BLPOP', KEYS[1], 0)" 1 eval-blocking`,
+			expected: protocol.NewNullBulkString(),
+		},
+		{
+			name:     "Commands not allowed from scripts",
+			input:    `EVAL "return redis.call('MULTI')" 0`,
+			expected: protocol.NewError(scriptDeniedErrMsg),
+		},
+		{
+			name:     "Unknown commands",
+			input:    `EVAL "return redis.call('NOPE')" 0`,
+			expected: protocol.NewError(scriptUnknownErrMsg),
+		},
+		{
+			name:     "Wrong number of arguments",
+			input:    `EVAL "return redis.call('GET')" 0`,
+			expected: protocol.NewError(scriptArityErrMsg),
+		},
+		{
+			name:     "Redis Lua libraries",
+			input:    `EVAL "return cjson.encode({bit.bor(1, 2), KEYS[1], #cmsgpack.pack(ARGV[1]), struct.size('>i')})" 1 eval-lib a`,
+			expected: bulkString(`[3,"eval-lib",2,4]`),
+		},
+		{
+			name:     "Globals are protected",
+			input:    fmt.Sprintf("EVAL %q 0", global),
+			expected: protocol.NewError(fmt.Sprintf(scriptRunErrMsg, "user_script:1: Script attempted to access nonexistent global variable 'x'", scriptSHA(global))),
+		},
+		{
+			name:     "Compile errors",
+			input:    `EVAL "return +" 0`,
+			expected: protocol.NewError(fmt.Sprintf(scriptCompileErrMsg, "user_script:1: syntax error near '+'")),
+		},
+		{
+			name:     "Negative number of keys",
+			input:    `EVAL "return 1" -1`,
+			expected: protocol.NewError(evalNegativeKeysErrMsg),
+		},
+		{
+			name:     "Too many keys",
+			input:    `EVAL "return 1" 2 a`,
+			expected: protocol.NewError(evalTooManyKeysErrMsg),
+		},
+		{
+			name:      "EVALSHA",
+			setupCmds: []string{fmt.Sprintf("SCRIPT LOAD %q", loaded)},
+			input:     "EVALSHA " + scriptSHA(loaded) + " 0",
+			expected:  bulkString("loaded"),
+		},
+		{
+			name:     "EVALSHA with a missing script",
+			input:    "EVALSHA ffffffffffffffffffffffffffffffffffffffff 0",
+			expected: protocol.NewError(noScriptErrMsg),
+		},
+		{
+			name:      "SCRIPT EXISTS",
+			setupCmds: []string{fmt.Sprintf("SCRIPT LOAD %q", loaded)},
+			input:     "SCRIPT EXISTS " + scriptSHA(loaded) + " ffffffffffffffffffffffffffffffffffffffff",
+			expected:  protocol.NewArray(protocol.NewInteger(1), protocol.NewInteger(0)),
+		},
+		{
+			name:     "SCRIPT KILL without a script",
+			input:    "SCRIPT KILL",
+			expected: protocol.NewError(scriptNotBusyErrMsg),
+		},
+	}
+	for _, tc := range etcs {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient()
+			for _, cmd := range tc.setupCmds {
+				processClientInline(t, client, cmd)
+			}
+			actual := processClientInline(t, client, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestScriptFlush(t *testing.T) {
+	client := NewClient()
+	sha := processClientInline(t, client, `SCRIPT LOAD "return 1"`).String()
+	processClientInline(t, client, "SCRIPT FLUSH")
+	if reply := processClientInline(t, client, "EVALSHA "+sha+" 0"); reply != protocol.NewError(noScriptErrMsg) {
+		t.Fatalf("the script should have been flushed, got %v", reply)
+	}
+}
+
+func TestScriptKill(t *testing.T) {
+	defer func(limit time.Duration) { scriptTimeLimit = limit }(scriptTimeLimit)
+	scriptTimeLimit = time.Millisecond
+	result := make(chan protocol.DataType)
+	data := parseInline(t, `EVAL "while true do end" 0`)
+	go func() {
+		result <- ProcessClientCommand(NewClient(), data)
+	}()
+	// Other clients get a BUSY error once the script runs past the time limit
+	deadline := time.Now().Add(5 * time.Second)
+	for ProcessBusyCommand(parseInline(t, "GET script-kill")) == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("the script should be busy")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if reply := ProcessBusyCommand(parseInline(t, "GET script-kill")); reply != protocol.NewError(scriptBusyErrMsg) {
+		t.Fatalf("unexpected reply while busy: %v", reply)
+	}
+	if reply := ProcessBusyCommand(parseInline(t, "SCRIPT KILL")); reply != protocol.NewSimpleString("OK") {
+		t.Fatalf("unexpected reply to SCRIPT KILL: %v", reply)
+	}
+	if reply := <-result; reply != protocol.NewError(scriptKilledErrMsg) {
+		t.Fatalf("the script should have been killed, got %v", reply)
+	}
+	if reply := ProcessBusyCommand(parseInline(t, "GET script-kill")); reply != nil {
+		t.Fatalf("no script should be busy, got %v", reply)
+	}
+
+	// A kill of a script that already finished doesn't stop the next one
+	scriptKill.Store(scriptRuns.Load())
+	defer scriptKill.Store(0)
+	if reply := processClientInline(t, NewClient(), `EVAL "local n = 0 for i = 1, 200000 do n = n + 1 end return n" 0`); reply != protocol.NewInteger(200000) {
+		t.Fatalf("the script shouldn't have been killed, got %v", reply)
+	}
+
+	// Scripts that wrote can't be killed
+	scriptBusy.Store(1)
+	scriptWrote.Store(true)
+	defer scriptBusy.Store(0)
+	defer scriptWrote.Store(false)
+	if reply := ProcessBusyCommand(parseInline(t, "SCRIPT KILL")); reply != protocol.NewError(scriptUnkillableErrMsg) {
+		t.Fatalf("unexpected reply to SCRIPT KILL: %v", reply)
+	}
+}
@@ -32,7 +32,7 @@ func (e existsCommand) processArguments(data protocol.Array) protocol.DataType {
 			return protocol.NewError(fmt.Sprintf("the KEY parameter for the EXISTS command must be a BulkString. Received a %T instead", element))
 
 		}
-		if _, ok := datastore.Get(key.String()); ok {
+		if datastore.Exists(key.String()) {
 			sum++
 		}
 	}
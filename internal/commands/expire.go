@@ -2,20 +2,41 @@ package commands
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/mhsantos/redis-server/internal/datastore"
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
+const (
+	expireInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key %s [NX | XX | GT | LT]"
+	expireInvalidTimeErrMsg   string = "invalid expire time in '%s' command"
+	expireNXCompatErrMsg      string = "NX and XX, GT or LT options at the same time are not compatible"
+	expireGTLTCompatErrMsg    string = "GT and LT options at the same time are not compatible"
+	expireInvalidOptionErrMsg string = "unsupported option %s"
+)
+
 func init() {
-	expire := expireCommand{"expire"}
-	registerCommand(expire)
+	registerCommand(expireCommand{name: "expire", unit: time.Second})
+	registerCommand(expireCommand{name: "pexpire", unit: time.Millisecond})
+	registerCommand(expireCommand{name: "expireat", unit: time.Second, absolute: true})
+	registerCommand(expireCommand{name: "pexpireat", unit: time.Millisecond, absolute: true})
 }
 
+// expireCommand implements EXPIRE, PEXPIRE, EXPIREAT and PEXPIREAT. The unit is the
+// unit of the time argument and absolute tells if the argument is a Unix time instead
+// of a time to live.
 type expireCommand struct {
-	name string
+	name     string
+	unit     time.Duration
+	absolute bool
+}
+
+type expireOptions struct {
+	nx, xx, gt, lt bool
 }
 
 func (e expireCommand) getName() string {
@@ -24,52 +45,108 @@ func (e expireCommand) getName() string {
 
 func (e expireCommand) processArguments(data protocol.Array) protocol.DataType {
 	elements := data.GetElements()
-	if len(elements) < 3 || len(elements) > 4 {
-		return protocol.NewError(fmt.Sprintf("invalid number of arguments: %d\n", len(elements)))
+	if len(elements) < 3 {
+		return protocol.NewError(e.syntaxError())
 	}
 	key := elements[1].String()
-	seconds, err := strconv.Atoi(elements[2].String())
+	when, err := strconv.ParseInt(elements[2].String(), 10, 64)
 	if err != nil {
-		return protocol.NewError("seconds argument must be a positive number")
+		return protocol.NewError(notIntegerErrMsg)
 	}
-	if seconds < 0 {
-		datastore.Delete(key)
-		return protocol.NewInteger(0)
+	options, errReply := parseExpireOptions(elements[3:])
+	if errReply != nil {
+		return errReply
 	}
-	existing, currentExpire, ok := datastore.GetWithExpire(key)
+	newExpire, ok := e.expireAt(when)
 	if !ok {
+		return protocol.NewError(fmt.Sprintf(expireInvalidTimeErrMsg, e.name))
+	}
+
+	currentExpire, ok := datastore.GetExpire(key)
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	if !options.allow(currentExpire, newExpire) {
 		return protocol.NewInteger(0)
 	}
-	newExpire := time.Now().Add(time.Duration(seconds) * time.Second).Unix()
-	if len(elements) == 3 {
-		datastore.SetWithExpire(key, existing, newExpire)
+	if newExpire <= time.Now().UnixMilli() {
+		// Setting an expiration in the past deletes the key right away
+		datastore.Delete(key)
 		return protocol.NewInteger(1)
 	}
-	// The only remaining option is 4 arguments
-	option := elements[3].String()
-	switch option {
-	case "NX":
-		if currentExpire == 0 {
-			datastore.SetWithExpire(key, existing, newExpire)
-			return protocol.NewInteger(1)
-		}
-	case "XX":
-		if currentExpire > 0 {
-			datastore.SetWithExpire(key, existing, newExpire)
-			return protocol.NewInteger(1)
-		}
-	case "GT":
-		if currentExpire > 0 && newExpire > currentExpire {
-			datastore.SetWithExpire(key, existing, newExpire)
-			return protocol.NewInteger(1)
-		}
-	case "LT":
-		if currentExpire > 0 && newExpire < currentExpire {
-			datastore.SetWithExpire(key, existing, newExpire)
-			return protocol.NewInteger(1)
+	datastore.SetExpire(key, newExpire)
+	return protocol.NewInteger(1)
+}
+
+func (e expireCommand) syntaxError() string {
+	argument := "seconds"
+	switch {
+	case e.absolute && e.unit == time.Second:
+		argument = "unix-time-seconds"
+	case e.absolute:
+		argument = "unix-time-milliseconds"
+	case e.unit == time.Millisecond:
+		argument = "milliseconds"
+	}
+	name := strings.ToUpper(e.name)
+	return fmt.Sprintf(expireInvalidLengthErrMsg, name, name, argument)
+}
+
+// expireAt converts the time argument to the Unix time in milliseconds the key expires
+// at. It returns false if the result would overflow.
+func (e expireCommand) expireAt(when int64) (int64, bool) {
+	multiplier := int64(e.unit / time.Millisecond)
+	if when > math.MaxInt64/multiplier || when < math.MinInt64/multiplier {
+		return 0, false
+	}
+	milliseconds := when * multiplier
+	if e.absolute {
+		return milliseconds, true
+	}
+	now := time.Now().UnixMilli()
+	if milliseconds > math.MaxInt64-now {
+		return 0, false
+	}
+	return now + milliseconds, true
+}
+
+func parseExpireOptions(arguments []protocol.DataType) (expireOptions, protocol.DataType) {
+	options := expireOptions{}
+	for _, argument := range arguments {
+		switch strings.ToUpper(argument.String()) {
+		case "NX":
+			options.nx = true
+		case "XX":
+			options.xx = true
+		case "GT":
+			options.gt = true
+		case "LT":
+			options.lt = true
+		default:
+			return options, protocol.NewError(fmt.Sprintf(expireInvalidOptionErrMsg, argument.String()))
 		}
-	default:
-		return protocol.NewError(fmt.Sprintf("invalid option %s\n", option))
 	}
-	return protocol.NewInteger(0)
+	if options.nx && (options.xx || options.gt || options.lt) {
+		return options, protocol.NewError(expireNXCompatErrMsg)
+	}
+	if options.gt && options.lt {
+		return options, protocol.NewError(expireGTLTCompatErrMsg)
+	}
+	return options, nil
+}
+
+// allow tells if the new expiration can be set given the options. Like Redis, a key
+// without an expiration is considered to have an infinite time to live by GT and LT.
+func (o expireOptions) allow(currentExpire, newExpire int64) bool {
+	switch {
+	case o.nx && currentExpire != 0:
+		return false
+	case o.xx && currentExpire == 0:
+		return false
+	case o.gt && (currentExpire == 0 || newExpire <= currentExpire):
+		return false
+	case o.lt && currentExpire != 0 && newExpire >= currentExpire:
+		return false
+	}
+	return true
 }
@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type expireTestCase struct {
+	name        string
+	setupCmds   []string
+	input       string
+	expected    any
+	ttlExpected int
+}
+
+func TestExpire(t *testing.T) {
+	etcs := []expireTestCase{
+		{
+			name:        "Missing key",
+			input:       "EXPIRE expire-missing 10",
+			expected:    protocol.NewInteger(0),
+			ttlExpected: -2,
+		},
+		{
+			name:        "EXPIRE",
+			setupCmds:   []string{"SET expire value"},
+			input:       "EXPIRE expire 10",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: 10,
+		},
+		{
+			name:        "PEXPIRE",
+			setupCmds:   []string{"SET pexpire value"},
+			input:       "PEXPIRE pexpire 20000",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: 20,
+		},
+		{
+			name:        "EXPIREAT",
+			setupCmds:   []string{"SET expireat value"},
+			input:       fmt.Sprintf("EXPIREAT expireat %d", time.Now().Add(30*time.Second+500*time.Millisecond).Unix()),
+			expected:    protocol.NewInteger(1),
+			ttlExpected: 30,
+		},
+		{
+			name:        "Time in the past deletes the key",
+			setupCmds:   []string{"SET expire-past value"},
+			input:       "EXPIRE expire-past -1",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: -2,
+		},
+		{
+			name:        "NX with an existing ttl",
+			setupCmds:   []string{"SET expire-nx value EX 100"},
+			input:       "EXPIRE expire-nx 10 NX",
+			expected:    protocol.NewInteger(0),
+			ttlExpected: 100,
+		},
+		{
+			name:        "XX without ttl",
+			setupCmds:   []string{"SET expire-xx value"},
+			input:       "EXPIRE expire-xx 10 XX",
+			expected:    protocol.NewInteger(0),
+			ttlExpected: -1,
+		},
+		{
+			name:        "GT without ttl",
+			setupCmds:   []string{"SET expire-gt value"},
+			input:       "EXPIRE expire-gt 10 GT",
+			expected:    protocol.NewInteger(0),
+			ttlExpected: -1,
+		},
+		{
+			name:        "GT with a smaller ttl",
+			setupCmds:   []string{"SET expire-gt-smaller value EX 5"},
+			input:       "EXPIRE expire-gt-smaller 10 GT",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: 10,
+		},
+		{
+			name:        "LT without ttl",
+			setupCmds:   []string{"SET expire-lt value"},
+			input:       "EXPIRE expire-lt 10 LT",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: 10,
+		},
+		{
+			name:        "NX and GT",
+			setupCmds:   []string{"SET expire-nx-gt value"},
+			input:       "EXPIRE expire-nx-gt 10 NX GT",
+			expected:    protocol.NewError(expireNXCompatErrMsg),
+			ttlExpected: -1,
+		},
+		{
+			name:        "PERSIST",
+			setupCmds:   []string{"SET expire-persist value EX 100"},
+			input:       "PERSIST expire-persist",
+			expected:    protocol.NewInteger(1),
+			ttlExpected: -1,
+		},
+		{
+			name:        "PERSIST without ttl",
+			setupCmds:   []string{"SET expire-persist-nottl value"},
+			input:       "PERSIST expire-persist-nottl",
+			expected:    protocol.NewInteger(0),
+			ttlExpected: -1,
+		},
+	}
+	for _, tc := range etcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if actual != tc.expected {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+			key := parseInline(t, tc.input).GetElements()[1].String()
+			ttl := processInline(t, "TTL "+key)
+			if ttl != protocol.NewInteger(tc.ttlExpected) {
+				t.Fatalf("unexpected ttl. Expected: %d, Actual: %v", tc.ttlExpected, ttl)
+			}
+		})
+	}
+}
+
+func TestMillisecondExpiration(t *testing.T) {
+	processInline(t, "SET short-lived value")
+	processInline(t, "PEXPIRE short-lived 50")
+	pttl := processInline(t, "PTTL short-lived").(protocol.Integer)
+	if ttl := pttl.String(); ttl == "-1" || ttl == "-2" || len(ttl) > 2 {
+		t.Fatalf("unexpected pttl %s", ttl)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if actual := processInline(t, "GET short-lived"); actual != protocol.NewNullBulkString() {
+		t.Fatalf("key should have expired. Actual: %v", actual)
+	}
+}
+
+func TestExpireTime(t *testing.T) {
+	at := time.Now().Add(time.Hour).UnixMilli()
+	processInline(t, fmt.Sprintf("SET expiretime value PXAT %d", at))
+	if actual := processInline(t, "PEXPIRETIME expiretime"); actual != protocol.NewInteger(int(at)) {
+		t.Fatalf("unexpected pexpiretime. Expected: %d, Actual: %v", at, actual)
+	}
+	if actual := processInline(t, "EXPIRETIME expiretime"); actual != protocol.NewInteger(int(at/1000)) {
+		t.Fatalf("unexpected expiretime. Expected: %d, Actual: %v", at/1000, actual)
+	}
+	if actual := processInline(t, "EXPIRETIME expiretime-missing"); actual != protocol.NewInteger(-2) {
+		t.Fatalf("unexpected expiretime. Expected: -2, Actual: %v", actual)
+	}
+}
+
+// infoField returns the value of the field in the reply to INFO with the arguments.
+func infoField(t *testing.T, arguments, field string) string {
+	t.Helper()
+	for _, line := range strings.Split(processInline(t, "INFO "+arguments).String(), "\r\n") {
+		if value, ok := strings.CutPrefix(line, field+":"); ok {
+			return value
+		}
+	}
+	t.Fatalf("missing field %s in INFO %s", field, arguments)
+	return ""
+}
+
+func TestExpireStatsInfo(t *testing.T) {
+	before, _ := strconv.Atoi(infoField(t, "stats", "expired_keys"))
+	processInline(t, "SET info-expired value PX 1")
+	time.Sleep(5 * time.Millisecond)
+	datastore.ActiveExpireCycle(time.Second)
+	after, _ := strconv.Atoi(infoField(t, "", "expired_keys"))
+	if after <= before {
+		t.Fatalf("the expired key should be counted. Before: %d, after: %d", before, after)
+	}
+	if cpu := infoField(t, "everything", "expire_cycle_cpu_milliseconds"); cpu == "" {
+		t.Fatalf("missing expire cycle cpu time")
+	}
+	if reply := processInline(t, "INFO unknown").String(); reply != "" {
+		t.Fatalf("unknown sections should be ignored, got %q", reply)
+	}
+	if reply := processInline(t, "INFO STATS").String(); !strings.HasPrefix(reply, "# Stats\r\n") {
+		t.Fatalf("unexpected stats section %q", reply)
+	}
+}
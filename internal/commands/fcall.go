@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	fcallInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s function numkeys [key [key ...]] [arg [arg ...]]"
+	functionNotFoundErrMsg   string = "Function not found"
+	fcallReadOnlyErrMsg      string = "Can not execute a script with write flag using *_ro command."
+)
+
+func init() {
+	registerClientCommand(fcallCommand{name: "fcall"})
+	registerClientCommand(fcallCommand{name: "fcall_ro", readOnly: true})
+}
+
+// fcallCommand implements FCALL, which calls a function of a library loaded with
+// FUNCTION LOAD, and FCALL_RO, which only calls functions registered with the
+// no-writes flag. The function gets the keys and the other arguments as two tables,
+// and calls commands with redis.call like the scripts run by EVAL. Functions with the
+// no-writes flag can't call commands that write.
+type fcallCommand struct {
+	name     string
+	readOnly bool
+}
+
+func (f fcallCommand) getName() string {
+	return f.name
+}
+
+func (f fcallCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		name := strings.ToUpper(f.name)
+		return protocol.NewError(fmt.Sprintf(fcallInvalidLengthErrMsg, name, name))
+	}
+	keys, args, errReply := scriptKeysAndArgs(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	function, ok := functionLibraries.functions[elements[1].String()]
+	if !ok {
+		return protocol.NewError(functionNotFoundErrMsg)
+	}
+	if f.readOnly && !function.readOnly() {
+		return protocol.NewError(fcallReadOnlyErrMsg)
+	}
+	// The redis library of the state is replaced, so the function calls commands as the
+	// client
+	L := function.library.state
+	L.SetGlobal("redis", redisLibrary(L, client, function.readOnly()))
+	values, err := runWithTimeLimit(client, L, function.callback, argumentsTable(L, keys), argumentsTable(L, args))
+	if err != nil {
+		return scriptErrorReply(err, function.name)
+	}
+	if len(values) == 0 {
+		return protocol.NewNullBulkString()
+	}
+	return scriptReply(values[0])
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	functionInvalidLengthErrMsg string = "invalid arguments for command FUNCTION. Syntax: FUNCTION <LOAD [REPLACE] code | DELETE library | LIST [LIBRARYNAME pattern] [WITHCODE] | DUMP | RESTORE payload [FLUSH | APPEND | REPLACE] | FLUSH [ASYNC | SYNC] | STATS | KILL>"
+	functionUnknownErrMsg       string = "unknown subcommand '%s'. Try FUNCTION HELP."
+	libraryNotFoundErrMsg       string = "Library not found"
+)
+
+func init() {
+	registerCommand(functionCommand{"function"})
+}
+
+// functionCommand manages the function libraries: LOAD loads a library from its code,
+// DELETE removes one, LIST describes them, DUMP serializes all of them and RESTORE loads
+// them back, FLUSH removes all of them, STATS counts them and KILL stops a busy function
+// that didn't write yet. Unlike the scripts cached by EVAL, functions are called by
+// name with FCALL.
+type functionCommand struct {
+	name string
+}
+
+func (f functionCommand) getName() string {
+	return f.name
+}
+
+func (f functionCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(functionInvalidLengthErrMsg)
+	}
+	arguments := elements[2:]
+	switch subcommand := elements[1].String(); strings.ToUpper(subcommand) {
+	case "LOAD":
+		return functionLoad(arguments)
+	case "DELETE":
+		if len(arguments) != 1 {
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+		if !functionLibraries.remove(arguments[0].String()) {
+			return protocol.NewError(libraryNotFoundErrMsg)
+		}
+		return protocol.NewSimpleString("OK")
+	case "LIST":
+		return functionList(arguments)
+	case "DUMP":
+		if len(arguments) != 0 {
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+		return protocol.NewBulkString(dumpLibraries(functionLibraries))
+	case "RESTORE":
+		return functionRestore(arguments)
+	case "FLUSH":
+		if len(arguments) > 1 || (len(arguments) == 1 && !isFlushMode(arguments[0].String())) {
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+		functionLibraries = newFunctionRegistry()
+		return protocol.NewSimpleString("OK")
+	case "STATS":
+		if len(arguments) != 0 {
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+		// Functions run in the task loop, so none is running when STATS runs
+		return protocol.NewMap(
+			bulkString("running_script"), protocol.NewNull(),
+			bulkString("engines"), protocol.NewMap(
+				bulkString("LUA"), protocol.NewMap(
+					bulkString("libraries_count"), protocol.NewInteger(len(functionLibraries.libraries)),
+					bulkString("functions_count"), protocol.NewInteger(len(functionLibraries.functions)),
+				),
+			),
+		)
+	case "KILL":
+		if len(arguments) != 0 {
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+		return killScript()
+	default:
+		return protocol.NewError(fmt.Sprintf(functionUnknownErrMsg, subcommand))
+	}
+}
+
+// functionLoad loads a library, replying with its name. A library with the same name is
+// only replaced with the REPLACE option.
+func functionLoad(arguments []protocol.DataType) protocol.DataType {
+	replace := false
+	if len(arguments) == 2 && strings.EqualFold(arguments[0].String(), "REPLACE") {
+		replace = true
+		arguments = arguments[1:]
+	}
+	if len(arguments) != 1 {
+		return protocol.NewError(functionInvalidLengthErrMsg)
+	}
+	lib, err := loadLibrary(arguments[0].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if err := functionLibraries.add(lib, replace); err != nil {
+		return errorReply(err)
+	}
+	return bulkString(lib.name)
+}
+
+// functionList describes the libraries whose name matches the pattern, with their code
+// if WITHCODE is given.
+func functionList(arguments []protocol.DataType) protocol.DataType {
+	pattern := "*"
+	withCode := false
+	for i := 0; i < len(arguments); i++ {
+		switch option := strings.ToUpper(arguments[i].String()); {
+		case option == "WITHCODE":
+			withCode = true
+		case option == "LIBRARYNAME" && i+1 < len(arguments):
+			pattern = arguments[i+1].String()
+			i++
+		default:
+			return protocol.NewError(functionInvalidLengthErrMsg)
+		}
+	}
+	reply := []protocol.DataType{}
+	for _, name := range functionLibraries.names() {
+		if !matchPattern(pattern, name) {
+			continue
+		}
+		lib := functionLibraries.libraries[name]
+		functions := []protocol.DataType{}
+		for _, function := range lib.sortedFunctions() {
+			var description protocol.DataType = protocol.NewNull()
+			if function.description != "" {
+				description = bulkString(function.description)
+			}
+			flags := []protocol.DataType{}
+			for _, flag := range function.flags {
+				flags = append(flags, bulkString(flag))
+			}
+			functions = append(functions, protocol.NewMap(
+				bulkString("name"), bulkString(function.name),
+				bulkString("description"), description,
+				bulkString("flags"), protocol.NewSet(flags...),
+			))
+		}
+		fields := []protocol.DataType{
+			bulkString("library_name"), bulkString(lib.name),
+			bulkString("engine"), bulkString("LUA"),
+			bulkString("functions"), protocol.NewArray(functions...),
+		}
+		if withCode {
+			fields = append(fields, bulkString("library_code"), bulkString(lib.code))
+		}
+		reply = append(reply, protocol.NewMap(fields...))
+	}
+	return protocol.NewArray(reply...)
+}
+
+// functionRestore loads the libraries of a payload created by FUNCTION DUMP. With the
+// default APPEND policy it fails if any of them already exists, with REPLACE it replaces
+// them and with FLUSH it removes all the libraries first. Either all the libraries are
+// loaded or none is.
+func functionRestore(arguments []protocol.DataType) protocol.DataType {
+	if len(arguments) < 1 || len(arguments) > 2 {
+		return protocol.NewError(functionInvalidLengthErrMsg)
+	}
+	policy := "APPEND"
+	if len(arguments) == 2 {
+		policy = strings.ToUpper(arguments[1].String())
+	}
+	var registry *functionRegistry
+	switch policy {
+	case "APPEND", "REPLACE":
+		registry = functionLibraries.clone()
+	case "FLUSH":
+		registry = newFunctionRegistry()
+	default:
+		return protocol.NewError(functionInvalidLengthErrMsg)
+	}
+	codes, err := parseDump([]byte(arguments[0].String()))
+	if err != nil {
+		return errorReply(err)
+	}
+	for _, code := range codes {
+		lib, err := loadLibrary(code)
+		if err != nil {
+			return errorReply(err)
+		}
+		if err := registry.add(lib, policy == "REPLACE"); err != nil {
+			return errorReply(err)
+		}
+	}
+	functionLibraries = registry
+	return protocol.NewSimpleString("OK")
+}
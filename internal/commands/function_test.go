@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type functionTestCase struct {
+	name     string
+	commands []string
+	expected []protocol.DataType
+}
+
+// loadCommand creates the FUNCTION LOAD command for the code of a library.
+func loadCommand(code string, options ...string) string {
+	command := "FUNCTION LOAD "
+	for _, option := range options {
+		command += option + " "
+	}
+	return command + fmt.Sprintf("%q", code)
+}
+
+func TestFunctions(t *testing.T) {
+	ok := protocol.NewSimpleString("OK")
+	lib := `#!lua name=fnlib
+redis.register_function('fn_get', function(keys, args) return redis.call('GET', keys[1]) end)
+redis.register_function{function_name = 'fn_set', callback = function(keys, args) return redis.call('SET', keys[1], args[1]) end}
+redis.register_function{function_name = 'fn_ro', callback = function(keys, args) return redis.call('SET', keys[1], 'x') end, flags = {'no-writes'}}`
+	listed := "#!lua name=fnlist\nredis.register_function{function_name = 'fn_listed', callback = function() return 1 end, flags = {'no-writes'}, description = 'listed'}"
+	ftcs := []functionTestCase{
+		{
+			name:     "FCALL",
+			commands: []string{loadCommand(lib), "FCALL fn_set 1 fn-key value", "FCALL fn_get 1 fn-key"},
+			expected: []protocol.DataType{bulkString("fnlib"), ok, bulkString("value")},
+		},
+		{
+			name:     "FCALL_RO",
+			commands: []string{"FCALL_RO fn_set 1 fn-key value", "FCALL_RO fn_ro 1 fn-key", "FCALL fn_ro 1 fn-key"},
+			expected: []protocol.DataType{protocol.NewError(fcallReadOnlyErrMsg), protocol.NewError(scriptReadOnlyErrMsg), protocol.NewError(scriptReadOnlyErrMsg)},
+		},
+		{
+			name:     "Functions share the locals of their library",
+			commands: []string{loadCommand("#!lua name=fnlocal\nlocal prefix = 'p:'\nredis.register_function('fn_local', function(keys) return prefix .. keys[1] end)"), "FCALL fn_local 1 k"},
+			expected: []protocol.DataType{bulkString("fnlocal"), bulkString("p:k")},
+		},
+		{
+			name:     "FUNCTION LOAD an existing library",
+			commands: []string{loadCommand(lib), loadCommand(lib, "REPLACE")},
+			expected: []protocol.DataType{protocol.NewError(fmt.Sprintf(libraryExistsErrMsg, "fnlib")), bulkString("fnlib")},
+		},
+		{
+			name:     "FCALL a missing function",
+			commands: []string{"FCALL fn_missing 0"},
+			expected: []protocol.DataType{protocol.NewError(functionNotFoundErrMsg)},
+		},
+		{
+			name:     "Library metadata",
+			commands: []string{loadCommand("return 1"), loadCommand("#!js name=fnjs\n"), loadCommand("#!lua\nreturn 1"), loadCommand("#!lua name=fn-lib\n")},
+			expected: []protocol.DataType{
+				protocol.NewError(libraryMetadataErrMsg),
+				protocol.NewError(fmt.Sprintf(libraryEngineErrMsg, "js")),
+				protocol.NewError(libraryNameMissingErrMsg),
+				protocol.NewError(libraryNameErrMsg),
+			},
+		},
+		{
+			name:     "Libraries must register functions",
+			commands: []string{loadCommand("#!lua name=fnempty\nlocal x = 1")},
+			expected: []protocol.DataType{protocol.NewError(libraryNoFunctionsErrMsg)},
+		},
+		{
+			name:     "Function names are unique",
+			commands: []string{loadCommand("#!lua name=fnother\nredis.register_function('fn_get', function() return 1 end)")},
+			expected: []protocol.DataType{protocol.NewError(fmt.Sprintf(functionExistsErrMsg, "fn_get"))},
+		},
+		{
+			name: "Registering errors",
+			commands: []string{
+				loadCommand("#!lua name=fnbad\nredis.register_function('fn-bad', function() return 1 end)"),
+				loadCommand("#!lua name=fnbad\nredis.call('SET', 'a', 'b')"),
+			},
+			expected: []protocol.DataType{
+				protocol.NewError(fmt.Sprintf(libraryRegisterErrMsg, "user_function:2: "+functionNameErrMsg)),
+				protocol.NewError(fmt.Sprintf(libraryRegisterErrMsg, "user_function:2: attempt to call a non-function object")),
+			},
+		},
+		{
+			name:     "FUNCTION DELETE",
+			commands: []string{loadCommand("#!lua name=fndel\nredis.register_function('fn_del', function() return 1 end)"), "FUNCTION DELETE fndel", "FCALL fn_del 0", "FUNCTION DELETE fndel"},
+			expected: []protocol.DataType{bulkString("fndel"), ok, protocol.NewError(functionNotFoundErrMsg), protocol.NewError(libraryNotFoundErrMsg)},
+		},
+		{
+			name:     "FUNCTION LIST",
+			commands: []string{loadCommand(listed), "FUNCTION LIST LIBRARYNAME fnlis* WITHCODE"},
+			expected: []protocol.DataType{bulkString("fnlist"), protocol.NewArray(protocol.NewMap(
+				bulkString("library_name"), bulkString("fnlist"),
+				bulkString("engine"), bulkString("LUA"),
+				bulkString("functions"), protocol.NewArray(protocol.NewMap(
+					bulkString("name"), bulkString("fn_listed"),
+					bulkString("description"), bulkString("listed"),
+					bulkString("flags"), protocol.NewSet(bulkString("no-writes")),
+				)),
+				bulkString("library_code"), bulkString(listed),
+			))},
+		},
+	}
+	for _, tc := range ftcs {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient()
+			client.protocolVersion = protocol.RESP3
+			for i, cmd := range tc.commands {
+				actual := processClientInline(t, client, cmd)
+				if !reflect.DeepEqual(actual, tc.expected[i]) {
+					t.Fatalf("unexpected reply to %s. Expected: %v, Actual: %v", cmd, tc.expected[i], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestFunctionDumpAndRestore(t *testing.T) {
+	ok := protocol.NewSimpleString("OK")
+	processInline(t, "FUNCTION FLUSH")
+	processInline(t, loadCommand("#!lua name=fndump\nredis.register_function('fn_dump', function() return 'restored' end)"))
+	payload := processInline(t, "FUNCTION DUMP").String()
+	restore := protocol.NewArray(bulkString("FUNCTION"), bulkString("RESTORE"), bulkString(payload))
+	restoreReplace := protocol.NewArray(bulkString("FUNCTION"), bulkString("RESTORE"), bulkString(payload), bulkString("REPLACE"))
+	if reply := ProcessCommand(restore); !reflect.DeepEqual(reply, protocol.NewError(fmt.Sprintf(libraryExistsErrMsg, "fndump"))) {
+		t.Fatalf("restoring an existing library should fail, got %v", reply)
+	}
+	if reply := ProcessCommand(restoreReplace); reply != ok {
+		t.Fatalf("unexpected reply to RESTORE with REPLACE: %v", reply)
+	}
+	processInline(t, "FUNCTION FLUSH")
+	if reply := ProcessCommand(restore); reply != ok {
+		t.Fatalf("unexpected reply to RESTORE: %v", reply)
+	}
+	if reply := processClientInline(t, NewClient(), "FCALL fn_dump 0"); !reflect.DeepEqual(reply, bulkString("restored")) {
+		t.Fatalf("unexpected reply from the restored function: %v", reply)
+	}
+	corrupted := protocol.NewArray(bulkString("FUNCTION"), bulkString("RESTORE"), bulkString("x"+payload[1:]))
+	if reply := ProcessCommand(corrupted); !reflect.DeepEqual(reply, protocol.NewError(errFunctionPayload.Error())) {
+		t.Fatalf("restoring a corrupted payload should fail, got %v", reply)
+	}
+	stats := protocol.NewMap(
+		bulkString("running_script"), protocol.NewNull(),
+		bulkString("engines"), protocol.NewMap(bulkString("LUA"), protocol.NewMap(
+			bulkString("libraries_count"), protocol.NewInteger(1),
+			bulkString("functions_count"), protocol.NewInteger(1),
+		)),
+	)
+	if reply := processInline(t, "FUNCTION STATS"); !reflect.DeepEqual(reply, stats) {
+		t.Fatalf("unexpected reply to FUNCTION STATS: %v", reply)
+	}
+}
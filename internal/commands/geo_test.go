@@ -0,0 +1,222 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type geoTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestGeoCommands(t *testing.T) {
+	sicily := "GEOADD geo-sicily 13.361389 38.115556 Palermo 15.087269 37.502669 Catania"
+	nearby := "GEOADD geo-sicily 12.758489 38.788135 edge1 17.241510 38.788135 edge2"
+	palermo := datastore.GeohashDecode(3479099956230698)
+	catania := datastore.GeohashDecode(3479447370796909)
+	gtcs := []geoTestCase{
+		{
+			name:     "GEOADD",
+			input:    "GEOADD geo-geoadd 13.361389 38.115556 Palermo 15.087269 37.502669 Catania",
+			expected: protocol.NewInteger(2),
+		},
+		{
+			name:      "GEOADD stores the geohash as the score",
+			setupCmds: []string{sicily},
+			input:     "ZSCORE geo-sicily Palermo",
+			expected:  protocol.NewDouble(3479099956230698),
+		},
+		{
+			name:      "GEOADD with CH",
+			setupCmds: []string{"GEOADD geo-geoadd-ch 13.361389 38.115556 Palermo"},
+			input:     "GEOADD geo-geoadd-ch CH 13.361389 38.115556 Palermo 15.087269 38.115556 Palermo",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "GEOADD with NX",
+			setupCmds: []string{"GEOADD geo-geoadd-nx 13.361389 38.115556 Palermo"},
+			input:     "GEOADD geo-geoadd-nx NX CH 15 37 Palermo 15.087269 37.502669 Catania",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "GEOADD with XX",
+			setupCmds: []string{"GEOADD geo-geoadd-xx 13.361389 38.115556 Palermo", "GEOADD geo-geoadd-xx XX 15.087269 37.502669 Catania"},
+			input:     "ZCARD geo-geoadd-xx",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:     "GEOADD with NX and XX",
+			input:    "GEOADD geo-geoadd-wrong NX XX 13.361389 38.115556 Palermo",
+			expected: protocol.NewError(zaddNXXXCompatErrMsg),
+		},
+		{
+			name:     "GEOADD with an invalid latitude",
+			input:    "GEOADD geo-geoadd-wrong 13.361389 86 Palermo",
+			expected: protocol.NewError(fmt.Sprintf(geoInvalidPointErrMsg, 13.361389, 86.0)),
+		},
+		{
+			name:     "GEOADD with a missing member",
+			input:    "GEOADD geo-geoadd-wrong 13.361389 38.115556",
+			expected: protocol.NewError(geoaddInvalidLengthErrMsg),
+		},
+		{
+			name:      "GEOPOS",
+			setupCmds: []string{sicily},
+			input:     "GEOPOS geo-sicily Palermo NonExisting",
+			expected:  protocol.NewArray(geoPointReply(palermo), protocol.NewNullArray()),
+		},
+		{
+			name:     "GEOPOS on a missing key",
+			input:    "GEOPOS geo-missing Palermo",
+			expected: protocol.NewArray(protocol.NewNullArray()),
+		},
+		{
+			name:      "GEODIST",
+			setupCmds: []string{sicily},
+			input:     "GEODIST geo-sicily Palermo Catania",
+			expected:  bulkString("166274.1516"),
+		},
+		{
+			name:      "GEODIST in kilometers",
+			setupCmds: []string{sicily},
+			input:     "GEODIST geo-sicily Palermo Catania km",
+			expected:  bulkString("166.2742"),
+		},
+		{
+			name:      "GEODIST in miles",
+			setupCmds: []string{sicily},
+			input:     "GEODIST geo-sicily Palermo Catania MI",
+			expected:  bulkString("103.3182"),
+		},
+		{
+			name:      "GEODIST with a missing member",
+			setupCmds: []string{sicily},
+			input:     "GEODIST geo-sicily Palermo Rome",
+			expected:  protocol.NewNullBulkString(),
+		},
+		{
+			name:      "GEODIST with an invalid unit",
+			setupCmds: []string{sicily},
+			input:     "GEODIST geo-sicily Palermo Catania yards",
+			expected:  protocol.NewError(geoUnitErrMsg),
+		},
+		{
+			name:      "GEOHASH",
+			setupCmds: []string{sicily},
+			input:     "GEOHASH geo-sicily Palermo Catania Rome",
+			expected:  protocol.NewArray(bulkString("sqc8b49rny0"), bulkString("sqdtr74hyu0"), protocol.NewNullBulkString()),
+		},
+		{
+			name:      "GEOSEARCH BYRADIUS",
+			setupCmds: []string{sicily, nearby},
+			input:     "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYRADIUS 200 km ASC",
+			expected:  bulkStringArray("Catania", "Palermo"),
+		},
+		{
+			name:      "GEOSEARCH BYBOX",
+			setupCmds: []string{"GEOADD geo-box 13.361389 38.115556 Palermo 15.087269 37.502669 Catania 17.241510 38.788135 edge2"},
+			input:     "GEOSEARCH geo-box FROMLONLAT 15 37 BYBOX 400 400 km DESC",
+			expected:  bulkStringArray("edge2", "Palermo", "Catania"),
+		},
+		{
+			name:      "GEOSEARCH FROMMEMBER",
+			setupCmds: []string{sicily, nearby},
+			input:     "GEOSEARCH geo-sicily FROMMEMBER Palermo BYRADIUS 100 km ASC",
+			expected:  bulkStringArray("Palermo", "edge1"),
+		},
+		{
+			name:      "GEOSEARCH with COUNT sorts by distance",
+			setupCmds: []string{sicily, nearby},
+			input:     "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYBOX 400 400 km COUNT 2",
+			expected:  bulkStringArray("Catania", "Palermo"),
+		},
+		{
+			name:      "GEOSEARCH with COUNT ANY",
+			setupCmds: []string{sicily, nearby},
+			input:     "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYBOX 400 400 km COUNT 1 ANY",
+			expected:  bulkStringArray("Palermo"),
+		},
+		{
+			name:      "GEOSEARCH WITHCOORD WITHDIST WITHHASH",
+			setupCmds: []string{sicily},
+			input:     "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYRADIUS 200 km ASC WITHCOORD WITHDIST WITHHASH",
+			expected: protocol.NewArray(
+				protocol.NewArray(bulkString("Catania"), bulkString("56.4413"), protocol.NewInteger(3479447370796909), geoPointReply(catania)),
+				protocol.NewArray(bulkString("Palermo"), bulkString("190.4424"), protocol.NewInteger(3479099956230698), geoPointReply(palermo)),
+			),
+		},
+		{
+			name:     "GEOSEARCH on a missing key",
+			input:    "GEOSEARCH geo-missing FROMMEMBER Palermo BYRADIUS 100 km",
+			expected: bulkStringArray(),
+		},
+		{
+			name:      "GEOSEARCH FROMMEMBER with a missing member",
+			setupCmds: []string{sicily},
+			input:     "GEOSEARCH geo-sicily FROMMEMBER Rome BYRADIUS 100 km",
+			expected:  protocol.NewError(geosearchMemberErrMsg),
+		},
+		{
+			name:     "GEOSEARCH without a center",
+			input:    "GEOSEARCH geo-sicily BYRADIUS 100 km",
+			expected: protocol.NewError(fmt.Sprintf(geosearchFromErrMsg, "GEOSEARCH")),
+		},
+		{
+			name:     "GEOSEARCH with BYRADIUS and BYBOX",
+			input:    "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYRADIUS 100 km BYBOX 1 1 km",
+			expected: protocol.NewError(fmt.Sprintf(geosearchByErrMsg, "GEOSEARCH")),
+		},
+		{
+			name:     "GEOSEARCH with ANY without COUNT",
+			input:    "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYRADIUS 100 km ANY",
+			expected: protocol.NewError(geosearchAnyErrMsg),
+		},
+		{
+			name:     "GEOSEARCH with a negative radius",
+			input:    "GEOSEARCH geo-sicily FROMLONLAT 15 37 BYRADIUS -1 km",
+			expected: protocol.NewError(geosearchRadiusErrMsg),
+		},
+		{
+			name:      "GEOSEARCHSTORE",
+			setupCmds: []string{sicily, nearby, "GEOSEARCHSTORE geo-store geo-sicily FROMLONLAT 15 37 BYRADIUS 200 km"},
+			input:     "ZSCORE geo-store Catania",
+			expected:  protocol.NewDouble(3479447370796909),
+		},
+		{
+			name:      "GEOSEARCHSTORE with STOREDIST",
+			setupCmds: []string{sicily, "GEOSEARCHSTORE geo-store-dist geo-sicily FROMLONLAT 15 37 BYRADIUS 200 km STOREDIST"},
+			input:     "ZRANGE geo-store-dist 0 0",
+			expected:  bulkStringArray("Catania"),
+		},
+		{
+			name:      "GEOSEARCHSTORE with WITHDIST",
+			setupCmds: []string{sicily},
+			input:     "GEOSEARCHSTORE geo-store-wrong geo-sicily FROMLONLAT 15 37 BYRADIUS 200 km WITHDIST",
+			expected:  protocol.NewError(fmt.Sprintf(geosearchInvalidLengthErrMsg, "GEOSEARCHSTORE", geosearchstoreSyntax)),
+		},
+		{
+			name:      "GEOSEARCHSTORE with no results deletes the destination",
+			setupCmds: []string{sicily, "SET geo-store-empty value", "GEOSEARCHSTORE geo-store-empty geo-sicily FROMLONLAT 0 0 BYRADIUS 1 km"},
+			input:     "EXISTS geo-store-empty",
+			expected:  protocol.NewInteger(0),
+		},
+	}
+	for _, tc := range gtcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
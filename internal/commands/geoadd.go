@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	geoaddInvalidLengthErrMsg string = "invalid arguments for command GEOADD. Syntax: GEOADD key [NX | XX] [CH] longitude latitude member [longitude latitude member ...]"
+	geoInvalidPointErrMsg     string = "invalid longitude,latitude pair %f,%f"
+)
+
+func init() {
+	geoadd := geoaddCommand{"geoadd"}
+	registerCommand(geoadd)
+}
+
+// geoaddCommand adds locations to a sorted set, with the geohash of each location as the
+// score of its member. NX, XX and CH work like in ZADD.
+type geoaddCommand struct {
+	name string
+}
+
+func (g geoaddCommand) getName() string {
+	return g.name
+}
+
+func (g geoaddCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 5 {
+		return protocol.NewError(geoaddInvalidLengthErrMsg)
+	}
+	var options zaddOptions
+	i := 2
+flags:
+	for ; i < len(elements); i++ {
+		switch strings.ToUpper(elements[i].String()) {
+		case "NX":
+			options.nx = true
+		case "XX":
+			options.xx = true
+		case "CH":
+			options.ch = true
+		default:
+			break flags
+		}
+	}
+	triples := elements[i:]
+	if len(triples) == 0 || len(triples)%3 != 0 {
+		return protocol.NewError(geoaddInvalidLengthErrMsg)
+	}
+	if options.nx && options.xx {
+		return protocol.NewError(zaddNXXXCompatErrMsg)
+	}
+	scores := []float64{}
+	for j := 0; j < len(triples); j += 3 {
+		point, errReply := parseGeoPoint(triples[j], triples[j+1])
+		if errReply != nil {
+			return errReply
+		}
+		scores = append(scores, float64(datastore.GeohashEncode(point)))
+	}
+	key := elements[1].String()
+	zset, err := datastore.GetOrCreateSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	added, changed := 0, 0
+	for j, score := range scores {
+		member := triples[j*3+2].String()
+		current, exists := zset.Score(member)
+		if (exists && options.nx) || (!exists && options.xx) {
+			continue
+		}
+		if !exists {
+			added++
+		} else if score != current {
+			changed++
+		}
+		zset.Add(member, score)
+	}
+	if zset.Len() == 0 {
+		datastore.Delete(key)
+	}
+	if options.ch {
+		return protocol.NewInteger(added + changed)
+	}
+	return protocol.NewInteger(added)
+}
+
+// parseGeoPoint parses a longitude and a latitude, which must be in the range geohashes
+// can encode.
+func parseGeoPoint(longitude, latitude protocol.DataType) (datastore.GeoPoint, protocol.DataType) {
+	var point datastore.GeoPoint
+	var err error
+	if point.Longitude, err = strconv.ParseFloat(longitude.String(), 64); err != nil {
+		return point, protocol.NewError(notFloatErrMsg)
+	}
+	if point.Latitude, err = strconv.ParseFloat(latitude.String(), 64); err != nil {
+		return point, protocol.NewError(notFloatErrMsg)
+	}
+	if !datastore.ValidGeoPoint(point) {
+		return point, protocol.NewError(fmt.Sprintf(geoInvalidPointErrMsg, point.Longitude, point.Latitude))
+	}
+	return point, nil
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	geodistInvalidLengthErrMsg string = "invalid arguments for command GEODIST. Syntax: GEODIST key member1 member2 [M | KM | FT | MI]"
+	geoUnitErrMsg              string = "unsupported unit provided. please use M, KM, FT, MI"
+)
+
+// geoUnits are the meters in each of the units of distance.
+var geoUnits = map[string]float64{
+	"M":  1,
+	"KM": 1000,
+	"FT": 0.3048,
+	"MI": 1609.34,
+}
+
+func init() {
+	geodist := geodistCommand{"geodist"}
+	registerCommand(geodist)
+}
+
+type geodistCommand struct {
+	name string
+}
+
+func (g geodistCommand) getName() string {
+	return g.name
+}
+
+func (g geodistCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 && len(elements) != 5 {
+		return protocol.NewError(geodistInvalidLengthErrMsg)
+	}
+	unit := 1.0
+	if len(elements) == 5 {
+		var ok bool
+		if unit, ok = parseGeoUnit(elements[4]); !ok {
+			return protocol.NewError(geoUnitErrMsg)
+		}
+	}
+	points, err := geoMemberPoints(elements[1].String(), elements[2:4])
+	if err != nil {
+		return errorReply(err)
+	}
+	if points[0] == nil || points[1] == nil {
+		return protocol.NewNullBulkString()
+	}
+	return geoDistanceReply(datastore.GeoDistance(*points[0], *points[1]) / unit)
+}
+
+// parseGeoUnit returns the meters in the unit.
+func parseGeoUnit(argument protocol.DataType) (float64, bool) {
+	unit, ok := geoUnits[strings.ToUpper(argument.String())]
+	return unit, ok
+}
+
+// geoDistanceReply formats a distance with 4 decimals, like Redis.
+func geoDistanceReply(distance float64) protocol.BulkString {
+	return bulkString(strconv.FormatFloat(distance, 'f', 4, 64))
+}
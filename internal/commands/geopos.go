@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	geoposInvalidLengthErrMsg  string = "invalid arguments for command GEOPOS. Syntax: GEOPOS key [member [member ...]]"
+	geohashInvalidLengthErrMsg string = "invalid arguments for command GEOHASH. Syntax: GEOHASH key [member [member ...]]"
+)
+
+func init() {
+	geopos := geoposCommand{"geopos"}
+	registerCommand(geopos)
+	geohash := geohashCommand{"geohash"}
+	registerCommand(geohash)
+}
+
+// geoposCommand replies with the longitude and latitude of each member, decoded from its
+// geohash, so they are close but not exactly the ones added.
+type geoposCommand struct {
+	name string
+}
+
+// geohashCommand replies with the standard geohash string of each member.
+type geohashCommand struct {
+	name string
+}
+
+func (g geoposCommand) getName() string {
+	return g.name
+}
+
+func (g geoposCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(geoposInvalidLengthErrMsg)
+	}
+	points, err := geoMemberPoints(elements[1].String(), elements[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, point := range points {
+		if point == nil {
+			reply = append(reply, protocol.NewNullArray())
+			continue
+		}
+		reply = append(reply, geoPointReply(*point))
+	}
+	return protocol.NewArray(reply...)
+}
+
+func (g geohashCommand) getName() string {
+	return g.name
+}
+
+func (g geohashCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(geohashInvalidLengthErrMsg)
+	}
+	points, err := geoMemberPoints(elements[1].String(), elements[2:])
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, point := range points {
+		if point == nil {
+			reply = append(reply, protocol.NewNullBulkString())
+			continue
+		}
+		reply = append(reply, bulkString(datastore.GeohashString(*point)))
+	}
+	return protocol.NewArray(reply...)
+}
+
+// geoMemberPoints returns the location of each member, or nil for the members not in the
+// sorted set.
+func geoMemberPoints(key string, members []protocol.DataType) ([]*datastore.GeoPoint, error) {
+	zset, exists, err := datastore.GetSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]*datastore.GeoPoint, len(members))
+	if !exists {
+		return points, nil
+	}
+	for i, member := range members {
+		if score, ok := zset.Score(member.String()); ok {
+			point := datastore.GeohashDecode(uint64(score))
+			points[i] = &point
+		}
+	}
+	return points, nil
+}
+
+func geoPointReply(point datastore.GeoPoint) protocol.Array {
+	return protocol.NewArray(protocol.NewDouble(point.Longitude), protocol.NewDouble(point.Latitude))
+}
@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	geosearchInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s"
+	geosearchFromErrMsg          string = "exactly one of FROMMEMBER or FROMLONLAT can be specified for %s"
+	geosearchByErrMsg            string = "exactly one of BYRADIUS and BYBOX can be specified for %s"
+	geosearchAnyErrMsg           string = "the ANY argument requires COUNT argument"
+	geosearchCountErrMsg         string = "COUNT must be > 0"
+	geosearchMemberErrMsg        string = "could not decode requested zset member"
+	geosearchRadiusErrMsg        string = "radius cannot be negative"
+	geosearchBoxErrMsg           string = "height or width cannot be negative"
+	geosearchSyntax              string = "GEOSEARCH key <FROMMEMBER member | FROMLONLAT longitude latitude> <BYRADIUS radius <M | KM | FT | MI> | BYBOX width height <M | KM | FT | MI>> [ASC | DESC] [COUNT count [ANY]] [WITHCOORD] [WITHDIST] [WITHHASH]"
+	geosearchstoreSyntax         string = "GEOSEARCHSTORE destination source <FROMMEMBER member | FROMLONLAT longitude latitude> <BYRADIUS radius <M | KM | FT | MI> | BYBOX width height <M | KM | FT | MI>> [ASC | DESC] [COUNT count [ANY]] [STOREDIST]"
+)
+
+func init() {
+	registerCommand(geosearchCommand{name: "geosearch", syntax: geosearchSyntax})
+	registerCommand(geosearchCommand{name: "geosearchstore", syntax: geosearchstoreSyntax, store: true})
+}
+
+// geosearchCommand implements GEOSEARCH, which finds the members of a sorted set of
+// locations in a circle or a box, and GEOSEARCHSTORE, which stores them in a new key.
+type geosearchCommand struct {
+	name   string
+	syntax string
+	store  bool
+}
+
+// geosearchRequest holds the parsed arguments of a search. The center is only set when
+// given by FROMLONLAT, and the sizes of the shape are in meters.
+type geosearchRequest struct {
+	fromMember string
+	hasMember  bool
+	hasCenter  bool
+	shape      datastore.GeoShape
+	byRadius   bool
+	byBox      bool
+	// unit is the meters in the unit of the sizes, which the distances are replied in
+	unit      float64
+	sort      int
+	count     int
+	any       bool
+	withCoord bool
+	withDist  bool
+	withHash  bool
+	storeDist bool
+}
+
+// geoResult is a member found by a search.
+type geoResult struct {
+	member   string
+	hash     uint64
+	point    datastore.GeoPoint
+	distance float64
+}
+
+func (g geosearchCommand) getName() string {
+	return g.name
+}
+
+func (g geosearchCommand) processArguments(data protocol.Array) protocol.DataType {
+	arguments := data.GetElements()[1:]
+	var destination string
+	if g.store {
+		if len(arguments) == 0 {
+			return g.syntaxError()
+		}
+		destination = arguments[0].String()
+		arguments = arguments[1:]
+	}
+	if len(arguments) < 1 {
+		return g.syntaxError()
+	}
+	request, errReply := g.parseRequest(arguments[1:])
+	if errReply != nil {
+		return errReply
+	}
+	zset, exists, err := datastore.GetSortedSet(arguments[0].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !exists {
+		if g.store {
+			datastore.Delete(destination)
+			return protocol.NewInteger(0)
+		}
+		return protocol.NewArray([]protocol.DataType{}...)
+	}
+	if request.hasMember {
+		score, ok := zset.Score(request.fromMember)
+		if !ok {
+			return protocol.NewError(geosearchMemberErrMsg)
+		}
+		request.shape.Center = datastore.GeohashDecode(uint64(score))
+	}
+	results := request.search(zset)
+	if g.store {
+		if len(results) == 0 {
+			datastore.Delete(destination)
+			return protocol.NewInteger(0)
+		}
+		result := datastore.NewSortedSet()
+		for _, r := range results {
+			score := float64(r.hash)
+			if request.storeDist {
+				score = r.distance / request.unit
+			}
+			result.Add(r.member, score)
+		}
+		datastore.StoreSortedSet(destination, result)
+		return protocol.NewInteger(result.Len())
+	}
+	return request.reply(results)
+}
+
+func (g geosearchCommand) parseRequest(arguments []protocol.DataType) (geosearchRequest, protocol.DataType) {
+	request := geosearchRequest{unit: 1}
+	for i := 0; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		remaining := len(arguments) - i - 1
+		switch {
+		case option == "FROMMEMBER" && remaining >= 1:
+			if request.hasMember || request.hasCenter {
+				return request, protocol.NewError(fmt.Sprintf(geosearchFromErrMsg, strings.ToUpper(g.name)))
+			}
+			request.fromMember = arguments[i+1].String()
+			request.hasMember = true
+			i++
+		case option == "FROMLONLAT" && remaining >= 2:
+			if request.hasMember || request.hasCenter {
+				return request, protocol.NewError(fmt.Sprintf(geosearchFromErrMsg, strings.ToUpper(g.name)))
+			}
+			point, errReply := parseGeoPoint(arguments[i+1], arguments[i+2])
+			if errReply != nil {
+				return request, errReply
+			}
+			request.shape.Center = point
+			request.hasCenter = true
+			i += 2
+		case option == "BYRADIUS" && remaining >= 2:
+			if request.byRadius || request.byBox {
+				return request, protocol.NewError(fmt.Sprintf(geosearchByErrMsg, strings.ToUpper(g.name)))
+			}
+			radius, err := strconv.ParseFloat(arguments[i+1].String(), 64)
+			if err != nil {
+				return request, protocol.NewError(notFloatErrMsg)
+			}
+			if radius < 0 {
+				return request, protocol.NewError(geosearchRadiusErrMsg)
+			}
+			unit, ok := parseGeoUnit(arguments[i+2])
+			if !ok {
+				return request, protocol.NewError(geoUnitErrMsg)
+			}
+			request.shape.Radius = radius * unit
+			request.unit = unit
+			request.byRadius = true
+			i += 2
+		case option == "BYBOX" && remaining >= 3:
+			if request.byRadius || request.byBox {
+				return request, protocol.NewError(fmt.Sprintf(geosearchByErrMsg, strings.ToUpper(g.name)))
+			}
+			width, err := strconv.ParseFloat(arguments[i+1].String(), 64)
+			if err != nil {
+				return request, protocol.NewError(notFloatErrMsg)
+			}
+			height, err := strconv.ParseFloat(arguments[i+2].String(), 64)
+			if err != nil {
+				return request, protocol.NewError(notFloatErrMsg)
+			}
+			if width < 0 || height < 0 {
+				return request, protocol.NewError(geosearchBoxErrMsg)
+			}
+			unit, ok := parseGeoUnit(arguments[i+3])
+			if !ok {
+				return request, protocol.NewError(geoUnitErrMsg)
+			}
+			request.shape.Width = width * unit
+			request.shape.Height = height * unit
+			request.unit = unit
+			request.byBox = true
+			i += 3
+		case option == "ASC":
+			request.sort = 1
+		case option == "DESC":
+			request.sort = -1
+		case option == "COUNT" && remaining >= 1:
+			count, ok := parseInt(arguments[i+1])
+			if !ok {
+				return request, protocol.NewError(notIntegerErrMsg)
+			}
+			if count <= 0 {
+				return request, protocol.NewError(geosearchCountErrMsg)
+			}
+			request.count = count
+			i++
+		case option == "ANY":
+			request.any = true
+		case option == "WITHCOORD" && !g.store:
+			request.withCoord = true
+		case option == "WITHDIST" && !g.store:
+			request.withDist = true
+		case option == "WITHHASH" && !g.store:
+			request.withHash = true
+		case option == "STOREDIST" && g.store:
+			request.storeDist = true
+		default:
+			return request, g.syntaxError()
+		}
+	}
+	if request.hasMember == request.hasCenter {
+		return request, protocol.NewError(fmt.Sprintf(geosearchFromErrMsg, strings.ToUpper(g.name)))
+	}
+	if request.byRadius == request.byBox {
+		return request, protocol.NewError(fmt.Sprintf(geosearchByErrMsg, strings.ToUpper(g.name)))
+	}
+	if request.any && request.count == 0 {
+		return request, protocol.NewError(geosearchAnyErrMsg)
+	}
+	// Like in Redis, the results are sorted when limited by COUNT, unless ANY is given to
+	// return the first ones found
+	if request.count > 0 && !request.any && request.sort == 0 {
+		request.sort = 1
+	}
+	return request, nil
+}
+
+func (g geosearchCommand) syntaxError() protocol.DataType {
+	return protocol.NewError(fmt.Sprintf(geosearchInvalidLengthErrMsg, strings.ToUpper(g.name), g.syntax))
+}
+
+// search returns the members in the shape, in the order of the request.
+func (r geosearchRequest) search(zset *datastore.SortedSet) []geoResult {
+	results := []geoResult{}
+	seen := make(map[string]bool)
+search:
+	for _, scores := range r.shape.ScoreRanges() {
+		start, end := zset.ScoreRanks(datastore.ScoreRange{
+			Min:          float64(scores[0]),
+			Max:          float64(scores[1]),
+			MaxExclusive: true,
+		})
+		for _, entry := range zset.Range(start, end) {
+			if seen[entry.Member] {
+				continue
+			}
+			seen[entry.Member] = true
+			hash := uint64(entry.Score)
+			point := datastore.GeohashDecode(hash)
+			distance, ok := r.shape.Contains(point)
+			if !ok {
+				continue
+			}
+			results = append(results, geoResult{entry.Member, hash, point, distance})
+			if r.any && len(results) == r.count {
+				break search
+			}
+		}
+	}
+	if r.sort != 0 {
+		slices.SortStableFunc(results, func(a, b geoResult) int {
+			if a.distance < b.distance {
+				return -r.sort
+			}
+			if a.distance > b.distance {
+				return r.sort
+			}
+			return 0
+		})
+	}
+	if r.count > 0 && len(results) > r.count {
+		results = results[:r.count]
+	}
+	return results
+}
+
+// reply creates the reply with the members, or with an array for each member holding the
+// information requested, in the order distance, hash and coordinates.
+func (r geosearchRequest) reply(results []geoResult) protocol.Array {
+	reply := []protocol.DataType{}
+	for _, result := range results {
+		member := bulkString(result.member)
+		if !r.withDist && !r.withHash && !r.withCoord {
+			reply = append(reply, member)
+			continue
+		}
+		item := []protocol.DataType{member}
+		if r.withDist {
+			item = append(item, geoDistanceReply(result.distance/r.unit))
+		}
+		if r.withHash {
+			item = append(item, protocol.NewInteger(int(result.hash)))
+		}
+		if r.withCoord {
+			item = append(item, geoPointReply(result.point))
+		}
+		reply = append(reply, protocol.NewArray(item...))
+	}
+	return protocol.NewArray(reply...)
+}
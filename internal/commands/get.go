@@ -30,9 +30,12 @@ func (g getCommand) processArguments(data protocol.Array) protocol.DataType {
 		return protocol.NewError(fmt.Sprintf("the KEY parameter for the GET command must be a BulkString. Received a %T instead", elements[1]))
 
 	}
-	val, ok := datastore.Get(key.String())
+	val, ok, err := datastore.Get(key.String())
+	if err != nil {
+		return errorReply(err)
+	}
 	if !ok {
-		return protocol.NewSimpleString("not found")
+		return protocol.NewNullBulkString()
 	}
 	return val
 }
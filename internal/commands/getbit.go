@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	getbitInvalidLengthErrMsg string = "the GETBIT command accepts 3 parameters: GETBIT, KEY and OFFSET. Received %d parameters instead"
+)
+
+func init() {
+	getbit := getbitCommand{"getbit"}
+	registerCommand(getbit)
+}
+
+type getbitCommand struct {
+	name string
+}
+
+func (g getbitCommand) getName() string {
+	return g.name
+}
+
+// processArguments returns the bit at the offset of the string stored in the key. Bits
+// past the end of the string are 0.
+func (g getbitCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(getbitInvalidLengthErrMsg, len(elements)))
+	}
+	offset, ok := parseBitOffset(elements[2])
+	if !ok {
+		return protocol.NewError(bitOffsetErrMsg)
+	}
+	bitmap, _, err := getBitmap(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	return protocol.NewInteger(datastore.GetBit(bitmap, offset))
+}
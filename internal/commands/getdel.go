@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	getdelInvalidLengthErrMsg string = "the GETDEL command accepts 2 parameters: GETDEL and KEY. Received %d parameters instead"
+)
+
+func init() {
+	getdel := getdelCommand{"getdel"}
+	registerCommand(getdel)
+}
+
+type getdelCommand struct {
+	name string
+}
+
+func (g getdelCommand) getName() string {
+	return g.name
+}
+
+// processArguments returns the string stored in the key and deletes the key.
+func (g getdelCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(getdelInvalidLengthErrMsg, len(elements)))
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	datastore.Delete(key)
+	return val
+}
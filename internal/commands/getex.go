@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	getexSyntaxErrMsg        string = "invalid arguments for command GETEX. Syntax: GETEX key [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | PERSIST]"
+	getexInvalidExpireErrMsg string = "invalid expire time in 'getex' command"
+)
+
+func init() {
+	getex := getexCommand{"getex"}
+	registerCommand(getex)
+}
+
+type getexCommand struct {
+	name string
+}
+
+func (g getexCommand) getName() string {
+	return g.name
+}
+
+// processArguments returns the string stored in the key, optionally changing its
+// expiration with the same options as SET, or removing it with PERSIST.
+func (g getexCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(getexSyntaxErrMsg)
+	}
+	options := setOptions{}
+	persist := false
+	arguments := elements[2:]
+	for i := 0; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		switch {
+		case option == "PERSIST" && options.expireOption == "" && !persist:
+			persist = true
+		case (option == "EX" || option == "PX" || option == "EXAT" || option == "PXAT") &&
+			options.expireOption == "" && !persist && i+1 < len(arguments):
+			value, err := strconv.ParseInt(arguments[i+1].String(), 10, 64)
+			if err != nil {
+				return protocol.NewError(notIntegerErrMsg)
+			}
+			options.expireOption = option
+			options.expireValue = value
+			i++
+		default:
+			return protocol.NewError(getexSyntaxErrMsg)
+		}
+	}
+	expire, errReply := options.expireAt(getexInvalidExpireErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	if persist || options.expireOption != "" {
+		datastore.SetExpire(key, expire)
+	}
+	return val
+}
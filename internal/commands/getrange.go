@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	getrangeInvalidLengthErrMsg string = "the %s command accepts 4 parameters: %s, KEY, START and END. Received %d parameters instead"
+)
+
+func init() {
+	registerCommand(getrangeCommand{"getrange"})
+	registerCommand(getrangeCommand{"substr"})
+}
+
+// getrangeCommand implements GETRANGE and SUBSTR, its older name.
+type getrangeCommand struct {
+	name string
+}
+
+func (g getrangeCommand) getName() string {
+	return g.name
+}
+
+// processArguments returns the substring of the string stored in the key between the
+// start and end offsets, both inclusive. Negative offsets count from the end of the
+// string, -1 being the last character.
+func (g getrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		name := strings.ToUpper(g.name)
+		return protocol.NewError(fmt.Sprintf(getrangeInvalidLengthErrMsg, name, name, len(elements)))
+	}
+	start, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	end, ok := parseInt(elements[3])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	val, ok, err := datastore.Get(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewBulkString([]byte{})
+	}
+	str := val.String()
+	// Unlike the other ranges of strings, a range with both offsets negative and
+	// reversed is empty instead of being clamped
+	if start < 0 && end < 0 && start > end {
+		return protocol.NewBulkString([]byte{})
+	}
+	first, last := stringRange(start, end, len(str))
+	return protocol.NewBulkString([]byte(str[first:last]))
+}
+
+// stringRange converts the start and end offsets of a range of a string, which can be
+// negative to count from the end, to the offsets from first, inclusive, to last,
+// exclusive. Unlike the ranges of lists, offsets before the start of the string are
+// clamped to its first character, even if both are.
+func stringRange(start, end, length int) (int, int) {
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if end < 0 {
+		end = max(length+end, 0)
+	}
+	end = min(end, length-1)
+	if start > end {
+		return 0, 0
+	}
+	return start, end + 1
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	getsetInvalidLengthErrMsg string = "the GETSET command accepts 3 parameters: GETSET, KEY and VALUE. Received %d parameters instead"
+)
+
+func init() {
+	getset := getsetCommand{"getset"}
+	registerCommand(getset)
+}
+
+type getsetCommand struct {
+	name string
+}
+
+func (g getsetCommand) getName() string {
+	return g.name
+}
+
+// processArguments stores the value in the key and returns the string it held before,
+// the same as SET with the GET option.
+func (g getsetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(getsetInvalidLengthErrMsg, len(elements)))
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	datastore.Set(key, elements[2])
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return val
+}
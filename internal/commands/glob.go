@@ -0,0 +1,96 @@
+package commands
+
+// matchPattern reports whether the subject matches the glob style pattern, with the same
+// rules Redis uses for commands like KEYS or HSCAN MATCH:
+//
+//   - * matches any sequence of characters, including an empty one
+//   - ? matches any single character
+//   - [abc] matches one of the characters between brackets, [^abc] negates the match
+//   - [a-z] matches a range of characters
+//   - \x matches the character x literally
+func matchPattern(pattern, subject string) bool {
+	p, s := 0, 0
+	// Position to return to when a mismatch happens after a *
+	starP, starS := -1, -1
+	for s < len(subject) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starP, starS = p, s
+				p++
+				continue
+			case '?':
+				p++
+				s++
+				continue
+			case '[':
+				if end, ok := matchClass(pattern, p, subject[s]); ok {
+					p = end
+					s++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == subject[s] {
+					p += 2
+					s++
+					continue
+				}
+			default:
+				if pattern[p] == subject[s] {
+					p++
+					s++
+					continue
+				}
+			}
+		}
+		if starP == -1 {
+			return false
+		}
+		// Let the last * consume one more character and try again
+		starS++
+		p, s = starP+1, starS
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchClass matches the character against the class starting at pattern[start], which
+// is a '['. It returns the position after the class and whether the character matched.
+func matchClass(pattern string, start int, c byte) (int, bool) {
+	p := start + 1
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+	matched := false
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			if pattern[p+1] == c {
+				matched = true
+			}
+			p += 2
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			low, high := pattern[p], pattern[p+2]
+			if low > high {
+				low, high = high, low
+			}
+			if c >= low && c <= high {
+				matched = true
+			}
+			p += 3
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+			p++
+		}
+	}
+	if p < len(pattern) {
+		// Skip the closing bracket
+		p++
+	}
+	return p, matched != negate
+}
@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type hashTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func integerArray(values ...int) protocol.Array {
+	elements := []protocol.DataType{}
+	for _, value := range values {
+		elements = append(elements, protocol.NewInteger(value))
+	}
+	return protocol.NewArray(elements...)
+}
+
+func TestHashCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	htcs := []hashTestCase{
+		{
+			name:     "HSET",
+			input:    "HSET hash-hset a 1 b 2",
+			expected: protocol.NewInteger(2),
+		},
+		{
+			name:      "HSET existing field",
+			setupCmds: []string{"HSET hash-hset-existing a 1"},
+			input:     "HSET hash-hset-existing a 2 b 3",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:     "HMSET",
+			input:    "HMSET hash-hmset a 1",
+			expected: protocol.NewSimpleString("OK"),
+		},
+		{
+			name:      "HGET",
+			setupCmds: []string{"HSET hash-hget a 1"},
+			input:     "HGET hash-hget a",
+			expected:  protocol.NewBulkString([]byte("1")),
+		},
+		{
+			name:     "HGET on missing key",
+			input:    "HGET hash-missing a",
+			expected: protocol.NewNullBulkString(),
+		},
+		{
+			name:      "HMGET",
+			setupCmds: []string{"HSET hash-hmget a 1 b 2"},
+			input:     "HMGET hash-hmget b c a",
+			expected:  protocol.NewArray(protocol.NewBulkString([]byte("2")), protocol.NewNullBulkString(), protocol.NewBulkString([]byte("1"))),
+		},
+		{
+			name:      "HSETNX on existing field",
+			setupCmds: []string{"HSET hash-hsetnx a 1"},
+			input:     "HSETNX hash-hsetnx a 2",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "HDEL",
+			setupCmds: []string{"HSET hash-hdel a 1 b 2"},
+			input:     "HDEL hash-hdel a c",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "HDEL removes empty hash",
+			setupCmds: []string{"HSET hash-hdel-empty a 1", "HDEL hash-hdel-empty a"},
+			input:     "EXISTS hash-hdel-empty",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "HGETALL",
+			setupCmds: []string{"HSET hash-hgetall a 1"},
+			input:     "HGETALL hash-hgetall",
+			expected:  protocol.NewMap(protocol.NewBulkString([]byte("a")), protocol.NewBulkString([]byte("1"))),
+		},
+		{
+			name:      "HLEN",
+			setupCmds: []string{"HSET hash-hlen a 1 b 2 c 3"},
+			input:     "HLEN hash-hlen",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "HEXISTS",
+			setupCmds: []string{"HSET hash-hexists a 1"},
+			input:     "HEXISTS hash-hexists a",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "HSTRLEN",
+			setupCmds: []string{"HSET hash-hstrlen a hello"},
+			input:     "HSTRLEN hash-hstrlen a",
+			expected:  protocol.NewInteger(5),
+		},
+		{
+			name:      "HINCRBY",
+			setupCmds: []string{"HSET hash-hincrby a 5"},
+			input:     "HINCRBY hash-hincrby a -7",
+			expected:  protocol.NewInteger(-2),
+		},
+		{
+			name:      "HINCRBY on a non integer",
+			setupCmds: []string{"HSET hash-hincrby-text a hello"},
+			input:     "HINCRBY hash-hincrby-text a 1",
+			expected:  protocol.NewError(hashNotIntegerErrMsg),
+		},
+		{
+			name:      "HINCRBY overflow",
+			setupCmds: []string{"HSET hash-hincrby-overflow a 9223372036854775807"},
+			input:     "HINCRBY hash-hincrby-overflow a 1",
+			expected:  protocol.NewError(overflowErrMsg),
+		},
+		{
+			name:      "HINCRBYFLOAT",
+			setupCmds: []string{"HSET hash-hincrbyfloat a 10.5"},
+			input:     "HINCRBYFLOAT hash-hincrbyfloat a 0.1",
+			expected:  protocol.NewBulkString([]byte("10.6")),
+		},
+		{
+			name:      "HRANDFIELD with negative count",
+			setupCmds: []string{"HSET hash-hrandfield a 1"},
+			input:     "HRANDFIELD hash-hrandfield -2 WITHVALUES",
+			expected:  bulkStringArray("a", "1", "a", "1"),
+		},
+		{
+			name:      "HRANDFIELD with negative count out of range",
+			setupCmds: []string{"HSET hash-hrandfield-range a 1"},
+			input:     "HRANDFIELD hash-hrandfield-range -9223372036854775808",
+			expected:  protocol.NewError(fmt.Sprintf(randomCountErrMsg, maxRandomCount)),
+		},
+		{
+			name:      "HSCAN with MATCH",
+			setupCmds: []string{"HSET hash-hscan apple 1 banana 2 avocado 3"},
+			input:     "HSCAN hash-hscan 0 MATCH a* NOVALUES",
+			expected:  protocol.NewArray(protocol.NewBulkString([]byte("0")), bulkStringArray("apple", "avocado")),
+		},
+		{
+			name:      "HSCAN with COUNT",
+			setupCmds: []string{"HSET hash-hscan-count a 1 b 2 c 3"},
+			input:     "HSCAN hash-hscan-count 0 COUNT 1",
+			expected:  protocol.NewArray(protocol.NewBulkString([]byte(strconv.FormatUint(scanHash("c"), 10))), bulkStringArray("a", "1")),
+		},
+		{
+			name:      "HEXPIRE",
+			setupCmds: []string{"HSET hash-hexpire a 1 b 2"},
+			input:     "HEXPIRE hash-hexpire 100 FIELDS 3 a b c",
+			expected:  integerArray(1, 1, -2),
+		},
+		{
+			name:      "HEXPIRE with NX",
+			setupCmds: []string{"HSET hash-hexpire-nx a 1 b 2", "HEXPIRE hash-hexpire-nx 100 FIELDS 1 a"},
+			input:     "HEXPIRE hash-hexpire-nx 200 NX FIELDS 2 a b",
+			expected:  integerArray(0, 1),
+		},
+		{
+			name:      "HEXPIRE in the past deletes the field",
+			setupCmds: []string{"HSET hash-hexpire-past a 1 b 2"},
+			input:     "HEXPIREAT hash-hexpire-past 1 FIELDS 1 a",
+			expected:  integerArray(2),
+		},
+		{
+			name:      "HEXPIRE with wrong numfields",
+			setupCmds: []string{"HSET hash-hexpire-numfields a 1"},
+			input:     "HEXPIRE hash-hexpire-numfields 100 FIELDS 2 a",
+			expected:  protocol.NewError(numFieldsMismatchErrMsg),
+		},
+		{
+			name:      "HTTL",
+			setupCmds: []string{"HSET hash-httl a 1 b 2", "HEXPIRE hash-httl 100 FIELDS 1 a"},
+			input:     "HTTL hash-httl FIELDS 3 a b c",
+			expected:  integerArray(100, -1, -2),
+		},
+		{
+			name:      "HPERSIST",
+			setupCmds: []string{"HSET hash-hpersist a 1 b 2", "HPEXPIRE hash-hpersist 100000 FIELDS 1 a"},
+			input:     "HPERSIST hash-hpersist FIELDS 2 a b",
+			expected:  integerArray(1, -1),
+		},
+		{
+			name:      "HSET clears the field expiration",
+			setupCmds: []string{"HSET hash-hset-ttl a 1", "HEXPIRE hash-hset-ttl 100 FIELDS 1 a", "HSET hash-hset-ttl a 2"},
+			input:     "HTTL hash-hset-ttl FIELDS 1 a",
+			expected:  integerArray(-1),
+		},
+		{
+			name:      "Hash command on a string",
+			setupCmds: []string{"SET hash-string value"},
+			input:     "HGET hash-string a",
+			expected:  wrongType,
+		},
+		{
+			name:      "String command on a hash",
+			setupCmds: []string{"HSET hash-get a 1"},
+			input:     "GET hash-get",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range htcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// scanWithDeletes runs the scan command over the key until the iteration is over, with
+// a COUNT of 3 and the options. Once the first page is returned, the first two items in
+// it are deleted with the delete command. It returns every item returned by the scan.
+func scanWithDeletes(t *testing.T, scan, delete, key, options string) map[string]bool {
+	t.Helper()
+	returned := map[string]bool{}
+	cursor := "0"
+	for page := 0; page == 0 || cursor != "0"; page++ {
+		reply := processInline(t, scan+" "+key+" "+cursor+" COUNT 3 "+options).(protocol.Array).GetElements()
+		cursor = reply[0].String()
+		items := reply[1].(protocol.Array).GetElements()
+		for _, item := range items {
+			returned[item.String()] = true
+		}
+		if page == 0 {
+			processInline(t, delete+" "+key+" "+items[0].String()+" "+items[1].String())
+		}
+	}
+	return returned
+}
+
+func TestHashScanWithDeletes(t *testing.T) {
+	processInline(t, "HSET hash-hscan-deletes f1 1 f2 2 f3 3 f4 4 f5 5 f6 6")
+	returned := scanWithDeletes(t, "HSCAN", "HDEL", "hash-hscan-deletes", "NOVALUES")
+	if len(returned) != 6 {
+		t.Fatalf("every field should have been returned, got %v", returned)
+	}
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hdelInvalidLengthErrMsg string = "invalid arguments for command HDEL. Syntax: HDEL key field [field ...]"
+)
+
+func init() {
+	hdel := hdelCommand{"hdel"}
+	registerCommand(hdel)
+}
+
+type hdelCommand struct {
+	name string
+}
+
+func (h hdelCommand) getName() string {
+	return h.name
+}
+
+func (h hdelCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(hdelInvalidLengthErrMsg)
+	}
+	key := elements[1].String()
+	hash, ok, err := datastore.GetHash(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	deleted := 0
+	for _, field := range elements[2:] {
+		if hash.Delete(field.String()) {
+			deleted++
+		}
+	}
+	if hash.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewInteger(deleted)
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	serverName    string = "redis"
+	serverVersion string = "7.2.0"
+
+	helloSyntaxErrMsg        string = "invalid arguments for command HELLO. Syntax: HELLO [protover [AUTH username password] [SETNAME clientname]]"
+	helloInvalidProtoErrMsg  string = "Protocol version is not an integer or out of range"
+	helloNoProtoErrMsg       string = "NOPROTO unsupported protocol version"
+	helloWrongPassErrMsg     string = "WRONGPASS invalid username-password pair or user is disabled."
+	helloInvalidClientErrMsg string = "Client names cannot contain spaces, newlines or special characters."
+)
+
+func init() {
+	hello := helloCommand{"hello"}
+	registerClientCommand(hello)
+}
+
+type helloCommand struct {
+	name string
+}
+
+func (h helloCommand) getName() string {
+	return h.name
+}
+
+func (h helloCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	version := client.protocolVersion
+	if len(elements) > 1 {
+		requested, err := strconv.Atoi(elements[1].String())
+		if err != nil {
+			return protocol.NewError(helloInvalidProtoErrMsg)
+		}
+		if requested != protocol.RESP2 && requested != protocol.RESP3 {
+			return protocol.NewError(helloNoProtoErrMsg)
+		}
+		version = requested
+	}
+	name := client.name
+	for i := 2; i < len(elements); i++ {
+		option := strings.ToUpper(elements[i].String())
+		switch {
+		case option == "AUTH" && i+2 < len(elements):
+			// There is no user management yet, so only the default user exists
+			if elements[i+1].String() != "default" {
+				return protocol.NewError(helloWrongPassErrMsg)
+			}
+			i += 2
+		case option == "SETNAME" && i+1 < len(elements):
+			name = elements[i+1].String()
+			if strings.ContainsFunc(name, func(r rune) bool { return r <= ' ' || r > '~' }) {
+				return protocol.NewError(helloInvalidClientErrMsg)
+			}
+			i++
+		default:
+			return protocol.NewError(fmt.Sprintf("%s. Invalid option %s", helloSyntaxErrMsg, elements[i].String()))
+		}
+	}
+	client.protocolVersion = version
+	client.name = name
+	return protocol.NewMap(
+		protocol.NewBulkString([]byte("server")), protocol.NewBulkString([]byte(serverName)),
+		protocol.NewBulkString([]byte("version")), protocol.NewBulkString([]byte(serverVersion)),
+		protocol.NewBulkString([]byte("proto")), protocol.NewInteger(version),
+		protocol.NewBulkString([]byte("id")), protocol.NewInteger(int(client.id)),
+		protocol.NewBulkString([]byte("mode")), protocol.NewBulkString([]byte("standalone")),
+		protocol.NewBulkString([]byte("role")), protocol.NewBulkString([]byte("master")),
+		protocol.NewBulkString([]byte("modules")), protocol.NewArray(),
+	)
+}
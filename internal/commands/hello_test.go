@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func TestHello(t *testing.T) {
+	client := NewClient()
+	hello := func(args ...string) protocol.DataType {
+		elements := []protocol.DataType{protocol.NewBulkString([]byte("HELLO"))}
+		for _, arg := range args {
+			elements = append(elements, protocol.NewBulkString([]byte(arg)))
+		}
+		return ProcessClientCommand(client, protocol.NewArray(elements...))
+	}
+
+	if _, ok := hello().(protocol.Array); !ok {
+		t.Fatalf("HELLO without a version should keep replying RESP2")
+	}
+	if actual := hello("4"); actual != protocol.NewError(helloNoProtoErrMsg) {
+		t.Fatalf("unexpected return value. Expected: %v, Actual: %v", helloNoProtoErrMsg, actual)
+	}
+	reply, ok := hello("3", "SETNAME", "worker").(protocol.Map)
+	if !ok {
+		t.Fatalf("HELLO 3 should reply with a map")
+	}
+	elements := reply.GetElements()
+	if !reflect.DeepEqual(elements[4:6], []protocol.DataType{protocol.NewBulkString([]byte("proto")), protocol.NewInteger(3)}) {
+		t.Fatalf("unexpected proto entry %v", elements[4:6])
+	}
+	if client.ProtocolVersion() != protocol.RESP3 || client.Name() != "worker" {
+		t.Fatalf("client state not updated. Version: %d, name: %s", client.ProtocolVersion(), client.Name())
+	}
+	if _, ok := hello("2").(protocol.Array); !ok {
+		t.Fatalf("HELLO 2 should reply with an array")
+	}
+}
@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hexpireInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key %s [NX | XX | GT | LT] FIELDS numfields field [field ...]"
+	hfieldsInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key FIELDS numfields field [field ...]"
+	numFieldsErrMsg            string = "Parameter `numFields` should be greater than 0"
+	numFieldsMismatchErrMsg    string = "The `numfields` parameter must match the number of arguments"
+)
+
+// Replies for each field of the hash field expiration commands
+const (
+	fieldNotFound     = -2
+	fieldNoExpire     = -1
+	fieldNotSet       = 0
+	fieldExpireSet    = 1
+	fieldExpireDelete = 2
+)
+
+func init() {
+	registerCommand(hexpireCommand{expireCommand{name: "hexpire", unit: time.Second}})
+	registerCommand(hexpireCommand{expireCommand{name: "hpexpire", unit: time.Millisecond}})
+	registerCommand(hexpireCommand{expireCommand{name: "hexpireat", unit: time.Second, absolute: true}})
+	registerCommand(hexpireCommand{expireCommand{name: "hpexpireat", unit: time.Millisecond, absolute: true}})
+}
+
+// hexpireCommand implements HEXPIRE, HPEXPIRE, HEXPIREAT and HPEXPIREAT, which set the
+// expiration of hash fields with the same options as their key counterparts.
+type hexpireCommand struct {
+	expireCommand
+}
+
+func (h hexpireCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	fieldsIndex := -1
+	for i := 3; i < len(elements); i++ {
+		if strings.ToUpper(elements[i].String()) == "FIELDS" {
+			fieldsIndex = i
+			break
+		}
+	}
+	if len(elements) < 6 || fieldsIndex == -1 {
+		return protocol.NewError(h.syntaxError())
+	}
+	when, err := strconv.ParseInt(elements[2].String(), 10, 64)
+	if err != nil {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	options, errReply := parseExpireOptions(elements[3:fieldsIndex])
+	if errReply != nil {
+		return errReply
+	}
+	fields, errReply := parseHashFields(elements[fieldsIndex:])
+	if errReply != nil {
+		return errReply
+	}
+	newExpire, ok := h.expireAt(when)
+	if !ok {
+		return protocol.NewError(fmt.Sprintf(expireInvalidTimeErrMsg, h.name))
+	}
+	key := elements[1].String()
+	hash, exists, err := datastore.GetHash(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, field := range fields {
+		if !exists {
+			reply = append(reply, protocol.NewInteger(fieldNotFound))
+			continue
+		}
+		currentExpire, ok := hash.GetExpire(field)
+		switch {
+		case !ok:
+			reply = append(reply, protocol.NewInteger(fieldNotFound))
+		case !options.allow(currentExpire, newExpire):
+			reply = append(reply, protocol.NewInteger(fieldNotSet))
+		case newExpire <= time.Now().UnixMilli():
+			hash.Delete(field)
+			reply = append(reply, protocol.NewInteger(fieldExpireDelete))
+		default:
+			hash.SetExpire(field, newExpire)
+			reply = append(reply, protocol.NewInteger(fieldExpireSet))
+		}
+	}
+	if exists && hash.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewArray(reply...)
+}
+
+func (h hexpireCommand) syntaxError() string {
+	argument := "seconds"
+	switch {
+	case h.absolute && h.unit == time.Second:
+		argument = "unix-time-seconds"
+	case h.absolute:
+		argument = "unix-time-milliseconds"
+	case h.unit == time.Millisecond:
+		argument = "milliseconds"
+	}
+	name := strings.ToUpper(h.name)
+	return fmt.Sprintf(hexpireInvalidLengthErrMsg, name, name, argument)
+}
+
+// parseHashFields parses the FIELDS numfields field [field ...] arguments.
+func parseHashFields(arguments []protocol.DataType) ([]string, protocol.DataType) {
+	if len(arguments) < 2 || strings.ToUpper(arguments[0].String()) != "FIELDS" {
+		return nil, protocol.NewError(numFieldsMismatchErrMsg)
+	}
+	numFields, ok := parseInt(arguments[1])
+	if !ok || numFields <= 0 {
+		return nil, protocol.NewError(numFieldsErrMsg)
+	}
+	if numFields != len(arguments)-2 {
+		return nil, protocol.NewError(numFieldsMismatchErrMsg)
+	}
+	fields := []string{}
+	for _, argument := range arguments[2:] {
+		fields = append(fields, argument.String())
+	}
+	return fields, nil
+}
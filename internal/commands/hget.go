@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hgetInvalidLengthErrMsg    string = "invalid arguments for command HGET. Syntax: HGET key field"
+	hmgetInvalidLengthErrMsg   string = "invalid arguments for command HMGET. Syntax: HMGET key field [field ...]"
+	hexistsInvalidLengthErrMsg string = "invalid arguments for command HEXISTS. Syntax: HEXISTS key field"
+	hstrlenInvalidLengthErrMsg string = "invalid arguments for command HSTRLEN. Syntax: HSTRLEN key field"
+)
+
+func init() {
+	hget := hgetCommand{"hget"}
+	registerCommand(hget)
+	hmget := hmgetCommand{"hmget"}
+	registerCommand(hmget)
+	hexists := hexistsCommand{"hexists"}
+	registerCommand(hexists)
+	hstrlen := hstrlenCommand{"hstrlen"}
+	registerCommand(hstrlen)
+}
+
+type hgetCommand struct {
+	name string
+}
+
+type hmgetCommand struct {
+	name string
+}
+
+type hexistsCommand struct {
+	name string
+}
+
+type hstrlenCommand struct {
+	name string
+}
+
+func (h hgetCommand) getName() string {
+	return h.name
+}
+
+func (h hgetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(hgetInvalidLengthErrMsg)
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	value, ok := hash.Get(elements[2].String())
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return protocol.NewBulkString(value)
+}
+
+func (h hmgetCommand) getName() string {
+	return h.name
+}
+
+func (h hmgetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(hmgetInvalidLengthErrMsg)
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	values := []protocol.DataType{}
+	for _, field := range elements[2:] {
+		if !ok {
+			values = append(values, protocol.NewNullBulkString())
+			continue
+		}
+		if value, found := hash.Get(field.String()); found {
+			values = append(values, protocol.NewBulkString(value))
+		} else {
+			values = append(values, protocol.NewNullBulkString())
+		}
+	}
+	return protocol.NewArray(values...)
+}
+
+func (h hexistsCommand) getName() string {
+	return h.name
+}
+
+func (h hexistsCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(hexistsInvalidLengthErrMsg)
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	if _, ok := hash.Get(elements[2].String()); ok {
+		return protocol.NewInteger(1)
+	}
+	return protocol.NewInteger(0)
+}
+
+func (h hstrlenCommand) getName() string {
+	return h.name
+}
+
+func (h hstrlenCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(hstrlenInvalidLengthErrMsg)
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	value, _ := hash.Get(elements[2].String())
+	return protocol.NewInteger(len(value))
+}
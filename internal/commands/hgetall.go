@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hgetallInvalidLengthErrMsg string = "the %s command accepts 2 parameters: %s and KEY. Received %d parameters instead"
+)
+
+func init() {
+	registerCommand(hgetallCommand{name: "hgetall", fields: true, values: true})
+	registerCommand(hgetallCommand{name: "hkeys", fields: true})
+	registerCommand(hgetallCommand{name: "hvals", values: true})
+	registerCommand(hlenCommand{"hlen"})
+}
+
+// hgetallCommand implements HGETALL, HKEYS and HVALS, which differ in returning the
+// fields, the values or both.
+type hgetallCommand struct {
+	name   string
+	fields bool
+	values bool
+}
+
+type hlenCommand struct {
+	name string
+}
+
+func (h hgetallCommand) getName() string {
+	return h.name
+}
+
+func (h hgetallCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		name := strings.ToUpper(h.name)
+		return protocol.NewError(fmt.Sprintf(hgetallInvalidLengthErrMsg, name, name, len(elements)))
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	if ok {
+		for _, field := range hash.Fields() {
+			if h.fields {
+				reply = append(reply, protocol.NewBulkString([]byte(field)))
+			}
+			if h.values {
+				value, _ := hash.Get(field)
+				reply = append(reply, protocol.NewBulkString(value))
+			}
+		}
+	}
+	if h.fields && h.values {
+		return protocol.NewMap(reply...)
+	}
+	return protocol.NewArray(reply...)
+}
+
+func (h hlenCommand) getName() string {
+	return h.name
+}
+
+func (h hlenCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(hgetallInvalidLengthErrMsg, "HLEN", "HLEN", len(elements)))
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(hash.Len())
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hincrbyInvalidLengthErrMsg      string = "invalid arguments for command HINCRBY. Syntax: HINCRBY key field increment"
+	hincrbyfloatInvalidLengthErrMsg string = "invalid arguments for command HINCRBYFLOAT. Syntax: HINCRBYFLOAT key field increment"
+	hashNotIntegerErrMsg            string = "hash value is not an integer"
+	hashNotFloatErrMsg              string = "hash value is not a float"
+	notFloatErrMsg                  string = "value is not a valid float"
+	overflowErrMsg                  string = "increment or decrement would overflow"
+	nanOrInfinityErrMsg             string = "increment would produce NaN or Infinity"
+)
+
+func init() {
+	hincrby := hincrbyCommand{"hincrby"}
+	registerCommand(hincrby)
+	hincrbyfloat := hincrbyfloatCommand{"hincrbyfloat"}
+	registerCommand(hincrbyfloat)
+}
+
+type hincrbyCommand struct {
+	name string
+}
+
+type hincrbyfloatCommand struct {
+	name string
+}
+
+func (h hincrbyCommand) getName() string {
+	return h.name
+}
+
+func (h hincrbyCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(hincrbyInvalidLengthErrMsg)
+	}
+	increment, err := strconv.ParseInt(elements[3].String(), 10, 64)
+	if err != nil {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	hash, err := datastore.GetOrCreateHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	field := elements[2].String()
+	var current int64
+	if value, ok := hash.Get(field); ok {
+		current, err = strconv.ParseInt(string(value), 10, 64)
+		if err != nil {
+			return protocol.NewError(hashNotIntegerErrMsg)
+		}
+	}
+	if (increment > 0 && current > math.MaxInt64-increment) || (increment < 0 && current < math.MinInt64-increment) {
+		return protocol.NewError(overflowErrMsg)
+	}
+	current += increment
+	setKeepingExpire(hash, field, []byte(strconv.FormatInt(current, 10)))
+	return protocol.NewInteger(int(current))
+}
+
+func (h hincrbyfloatCommand) getName() string {
+	return h.name
+}
+
+func (h hincrbyfloatCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(hincrbyfloatInvalidLengthErrMsg)
+	}
+	increment, err := strconv.ParseFloat(elements[3].String(), 64)
+	if err != nil || math.IsNaN(increment) || math.IsInf(increment, 0) {
+		return protocol.NewError(notFloatErrMsg)
+	}
+	hash, err := datastore.GetOrCreateHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	field := elements[2].String()
+	var current float64
+	if value, ok := hash.Get(field); ok {
+		current, err = strconv.ParseFloat(string(value), 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return protocol.NewError(hashNotFloatErrMsg)
+		}
+	}
+	current += increment
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return protocol.NewError(nanOrInfinityErrMsg)
+	}
+	value := []byte(strconv.FormatFloat(current, 'f', -1, 64))
+	setKeepingExpire(hash, field, value)
+	return protocol.NewBulkString(value)
+}
+
+// setKeepingExpire changes the value of a field keeping its expiration, since Redis
+// doesn't discard the expiration of fields modified by increments.
+func setKeepingExpire(hash *datastore.Hash, field string, value []byte) {
+	expire, _ := hash.GetExpire(field)
+	hash.Set(field, value)
+	if expire > 0 {
+		hash.SetExpire(field, expire)
+	}
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func init() {
+	registerCommand(hpersistCommand{"hpersist"})
+}
+
+type hpersistCommand struct {
+	name string
+}
+
+func (h hpersistCommand) getName() string {
+	return h.name
+}
+
+func (h hpersistCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 5 {
+		return protocol.NewError(fmt.Sprintf(hfieldsInvalidLengthErrMsg, "HPERSIST", "HPERSIST"))
+	}
+	fields, errReply := parseHashFields(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	hash, exists, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, field := range fields {
+		var currentExpire int64
+		ok := false
+		if exists {
+			currentExpire, ok = hash.GetExpire(field)
+		}
+		switch {
+		case !ok:
+			reply = append(reply, protocol.NewInteger(fieldNotFound))
+		case currentExpire == 0:
+			reply = append(reply, protocol.NewInteger(fieldNoExpire))
+		default:
+			hash.SetExpire(field, 0)
+			reply = append(reply, protocol.NewInteger(fieldExpireSet))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
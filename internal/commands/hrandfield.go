@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hrandfieldInvalidLengthErrMsg string = "invalid arguments for command HRANDFIELD. Syntax: HRANDFIELD key [count [WITHVALUES]]"
+)
+
+func init() {
+	hrandfield := hrandfieldCommand{"hrandfield"}
+	registerCommand(hrandfield)
+}
+
+type hrandfieldCommand struct {
+	name string
+}
+
+func (h hrandfieldCommand) getName() string {
+	return h.name
+}
+
+// processArguments returns random fields of the hash. A positive count returns up to
+// count distinct fields, while a negative count returns exactly -count fields which
+// may repeat, up to maxRandomCount.
+func (h hrandfieldCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 || len(elements) > 4 {
+		return protocol.NewError(hrandfieldInvalidLengthErrMsg)
+	}
+	withCount := len(elements) > 2
+	count := 1
+	if withCount {
+		var ok bool
+		count, ok = parseInt(elements[2])
+		if !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		if count < -maxRandomCount {
+			return protocol.NewError(fmt.Sprintf(randomCountErrMsg, maxRandomCount))
+		}
+	}
+	withValues := false
+	if len(elements) == 4 {
+		if strings.ToUpper(elements[3].String()) != "WITHVALUES" {
+			return protocol.NewError(hrandfieldInvalidLengthErrMsg)
+		}
+		withValues = true
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		if withCount {
+			return protocol.NewArray()
+		}
+		return protocol.NewNullBulkString()
+	}
+	fields := hash.Fields()
+	var selected []string
+	if count >= 0 {
+		rand.Shuffle(len(fields), func(i, j int) { fields[i], fields[j] = fields[j], fields[i] })
+		selected = fields[:min(count, len(fields))]
+	} else {
+		for i := 0; i < -count; i++ {
+			selected = append(selected, fields[rand.IntN(len(fields))])
+		}
+	}
+	if !withCount {
+		return protocol.NewBulkString([]byte(selected[0]))
+	}
+	reply := []protocol.DataType{}
+	for _, field := range selected {
+		reply = append(reply, protocol.NewBulkString([]byte(field)))
+		if withValues {
+			value, _ := hash.Get(field)
+			reply = append(reply, protocol.NewBulkString(value))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"cmp"
+	"container/heap"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hscanInvalidLengthErrMsg string = "invalid arguments for command HSCAN. Syntax: HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]"
+	invalidCursorErrMsg      string = "invalid cursor"
+	defaultScanCount         int    = 10
+)
+
+func init() {
+	hscan := hscanCommand{"hscan"}
+	registerCommand(hscan)
+}
+
+type hscanCommand struct {
+	name string
+}
+
+// scanOptions holds the options shared by the SCAN family of commands.
+type scanOptions struct {
+	cursor   int
+	pattern  string
+	count    int
+	noValues bool
+}
+
+func (h hscanCommand) getName() string {
+	return h.name
+}
+
+// processArguments iterates over the fields of the hash, in the order of scanPage.
+func (h hscanCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(hscanInvalidLengthErrMsg)
+	}
+	options, errReply := parseScanOptions(elements[2:], hscanInvalidLengthErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	hash, ok, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewArray(protocol.NewBulkString([]byte("0")), protocol.NewArray())
+	}
+	page, next := scanPage(hash.Fields(), options)
+	reply := []protocol.DataType{}
+	for _, field := range page {
+		reply = append(reply, protocol.NewBulkString([]byte(field)))
+		if !options.noValues {
+			value, _ := hash.Get(field)
+			reply = append(reply, protocol.NewBulkString(value))
+		}
+	}
+	return protocol.NewArray(protocol.NewBulkString([]byte(strconv.Itoa(next))), protocol.NewArray(reply...))
+}
+
+func parseScanOptions(arguments []protocol.DataType, syntaxErrMsg string) (scanOptions, protocol.DataType) {
+	options := scanOptions{count: defaultScanCount}
+	cursor, err := strconv.ParseUint(arguments[0].String(), 10, 63)
+	if err != nil {
+		return options, protocol.NewError(invalidCursorErrMsg)
+	}
+	options.cursor = int(cursor)
+	for i := 1; i < len(arguments); i++ {
+		switch strings.ToUpper(arguments[i].String()) {
+		case "MATCH":
+			if i+1 == len(arguments) {
+				return options, protocol.NewError(syntaxErrMsg)
+			}
+			options.pattern = arguments[i+1].String()
+			i++
+		case "COUNT":
+			if i+1 == len(arguments) {
+				return options, protocol.NewError(syntaxErrMsg)
+			}
+			count, ok := parseInt(arguments[i+1])
+			if !ok {
+				return options, protocol.NewError(notIntegerErrMsg)
+			}
+			if count < 1 {
+				return options, protocol.NewError(syntaxErrMsg)
+			}
+			options.count = count
+			i++
+		case "NOVALUES":
+			options.noValues = true
+		default:
+			return options, protocol.NewError(syntaxErrMsg)
+		}
+	}
+	return options, nil
+}
+
+// scanPage returns the items visited in one call, filtered by the pattern, and the
+// cursor for the next call, which is 0 when the iteration is over. Items are visited in
+// the order of their scanHash and the cursor is the hash of the next item to visit, so
+// deleting items doesn't move it: every item present during the whole iteration is
+// returned. Items sharing a hash are returned in the same call, so a page can have more
+// than count items.
+func scanPage(items []string, options scanOptions) ([]string, int) {
+	cursor := uint64(options.cursor)
+	// pending holds the count lowest hashes not visited yet, as a max heap
+	pending := &hashHeap{}
+	for _, item := range items {
+		hash := scanHash(item)
+		if hash < cursor {
+			continue
+		}
+		if pending.Len() < options.count {
+			heap.Push(pending, hash)
+		} else if hash < (*pending)[0] {
+			(*pending)[0] = hash
+			heap.Fix(pending, 0)
+		}
+	}
+	last := uint64(math.MaxUint64)
+	if pending.Len() == options.count {
+		last = (*pending)[0]
+	}
+	page := []string{}
+	next := uint64(0)
+	for _, item := range items {
+		hash := scanHash(item)
+		switch {
+		case hash < cursor:
+		case hash <= last:
+			if options.pattern == "" || matchPattern(options.pattern, item) {
+				page = append(page, item)
+			}
+		case next == 0 || hash < next:
+			next = hash
+		}
+	}
+	// The items come in no particular order, like the fields of a hash
+	slices.SortFunc(page, func(a, b string) int {
+		return cmp.Compare(scanHash(a), scanHash(b))
+	})
+	return page, int(next)
+}
+
+// scanHash returns the position of the item in the iteration order of the SCAN family,
+// a 63 bit hash so it can be used as a cursor.
+func scanHash(item string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(item))
+	return h.Sum64() >> 1
+}
+
+// hashHeap is a max heap of hashes.
+type hashHeap []uint64
+
+func (h hashHeap) Len() int           { return len(h) }
+func (h hashHeap) Less(i, j int) bool { return h[i] > h[j] }
+func (h hashHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *hashHeap) Push(x any)        { *h = append(*h, x.(uint64)) }
+func (h *hashHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
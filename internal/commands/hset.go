@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hsetInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key field value [field value ...]"
+)
+
+func init() {
+	registerCommand(hsetCommand{name: "hset"})
+	registerCommand(hsetCommand{name: "hmset", legacy: true})
+}
+
+// hsetCommand implements HSET and the deprecated HMSET, which replies OK instead of the
+// number of fields added.
+type hsetCommand struct {
+	name   string
+	legacy bool
+}
+
+func (h hsetCommand) getName() string {
+	return h.name
+}
+
+func (h hsetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 || len(elements)%2 != 0 {
+		name := strings.ToUpper(h.name)
+		return protocol.NewError(fmt.Sprintf(hsetInvalidLengthErrMsg, name, name))
+	}
+	hash, err := datastore.GetOrCreateHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	added := 0
+	for i := 2; i < len(elements); i += 2 {
+		if hash.Set(elements[i].String(), []byte(elements[i+1].String())) {
+			added++
+		}
+	}
+	if h.legacy {
+		return protocol.NewSimpleString("OK")
+	}
+	return protocol.NewInteger(added)
+}
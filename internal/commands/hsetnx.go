@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	hsetnxInvalidLengthErrMsg string = "invalid arguments for command HSETNX. Syntax: HSETNX key field value"
+)
+
+func init() {
+	hsetnx := hsetnxCommand{"hsetnx"}
+	registerCommand(hsetnx)
+}
+
+type hsetnxCommand struct {
+	name string
+}
+
+func (h hsetnxCommand) getName() string {
+	return h.name
+}
+
+func (h hsetnxCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(hsetnxInvalidLengthErrMsg)
+	}
+	hash, err := datastore.GetOrCreateHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	field := elements[2].String()
+	if _, ok := hash.Get(field); ok {
+		return protocol.NewInteger(0)
+	}
+	hash.Set(field, []byte(elements[3].String()))
+	return protocol.NewInteger(1)
+}
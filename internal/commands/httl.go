@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func init() {
+	registerCommand(httlCommand{ttlCommand{name: "httl", unit: time.Second}})
+	registerCommand(httlCommand{ttlCommand{name: "hpttl", unit: time.Millisecond}})
+	registerCommand(httlCommand{ttlCommand{name: "hexpiretime", unit: time.Second, absolute: true}})
+	registerCommand(httlCommand{ttlCommand{name: "hpexpiretime", unit: time.Millisecond, absolute: true}})
+}
+
+// httlCommand implements HTTL, HPTTL, HEXPIRETIME and HPEXPIRETIME, which reply the
+// expiration of each of the fields like their key counterparts.
+type httlCommand struct {
+	ttlCommand
+}
+
+func (h httlCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 5 {
+		name := strings.ToUpper(h.name)
+		return protocol.NewError(fmt.Sprintf(hfieldsInvalidLengthErrMsg, name, name))
+	}
+	fields, errReply := parseHashFields(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	hash, exists, err := datastore.GetHash(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, field := range fields {
+		var currentExpire int64
+		ok := false
+		if exists {
+			currentExpire, ok = hash.GetExpire(field)
+		}
+		switch {
+		case !ok:
+			reply = append(reply, protocol.NewInteger(fieldNotFound))
+		case currentExpire == 0:
+			reply = append(reply, protocol.NewInteger(fieldNoExpire))
+		default:
+			reply = append(reply, protocol.NewInteger(int(h.remaining(currentExpire))))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type hyperLogLogTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestHyperLogLogCommands(t *testing.T) {
+	htcs := []hyperLogLogTestCase{
+		{
+			name:     "PFADD",
+			input:    "PFADD hll-pfadd a b c",
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "PFADD with existing elements",
+			setupCmds: []string{"PFADD hll-pfadd-existing a b c"},
+			input:     "PFADD hll-pfadd-existing b a",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:     "PFADD without elements",
+			input:    "PFADD hll-pfadd-empty",
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "PFADD creates a string",
+			setupCmds: []string{"PFADD hll-pfadd-type a"},
+			input:     "TYPE hll-pfadd-type",
+			expected:  protocol.NewSimpleString("string"),
+		},
+		{
+			name:      "PFCOUNT",
+			setupCmds: []string{"PFADD hll-pfcount 1 2 3 4 5", "PFADD hll-pfcount 4 5 6 7 8 9 10"},
+			input:     "PFCOUNT hll-pfcount",
+			expected:  protocol.NewInteger(10),
+		},
+		{
+			name:     "PFCOUNT on a missing key",
+			input:    "PFCOUNT hll-pfcount-missing",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:      "PFCOUNT of several keys",
+			setupCmds: []string{"PFADD hll-pfcount-a a b c", "PFADD hll-pfcount-b c d e"},
+			input:     "PFCOUNT hll-pfcount-a hll-pfcount-b hll-pfcount-missing",
+			expected:  protocol.NewInteger(5),
+		},
+		{
+			name:      "PFMERGE",
+			setupCmds: []string{"PFADD hll-pfmerge-a a b c", "PFADD hll-pfmerge-b c d e", "PFADD hll-pfmerge a z", "PFMERGE hll-pfmerge hll-pfmerge-a hll-pfmerge-b"},
+			input:     "PFCOUNT hll-pfmerge",
+			expected:  protocol.NewInteger(6),
+		},
+		{
+			name:     "PFMERGE without sources",
+			input:    "PFMERGE hll-pfmerge-empty",
+			expected: protocol.NewSimpleString("OK"),
+		},
+		{
+			name:      "PFADD on a string",
+			setupCmds: []string{"SET hll-string value"},
+			input:     "PFADD hll-string a",
+			expected:  protocol.NewError(datastore.ErrNotHyperLogLog.Error()),
+		},
+		{
+			name:      "PFCOUNT on a list",
+			setupCmds: []string{"RPUSH hll-list a"},
+			input:     "PFCOUNT hll-list",
+			expected:  protocol.NewError(datastore.ErrWrongType.Error()),
+		},
+		{
+			name:      "PFMERGE with a string source",
+			setupCmds: []string{"SET hll-merge-string value"},
+			input:     "PFMERGE hll-merge-dest hll-merge-string",
+			expected:  protocol.NewError(datastore.ErrNotHyperLogLog.Error()),
+		},
+	}
+	for _, tc := range htcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -2,51 +2,85 @@ package commands
 
 import (
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
 
 	"github.com/mhsantos/redis-server/internal/datastore"
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
 const (
-	incrInvalidLengthErrMsg string = "the INCR command accepts 2 parameters: INCR and KEY. Received %d parameters instead"
-	incrKeyTypeErrMsg       string = "the KEY parameter for the INCR command must be a BulkString. Received a %T instead"
+	incrInvalidLengthErrMsg   string = "the INCR command accepts 2 parameters: INCR and KEY. Received %d parameters instead"
+	incrKeyTypeErrMsg         string = "the KEY parameter for the INCR command must be a BulkString. Received a %T instead"
+	incrbyInvalidLengthErrMsg string = "the %s command accepts %d parameters: %s. Received %d parameters instead"
+	decrementOverflowErrMsg   string = "decrement would overflow"
 )
 
 func init() {
-	incr := incrCommand{"incr"}
-	registerCommand(incr)
+	registerCommand(incrCommand{name: "incr", sign: 1})
+	registerCommand(incrCommand{name: "decr", sign: -1})
+	registerCommand(incrCommand{name: "incrby", sign: 1, byArgument: true})
+	registerCommand(incrCommand{name: "decrby", sign: -1, byArgument: true})
 }
 
+// incrCommand implements INCR and DECR, which add 1 or -1 to the integer stored in the
+// key, and INCRBY and DECRBY, which add or subtract the increment argument. A missing
+// key is taken as 0.
 type incrCommand struct {
-	name string
+	name       string
+	sign       int64
+	byArgument bool
 }
 
-func (g incrCommand) getName() string {
-	return g.name
+func (i incrCommand) getName() string {
+	return i.name
 }
 
-func (g incrCommand) processArguments(data protocol.Array) protocol.DataType {
+func (i incrCommand) processArguments(data protocol.Array) protocol.DataType {
 	elements := data.GetElements()
-	if len(elements) != 2 {
-		return protocol.NewError(fmt.Sprintf(incrInvalidLengthErrMsg, len(elements)))
+	if errReply := i.checkLength(elements); errReply != nil {
+		return errReply
 	}
 	key, ok := elements[1].(protocol.BulkString)
 	if !ok {
 		return protocol.NewError(fmt.Sprintf(incrKeyTypeErrMsg, elements[1]))
-
 	}
-	val, ok := datastore.Get(key.String())
-	if !ok {
-		val = protocol.NewSimpleString("0")
-		datastore.Set(key.String(), val)
+	increment := i.sign
+	if i.byArgument {
+		argument, err := strconv.ParseInt(elements[2].String(), 10, 64)
+		if err != nil {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		// The increment of DECRBY is negated, which isn't possible for the lowest int64
+		if i.sign < 0 && argument == math.MinInt64 {
+			return protocol.NewError(decrementOverflowErrMsg)
+		}
+		increment = argument * i.sign
 	}
-	asNumber, err := strconv.Atoi(val.String())
+	current, _, err := datastore.GetInteger(key.String())
 	if err != nil {
-		return protocol.NewError("value can't be converted to number")
+		return errorReply(err)
+	}
+	if (increment > 0 && current > math.MaxInt64-increment) || (increment < 0 && current < math.MinInt64-increment) {
+		return protocol.NewError(overflowErrMsg)
+	}
+	current += increment
+	datastore.SetInteger(key.String(), current)
+	return protocol.NewInteger(int(current))
+}
+
+func (i incrCommand) checkLength(elements []protocol.DataType) protocol.DataType {
+	switch {
+	case !i.byArgument && len(elements) != 2:
+		if i.name == "incr" {
+			return protocol.NewError(fmt.Sprintf(incrInvalidLengthErrMsg, len(elements)))
+		}
+		name := strings.ToUpper(i.name)
+		return protocol.NewError(fmt.Sprintf(incrbyInvalidLengthErrMsg, name, 2, name+" and KEY", len(elements)))
+	case i.byArgument && len(elements) != 3:
+		name := strings.ToUpper(i.name)
+		return protocol.NewError(fmt.Sprintf(incrbyInvalidLengthErrMsg, name, 3, name+", KEY and INCREMENT", len(elements)))
 	}
-	asNumber++
-	asStr := strconv.Itoa(asNumber)
-	datastore.Set(key.String(), protocol.NewSimpleString(asStr))
-	return protocol.NewInteger(asNumber)
+	return nil
 }
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type incrTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestIncrCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	itcs := []incrTestCase{
+		{
+			name:     "INCR on a missing key",
+			input:    "INCR incr-missing",
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "INCR",
+			setupCmds: []string{"SET incr-existing 41"},
+			input:     "INCR incr-existing",
+			expected:  protocol.NewInteger(42),
+		},
+		{
+			name:      "DECR",
+			setupCmds: []string{"SET decr-existing -41"},
+			input:     "DECR decr-existing",
+			expected:  protocol.NewInteger(-42),
+		},
+		{
+			name:      "INCRBY",
+			setupCmds: []string{"SET incrby-existing 10"},
+			input:     "INCRBY incrby-existing -25",
+			expected:  protocol.NewInteger(-15),
+		},
+		{
+			name:      "DECRBY",
+			setupCmds: []string{"SET decrby-existing 10"},
+			input:     "DECRBY decrby-existing 25",
+			expected:  protocol.NewInteger(-15),
+		},
+		{
+			name:      "GET after INCRBY",
+			setupCmds: []string{"INCRBY incr-get 100"},
+			input:     "GET incr-get",
+			expected:  protocol.NewBulkString([]byte("100")),
+		},
+		{
+			name:      "INCRBY keeps the int encoding",
+			setupCmds: []string{"SET incr-encoding 1", "INCRBY incr-encoding 5"},
+			input:     "OBJECT ENCODING incr-encoding",
+			expected:  protocol.NewBulkString([]byte("int")),
+		},
+		{
+			name:     "INCRBY with a non integer increment",
+			input:    "INCRBY incr-invalid 1.5",
+			expected: protocol.NewError(notIntegerErrMsg),
+		},
+		{
+			name:      "INCR on a number with spaces",
+			setupCmds: []string{"SET incr-spaces \" 1\""},
+			input:     "INCR incr-spaces",
+			expected:  protocol.NewError(notIntegerErrMsg),
+		},
+		{
+			name:      "DECR underflow",
+			setupCmds: []string{"SET decr-underflow -9223372036854775808"},
+			input:     "DECR decr-underflow",
+			expected:  protocol.NewError(overflowErrMsg),
+		},
+		{
+			name:      "INCRBY overflow",
+			setupCmds: []string{"SET incrby-overflow 9223372036854775800"},
+			input:     "INCRBY incrby-overflow 8",
+			expected:  protocol.NewError(overflowErrMsg),
+		},
+		{
+			name:     "DECRBY with the lowest integer",
+			input:    "DECRBY decrby-lowest -9223372036854775808",
+			expected: protocol.NewError(decrementOverflowErrMsg),
+		},
+		{
+			name:      "DECR on a set",
+			setupCmds: []string{"SADD decr-set 1"},
+			input:     "DECR decr-set",
+			expected:  wrongType,
+		},
+		{
+			name:      "INCRBYFLOAT",
+			setupCmds: []string{"SET incrbyfloat-existing 10.50"},
+			input:     "INCRBYFLOAT incrbyfloat-existing 0.1",
+			expected:  protocol.NewBulkString([]byte("10.6")),
+		},
+		{
+			name:      "INCRBYFLOAT on an integer",
+			setupCmds: []string{"SET incrbyfloat-int 5"},
+			input:     "INCRBYFLOAT incrbyfloat-int -5",
+			expected:  protocol.NewBulkString([]byte("0")),
+		},
+		{
+			name:      "INCRBYFLOAT with exponent",
+			setupCmds: []string{"SET incrbyfloat-exp 5.0e3"},
+			input:     "INCRBYFLOAT incrbyfloat-exp 2.0e2",
+			expected:  protocol.NewBulkString([]byte("5200")),
+		},
+		{
+			name:      "INCRBYFLOAT on a non float",
+			setupCmds: []string{"SET incrbyfloat-text abc"},
+			input:     "INCRBYFLOAT incrbyfloat-text 1",
+			expected:  protocol.NewError(notFloatErrMsg),
+		},
+		{
+			name:     "INCRBYFLOAT with infinity",
+			input:    "INCRBYFLOAT incrbyfloat-inf +inf",
+			expected: protocol.NewError(notFloatErrMsg),
+		},
+		{
+			name:      "INCRBYFLOAT to infinity",
+			setupCmds: []string{"SET incrbyfloat-overflow 1.7e308"},
+			input:     "INCRBYFLOAT incrbyfloat-overflow 1.7e308",
+			expected:  protocol.NewError(nanOrInfinityErrMsg),
+		},
+	}
+	for _, tc := range itcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
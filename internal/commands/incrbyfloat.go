@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	incrbyfloatInvalidLengthErrMsg string = "the INCRBYFLOAT command accepts 3 parameters: INCRBYFLOAT, KEY and INCREMENT. Received %d parameters instead"
+)
+
+func init() {
+	incrbyfloat := incrbyfloatCommand{"incrbyfloat"}
+	registerCommand(incrbyfloat)
+}
+
+type incrbyfloatCommand struct {
+	name string
+}
+
+func (i incrbyfloatCommand) getName() string {
+	return i.name
+}
+
+// processArguments adds the increment to the floating point number stored in the key,
+// taking a missing key as 0, and returns the result as a string, which is also how it's
+// stored.
+func (i incrbyfloatCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(incrbyfloatInvalidLengthErrMsg, len(elements)))
+	}
+	increment, err := strconv.ParseFloat(elements[2].String(), 64)
+	if err != nil || math.IsNaN(increment) || math.IsInf(increment, 0) {
+		return protocol.NewError(notFloatErrMsg)
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var current float64
+	if ok {
+		current, err = strconv.ParseFloat(val.String(), 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return protocol.NewError(notFloatErrMsg)
+		}
+	}
+	current += increment
+	if math.IsNaN(current) || math.IsInf(current, 0) {
+		return protocol.NewError(nanOrInfinityErrMsg)
+	}
+	result := protocol.NewBulkString([]byte(strconv.FormatFloat(current, 'f', -1, 64)))
+	datastore.SetKeepTTL(key, result)
+	return result
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func init() {
+	info := infoCommand{"info"}
+	registerCommand(info)
+}
+
+// infoSection is a section of the INFO reply, written as lines of field:value pairs.
+type infoSection struct {
+	name   string
+	fields func() []string
+}
+
+// infoSections are the sections of the INFO reply, in the order they are written.
+var infoSections = []infoSection{
+	{"stats", statsInfo},
+}
+
+// infoCommand replies with information about the server, in the sections given as
+// arguments. Without arguments, or with default, all or everything, it replies with all
+// of them. Unknown sections are ignored, like in Redis.
+type infoCommand struct {
+	name string
+}
+
+func (i infoCommand) getName() string {
+	return i.name
+}
+
+func (i infoCommand) processArguments(data protocol.Array) protocol.DataType {
+	requested := make(map[string]bool)
+	for _, argument := range data.GetElements()[1:] {
+		requested[strings.ToLower(argument.String())] = true
+	}
+	all := len(requested) == 0 || requested["default"] || requested["all"] || requested["everything"]
+	var reply strings.Builder
+	for _, section := range infoSections {
+		if !all && !requested[section.name] {
+			continue
+		}
+		if reply.Len() > 0 {
+			reply.WriteString("\r\n")
+		}
+		fmt.Fprintf(&reply, "# %s%s\r\n", strings.ToUpper(section.name[:1]), section.name[1:])
+		for _, field := range section.fields() {
+			reply.WriteString(field + "\r\n")
+		}
+	}
+	return protocol.NewVerbatimString("txt", []byte(reply.String()))
+}
+
+// statsInfo returns the fields of the stats section, with the counters of the keys
+// removed because their time to live was over.
+func statsInfo() []string {
+	stats := datastore.Stats()
+	return []string{
+		fmt.Sprintf("expired_keys:%d", stats.ActiveExpiredKeys+stats.LazyExpiredKeys),
+		fmt.Sprintf("expired_keys_active:%d", stats.ActiveExpiredKeys),
+		fmt.Sprintf("expired_keys_lazy:%d", stats.LazyExpiredKeys),
+		fmt.Sprintf("expired_time_cap_reached_count:%d", stats.TimeLimitReached),
+		fmt.Sprintf("expire_cycles:%d", stats.Cycles),
+		fmt.Sprintf("expire_cycle_cpu_milliseconds:%d", stats.CycleTime.Milliseconds()),
+	}
+}
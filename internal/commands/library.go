@@ -0,0 +1,341 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/rdb"
+	"github.com/mhsantos/redis-server/internal/scripting"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	libraryMetadataErrMsg      string = "Missing library metadata"
+	libraryEngineErrMsg        string = "Engine '%s' not found"
+	libraryMetadataValueErrMsg string = "Invalid metadata value given: %s"
+	libraryNameMissingErrMsg   string = "Library name was not given"
+	libraryNameErrMsg          string = "Library names can only contain letters, numbers, or underscores(_) and must be at least one character long"
+	libraryExistsErrMsg        string = "Library '%s' already exists"
+	libraryNoFunctionsErrMsg   string = "No functions registered"
+	libraryRegisterErrMsg      string = "Error registering functions: %s"
+	libraryLoadTimeoutErrMsg   string = "FUNCTION LOAD timeout"
+	functionExistsErrMsg       string = "Function %s already exists"
+	functionNameErrMsg         string = "Function names can only contain letters, numbers, or underscores(_) and must be at least one character long"
+	functionDuplicateErrMsg    string = "Function already exists in the library"
+	functionFlagErrMsg         string = "unknown flag given"
+	registerArgumentsErrMsg    string = "wrong number of arguments to redis.register_function"
+	registerNameErrMsg         string = "function_name argument given to redis.register_function must be a string"
+	registerCallbackErrMsg     string = "callback argument given to redis.register_function must be a function"
+	registerUnknownErrMsg      string = "unknown argument given to redis.register_function"
+)
+
+// libraryLoadTimeout limits how long the code of a library runs when it's loaded, since
+// it should only register functions.
+const libraryLoadTimeout = 500 * time.Millisecond
+
+// functionFlags are the flags a function can be registered with. Only no-writes changes
+// how functions run, the others are accepted for compatibility.
+var functionFlags = []string{"no-writes", "allow-oom", "allow-stale", "no-cluster", "allow-cross-slot-keys"}
+
+// library is a set of functions loaded together from the same code, whose first line
+// holds its metadata, like #!lua name=mylib. The functions run in the state the code
+// was loaded in, so they share the local variables of the library.
+type library struct {
+	name      string
+	code      string
+	functions map[string]*scriptFunction
+	state     *lua.LState
+}
+
+// scriptFunction is a function registered by a library, which FCALL calls with the keys
+// and arguments.
+type scriptFunction struct {
+	name        string
+	description string
+	flags       []string
+	callback    *lua.LFunction
+	library     *library
+}
+
+func (f *scriptFunction) readOnly() bool {
+	return slices.Contains(f.flags, "no-writes")
+}
+
+// functionRegistry holds the libraries by name, and their functions by name, since
+// function names are unique across libraries.
+type functionRegistry struct {
+	libraries map[string]*library
+	functions map[string]*scriptFunction
+}
+
+func newFunctionRegistry() *functionRegistry {
+	return &functionRegistry{
+		libraries: make(map[string]*library),
+		functions: make(map[string]*scriptFunction),
+	}
+}
+
+var functionLibraries = newFunctionRegistry()
+
+// clone copies the registry, so a set of libraries can be added all at once or not at
+// all.
+func (r *functionRegistry) clone() *functionRegistry {
+	return &functionRegistry{
+		libraries: maps.Clone(r.libraries),
+		functions: maps.Clone(r.functions),
+	}
+}
+
+// add adds the library to the registry. A library with the same name is only replaced if
+// replace is set. The functions of the library can't have the same name as the functions
+// of another library.
+func (r *functionRegistry) add(lib *library, replace bool) error {
+	if _, ok := r.libraries[lib.name]; ok && !replace {
+		return fmt.Errorf(libraryExistsErrMsg, lib.name)
+	}
+	for name := range lib.functions {
+		if existing, ok := r.functions[name]; ok && existing.library.name != lib.name {
+			return fmt.Errorf(functionExistsErrMsg, name)
+		}
+	}
+	r.remove(lib.name)
+	r.libraries[lib.name] = lib
+	for name, function := range lib.functions {
+		r.functions[name] = function
+	}
+	return nil
+}
+
+// remove removes the library and its functions, returning false if it didn't exist.
+func (r *functionRegistry) remove(name string) bool {
+	lib, ok := r.libraries[name]
+	if !ok {
+		return false
+	}
+	for function := range lib.functions {
+		delete(r.functions, function)
+	}
+	delete(r.libraries, name)
+	return true
+}
+
+// names returns the names of the libraries in order.
+func (r *functionRegistry) names() []string {
+	return slices.Sorted(maps.Keys(r.libraries))
+}
+
+// loadLibrary runs the code of a library, which registers its functions with
+// redis.register_function. Only registering functions and logging are allowed while
+// loading, commands can only be called once the functions run.
+func loadLibrary(code string) (*library, error) {
+	name, body, err := parseLibraryMetadata(code)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := scripting.Compile(body, "user_function")
+	if err != nil {
+		return nil, fmt.Errorf(libraryRegisterErrMsg, err)
+	}
+	L := scripting.NewState()
+	lib := &library{name: name, code: code, functions: make(map[string]*scriptFunction), state: L}
+	redis := L.NewTable()
+	redis.RawSetString("register_function", L.NewFunction(func(L *lua.LState) int {
+		if err := registerFunction(L, lib); err != nil {
+			L.RaiseError("%s", err)
+		}
+		return 0
+	}))
+	addLogFunction(L, redis)
+	L.SetGlobal("redis", redis)
+	scripting.ProtectGlobals(L)
+	start := time.Now()
+	scripting.SetInterrupt(L, func() error {
+		if time.Since(start) > libraryLoadTimeout {
+			return errors.New(libraryLoadTimeoutErrMsg)
+		}
+		return nil
+	})
+	if _, err := scripting.Call(L, L.NewFunctionFromProto(proto)); err != nil {
+		var luaErr *lua.ApiError
+		if errors.As(err, &luaErr) {
+			return nil, fmt.Errorf(libraryRegisterErrMsg, luaErr.Object)
+		}
+		return nil, err
+	}
+	if len(lib.functions) == 0 {
+		return nil, errors.New(libraryNoFunctionsErrMsg)
+	}
+	return lib, nil
+}
+
+// parseLibraryMetadata reads the engine and name of the library from the first line of
+// its code, returning the name and the code to run. The first line is replaced by an
+// empty one, so line numbers in error messages match the code.
+func parseLibraryMetadata(code string) (string, string, error) {
+	if !strings.HasPrefix(code, "#!") {
+		return "", "", errors.New(libraryMetadataErrMsg)
+	}
+	firstLine, body, _ := strings.Cut(code, "\n")
+	fields := strings.Fields(strings.TrimPrefix(firstLine, "#!"))
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "lua") {
+		engine := ""
+		if len(fields) > 0 {
+			engine = fields[0]
+		}
+		return "", "", fmt.Errorf(libraryEngineErrMsg, engine)
+	}
+	name := ""
+	for _, field := range fields[1:] {
+		value, ok := strings.CutPrefix(field, "name=")
+		if !ok {
+			return "", "", fmt.Errorf(libraryMetadataValueErrMsg, field)
+		}
+		name = value
+	}
+	if name == "" {
+		return "", "", errors.New(libraryNameMissingErrMsg)
+	}
+	if !validFunctionName(name) {
+		return "", "", errors.New(libraryNameErrMsg)
+	}
+	return name, "\n" + body, nil
+}
+
+// registerFunction implements redis.register_function, which takes the name and the
+// callback, or a table with the function_name, callback, flags and description fields.
+func registerFunction(L *lua.LState, lib *library) error {
+	function := &scriptFunction{library: lib}
+	var callback lua.LValue
+	switch L.GetTop() {
+	case 1:
+		t, ok := L.Get(1).(*lua.LTable)
+		if !ok {
+			return errors.New(registerArgumentsErrMsg)
+		}
+		for key, value := t.Next(lua.LNil); key != lua.LNil; key, value = t.Next(key) {
+			switch key.String() {
+			case "function_name":
+				name, ok := value.(lua.LString)
+				if !ok {
+					return errors.New(registerNameErrMsg)
+				}
+				function.name = string(name)
+			case "callback":
+				callback = value
+			case "description":
+				description, ok := value.(lua.LString)
+				if !ok {
+					return errors.New(registerUnknownErrMsg)
+				}
+				function.description = string(description)
+			case "flags":
+				flags, ok := value.(*lua.LTable)
+				if !ok {
+					return errors.New(functionFlagErrMsg)
+				}
+				for i := 1; flags.RawGetInt(i) != lua.LNil; i++ {
+					flag, ok := flags.RawGetInt(i).(lua.LString)
+					if !ok || !slices.Contains(functionFlags, string(flag)) {
+						return errors.New(functionFlagErrMsg)
+					}
+					function.flags = append(function.flags, string(flag))
+				}
+			default:
+				return errors.New(registerUnknownErrMsg)
+			}
+		}
+	case 2:
+		name, ok := L.Get(1).(lua.LString)
+		if !ok {
+			return errors.New(registerNameErrMsg)
+		}
+		function.name = string(name)
+		callback = L.Get(2)
+	default:
+		return errors.New(registerArgumentsErrMsg)
+	}
+	var ok bool
+	if function.callback, ok = callback.(*lua.LFunction); !ok {
+		return errors.New(registerCallbackErrMsg)
+	}
+	if !validFunctionName(function.name) {
+		return errors.New(functionNameErrMsg)
+	}
+	if _, ok := lib.functions[function.name]; ok {
+		return errors.New(functionDuplicateErrMsg)
+	}
+	lib.functions[function.name] = function
+	return nil
+}
+
+func validFunctionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// The payload of FUNCTION DUMP is the one of Redis: the code of each library as it's
+// stored in an RDB file, followed by the version of the RDB format and a CRC64 checksum
+// of everything before it, so FUNCTION RESTORE can reject payloads it doesn't understand
+// or that were corrupted.
+var errFunctionPayload = errors.New("payload version or checksum are wrong")
+
+// dumpLibraries serializes the code of the libraries.
+func dumpLibraries(r *functionRegistry) []byte {
+	var payload bytes.Buffer
+	encoder := rdb.NewEncoder(&payload)
+	for _, name := range r.names() {
+		encoder.WriteOpcode(rdb.OpcodeFunction)
+		encoder.WriteString([]byte(r.libraries[name].code))
+	}
+	footer := binary.LittleEndian.AppendUint16(nil, rdb.Version)
+	encoder.WriteRaw(footer)
+	return binary.LittleEndian.AppendUint64(payload.Bytes(), encoder.Checksum())
+}
+
+// parseDump returns the code of the libraries of a payload created by dumpLibraries.
+func parseDump(payload []byte) ([]string, error) {
+	if len(payload) < 10 {
+		return nil, errFunctionPayload
+	}
+	body, footer := payload[:len(payload)-10], payload[len(payload)-10:]
+	if binary.LittleEndian.Uint16(footer) > rdb.Version ||
+		binary.LittleEndian.Uint64(footer[2:]) != rdb.Checksum(0, payload[:len(payload)-8]) {
+		return nil, errFunctionPayload
+	}
+	var codes []string
+	decoder := rdb.NewDecoder(bytes.NewReader(body))
+	for decoder.More() {
+		opcode, err := decoder.ReadOpcode()
+		if err != nil || opcode != rdb.OpcodeFunction {
+			return nil, errFunctionPayload
+		}
+		code, err := decoder.ReadString()
+		if err != nil {
+			return nil, errFunctionPayload
+		}
+		codes = append(codes, string(code))
+	}
+	return codes, nil
+}
+
+// sortedFunctions returns the functions of the library ordered by name.
+func (l *library) sortedFunctions() []*scriptFunction {
+	functions := slices.Collect(maps.Values(l.functions))
+	slices.SortFunc(functions, func(a, b *scriptFunction) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return functions
+}
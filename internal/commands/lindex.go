@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lindexInvalidLengthErrMsg string = "invalid arguments for command LINDEX. Syntax: LINDEX key index"
+)
+
+func init() {
+	lindex := lindexCommand{"lindex"}
+	registerCommand(lindex)
+}
+
+type lindexCommand struct {
+	name string
+}
+
+func (l lindexCommand) getName() string {
+	return l.name
+}
+
+func (l lindexCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(lindexInvalidLengthErrMsg)
+	}
+	index, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	list, ok, err := datastore.GetList(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	value, ok := list.Index(index)
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return protocol.NewBulkString(value)
+}
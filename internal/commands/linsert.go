@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	linsertInvalidLengthErrMsg string = "invalid arguments for command LINSERT. Syntax: LINSERT key BEFORE|AFTER pivot element"
+)
+
+func init() {
+	linsert := linsertCommand{"linsert"}
+	registerCommand(linsert)
+}
+
+type linsertCommand struct {
+	name string
+}
+
+func (l linsertCommand) getName() string {
+	return l.name
+}
+
+func (l linsertCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 5 {
+		return protocol.NewError(linsertInvalidLengthErrMsg)
+	}
+	var before bool
+	switch strings.ToUpper(elements[2].String()) {
+	case "BEFORE":
+		before = true
+	case "AFTER":
+		before = false
+	default:
+		return protocol.NewError(linsertInvalidLengthErrMsg)
+	}
+	list, ok, err := datastore.GetList(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	if !list.Insert([]byte(elements[3].String()), []byte(elements[4].String()), before) {
+		return protocol.NewInteger(-1)
+	}
+	return protocol.NewInteger(list.Len())
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type listTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+// bulkStringArray creates the expected reply for commands that return lists of values.
+func bulkStringArray(values ...string) protocol.Array {
+	elements := []protocol.DataType{}
+	for _, value := range values {
+		elements = append(elements, protocol.NewBulkString([]byte(value)))
+	}
+	return protocol.NewArray(elements...)
+}
+
+func TestListCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	ltcs := []listTestCase{
+		{
+			name:     "LPUSH",
+			input:    "LPUSH list-lpush a b c",
+			expected: protocol.NewInteger(3),
+		},
+		{
+			name:      "LRANGE after LPUSH and RPUSH",
+			setupCmds: []string{"LPUSH list-range b a", "RPUSH list-range c d"},
+			input:     "LRANGE list-range 0 -1",
+			expected:  bulkStringArray("a", "b", "c", "d"),
+		},
+		{
+			name:     "LPUSHX on missing key",
+			input:    "LPUSHX list-pushx a",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:      "LPOP",
+			setupCmds: []string{"RPUSH list-lpop a b"},
+			input:     "LPOP list-lpop",
+			expected:  protocol.NewBulkString([]byte("a")),
+		},
+		{
+			name:      "RPOP with count",
+			setupCmds: []string{"RPUSH list-rpop a b c"},
+			input:     "RPOP list-rpop 2",
+			expected:  bulkStringArray("c", "b"),
+		},
+		{
+			name:     "LPOP with count on missing key",
+			input:    "LPOP list-missing 2",
+			expected: protocol.NewNullArray(),
+		},
+		{
+			name:      "LLEN",
+			setupCmds: []string{"RPUSH list-llen a b c"},
+			input:     "LLEN list-llen",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "LINDEX",
+			setupCmds: []string{"RPUSH list-lindex a b c"},
+			input:     "LINDEX list-lindex -1",
+			expected:  protocol.NewBulkString([]byte("c")),
+		},
+		{
+			name:      "LINDEX out of range",
+			setupCmds: []string{"RPUSH list-lindex-range a"},
+			input:     "LINDEX list-lindex-range 5",
+			expected:  protocol.NewNullBulkString(),
+		},
+		{
+			name:      "LSET",
+			setupCmds: []string{"RPUSH list-lset a b c", "LSET list-lset 1 x"},
+			input:     "LRANGE list-lset 0 -1",
+			expected:  bulkStringArray("a", "x", "c"),
+		},
+		{
+			name:      "LSET out of range",
+			setupCmds: []string{"RPUSH list-lset-range a"},
+			input:     "LSET list-lset-range 3 x",
+			expected:  protocol.NewError(indexOutOfRangeErrMsg),
+		},
+		{
+			name:      "LREM",
+			setupCmds: []string{"RPUSH list-lrem a b a c a"},
+			input:     "LREM list-lrem -2 a",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "LTRIM",
+			setupCmds: []string{"RPUSH list-ltrim a b c d", "LTRIM list-ltrim 1 2"},
+			input:     "LRANGE list-ltrim 0 -1",
+			expected:  bulkStringArray("b", "c"),
+		},
+		{
+			name:      "LINSERT",
+			setupCmds: []string{"RPUSH list-linsert a c"},
+			input:     "LINSERT list-linsert BEFORE c b",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "LINSERT missing pivot",
+			setupCmds: []string{"RPUSH list-linsert-pivot a c"},
+			input:     "LINSERT list-linsert-pivot AFTER x b",
+			expected:  protocol.NewInteger(-1),
+		},
+		{
+			name:      "LMOVE",
+			setupCmds: []string{"RPUSH list-lmove-src a b c", "RPUSH list-lmove-dst x"},
+			input:     "LMOVE list-lmove-src list-lmove-dst RIGHT LEFT",
+			expected:  protocol.NewBulkString([]byte("c")),
+		},
+		{
+			name:      "LMOVE result",
+			setupCmds: []string{"RPUSH list-lmove-src2 a b", "LMOVE list-lmove-src2 list-lmove-dst2 LEFT RIGHT", "LMOVE list-lmove-src2 list-lmove-dst2 LEFT RIGHT"},
+			input:     "LRANGE list-lmove-dst2 0 -1",
+			expected:  bulkStringArray("a", "b"),
+		},
+		{
+			name:      "Empty list is removed",
+			setupCmds: []string{"RPUSH list-empty a", "RPOP list-empty"},
+			input:     "EXISTS list-empty",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "List command on a string",
+			setupCmds: []string{"SET list-string value"},
+			input:     "LPUSH list-string a",
+			expected:  wrongType,
+		},
+		{
+			name:      "String command on a list",
+			setupCmds: []string{"RPUSH list-get a"},
+			input:     "GET list-get",
+			expected:  wrongType,
+		},
+		{
+			name:      "LMOVE to a string",
+			setupCmds: []string{"RPUSH list-lmove-wrong a", "SET list-lmove-string value"},
+			input:     "LMOVE list-lmove-wrong list-lmove-string LEFT LEFT",
+			expected:  wrongType,
+		},
+		{
+			name:      "SET overwrites a list",
+			setupCmds: []string{"RPUSH list-overwrite a", "SET list-overwrite value"},
+			input:     "GET list-overwrite",
+			expected:  protocol.NewBulkString([]byte("value")),
+		},
+	}
+	for _, tc := range ltcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	llenInvalidLengthErrMsg string = "the LLEN command accepts 2 parameters: LLEN and KEY. Received %d parameters instead"
+)
+
+func init() {
+	llen := llenCommand{"llen"}
+	registerCommand(llen)
+}
+
+type llenCommand struct {
+	name string
+}
+
+func (l llenCommand) getName() string {
+	return l.name
+}
+
+func (l llenCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(llenInvalidLengthErrMsg, len(elements)))
+	}
+	list, ok, err := datastore.GetList(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(list.Len())
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lmoveInvalidLengthErrMsg     string = "invalid arguments for command LMOVE. Syntax: LMOVE source destination LEFT|RIGHT LEFT|RIGHT"
+	rpoplpushInvalidLengthErrMsg string = "the RPOPLPUSH command accepts 3 parameters: RPOPLPUSH, SOURCE and DESTINATION. Received %d parameters instead"
+)
+
+func init() {
+	lmove := lmoveCommand{"lmove"}
+	registerCommand(lmove)
+	rpoplpush := rpoplpushCommand{"rpoplpush"}
+	registerCommand(rpoplpush)
+}
+
+type lmoveCommand struct {
+	name string
+}
+
+type rpoplpushCommand struct {
+	name string
+}
+
+func (l lmoveCommand) getName() string {
+	return l.name
+}
+
+func (l lmoveCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 5 {
+		return protocol.NewError(lmoveInvalidLengthErrMsg)
+	}
+	fromFront, ok := parseListSide(elements[3])
+	if !ok {
+		return protocol.NewError(lmoveInvalidLengthErrMsg)
+	}
+	toFront, ok := parseListSide(elements[4])
+	if !ok {
+		return protocol.NewError(lmoveInvalidLengthErrMsg)
+	}
+	return moveListElement(elements[1].String(), elements[2].String(), fromFront, toFront)
+}
+
+func (r rpoplpushCommand) getName() string {
+	return r.name
+}
+
+func (r rpoplpushCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(rpoplpushInvalidLengthErrMsg, len(elements)))
+	}
+	return moveListElement(elements[1].String(), elements[2].String(), false, true)
+}
+
+// parseListSide parses the LEFT or RIGHT argument, returning true for LEFT.
+func parseListSide(argument protocol.DataType) (bool, bool) {
+	switch strings.ToUpper(argument.String()) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// moveListElement pops an element from the source list and pushes it to the destination
+// list, replying with the element or a null if the source doesn't exist.
+func moveListElement(source, destination string, fromFront, toFront bool) protocol.DataType {
+	sourceList, ok, err := datastore.GetList(source)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	// Check the destination type before popping, so the element isn't lost
+	if _, _, err := datastore.GetList(destination); err != nil {
+		return errorReply(err)
+	}
+	value := popListElement(source, sourceList, fromFront)
+	destinationList, _ := datastore.GetOrCreateList(destination)
+	if toFront {
+		destinationList.PushFront(value)
+	} else {
+		destinationList.PushBack(value)
+	}
+	signalKeyAsReady(destination)
+	return protocol.NewBulkString(value)
+}
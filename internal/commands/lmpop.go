@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lmpopInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %snumkeys key [key ...] LEFT|RIGHT [COUNT count]"
+	numkeysErrMsg            string = "numkeys should be greater than 0"
+	countErrMsg              string = "count should be greater than 0"
+)
+
+func init() {
+	registerCommand(lmpopCommand{name: "lmpop"})
+	registerClientCommand(lmpopCommand{name: "blmpop", blocking: true})
+}
+
+// lmpopCommand implements LMPOP and its blocking version BLMPOP, which takes a timeout
+// before the other arguments.
+type lmpopCommand struct {
+	name     string
+	blocking bool
+}
+
+func (l lmpopCommand) getName() string {
+	return l.name
+}
+
+func (l lmpopCommand) processArguments(data protocol.Array) protocol.DataType {
+	return l.processClientArguments(nil, data)
+}
+
+func (l lmpopCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()[1:]
+	var timeout time.Duration
+	if l.blocking {
+		if len(elements) == 0 {
+			return l.syntaxError()
+		}
+		var errReply protocol.DataType
+		timeout, errReply = parseBlockingTimeout(elements[0])
+		if errReply != nil {
+			return errReply
+		}
+		elements = elements[1:]
+	}
+	if len(elements) < 3 {
+		return l.syntaxError()
+	}
+	numkeys, ok := parseInt(elements[0])
+	if !ok || numkeys <= 0 {
+		return protocol.NewError(numkeysErrMsg)
+	}
+	if len(elements) < numkeys+2 {
+		return l.syntaxError()
+	}
+	keys := []string{}
+	for _, element := range elements[1 : numkeys+1] {
+		keys = append(keys, element.String())
+	}
+	front, ok := parseListSide(elements[numkeys+1])
+	if !ok {
+		return l.syntaxError()
+	}
+	count := 1
+	options := elements[numkeys+2:]
+	switch {
+	case len(options) == 2 && strings.ToUpper(options[0].String()) == "COUNT":
+		count, ok = parseInt(options[1])
+		if !ok || count <= 0 {
+			return protocol.NewError(countErrMsg)
+		}
+	case len(options) != 0:
+		return l.syntaxError()
+	}
+
+	for _, key := range keys {
+		if _, _, err := datastore.GetList(key); err != nil {
+			return errorReply(err)
+		}
+	}
+	serve := func(key string) (protocol.DataType, bool) {
+		list, ok, err := datastore.GetList(key)
+		if err != nil || !ok {
+			return nil, false
+		}
+		values := [][]byte{}
+		for i := 0; i < count && list.Len() > 0; i++ {
+			values = append(values, popListElement(key, list, front))
+		}
+		return protocol.NewArray(protocol.NewBulkString([]byte(key)), bulkStrings(values)), true
+	}
+	if !l.blocking {
+		for _, key := range keys {
+			if reply, ok := serve(key); ok {
+				return reply
+			}
+		}
+		return protocol.NewNullArray()
+	}
+	return serveOrBlock(client, keys, timeout, serve, protocol.NewNullArray())
+}
+
+func (l lmpopCommand) syntaxError() protocol.DataType {
+	name := strings.ToUpper(l.name)
+	timeout := ""
+	if l.blocking {
+		timeout = "timeout "
+	}
+	return protocol.NewError(fmt.Sprintf(lmpopInvalidLengthErrMsg, name, name+" "+timeout))
+}
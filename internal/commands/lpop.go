@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	popInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key [count]"
+)
+
+func init() {
+	registerCommand(popCommand{name: "lpop", front: true})
+	registerCommand(popCommand{name: "rpop"})
+}
+
+// popCommand implements LPOP and RPOP. The front flag tells if the elements are popped
+// from the head of the list.
+type popCommand struct {
+	name  string
+	front bool
+}
+
+func (p popCommand) getName() string {
+	return p.name
+}
+
+func (p popCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 || len(elements) > 3 {
+		name := strings.ToUpper(p.name)
+		return protocol.NewError(fmt.Sprintf(popInvalidLengthErrMsg, name, name))
+	}
+	key := elements[1].String()
+	count := -1
+	if len(elements) == 3 {
+		value, ok := parseInt(elements[2])
+		if !ok || value < 0 {
+			return protocol.NewError(notPositiveErrMsg)
+		}
+		count = value
+	}
+	list, ok, err := datastore.GetList(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		if count == -1 {
+			return protocol.NewNullBulkString()
+		}
+		return protocol.NewNullArray()
+	}
+	if count == -1 {
+		value := popListElement(key, list, p.front)
+		return protocol.NewBulkString(value)
+	}
+	values := [][]byte{}
+	for i := 0; i < count && list.Len() > 0; i++ {
+		values = append(values, popListElement(key, list, p.front))
+	}
+	return bulkStrings(values)
+}
+
+// popListElement pops an element from a non empty list stored in the key, removing the
+// key once the list is empty.
+func popListElement(key string, list *datastore.List, front bool) []byte {
+	var value []byte
+	if front {
+		value, _ = list.PopFront()
+	} else {
+		value, _ = list.PopBack()
+	}
+	if list.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return value
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pushInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key element [element ...]"
+)
+
+func init() {
+	registerCommand(pushCommand{name: "lpush", front: true})
+	registerCommand(pushCommand{name: "rpush"})
+	registerCommand(pushCommand{name: "lpushx", front: true, onlyExisting: true})
+	registerCommand(pushCommand{name: "rpushx", onlyExisting: true})
+}
+
+// pushCommand implements LPUSH, RPUSH, LPUSHX and RPUSHX. The front flag tells if the
+// elements are pushed to the head of the list and onlyExisting if the list must
+// already exist.
+type pushCommand struct {
+	name         string
+	front        bool
+	onlyExisting bool
+}
+
+func (p pushCommand) getName() string {
+	return p.name
+}
+
+func (p pushCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		name := strings.ToUpper(p.name)
+		return protocol.NewError(fmt.Sprintf(pushInvalidLengthErrMsg, name, name))
+	}
+	key := elements[1].String()
+	if p.onlyExisting {
+		_, ok, err := datastore.GetList(key)
+		if err != nil {
+			return errorReply(err)
+		}
+		if !ok {
+			return protocol.NewInteger(0)
+		}
+	}
+	list, err := datastore.GetOrCreateList(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	for _, element := range elements[2:] {
+		if p.front {
+			list.PushFront([]byte(element.String()))
+		} else {
+			list.PushBack([]byte(element.String()))
+		}
+	}
+	signalKeyAsReady(key)
+	return protocol.NewInteger(list.Len())
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lrangeInvalidLengthErrMsg string = "invalid arguments for command LRANGE. Syntax: LRANGE key start stop"
+)
+
+func init() {
+	lrange := lrangeCommand{"lrange"}
+	registerCommand(lrange)
+}
+
+type lrangeCommand struct {
+	name string
+}
+
+func (l lrangeCommand) getName() string {
+	return l.name
+}
+
+func (l lrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(lrangeInvalidLengthErrMsg)
+	}
+	start, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	stop, ok := parseInt(elements[3])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	list, ok, err := datastore.GetList(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewArray()
+	}
+	return bulkStrings(list.Range(start, stop))
+}
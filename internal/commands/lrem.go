@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lremInvalidLengthErrMsg string = "invalid arguments for command LREM. Syntax: LREM key count element"
+)
+
+func init() {
+	lrem := lremCommand{"lrem"}
+	registerCommand(lrem)
+}
+
+type lremCommand struct {
+	name string
+}
+
+func (l lremCommand) getName() string {
+	return l.name
+}
+
+func (l lremCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(lremInvalidLengthErrMsg)
+	}
+	count, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	key := elements[1].String()
+	list, ok, err := datastore.GetList(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	removed := list.Remove(count, []byte(elements[3].String()))
+	if list.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewInteger(removed)
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	lsetInvalidLengthErrMsg string = "invalid arguments for command LSET. Syntax: LSET key index element"
+	noSuchKeyErrMsg         string = "no such key"
+	indexOutOfRangeErrMsg   string = "index out of range"
+)
+
+func init() {
+	lset := lsetCommand{"lset"}
+	registerCommand(lset)
+}
+
+type lsetCommand struct {
+	name string
+}
+
+func (l lsetCommand) getName() string {
+	return l.name
+}
+
+func (l lsetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(lsetInvalidLengthErrMsg)
+	}
+	index, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	list, ok, err := datastore.GetList(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewError(noSuchKeyErrMsg)
+	}
+	if !list.Set(index, []byte(elements[3].String())) {
+		return protocol.NewError(indexOutOfRangeErrMsg)
+	}
+	return protocol.NewSimpleString("OK")
+}
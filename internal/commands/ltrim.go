@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	ltrimInvalidLengthErrMsg string = "invalid arguments for command LTRIM. Syntax: LTRIM key start stop"
+)
+
+func init() {
+	ltrim := ltrimCommand{"ltrim"}
+	registerCommand(ltrim)
+}
+
+type ltrimCommand struct {
+	name string
+}
+
+func (l ltrimCommand) getName() string {
+	return l.name
+}
+
+func (l ltrimCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(ltrimInvalidLengthErrMsg)
+	}
+	start, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	stop, ok := parseInt(elements[3])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	key := elements[1].String()
+	list, ok, err := datastore.GetList(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewSimpleString("OK")
+	}
+	list.Trim(start, stop)
+	if list.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewSimpleString("OK")
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	mgetInvalidLengthErrMsg string = "invalid arguments for command MGET. Syntax: MGET key [key ...]"
+)
+
+func init() {
+	mget := mgetCommand{"mget"}
+	registerCommand(mget)
+}
+
+type mgetCommand struct {
+	name string
+}
+
+func (m mgetCommand) getName() string {
+	return m.name
+}
+
+// processArguments returns the strings stored in the keys. Like in Redis, keys that
+// don't exist or hold another type are replied as nil instead of failing the command.
+func (m mgetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(mgetInvalidLengthErrMsg)
+	}
+	values := []protocol.DataType{}
+	for _, key := range elements[1:] {
+		val, ok, err := datastore.Get(key.String())
+		if err != nil || !ok {
+			values = append(values, protocol.NewNullBulkString())
+			continue
+		}
+		values = append(values, val)
+	}
+	return protocol.NewArray(values...)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	msetInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key value [key value ...]"
+)
+
+func init() {
+	registerCommand(msetCommand{name: "mset"})
+	registerCommand(msetCommand{name: "msetnx", nx: true})
+}
+
+// msetCommand implements MSET and MSETNX, which only stores the values if none of the
+// keys exist.
+type msetCommand struct {
+	name string
+	nx   bool
+}
+
+func (m msetCommand) getName() string {
+	return m.name
+}
+
+// processArguments stores each value in its key, discarding any previous time to live
+// like SET. MSETNX is all or nothing: it returns 0 without storing anything if any of
+// the keys exists, and 1 otherwise.
+func (m msetCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 || len(elements)%2 == 0 {
+		name := strings.ToUpper(m.name)
+		return protocol.NewError(fmt.Sprintf(msetInvalidLengthErrMsg, name, name))
+	}
+	pairs := elements[1:]
+	if m.nx {
+		for i := 0; i < len(pairs); i += 2 {
+			if datastore.Exists(pairs[i].String()) {
+				return protocol.NewInteger(0)
+			}
+		}
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		datastore.Set(pairs[i].String(), pairs[i+1])
+	}
+	if m.nx {
+		return protocol.NewInteger(1)
+	}
+	return protocol.NewSimpleString("OK")
+}
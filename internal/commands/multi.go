@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	multiNestedErrMsg        string = "MULTI calls can not be nested"
+	execWithoutMultiErrMsg   string = "EXEC without MULTI"
+	discardNoMultiErrMsg     string = "DISCARD without MULTI"
+	execAbortErrMsg          string = "EXECABORT Transaction discarded because of previous errors."
+	watchInMultiErrMsg       string = "WATCH inside MULTI is not allowed"
+	notInMultiErrMsg         string = "Command not allowed inside a transaction"
+	wrongArityErrMsg         string = "wrong number of arguments for '%s' command"
+	multiInvalidLengthErrMsg string = "the %s command accepts 1 parameter: %s. Received %d parameters instead"
+	watchInvalidLengthErrMsg string = "invalid arguments for command WATCH. Syntax: WATCH key [key ...]"
+)
+
+// transactionCommands are the commands run right away in a transaction instead of
+// being queued.
+var transactionCommands = map[string]bool{
+	"multi":   true,
+	"exec":    true,
+	"discard": true,
+	"watch":   true,
+	"unwatch": true,
+}
+
+// notInMultiCommands are the commands that can't be queued in a transaction, since
+// their reply doesn't fit in the reply of EXEC.
+var notInMultiCommands = map[string]bool{
+	"subscribe":    true,
+	"psubscribe":   true,
+	"ssubscribe":   true,
+	"unsubscribe":  true,
+	"punsubscribe": true,
+	"sunsubscribe": true,
+}
+
+func init() {
+	registerClientCommand(multiCommand{"multi"})
+	registerClientCommand(execCommand{"exec"})
+	registerClientCommand(discardCommand{"discard"})
+	registerClientCommand(watchCommand{"watch"})
+	registerClientCommand(unwatchCommand{"unwatch"})
+}
+
+// transaction holds the state of a client between MULTI and EXEC: the commands queued
+// and whether one of them was rejected, which makes EXEC discard the transaction.
+type transaction struct {
+	queued  []protocol.Array
+	aborted bool
+}
+
+// multiCommand starts a transaction. The commands sent after it are queued and run
+// together by EXEC, with no other command running in between.
+type multiCommand struct {
+	name string
+}
+
+// execCommand runs the commands queued since MULTI, replying with an array of their
+// replies. If any of the keys watched by the client was modified since WATCH, nothing
+// runs and the reply is null.
+type execCommand struct {
+	name string
+}
+
+// discardCommand drops the commands queued since MULTI.
+type discardCommand struct {
+	name string
+}
+
+// watchCommand makes the next EXEC of the client fail if any of the keys is modified,
+// deleted or expired before it runs, which is how transactions do optimistic locking.
+type watchCommand struct {
+	name string
+}
+
+// unwatchCommand forgets the keys watched by the client.
+type unwatchCommand struct {
+	name string
+}
+
+func (m multiCommand) getName() string {
+	return m.name
+}
+
+func (m multiCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	if errReply := noArguments(data); errReply != nil {
+		return errReply
+	}
+	if client.multi != nil {
+		return protocol.NewError(multiNestedErrMsg)
+	}
+	client.multi = &transaction{}
+	return protocol.NewSimpleString("OK")
+}
+
+func (e execCommand) getName() string {
+	return e.name
+}
+
+func (e execCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	if errReply := noArguments(data); errReply != nil {
+		return errReply
+	}
+	multi := client.multi
+	if multi == nil {
+		return protocol.NewError(execWithoutMultiErrMsg)
+	}
+	client.multi = nil
+	defer unwatch(client)
+	if multi.aborted {
+		return protocol.NewError(execAbortErrMsg)
+	}
+	for key, version := range client.watched {
+		if datastore.Version(key) != version {
+			return protocol.NewNullArray()
+		}
+	}
+	// The commands run one after the other in the task loop, so nothing else runs in
+	// between. A blocking command doesn't block inside a transaction, it replies as if
+	// its timeout was over.
+	client.nonBlocking = true
+	defer func() { client.nonBlocking = false }()
+	reply := make([]protocol.DataType, 0, len(multi.queued))
+	for _, command := range multi.queued {
+		reply = append(reply, dispatchCommand(client, command))
+	}
+	return protocol.NewArray(reply...)
+}
+
+func (d discardCommand) getName() string {
+	return d.name
+}
+
+func (d discardCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	if errReply := noArguments(data); errReply != nil {
+		return errReply
+	}
+	if client.multi == nil {
+		return protocol.NewError(discardNoMultiErrMsg)
+	}
+	client.multi = nil
+	unwatch(client)
+	return protocol.NewSimpleString("OK")
+}
+
+func (w watchCommand) getName() string {
+	return w.name
+}
+
+func (w watchCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(watchInvalidLengthErrMsg)
+	}
+	if client.multi != nil {
+		client.multi.aborted = true
+		return protocol.NewError(watchInMultiErrMsg)
+	}
+	for _, key := range elements[1:] {
+		// A key watched twice keeps the version of the first WATCH
+		if _, ok := client.watched[key.String()]; !ok {
+			client.watched[key.String()] = datastore.Watch(key.String())
+		}
+	}
+	return protocol.NewSimpleString("OK")
+}
+
+func (u unwatchCommand) getName() string {
+	return u.name
+}
+
+func (u unwatchCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	if errReply := noArguments(data); errReply != nil {
+		return errReply
+	}
+	unwatch(client)
+	return protocol.NewSimpleString("OK")
+}
+
+// queueCommand queues a command sent by a client in a transaction. Like in Redis, the
+// commands that are unknown or have the wrong number of arguments are rejected right
+// away, and make EXEC discard the whole transaction.
+func queueCommand(client *Client, name string, data protocol.Array) protocol.DataType {
+	var errReply protocol.DataType
+	_, ok := registeredCommands[name]
+	_, clientOk := registeredClientCommands[name]
+	switch {
+	case !ok && !clientOk:
+		errReply = protocol.NewError(fmt.Sprintf("invalid command %s", data.GetElements()[0].String()))
+	case notInMultiCommands[name]:
+		errReply = protocol.NewError(notInMultiErrMsg)
+	case !validArity(name, len(data.GetElements())):
+		errReply = protocol.NewError(fmt.Sprintf(wrongArityErrMsg, name))
+	}
+	if errReply != nil {
+		client.multi.aborted = true
+		return errReply
+	}
+	client.multi.queued = append(client.multi.queued, data)
+	return protocol.NewSimpleString("QUEUED")
+}
+
+func unwatch(client *Client) {
+	for key := range client.watched {
+		datastore.Unwatch(key)
+	}
+	clear(client.watched)
+}
+
+// noArguments returns an error reply if the command was given any argument.
+func noArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 1 {
+		name := strings.ToUpper(elements[0].String())
+		return protocol.NewError(fmt.Sprintf(multiInvalidLengthErrMsg, name, name, len(elements)))
+	}
+	return nil
+}
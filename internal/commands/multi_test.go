@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type multiTestCase struct {
+	name     string
+	commands []string
+	expected []protocol.DataType
+}
+
+func TestTransactions(t *testing.T) {
+	ok := protocol.NewSimpleString("OK")
+	queued := protocol.NewSimpleString("QUEUED")
+	mtcs := []multiTestCase{
+		{
+			name:     "EXEC runs the queued commands",
+			commands: []string{"MULTI", "SET multi-exec 1", "INCR multi-exec", "GET multi-exec", "EXEC"},
+			expected: []protocol.DataType{ok, queued, queued, queued, protocol.NewArray(ok, protocol.NewInteger(2), bulkString("2"))},
+		},
+		{
+			name:     "Runtime errors don't stop the transaction",
+			commands: []string{"MULTI", "SET multi-runtime a", "LPUSH multi-runtime b", "GET multi-runtime", "EXEC"},
+			expected: []protocol.DataType{ok, queued, queued, queued, protocol.NewArray(ok, protocol.NewError(datastore.ErrWrongType.Error()), bulkString("a"))},
+		},
+		{
+			name:     "Queuing errors abort the transaction",
+			commands: []string{"MULTI", "SET multi-abort 1", "GET", "EXEC", "EXISTS multi-abort"},
+			expected: []protocol.DataType{ok, queued, protocol.NewError(fmt.Sprintf(wrongArityErrMsg, "get")), protocol.NewError(execAbortErrMsg), protocol.NewInteger(0)},
+		},
+		{
+			name:     "Unknown commands abort the transaction",
+			commands: []string{"MULTI", "NOPE", "EXEC"},
+			expected: []protocol.DataType{ok, protocol.NewError("invalid command NOPE"), protocol.NewError(execAbortErrMsg)},
+		},
+		{
+			name:     "DISCARD drops the queued commands",
+			commands: []string{"MULTI", "SET multi-discard 1", "DISCARD", "EXISTS multi-discard"},
+			expected: []protocol.DataType{ok, queued, ok, protocol.NewInteger(0)},
+		},
+		{
+			name:     "MULTI can't be nested",
+			commands: []string{"MULTI", "MULTI", "EXEC"},
+			expected: []protocol.DataType{ok, protocol.NewError(multiNestedErrMsg), bulkStringArray()},
+		},
+		{
+			name:     "EXEC and DISCARD without MULTI",
+			commands: []string{"EXEC", "DISCARD"},
+			expected: []protocol.DataType{protocol.NewError(execWithoutMultiErrMsg), protocol.NewError(discardNoMultiErrMsg)},
+		},
+		{
+			name:     "WATCH inside MULTI",
+			commands: []string{"MULTI", "WATCH multi-watch-inside", "EXEC"},
+			expected: []protocol.DataType{ok, protocol.NewError(watchInMultiErrMsg), protocol.NewError(execAbortErrMsg)},
+		},
+		{
+			name:     "Subscribing isn't allowed in a transaction",
+			commands: []string{"MULTI", "SUBSCRIBE multi-channel", "EXEC"},
+			expected: []protocol.DataType{ok, protocol.NewError(notInMultiErrMsg), protocol.NewError(execAbortErrMsg)},
+		},
+		{
+			name:     "Blocking commands don't block in a transaction",
+			commands: []string{"MULTI", "BLPOP multi-blocking 0", "XREAD BLOCK 0 STREAMS multi-stream $", "EXEC"},
+			expected: []protocol.DataType{ok, queued, queued, protocol.NewArray(protocol.NewNullArray(), protocol.NewNullArray())},
+		},
+		{
+			name:     "Scripts don't let blocking commands block in a transaction",
+			commands: []string{"MULTI", "EVAL \"return 1\" 0", "BLPOP multi-script-blocking 0", "EXEC"},
+			expected: []protocol.DataType{ok, queued, queued, protocol.NewArray(protocol.NewInteger(1), protocol.NewNullArray())},
+		},
+		{
+			name:     "Unmodified watched keys",
+			commands: []string{"SET multi-watched 1", "WATCH multi-watched multi-watched-missing", "GET multi-watched", "MULTI", "INCR multi-watched", "EXEC"},
+			expected: []protocol.DataType{ok, ok, bulkString("1"), ok, queued, protocol.NewArray(protocol.NewInteger(2))},
+		},
+		{
+			name:     "Modified watched keys abort EXEC",
+			commands: []string{"WATCH multi-modified", "SET multi-modified 1", "MULTI", "INCR multi-modified", "EXEC", "GET multi-modified"},
+			expected: []protocol.DataType{ok, ok, ok, queued, protocol.NewNullArray(), bulkString("1")},
+		},
+		{
+			name:     "Missing watched keys created and deleted abort EXEC",
+			commands: []string{"WATCH multi-recreated", "SET multi-recreated 1", "DEL multi-recreated", "MULTI", "SET multi-recreated-other 1", "EXEC"},
+			expected: []protocol.DataType{ok, ok, protocol.NewInteger(1), ok, queued, protocol.NewNullArray()},
+		},
+		{
+			name:     "UNWATCH forgets the watched keys",
+			commands: []string{"WATCH multi-unwatch", "SET multi-unwatch 1", "UNWATCH", "MULTI", "INCR multi-unwatch", "EXEC"},
+			expected: []protocol.DataType{ok, ok, ok, ok, queued, protocol.NewArray(protocol.NewInteger(2))},
+		},
+		{
+			name:     "EXEC forgets the watched keys",
+			commands: []string{"WATCH multi-after", "SET multi-after 1", "MULTI", "EXEC", "MULTI", "INCR multi-after", "EXEC"},
+			expected: []protocol.DataType{ok, ok, ok, protocol.NewNullArray(), ok, queued, protocol.NewArray(protocol.NewInteger(2))},
+		},
+	}
+	for _, tc := range mtcs {
+		t.Run(tc.name, func(t *testing.T) {
+			client := NewClient()
+			for i, cmd := range tc.commands {
+				actual := processClientInline(t, client, cmd)
+				if !reflect.DeepEqual(actual, tc.expected[i]) {
+					t.Fatalf("unexpected reply to %s. Expected: %v, Actual: %v", cmd, tc.expected[i], actual)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchDetectsChanges(t *testing.T) {
+	processInline(t, "RPUSH multi-list a")
+	processInline(t, "HSET multi-hash f v")
+	processInline(t, "XADD multi-stream-groups 1-1 f v")
+	processInline(t, "XGROUP CREATE multi-stream-groups group 0")
+	processInline(t, "SET multi-expiring v PX 20")
+	changes := map[string]string{
+		"multi-list":           "LSET multi-list 0 b",
+		"multi-hash":           "HDEL multi-hash f",
+		"multi-stream-groups":  "XREADGROUP GROUP group consumer STREAMS multi-stream-groups >",
+		"multi-expiring":       "",
+		"multi-deleted-string": "DEL multi-deleted-string",
+	}
+	processInline(t, "SET multi-deleted-string v")
+	for key, change := range changes {
+		t.Run(key, func(t *testing.T) {
+			client := NewClient()
+			processClientInline(t, client, "WATCH "+key)
+			if change == "" {
+				time.Sleep(30 * time.Millisecond)
+			} else {
+				processClientInline(t, NewClient(), change)
+			}
+			processClientInline(t, client, "MULTI")
+			processClientInline(t, client, "PING")
+			if reply := processClientInline(t, client, "EXEC"); reply != protocol.NewNullArray() {
+				t.Fatalf("EXEC should have been aborted, got %v", reply)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	objectInvalidLengthErrMsg string = "invalid arguments for command OBJECT. Syntax: OBJECT ENCODING key"
+	objectUnknownErrMsg       string = "unknown subcommand '%s'. Try OBJECT HELP."
+)
+
+func init() {
+	object := objectCommand{"object"}
+	registerCommand(object)
+}
+
+type objectCommand struct {
+	name string
+}
+
+func (o objectCommand) getName() string {
+	return o.name
+}
+
+// processArguments implements OBJECT ENCODING, which returns how the value stored in
+// the key is represented, like intset or hashtable for a set.
+func (o objectCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(objectInvalidLengthErrMsg)
+	}
+	if subcommand := elements[1].String(); strings.ToUpper(subcommand) != "ENCODING" {
+		return protocol.NewError(fmt.Sprintf(objectUnknownErrMsg, subcommand))
+	}
+	if len(elements) != 3 {
+		return protocol.NewError(objectInvalidLengthErrMsg)
+	}
+	encoding, ok := datastore.Encoding(elements[2].String())
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return protocol.NewBulkString([]byte(encoding))
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type objectTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestObjectCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	otcs := []objectTestCase{
+		{
+			name:      "TYPE of a string",
+			setupCmds: []string{"SET object-type-string value"},
+			input:     "TYPE object-type-string",
+			expected:  protocol.NewSimpleString("string"),
+		},
+		{
+			name:      "TYPE of a sorted set",
+			setupCmds: []string{"ZADD object-type-zset 1 a"},
+			input:     "TYPE object-type-zset",
+			expected:  protocol.NewSimpleString("zset"),
+		},
+		{
+			name:      "TYPE of a stream",
+			setupCmds: []string{"XADD object-type-stream * a 1"},
+			input:     "TYPE object-type-stream",
+			expected:  protocol.NewSimpleString("stream"),
+		},
+		{
+			name:     "TYPE of a missing key",
+			input:    "TYPE object-missing",
+			expected: protocol.NewSimpleString("none"),
+		},
+		{
+			name:      "OBJECT ENCODING of an integer",
+			setupCmds: []string{"SET object-int 12345"},
+			input:     "OBJECT ENCODING object-int",
+			expected:  protocol.NewBulkString([]byte("int")),
+		},
+		{
+			name:      "OBJECT ENCODING of a short string",
+			setupCmds: []string{"SET object-embstr 012345"},
+			input:     "OBJECT ENCODING object-embstr",
+			expected:  protocol.NewBulkString([]byte("embstr")),
+		},
+		{
+			name:      "OBJECT ENCODING of a long string",
+			setupCmds: []string{"SET object-raw " + strings.Repeat("a", 45)},
+			input:     "OBJECT ENCODING object-raw",
+			expected:  protocol.NewBulkString([]byte("raw")),
+		},
+		{
+			name:      "OBJECT ENCODING of an intset",
+			setupCmds: []string{"SADD object-intset 1 2 3"},
+			input:     "OBJECT ENCODING object-intset",
+			expected:  protocol.NewBulkString([]byte("intset")),
+		},
+		{
+			name:      "OBJECT ENCODING of a set converted to hashtable",
+			setupCmds: []string{"SADD object-set 1 2 3", "SADD object-set a"},
+			input:     "OBJECT ENCODING object-set",
+			expected:  protocol.NewBulkString([]byte("hashtable")),
+		},
+		{
+			name:      "OBJECT ENCODING of a small sorted set",
+			setupCmds: []string{"ZADD object-zset 1 a"},
+			input:     "OBJECT ENCODING object-zset",
+			expected:  protocol.NewBulkString([]byte("listpack")),
+		},
+		{
+			name:      "OBJECT ENCODING of a sorted set with a long member",
+			setupCmds: []string{"ZADD object-skiplist 1 " + strings.Repeat("a", 65)},
+			input:     "OBJECT ENCODING object-skiplist",
+			expected:  protocol.NewBulkString([]byte("skiplist")),
+		},
+		{
+			name:     "OBJECT ENCODING of a missing key",
+			input:    "OBJECT ENCODING object-missing",
+			expected: protocol.NewNullBulkString(),
+		},
+		{
+			name:     "OBJECT with unknown subcommand",
+			input:    "OBJECT FOO object-missing",
+			expected: protocol.NewError(fmt.Sprintf(objectUnknownErrMsg, "FOO")),
+		},
+		{
+			name:      "INCR keeps the time to live",
+			setupCmds: []string{"SET object-incr-ttl 10 EX 100", "INCR object-incr-ttl"},
+			input:     "TTL object-incr-ttl",
+			expected:  protocol.NewInteger(100),
+		},
+		{
+			name:      "INCR on a non integer",
+			setupCmds: []string{"SET object-incr-text 1.5"},
+			input:     "INCR object-incr-text",
+			expected:  protocol.NewError(notIntegerErrMsg),
+		},
+		{
+			name:      "INCR overflow",
+			setupCmds: []string{"SET object-incr-overflow 9223372036854775807"},
+			input:     "INCR object-incr-overflow",
+			expected:  protocol.NewError(overflowErrMsg),
+		},
+		{
+			name:      "INCR on a list",
+			setupCmds: []string{"RPUSH object-incr-list 1"},
+			input:     "INCR object-incr-list",
+			expected:  wrongType,
+		},
+		{
+			name:      "GET on a hash",
+			setupCmds: []string{"HSET object-get-hash a 1"},
+			input:     "GET object-get-hash",
+			expected:  wrongType,
+		},
+		{
+			name:      "SADD on a sorted set",
+			setupCmds: []string{"ZADD object-sadd-zset 1 a"},
+			input:     "SADD object-sadd-zset a",
+			expected:  wrongType,
+		},
+		{
+			name:      "XLEN on a list",
+			setupCmds: []string{"RPUSH object-xlen-list a"},
+			input:     "XLEN object-xlen-list",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range otcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+// The output limits of the clients in subscribed mode, the same defaults as the
+// client-output-buffer-limit pubsub option of Redis. A subscriber that doesn't read its
+// messages fast enough is disconnected once its pending output reaches the hard limit,
+// or stays over the soft limit for longer than the soft limit duration.
+var (
+	pubsubHardLimit         = 32 * 1024 * 1024
+	pubsubSoftLimit         = 8 * 1024 * 1024
+	pubsubSoftLimitDuration = 60 * time.Second
+)
+
+// output holds the encoded frames waiting to be written to the connection of a client.
+// The replies and the messages pushed by the task loop go through the same output, so
+// the client receives them in the order they were produced.
+type output struct {
+	mu      sync.Mutex
+	pending []byte
+	// overSoftLimitSince is when the pending output went over the soft limit
+	overSoftLimitSince time.Time
+	// disconnect is set when the client went over the output limits and its connection
+	// must be closed
+	disconnect bool
+	closed     bool
+	ready      chan struct{}
+}
+
+func newOutput() *output {
+	return &output{ready: make(chan struct{}, 1)}
+}
+
+// Write adds the reply to a command to the output of the client. The reply must already
+// be converted to the protocol version of the client.
+func (c *Client) Write(reply protocol.DataType) {
+	c.output.write(reply.Encode(), false)
+}
+
+// push adds a message the client didn't ask for, like the messages published to its
+// channels, to its output. Pushes are what the output limits apply to.
+func (c *Client) push(message protocol.DataType) {
+	c.output.write(protocol.Convert(message, c.protocolVersion).Encode(), true)
+}
+
+// OutputReady returns a channel that receives a value when there is output to be taken,
+// and is closed when the client is freed.
+func (c *Client) OutputReady() <-chan struct{} {
+	return c.output.ready
+}
+
+// TakeOutput returns the pending output of the client, emptying it. The bool is true if
+// the client went over its output limits and must be disconnected.
+func (c *Client) TakeOutput() ([]byte, bool) {
+	o := c.output
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending := o.pending
+	o.pending = nil
+	o.overSoftLimitSince = time.Time{}
+	return pending, o.disconnect
+}
+
+func (o *output) write(frame []byte, limited bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closed || o.disconnect {
+		return
+	}
+	o.pending = append(o.pending, frame...)
+	if limited {
+		o.checkLimits(time.Now())
+	}
+	o.signal()
+}
+
+// checkLimits flags the client to be disconnected if its pending output is over the
+// limits. The pending output is dropped, since it won't be written.
+func (o *output) checkLimits(now time.Time) {
+	size := len(o.pending)
+	switch {
+	case size >= pubsubHardLimit:
+		o.disconnect = true
+	case size > pubsubSoftLimit:
+		if o.overSoftLimitSince.IsZero() {
+			o.overSoftLimitSince = now
+		} else if now.Sub(o.overSoftLimitSince) >= pubsubSoftLimitDuration {
+			o.disconnect = true
+		}
+	default:
+		o.overSoftLimitSince = time.Time{}
+	}
+	if o.disconnect {
+		o.pending = nil
+	}
+}
+
+func (o *output) signal() {
+	select {
+	case o.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (o *output) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.closed {
+		o.closed = true
+		close(o.ready)
+	}
+}
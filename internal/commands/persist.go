@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	persistInvalidLengthErrMsg string = "the PERSIST command accepts 2 parameters: PERSIST and KEY. Received %d parameters instead"
+)
+
+func init() {
+	persist := persistCommand{"persist"}
+	registerCommand(persist)
+}
+
+type persistCommand struct {
+	name string
+}
+
+func (p persistCommand) getName() string {
+	return p.name
+}
+
+func (p persistCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(persistInvalidLengthErrMsg, len(elements)))
+	}
+	key := elements[1].String()
+	currentExpire, ok := datastore.GetExpire(key)
+	if !ok || currentExpire == 0 {
+		return protocol.NewInteger(0)
+	}
+	datastore.SetExpire(key, 0)
+	return protocol.NewInteger(1)
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pfaddInvalidLengthErrMsg string = "invalid arguments for command PFADD. Syntax: PFADD key [element [element ...]]"
+)
+
+func init() {
+	pfadd := pfaddCommand{"pfadd"}
+	registerCommand(pfadd)
+}
+
+type pfaddCommand struct {
+	name string
+}
+
+func (p pfaddCommand) getName() string {
+	return p.name
+}
+
+// processArguments adds the elements to the HyperLogLog stored in the key, creating it
+// if the key doesn't exist. It returns 1 if the estimated cardinality may have changed
+// or the key was created, and 0 otherwise.
+func (p pfaddCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(pfaddInvalidLengthErrMsg)
+	}
+	key := elements[1].String()
+	hll, ok, err := datastore.GetHyperLogLog(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	changed := !ok
+	if !ok {
+		hll = datastore.NewHyperLogLog()
+	}
+	for _, element := range elements[2:] {
+		if hll.Add([]byte(element.String())) {
+			changed = true
+		}
+	}
+	if !changed {
+		return protocol.NewInteger(0)
+	}
+	datastore.StoreHyperLogLog(key, hll)
+	return protocol.NewInteger(1)
+}
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pfcountInvalidLengthErrMsg string = "invalid arguments for command PFCOUNT. Syntax: PFCOUNT key [key ...]"
+)
+
+func init() {
+	pfcount := pfcountCommand{"pfcount"}
+	registerCommand(pfcount)
+}
+
+type pfcountCommand struct {
+	name string
+}
+
+func (p pfcountCommand) getName() string {
+	return p.name
+}
+
+// processArguments returns the estimated cardinality of the HyperLogLog stored in the
+// key, or of the union of the HyperLogLogs stored in the keys. Missing keys are taken as
+// empty. For a single key the estimate is cached in the HyperLogLog, like in Redis.
+func (p pfcountCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(pfcountInvalidLengthErrMsg)
+	}
+	if len(elements) == 2 {
+		key := elements[1].String()
+		hll, ok, err := datastore.GetHyperLogLog(key)
+		if err != nil {
+			return errorReply(err)
+		}
+		if !ok {
+			return protocol.NewInteger(0)
+		}
+		cached := hll.IsCountCached()
+		count := hll.Count()
+		if !cached {
+			datastore.StoreHyperLogLog(key, hll)
+		}
+		return protocol.NewInteger(int(count))
+	}
+	union := datastore.NewHyperLogLog()
+	for _, key := range elements[1:] {
+		hll, ok, err := datastore.GetHyperLogLog(key.String())
+		if err != nil {
+			return errorReply(err)
+		}
+		if ok {
+			union.Merge(hll)
+		}
+	}
+	return protocol.NewInteger(int(union.Count()))
+}
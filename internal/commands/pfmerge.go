@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pfmergeInvalidLengthErrMsg string = "invalid arguments for command PFMERGE. Syntax: PFMERGE destkey [sourcekey [sourcekey ...]]"
+)
+
+func init() {
+	pfmerge := pfmergeCommand{"pfmerge"}
+	registerCommand(pfmerge)
+}
+
+type pfmergeCommand struct {
+	name string
+}
+
+func (p pfmergeCommand) getName() string {
+	return p.name
+}
+
+// processArguments stores the union of the HyperLogLogs stored in the destination key
+// and the source keys in the destination key, creating it if it doesn't exist.
+func (p pfmergeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(pfmergeInvalidLengthErrMsg)
+	}
+	destination := elements[1].String()
+	union, ok, err := datastore.GetHyperLogLog(destination)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		union = datastore.NewHyperLogLog()
+	}
+	for _, key := range elements[2:] {
+		hll, ok, err := datastore.GetHyperLogLog(key.String())
+		if err != nil {
+			return errorReply(err)
+		}
+		if ok {
+			union.Merge(hll)
+		}
+	}
+	datastore.StoreHyperLogLog(destination, union)
+	return protocol.NewSimpleString("OK")
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pingInvalidLengthErrMsg string = "invalid arguments for command PING. Syntax: PING [message]"
+)
+
+func init() {
+	ping := pingCommand{"ping"}
+	registerClientCommand(ping)
+}
+
+// pingCommand replies with PONG, or with the message given. Like in Redis, a RESP2
+// client in subscribed mode gets the reply as an array, the same shape as the messages,
+// since it can only read pushes.
+type pingCommand struct {
+	name string
+}
+
+func (p pingCommand) getName() string {
+	return p.name
+}
+
+func (p pingCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) > 2 {
+		return protocol.NewError(pingInvalidLengthErrMsg)
+	}
+	if client.protocolVersion == protocol.RESP2 && client.subscriptions() > 0 {
+		var message protocol.DataType = bulkString("")
+		if len(elements) == 2 {
+			message = elements[1]
+		}
+		return protocol.NewArray(bulkString("pong"), message)
+	}
+	if len(elements) == 2 {
+		return elements[1]
+	}
+	return protocol.NewSimpleString("PONG")
+}
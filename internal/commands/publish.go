@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	publishInvalidLengthErrMsg string = "the %s command accepts 3 parameters: %s, CHANNEL and MESSAGE. Received %d parameters instead"
+)
+
+func init() {
+	registerCommand(publishCommand{name: "publish"})
+	registerCommand(publishCommand{name: "spublish", shard: true})
+}
+
+// publishCommand implements PUBLISH, which sends a message to the subscribers of a
+// channel, and SPUBLISH, which sends it to the subscribers of a sharded channel. The
+// reply is how many subscribers received it.
+type publishCommand struct {
+	name  string
+	shard bool
+}
+
+func (p publishCommand) getName() string {
+	return p.name
+}
+
+func (p publishCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		name := strings.ToUpper(p.name)
+		return protocol.NewError(fmt.Sprintf(publishInvalidLengthErrMsg, name, name, len(elements)))
+	}
+	if p.shard {
+		return protocol.NewInteger(publishShard(elements[1].String(), elements[2]))
+	}
+	return protocol.NewInteger(publish(elements[1].String(), elements[2]))
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	pubsubInvalidLengthErrMsg string = "invalid arguments for command PUBSUB. Syntax: PUBSUB <CHANNELS [pattern] | NUMSUB [channel [channel ...]] | NUMPAT | SHARDCHANNELS [pattern] | SHARDNUMSUB [shardchannel [shardchannel ...]]>"
+	pubsubUnknownErrMsg       string = "unknown subcommand '%s'. Try PUBSUB HELP."
+)
+
+var (
+	// channelSubscribers holds the clients subscribed to each channel,
+	// patternSubscribers the clients subscribed to each pattern and
+	// shardChannelSubscribers the clients subscribed to each sharded channel. Channels
+	// and patterns without subscribers are removed.
+	channelSubscribers      = make(map[string]map[*Client]struct{})
+	patternSubscribers      = make(map[string]map[*Client]struct{})
+	shardChannelSubscribers = make(map[string]map[*Client]struct{})
+)
+
+func init() {
+	pubsub := pubsubCommand{"pubsub"}
+	registerCommand(pubsub)
+}
+
+// pubsubCommand implements the PUBSUB introspection subcommands: CHANNELS lists the
+// channels with subscribers, optionally matching a pattern, NUMSUB counts the
+// subscribers of channels and NUMPAT counts the patterns with subscribers.
+// SHARDCHANNELS and SHARDNUMSUB are CHANNELS and NUMSUB for the sharded channels.
+type pubsubCommand struct {
+	name string
+}
+
+func (p pubsubCommand) getName() string {
+	return p.name
+}
+
+func (p pubsubCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(pubsubInvalidLengthErrMsg)
+	}
+	arguments := elements[2:]
+	switch subcommand := elements[1].String(); strings.ToUpper(subcommand) {
+	case "CHANNELS":
+		return activeChannels(channelSubscribers, arguments)
+	case "SHARDCHANNELS":
+		return activeChannels(shardChannelSubscribers, arguments)
+	case "NUMSUB":
+		return subscriberCounts(channelSubscribers, arguments)
+	case "SHARDNUMSUB":
+		return subscriberCounts(shardChannelSubscribers, arguments)
+	case "NUMPAT":
+		if len(arguments) > 0 {
+			return protocol.NewError(pubsubInvalidLengthErrMsg)
+		}
+		return protocol.NewInteger(len(patternSubscribers))
+	default:
+		return protocol.NewError(fmt.Sprintf(pubsubUnknownErrMsg, subcommand))
+	}
+}
+
+// activeChannels lists the channels with subscribers, matching the optional pattern in
+// the arguments.
+func activeChannels(subscribers map[string]map[*Client]struct{}, arguments []protocol.DataType) protocol.DataType {
+	if len(arguments) > 1 {
+		return protocol.NewError(pubsubInvalidLengthErrMsg)
+	}
+	reply := []protocol.DataType{}
+	for _, channel := range slices.Sorted(maps.Keys(subscribers)) {
+		if len(arguments) == 0 || matchPattern(arguments[0].String(), channel) {
+			reply = append(reply, bulkString(channel))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
+
+// subscriberCounts replies with each channel followed by its number of subscribers.
+func subscriberCounts(subscribers map[string]map[*Client]struct{}, channels []protocol.DataType) protocol.DataType {
+	reply := []protocol.DataType{}
+	for _, channel := range channels {
+		reply = append(reply, channel, protocol.NewInteger(len(subscribers[channel.String()])))
+	}
+	return protocol.NewArray(reply...)
+}
+
+// subscribe adds the client to the subscribers of the channel, or of the pattern, in
+// subscribers and to its own subscriptions. It returns false if it was already
+// subscribed.
+func subscribe(subscribers map[string]map[*Client]struct{}, subscriptions map[string]struct{}, client *Client, channel string) bool {
+	if _, ok := subscriptions[channel]; ok {
+		return false
+	}
+	subscriptions[channel] = struct{}{}
+	if subscribers[channel] == nil {
+		subscribers[channel] = make(map[*Client]struct{})
+	}
+	subscribers[channel][client] = struct{}{}
+	return true
+}
+
+// unsubscribe undoes subscribe. It returns false if the client wasn't subscribed.
+func unsubscribe(subscribers map[string]map[*Client]struct{}, subscriptions map[string]struct{}, client *Client, channel string) bool {
+	if _, ok := subscriptions[channel]; !ok {
+		return false
+	}
+	delete(subscriptions, channel)
+	delete(subscribers[channel], client)
+	if len(subscribers[channel]) == 0 {
+		delete(subscribers, channel)
+	}
+	return true
+}
+
+func unsubscribeAll(client *Client) {
+	for channel := range client.channels {
+		unsubscribe(channelSubscribers, client.channels, client, channel)
+	}
+	for pattern := range client.patterns {
+		unsubscribe(patternSubscribers, client.patterns, client, pattern)
+	}
+	for channel := range client.shardChannels {
+		unsubscribe(shardChannelSubscribers, client.shardChannels, client, channel)
+	}
+}
+
+// publish pushes the message to the clients subscribed to the channel, and to the ones
+// subscribed to a pattern matching it. It returns how many clients received it, counting
+// a client once for each of its matching subscriptions.
+func publish(channel string, message protocol.DataType) int {
+	receivers := 0
+	for client := range channelSubscribers[channel] {
+		client.push(protocol.NewPush(bulkString("message"), bulkString(channel), message))
+		receivers++
+	}
+	for pattern, clients := range patternSubscribers {
+		if !matchPattern(pattern, channel) {
+			continue
+		}
+		for client := range clients {
+			client.push(protocol.NewPush(bulkString("pmessage"), bulkString(pattern), bulkString(channel), message))
+			receivers++
+		}
+	}
+	return receivers
+}
+
+// publishShard pushes the message to the clients subscribed to the sharded channel,
+// returning how many received it.
+func publishShard(channel string, message protocol.DataType) int {
+	for client := range shardChannelSubscribers[channel] {
+		client.push(protocol.NewPush(bulkString("smessage"), bulkString(channel), message))
+	}
+	return len(shardChannelSubscribers[channel])
+}
@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+// pushed returns the output pushed to the client since the last call.
+func pushed(client *Client) string {
+	output, _ := client.TakeOutput()
+	return string(output)
+}
+
+func TestSubscribe(t *testing.T) {
+	client := NewClient()
+	reply := processClientInline(t, client, "SUBSCRIBE pubsub-a pubsub-b pubsub-a")
+	expected := replies{
+		protocol.NewArray(bulkString("subscribe"), bulkString("pubsub-a"), protocol.NewInteger(1)),
+		protocol.NewArray(bulkString("subscribe"), bulkString("pubsub-b"), protocol.NewInteger(2)),
+		protocol.NewArray(bulkString("subscribe"), bulkString("pubsub-a"), protocol.NewInteger(2)),
+	}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+	reply = processClientInline(t, client, "PSUBSCRIBE pubsub-*")
+	expected = replies{protocol.NewArray(bulkString("psubscribe"), bulkString("pubsub-*"), protocol.NewInteger(3))}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+
+	if reply := processInline(t, "PUBLISH pubsub-a hello"); reply != protocol.NewInteger(2) {
+		t.Fatalf("unexpected number of receivers %v", reply)
+	}
+	expectedOutput := string(bulkStringArray("message", "pubsub-a", "hello").Encode()) +
+		string(bulkStringArray("pmessage", "pubsub-*", "pubsub-a", "hello").Encode())
+	if output := pushed(client); output != expectedOutput {
+		t.Fatalf("unexpected output. Expected: %q, Actual: %q", expectedOutput, output)
+	}
+	if reply := processInline(t, "PUBLISH other hello"); reply != protocol.NewInteger(0) {
+		t.Fatalf("unexpected number of receivers %v", reply)
+	}
+
+	reply = processClientInline(t, client, "UNSUBSCRIBE")
+	expected = replies{
+		protocol.NewArray(bulkString("unsubscribe"), bulkString("pubsub-a"), protocol.NewInteger(2)),
+		protocol.NewArray(bulkString("unsubscribe"), bulkString("pubsub-b"), protocol.NewInteger(1)),
+	}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+	reply = processClientInline(t, client, "PUNSUBSCRIBE pubsub-*")
+	expected = replies{protocol.NewArray(bulkString("punsubscribe"), bulkString("pubsub-*"), protocol.NewInteger(0))}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+	reply = processClientInline(t, client, "UNSUBSCRIBE")
+	if !reflect.DeepEqual(reply, protocol.NewArray(bulkString("unsubscribe"), protocol.NewNullBulkString(), protocol.NewInteger(0))) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if reply := processInline(t, "PUBLISH pubsub-a hello"); reply != protocol.NewInteger(0) {
+		t.Fatalf("unexpected number of receivers %v", reply)
+	}
+}
+
+func TestSubscribedMode(t *testing.T) {
+	client := NewClient()
+	processClientInline(t, client, "SUBSCRIBE pubsub-mode")
+	if reply := processClientInline(t, client, "GET pubsub-mode"); reply != protocol.NewError(fmt.Sprintf(subscribedModeErrMsg, "get")) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if reply := processClientInline(t, client, "PING"); !reflect.DeepEqual(reply, bulkStringArray("pong", "")) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+
+	// RESP3 clients can run any command, and receive the messages as pushes
+	resp3 := NewClient()
+	processClientInline(t, resp3, "HELLO 3")
+	processClientInline(t, resp3, "SUBSCRIBE pubsub-mode")
+	if reply := processClientInline(t, resp3, "GET pubsub-mode"); reply != protocol.NewNull() {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if reply := processClientInline(t, resp3, "PING"); reply != protocol.NewSimpleString("PONG") {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	processInline(t, "PUBLISH pubsub-mode hello")
+	expected := string(protocol.NewPush(bulkString("message"), bulkString("pubsub-mode"), bulkString("hello")).Encode())
+	if output := pushed(resp3); output != expected {
+		t.Fatalf("unexpected output. Expected: %q, Actual: %q", expected, output)
+	}
+	FreeClient(client)
+	FreeClient(resp3)
+}
+
+func TestPubSubIntrospection(t *testing.T) {
+	first, second := NewClient(), NewClient()
+	processClientInline(t, first, "SUBSCRIBE pubsub-news pubsub-sports")
+	processClientInline(t, second, "SUBSCRIBE pubsub-news")
+	processClientInline(t, second, "PSUBSCRIBE pubsub-n* pubsub-s*")
+	ptcs := []struct {
+		input    string
+		expected protocol.DataType
+	}{
+		{"PUBSUB CHANNELS pubsub-*", bulkStringArray("pubsub-news", "pubsub-sports")},
+		{"PUBSUB CHANNELS pubsub-n*", bulkStringArray("pubsub-news")},
+		{"PUBSUB NUMSUB pubsub-news pubsub-sports pubsub-none", protocol.NewArray(
+			bulkString("pubsub-news"), protocol.NewInteger(2),
+			bulkString("pubsub-sports"), protocol.NewInteger(1),
+			bulkString("pubsub-none"), protocol.NewInteger(0),
+		)},
+		{"PUBSUB NUMPAT", protocol.NewInteger(2)},
+		{"PUBSUB LIST", protocol.NewError(fmt.Sprintf(pubsubUnknownErrMsg, "LIST"))},
+	}
+	for _, tc := range ptcs {
+		t.Run(tc.input, func(t *testing.T) {
+			if actual := processInline(t, tc.input); !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+
+	// Freeing a client removes its subscriptions
+	FreeClient(second)
+	if reply := processInline(t, "PUBSUB NUMPAT"); reply != protocol.NewInteger(0) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if _, ok := <-second.OutputReady(); ok {
+		t.Fatalf("the output of a freed client should be closed")
+	}
+	FreeClient(first)
+}
+
+func TestSubscriberOutputLimits(t *testing.T) {
+	defer func(hard, soft int, duration time.Duration) {
+		pubsubHardLimit, pubsubSoftLimit, pubsubSoftLimitDuration = hard, soft, duration
+	}(pubsubHardLimit, pubsubSoftLimit, pubsubSoftLimitDuration)
+	pubsubHardLimit, pubsubSoftLimit, pubsubSoftLimitDuration = 1000, 200, 50*time.Millisecond
+
+	slow, soft := NewClient(), NewClient()
+	processClientInline(t, slow, "SUBSCRIBE pubsub-slow")
+	processClientInline(t, soft, "SUBSCRIBE pubsub-soft")
+	message := strings.Repeat("x", 100)
+	for i := 0; i < 5; i++ {
+		processInline(t, "PUBLISH pubsub-slow "+message)
+	}
+	if _, disconnect := slow.TakeOutput(); disconnect {
+		t.Fatalf("the client shouldn't be over the hard limit yet")
+	}
+	for i := 0; i < 10; i++ {
+		processInline(t, "PUBLISH pubsub-slow "+message)
+	}
+	if output, disconnect := slow.TakeOutput(); !disconnect || len(output) != 0 {
+		t.Fatalf("the client should be disconnected for going over the hard limit")
+	}
+
+	for i := 0; i < 3; i++ {
+		processInline(t, "PUBLISH pubsub-soft "+message)
+	}
+	time.Sleep(pubsubSoftLimitDuration)
+	processInline(t, "PUBLISH pubsub-soft "+message)
+	if _, disconnect := soft.TakeOutput(); !disconnect {
+		t.Fatalf("the client should be disconnected for staying over the soft limit")
+	}
+	FreeClient(slow)
+	FreeClient(soft)
+}
+
+func TestShardedPubSub(t *testing.T) {
+	client := NewClient()
+	processClientInline(t, client, "SUBSCRIBE pubsub-shard")
+	reply := processClientInline(t, client, "SSUBSCRIBE pubsub-shard pubsub-shard-other")
+	expected := replies{
+		protocol.NewArray(bulkString("ssubscribe"), bulkString("pubsub-shard"), protocol.NewInteger(1)),
+		protocol.NewArray(bulkString("ssubscribe"), bulkString("pubsub-shard-other"), protocol.NewInteger(2)),
+	}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+
+	// The sharded channels are a separate namespace from the classic channels
+	if reply := processInline(t, "SPUBLISH pubsub-shard hello"); reply != protocol.NewInteger(1) {
+		t.Fatalf("unexpected number of receivers %v", reply)
+	}
+	expectedOutput := string(bulkStringArray("smessage", "pubsub-shard", "hello").Encode())
+	if output := pushed(client); output != expectedOutput {
+		t.Fatalf("unexpected output. Expected: %q, Actual: %q", expectedOutput, output)
+	}
+	if reply := processInline(t, "SPUBLISH pubsub-unknown hello"); reply != protocol.NewInteger(0) {
+		t.Fatalf("unexpected number of receivers %v", reply)
+	}
+	if reply := processInline(t, "PUBSUB SHARDCHANNELS pubsub-shard*"); !reflect.DeepEqual(reply, bulkStringArray("pubsub-shard", "pubsub-shard-other")) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if reply := processInline(t, "PUBSUB SHARDNUMSUB pubsub-shard"); !reflect.DeepEqual(reply, protocol.NewArray(bulkString("pubsub-shard"), protocol.NewInteger(1))) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+
+	processClientInline(t, client, "UNSUBSCRIBE")
+	if reply := processClientInline(t, client, "GET pubsub-shard"); reply != protocol.NewError(fmt.Sprintf(subscribedModeErrMsg, "get")) {
+		t.Fatalf("the client should still be in subscribed mode, got %v", reply)
+	}
+	reply = processClientInline(t, client, "SUNSUBSCRIBE")
+	expected = replies{
+		protocol.NewArray(bulkString("sunsubscribe"), bulkString("pubsub-shard"), protocol.NewInteger(1)),
+		protocol.NewArray(bulkString("sunsubscribe"), bulkString("pubsub-shard-other"), protocol.NewInteger(0)),
+	}
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+	if reply := processClientInline(t, client, "GET pubsub-shard"); reply != protocol.NewNullBulkString() {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	FreeClient(client)
+}
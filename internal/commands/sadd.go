@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	saddInvalidLengthErrMsg string = "invalid arguments for command SADD. Syntax: SADD key member [member ...]"
+)
+
+func init() {
+	sadd := saddCommand{"sadd"}
+	registerCommand(sadd)
+}
+
+type saddCommand struct {
+	name string
+}
+
+func (s saddCommand) getName() string {
+	return s.name
+}
+
+func (s saddCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(saddInvalidLengthErrMsg)
+	}
+	set, err := datastore.GetOrCreateSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	added := 0
+	for _, member := range elements[2:] {
+		if set.Add(member.String()) {
+			added++
+		}
+	}
+	return protocol.NewInteger(added)
+}
@@ -0,0 +1,232 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	saveInvalidLengthErrMsg     string = "invalid arguments for command SAVE. Syntax: SAVE"
+	bgsaveInvalidLengthErrMsg   string = "invalid arguments for command BGSAVE. Syntax: BGSAVE [SCHEDULE]"
+	lastsaveInvalidLengthErrMsg string = "invalid arguments for command LASTSAVE. Syntax: LASTSAVE"
+	saveRulesErrMsg             string = "Invalid save parameters: %s"
+	bgsaveStartedMsg            string = "Background saving started"
+	bgsaveScheduledMsg          string = "Background saving scheduled"
+)
+
+const (
+	// backgroundSaveBudget is the time each cycle of a background save can use, which
+	// leaves most of the task loop to the commands.
+	backgroundSaveBudget = 25 * time.Millisecond
+	// bgsaveRetryDelay is how long the save rules wait to try again after a background
+	// save failed, the same delay Redis uses.
+	bgsaveRetryDelay = 5 * time.Second
+)
+
+// SaveRule makes the server save a snapshot in the background once at least Changes
+// writes happened and Seconds passed since the last save, like the save option of Redis.
+type SaveRule struct {
+	Seconds int64
+	Changes uint64
+}
+
+// DefaultSaveRules are the save rules of Redis: save after an hour if anything changed,
+// after 5 minutes if 100 writes happened and after a minute if 10000 writes happened.
+var DefaultSaveRules = []SaveRule{{3600, 1}, {300, 100}, {60, 10000}}
+
+var (
+	snapshotPath = "dump.rdb"
+	saveRules    = DefaultSaveRules
+	// bgsaveScheduled is set by BGSAVE SCHEDULE while a background save is in progress,
+	// so another one starts once it's over.
+	bgsaveScheduled  bool
+	lastBgsaveFailed bool
+	lastBgsaveTry    time.Time
+)
+
+func init() {
+	registerCommand(saveCommand{"save"})
+	registerCommand(bgsaveCommand{"bgsave"})
+	registerCommand(lastsaveCommand{"lastsave"})
+}
+
+// ConfigurePersistence sets the file snapshots are saved to and loaded from, and the
+// rules that trigger a background save. It must be called before the task loop starts.
+func ConfigurePersistence(dir, filename string, rules []SaveRule) {
+	snapshotPath = filepath.Join(dir, filename)
+	saveRules = rules
+}
+
+// ParseSaveRules parses save rules written like the save option of Redis: pairs of
+// seconds and changes, like "3600 1 300 100". An empty string disables the rules.
+func ParseSaveRules(value string) ([]SaveRule, error) {
+	fields := strings.Fields(value)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf(saveRulesErrMsg, value)
+	}
+	rules := make([]SaveRule, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		seconds, err := strconv.ParseInt(fields[i], 10, 64)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf(saveRulesErrMsg, value)
+		}
+		changes, err := strconv.ParseUint(fields[i+1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(saveRulesErrMsg, value)
+		}
+		rules = append(rules, SaveRule{seconds, changes})
+	}
+	return rules, nil
+}
+
+// LoadSnapshot loads the snapshot saved in the configured file, with its function
+// libraries, if there is one. It must be called before the task loop starts.
+func LoadSnapshot() error {
+	functions, err := datastore.Load(snapshotPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	registry := newFunctionRegistry()
+	for _, code := range functions {
+		lib, err := loadLibrary(string(code))
+		if err != nil {
+			return err
+		}
+		if err := registry.add(lib, false); err != nil {
+			return err
+		}
+	}
+	functionLibraries = registry
+	return nil
+}
+
+// SaveCron moves the background save in progress forward, or starts one if it was
+// scheduled or one of the save rules matches. It must be called periodically from the
+// task loop.
+func SaveCron(now time.Time) {
+	if datastore.BackgroundSaveInProgress() {
+		if done, err := datastore.BackgroundSaveCycle(backgroundSaveBudget); done {
+			lastBgsaveFailed = err != nil
+			if err != nil {
+				fmt.Printf("Background saving error: %v\n", err)
+			}
+		}
+		return
+	}
+	if bgsaveScheduled || saveRuleMatches(now) {
+		if err := startBackgroundSave(now); err != nil {
+			fmt.Printf("Can't save in background: %v\n", err)
+		}
+	}
+}
+
+// saveRuleMatches tells if one of the save rules matches the changes since the last
+// save. After a failed background save the rules wait for bgsaveRetryDelay, so a full
+// disk isn't retried in a loop.
+func saveRuleMatches(now time.Time) bool {
+	if lastBgsaveFailed && now.Sub(lastBgsaveTry) < bgsaveRetryDelay {
+		return false
+	}
+	changes := datastore.ChangesSinceSave()
+	elapsed := now.Unix() - datastore.LastSave()
+	for _, rule := range saveRules {
+		if changes >= rule.Changes && changes > 0 && elapsed >= rule.Seconds {
+			return true
+		}
+	}
+	return false
+}
+
+func startBackgroundSave(now time.Time) error {
+	bgsaveScheduled = false
+	lastBgsaveTry = now
+	err := datastore.StartBackgroundSave(snapshotPath, functionCodes())
+	lastBgsaveFailed = err != nil
+	return err
+}
+
+// functionCodes returns the code of the function libraries, which are saved with the
+// snapshots.
+func functionCodes() [][]byte {
+	codes := [][]byte{}
+	for _, name := range functionLibraries.names() {
+		codes = append(codes, []byte(functionLibraries.libraries[name].code))
+	}
+	return codes
+}
+
+// saveCommand saves a snapshot of the datastore, blocking the server until it's done.
+type saveCommand struct {
+	name string
+}
+
+func (s saveCommand) getName() string {
+	return s.name
+}
+
+func (s saveCommand) processArguments(data protocol.Array) protocol.DataType {
+	if len(data.GetElements()) != 1 {
+		return protocol.NewError(saveInvalidLengthErrMsg)
+	}
+	if err := datastore.Save(snapshotPath, functionCodes()); err != nil {
+		return errorReply(err)
+	}
+	return protocol.NewSimpleString("OK")
+}
+
+// bgsaveCommand starts saving a snapshot of the datastore in the background. With
+// SCHEDULE, a save requested while another one is in progress starts once it's over
+// instead of failing.
+type bgsaveCommand struct {
+	name string
+}
+
+func (b bgsaveCommand) getName() string {
+	return b.name
+}
+
+func (b bgsaveCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	schedule := len(elements) == 2 && strings.EqualFold(elements[1].String(), "SCHEDULE")
+	if len(elements) > 2 || (len(elements) == 2 && !schedule) {
+		return protocol.NewError(bgsaveInvalidLengthErrMsg)
+	}
+	if datastore.BackgroundSaveInProgress() {
+		if !schedule {
+			return protocol.NewError(datastore.ErrBackgroundSaveInProgress.Error())
+		}
+		bgsaveScheduled = true
+		return protocol.NewSimpleString(bgsaveScheduledMsg)
+	}
+	if err := startBackgroundSave(time.Now()); err != nil {
+		return errorReply(err)
+	}
+	return protocol.NewSimpleString(bgsaveStartedMsg)
+}
+
+// lastsaveCommand replies with the Unix time in seconds of the last successful save.
+type lastsaveCommand struct {
+	name string
+}
+
+func (l lastsaveCommand) getName() string {
+	return l.name
+}
+
+func (l lastsaveCommand) processArguments(data protocol.Array) protocol.DataType {
+	if len(data.GetElements()) != 1 {
+		return protocol.NewError(lastsaveInvalidLengthErrMsg)
+	}
+	return protocol.NewInteger(int(datastore.LastSave()))
+}
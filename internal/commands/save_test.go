@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+// usePersistence saves the snapshots of the test to a temporary directory with the
+// rules, restoring the configuration once the test is over.
+func usePersistence(t *testing.T, rules []SaveRule) string {
+	t.Helper()
+	previousPath, previousRules := snapshotPath, saveRules
+	dir := t.TempDir()
+	ConfigurePersistence(dir, "dump.rdb", rules)
+	t.Cleanup(func() {
+		snapshotPath, saveRules = previousPath, previousRules
+	})
+	return filepath.Join(dir, "dump.rdb")
+}
+
+// finishBackgroundSave runs the save cron until the background saves are over.
+func finishBackgroundSave(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for datastore.BackgroundSaveInProgress() || bgsaveScheduled {
+		if time.Now().After(deadline) {
+			t.Fatalf("the background save didn't finish")
+		}
+		SaveCron(time.Now())
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSave(t *testing.T) {
+	path := usePersistence(t, nil)
+	ok := protocol.NewSimpleString("OK")
+	processInline(t, "SET save-key value")
+	processInline(t, loadCommand("#!lua name=savelib\nredis.register_function('save_fn', function(keys) return redis.call('GET', keys[1]) end)"))
+	before := time.Now().Unix()
+	if reply := processInline(t, "SAVE"); reply != ok {
+		t.Fatalf("unexpected reply to SAVE: %v", reply)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the snapshot wasn't saved: %v", err)
+	}
+	if lastSave := processInline(t, "LASTSAVE").(protocol.Integer).Value(); int64(lastSave) < before {
+		t.Fatalf("unexpected LASTSAVE %d, the save was at %d or later", lastSave, before)
+	}
+
+	processInline(t, "FUNCTION DELETE savelib")
+	if err := LoadSnapshot(); err != nil {
+		t.Fatalf("unexpected error loading the snapshot: %v", err)
+	}
+	if reply := processClientInline(t, NewClient(), "FCALL save_fn 1 save-key"); !reflect.DeepEqual(reply, bulkString("value")) {
+		t.Fatalf("unexpected reply from the loaded function: %v", reply)
+	}
+}
+
+func TestBackgroundSave(t *testing.T) {
+	path := usePersistence(t, nil)
+	processInline(t, "SET bgsave-key before")
+	if reply := processInline(t, "BGSAVE"); reply != protocol.NewSimpleString(bgsaveStartedMsg) {
+		t.Fatalf("unexpected reply to BGSAVE: %v", reply)
+	}
+	processInline(t, "SET bgsave-key after")
+	if reply := processInline(t, "BGSAVE"); !reflect.DeepEqual(reply, protocol.NewError(datastore.ErrBackgroundSaveInProgress.Error())) {
+		t.Fatalf("BGSAVE should fail while another one is in progress, got %v", reply)
+	}
+	if reply := processInline(t, "SAVE"); !reflect.DeepEqual(reply, protocol.NewError(datastore.ErrBackgroundSaveInProgress.Error())) {
+		t.Fatalf("SAVE should fail while a background save is in progress, got %v", reply)
+	}
+	if reply := processInline(t, "BGSAVE SCHEDULE"); reply != protocol.NewSimpleString(bgsaveScheduledMsg) {
+		t.Fatalf("unexpected reply to BGSAVE SCHEDULE: %v", reply)
+	}
+	finishBackgroundSave(t)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the snapshot wasn't saved: %v", err)
+	}
+	if datastore.ChangesSinceSave() != 0 {
+		t.Fatalf("the scheduled save should have saved the last changes")
+	}
+	if reply := processInline(t, "BGSAVE NOW"); !reflect.DeepEqual(reply, protocol.NewError(bgsaveInvalidLengthErrMsg)) {
+		t.Fatalf("unexpected reply to BGSAVE with an invalid argument: %v", reply)
+	}
+}
+
+func TestSaveRules(t *testing.T) {
+	usePersistence(t, []SaveRule{{Seconds: 0, Changes: 2}})
+	processInline(t, "SAVE")
+	processInline(t, "SET save-rules 1")
+	SaveCron(time.Now())
+	if datastore.BackgroundSaveInProgress() {
+		t.Fatalf("a single change shouldn't start a save")
+	}
+	processInline(t, "SET save-rules 2")
+	SaveCron(time.Now())
+	if !datastore.BackgroundSaveInProgress() {
+		t.Fatalf("two changes should start a save")
+	}
+	finishBackgroundSave(t)
+}
+
+func TestParseSaveRules(t *testing.T) {
+	tcs := []struct {
+		value    string
+		expected []SaveRule
+		valid    bool
+	}{
+		{"3600 1 300 100", []SaveRule{{3600, 1}, {300, 100}}, true},
+		{"", []SaveRule{}, true},
+		{"3600", nil, false},
+		{"3600 -1", nil, false},
+		{"an hour 1", nil, false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.value, func(t *testing.T) {
+			rules, err := ParseSaveRules(tc.value)
+			if (err == nil) != tc.valid || !reflect.DeepEqual(rules, tc.expected) {
+				t.Fatalf("unexpected rules %v, error: %v", rules, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	scriptInvalidLengthErrMsg string = "invalid arguments for command SCRIPT. Syntax: SCRIPT <LOAD script | EXISTS sha1 [sha1 ...] | FLUSH [ASYNC | SYNC] | KILL>"
+	scriptUnknownCmdErrMsg    string = "unknown subcommand '%s'. Try SCRIPT HELP."
+)
+
+func init() {
+	registerCommand(scriptCommand{"script"})
+}
+
+// scriptCommand manages the cache of scripts: LOAD compiles a script and caches it
+// without running it, EXISTS tells which digests are cached, FLUSH empties the cache
+// and KILL stops a busy script that didn't write yet.
+type scriptCommand struct {
+	name string
+}
+
+func (s scriptCommand) getName() string {
+	return s.name
+}
+
+func (s scriptCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		return protocol.NewError(scriptInvalidLengthErrMsg)
+	}
+	switch subcommand := elements[1].String(); strings.ToUpper(subcommand) {
+	case "LOAD":
+		if len(elements) != 3 {
+			return protocol.NewError(scriptInvalidLengthErrMsg)
+		}
+		loaded, errReply := loadScript(elements[2].String())
+		if errReply != nil {
+			return errReply
+		}
+		return bulkString(loaded.sha)
+	case "EXISTS":
+		if len(elements) < 3 {
+			return protocol.NewError(scriptInvalidLengthErrMsg)
+		}
+		reply := make([]protocol.DataType, 0, len(elements)-2)
+		for _, sha := range elements[2:] {
+			exists := 0
+			if _, ok := scripts[strings.ToLower(sha.String())]; ok {
+				exists = 1
+			}
+			reply = append(reply, protocol.NewInteger(exists))
+		}
+		return protocol.NewArray(reply...)
+	case "FLUSH":
+		// The cache is always emptied synchronously, ASYNC is accepted for compatibility
+		if len(elements) > 3 || (len(elements) == 3 && !isFlushMode(elements[2].String())) {
+			return protocol.NewError(scriptInvalidLengthErrMsg)
+		}
+		clear(scripts)
+		return protocol.NewSimpleString("OK")
+	case "KILL":
+		if len(elements) != 2 {
+			return protocol.NewError(scriptInvalidLengthErrMsg)
+		}
+		return killScript()
+	default:
+		return protocol.NewError(fmt.Sprintf(scriptUnknownCmdErrMsg, subcommand))
+	}
+}
+
+func isFlushMode(mode string) bool {
+	return strings.EqualFold(mode, "ASYNC") || strings.EqualFold(mode, "SYNC")
+}
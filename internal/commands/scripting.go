@@ -0,0 +1,374 @@
+package commands
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+	"github.com/mhsantos/redis-server/internal/scripting"
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	scriptCompileErrMsg      string = "Error compiling script (new function): %s"
+	scriptRunErrMsg          string = "%s script: %s"
+	scriptKilledErrMsg       string = "Script killed by user with SCRIPT KILL..."
+	scriptBusyErrMsg         string = "BUSY Redis is busy running a script. You can only call SCRIPT KILL or SHUTDOWN NOSAVE."
+	scriptNotBusyErrMsg      string = "NOTBUSY No scripts in execution right now."
+	scriptUnkillableErrMsg   string = "UNKILLABLE Sorry the script already executed write commands against the dataset. You can either wait the script termination or kill the server in a hard way using the SHUTDOWN NOSAVE command."
+	scriptNoArgumentsErrMsg  string = "Please specify at least one argument for this redis lib call"
+	scriptArgumentTypeErrMsg string = "Lua redis lib command arguments must be strings or integers"
+	scriptUnknownErrMsg      string = "Unknown Redis command called from script"
+	scriptDeniedErrMsg       string = "This Redis command is not allowed from script"
+	scriptArityErrMsg        string = "Wrong number of args calling Redis command from script"
+	scriptReadOnlyErrMsg     string = "Write commands are not allowed from read-only scripts."
+)
+
+// scriptName is the name scripts get in their error messages, like user_script:1.
+const scriptName = "user_script"
+
+// scriptTimeLimit is how long a script runs before it's considered busy. A busy script
+// keeps running, but other clients get a BUSY error instead of waiting for it, and
+// SCRIPT KILL can stop it. It's the lua-time-limit option of Redis.
+var scriptTimeLimit = 5 * time.Second
+
+// deniedScriptCommands are the commands scripts can't call, since they change the state
+// of the client, run scripts themselves or save the datastore.
+var deniedScriptCommands = map[string]bool{
+	"multi":        true,
+	"exec":         true,
+	"discard":      true,
+	"watch":        true,
+	"unwatch":      true,
+	"subscribe":    true,
+	"psubscribe":   true,
+	"ssubscribe":   true,
+	"unsubscribe":  true,
+	"punsubscribe": true,
+	"sunsubscribe": true,
+	"eval":         true,
+	"evalsha":      true,
+	"script":       true,
+	"fcall":        true,
+	"fcall_ro":     true,
+	"function":     true,
+	"save":         true,
+	"bgsave":       true,
+}
+
+// errScriptKilled stops a script killed by SCRIPT KILL.
+var errScriptKilled = errors.New(scriptKilledErrMsg)
+
+// The state of the running script that other connections read while the task loop is
+// busy running it, which is why it's kept in atomics. Each run of a script gets its own
+// id from scriptRuns. busy holds that id once the script runs for longer than
+// scriptTimeLimit, and 0 otherwise. wrote is set once the script writes to the
+// datastore, and kill holds the id of the run SCRIPT KILL asked to stop, so a kill that
+// arrives as a script finishes can't stop the next one.
+var (
+	scriptRuns  atomic.Uint64
+	scriptBusy  atomic.Uint64
+	scriptWrote atomic.Bool
+	scriptKill  atomic.Uint64
+)
+
+// script is a compiled script, identified by the SHA1 digest of its source.
+type script struct {
+	sha   string
+	proto *lua.FunctionProto
+}
+
+// scripts is the cache of the scripts run by EVAL or loaded by SCRIPT LOAD.
+var scripts = make(map[string]*script)
+
+func scriptSHA(source string) string {
+	digest := sha1.Sum([]byte(source))
+	return hex.EncodeToString(digest[:])
+}
+
+// loadScript compiles the script and adds it to the cache, returning the cached one if
+// it was already loaded.
+func loadScript(source string) (*script, protocol.DataType) {
+	sha := scriptSHA(source)
+	if s, ok := scripts[sha]; ok {
+		return s, nil
+	}
+	proto, err := scripting.Compile(source, scriptName)
+	if err != nil {
+		return nil, protocol.NewError(fmt.Sprintf(scriptCompileErrMsg, err))
+	}
+	s := &script{sha: sha, proto: proto}
+	scripts[sha] = s
+	return s, nil
+}
+
+// runScript runs the script for the client with the KEYS and ARGV tables, converting
+// the value it returns to a reply.
+func runScript(client *Client, s *script, keys, args []protocol.DataType) protocol.DataType {
+	L := scripting.NewState()
+	defer L.Close()
+	L.SetGlobal("redis", redisLibrary(L, client, false))
+	L.SetGlobal("KEYS", argumentsTable(L, keys))
+	L.SetGlobal("ARGV", argumentsTable(L, args))
+	scripting.ProtectGlobals(L)
+	values, err := runWithTimeLimit(client, L, L.NewFunctionFromProto(s.proto))
+	if err != nil {
+		return scriptErrorReply(err, s.sha)
+	}
+	if len(values) == 0 {
+		return protocol.NewNullBulkString()
+	}
+	return scriptReply(values[0])
+}
+
+// runWithTimeLimit calls a function of the state with the arguments, marking the
+// script as busy once it runs for longer than scriptTimeLimit, and stopping it if it's
+// killed. The commands the script calls can't block the client.
+func runWithTimeLimit(client *Client, L *lua.LState, function lua.LValue, arguments ...lua.LValue) ([]lua.LValue, error) {
+	id := scriptRuns.Add(1)
+	start := time.Now()
+	scripting.SetInterrupt(L, func() error {
+		if time.Since(start) < scriptTimeLimit {
+			return nil
+		}
+		scriptBusy.Store(id)
+		if scriptKill.Load() == id {
+			return errScriptKilled
+		}
+		return nil
+	})
+	// Scripts run inside EXEC too, which doesn't let the client block either
+	nonBlocking := client.nonBlocking
+	client.nonBlocking = true
+	defer func() {
+		client.nonBlocking = nonBlocking
+		scriptBusy.Store(0)
+		scriptWrote.Store(false)
+	}()
+	return scripting.Call(L, function, arguments...)
+}
+
+// redisLibrary creates the redis library scripts use to call commands as the client.
+// Read-only scripts can't call commands that write.
+func redisLibrary(L *lua.LState, client *Client, readOnly bool) *lua.LTable {
+	library := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"call": func(L *lua.LState) int {
+			return scriptCall(L, client, readOnly, true)
+		},
+		"pcall": func(L *lua.LState) int {
+			return scriptCall(L, client, readOnly, false)
+		},
+		"error_reply": func(L *lua.LState) int {
+			L.Push(replyTable(L, "err"))
+			return 1
+		},
+		"status_reply": func(L *lua.LState) int {
+			L.Push(replyTable(L, "ok"))
+			return 1
+		},
+		"sha1hex": func(L *lua.LState) int {
+			if L.GetTop() != 1 {
+				L.RaiseError("wrong number of arguments")
+			}
+			L.Push(lua.LString(scriptSHA(L.ToString(1))))
+			return 1
+		},
+	})
+	addLogFunction(L, library)
+	return library
+}
+
+// addLogFunction adds redis.log and the log levels to the redis library. They are there
+// for compatibility, but there is no log to write to.
+func addLogFunction(L *lua.LState, library *lua.LTable) {
+	library.RawSetString("log", L.NewFunction(func(L *lua.LState) int {
+		return 0
+	}))
+	for i, level := range []string{"LOG_DEBUG", "LOG_VERBOSE", "LOG_NOTICE", "LOG_WARNING"} {
+		library.RawSetString(level, lua.LNumber(i))
+	}
+}
+
+// scriptCall runs a command called by a script with redis.call, which raises errors, or
+// redis.pcall, which returns them as a table with an err field.
+func scriptCall(L *lua.LState, client *Client, readOnly, raise bool) int {
+	arguments := make([]lua.LValue, L.GetTop())
+	for i := range arguments {
+		arguments[i] = L.Get(i + 1)
+	}
+	reply := scriptCommandReply(client, arguments, readOnly)
+	if errReply, ok := reply.(protocol.Error); ok && raise {
+		L.Error(scriptValue(L, errReply), 1)
+	}
+	L.Push(scriptValue(L, reply))
+	return 1
+}
+
+func scriptCommandReply(client *Client, arguments []lua.LValue, readOnly bool) protocol.DataType {
+	if len(arguments) == 0 {
+		return protocol.NewError(scriptNoArgumentsErrMsg)
+	}
+	elements := make([]protocol.DataType, len(arguments))
+	for i, argument := range arguments {
+		switch argument.(type) {
+		case lua.LString, lua.LNumber:
+			elements[i] = protocol.NewBulkString([]byte(argument.String()))
+		default:
+			return protocol.NewError(scriptArgumentTypeErrMsg)
+		}
+	}
+	name := strings.ToLower(elements[0].String())
+	_, ok := registeredCommands[name]
+	_, clientOk := registeredClientCommands[name]
+	switch {
+	case !ok && !clientOk:
+		return protocol.NewError(scriptUnknownErrMsg)
+	case deniedScriptCommands[name]:
+		return protocol.NewError(scriptDeniedErrMsg)
+	case !validArity(name, len(elements)):
+		return protocol.NewError(scriptArityErrMsg)
+	case readOnly && writeCommands[name]:
+		return protocol.NewError(scriptReadOnlyErrMsg)
+	}
+	dirty := datastore.Dirty()
+	reply := dispatchCommand(client, protocol.NewArray(elements...))
+	if datastore.Dirty() != dirty {
+		scriptWrote.Store(true)
+	}
+	// Scripts see the replies in RESP2, so doubles are strings and nulls are false
+	return protocol.Convert(reply, protocol.RESP2)
+}
+
+// scriptValue converts a reply to the value scripts get from redis.call. Integers are
+// numbers, bulk strings are strings, nulls are false and arrays are tables. Status and
+// error replies are tables with an ok or err field.
+func scriptValue(L *lua.LState, reply protocol.DataType) lua.LValue {
+	switch reply := reply.(type) {
+	case protocol.Integer:
+		return lua.LNumber(reply.Value())
+	case protocol.BulkString:
+		return lua.LString(reply.String())
+	case protocol.SimpleString:
+		t := L.NewTable()
+		t.RawSetString("ok", lua.LString(reply.String()))
+		return t
+	case protocol.Error:
+		t := L.NewTable()
+		t.RawSetString("err", lua.LString(reply.String()))
+		return t
+	case protocol.Array:
+		t := L.NewTable()
+		for i, element := range reply.GetElements() {
+			t.RawSetInt(i+1, scriptValue(L, element))
+		}
+		return t
+	}
+	return lua.LFalse
+}
+
+// scriptReply converts the value returned by a script to a reply, the opposite of
+// scriptValue. Numbers are truncated to integers, true is 1, and tables are arrays with
+// their values up to the first nil, unless they have an ok or err field.
+func scriptReply(value lua.LValue) protocol.DataType {
+	switch value := value.(type) {
+	case lua.LNumber:
+		return protocol.NewInteger(int(value))
+	case lua.LString:
+		return protocol.NewBulkString([]byte(value))
+	case lua.LBool:
+		if value {
+			return protocol.NewInteger(1)
+		}
+	case *lua.LTable:
+		if message, ok := value.RawGetString("err").(lua.LString); ok {
+			return protocol.NewError(string(message))
+		}
+		if status, ok := value.RawGetString("ok").(lua.LString); ok {
+			return protocol.NewSimpleString(string(status))
+		}
+		elements := make([]protocol.DataType, 0)
+		for i := 1; value.RawGetInt(i) != lua.LNil; i++ {
+			elements = append(elements, scriptReply(value.RawGetInt(i)))
+		}
+		return protocol.NewArray(elements...)
+	}
+	return protocol.NewNullBulkString()
+}
+
+// scriptErrorReply converts an error raised by a script to an error reply. Errors of
+// commands called by the script keep their message, runtime errors tell the script they
+// happened in.
+func scriptErrorReply(err error, sha string) protocol.DataType {
+	if errors.Is(err, errScriptKilled) {
+		return protocol.NewError(scriptKilledErrMsg)
+	}
+	message := err.Error()
+	var luaErr *lua.ApiError
+	if errors.As(err, &luaErr) {
+		if t, ok := luaErr.Object.(*lua.LTable); ok {
+			if message, ok := t.RawGetString("err").(lua.LString); ok {
+				return protocol.NewError(string(message))
+			}
+		}
+		// The message of the error has the stack trace too
+		message = luaErr.Object.String()
+	}
+	return protocol.NewError(fmt.Sprintf(scriptRunErrMsg, message, sha))
+}
+
+func argumentsTable(L *lua.LState, arguments []protocol.DataType) *lua.LTable {
+	t := L.CreateTable(len(arguments), 0)
+	for i, argument := range arguments {
+		t.RawSetInt(i+1, lua.LString(argument.String()))
+	}
+	return t
+}
+
+// replyTable creates the table of redis.error_reply and redis.status_reply, with the
+// field set to the first argument.
+func replyTable(L *lua.LState, field string) lua.LValue {
+	t := L.NewTable()
+	if L.GetTop() > 0 {
+		t.RawSetString(field, lua.LString(L.ToString(1)))
+	}
+	return t
+}
+
+// ProcessBusyCommand handles a command received while a script is busy, running for
+// longer than scriptTimeLimit, which keeps the task loop from running any other command.
+// SCRIPT KILL or FUNCTION KILL stops the script and every other command gets a BUSY
+// error. It's safe to call from any goroutine, and returns nil when no script is busy,
+// in which case the command goes through the task loop as usual.
+func ProcessBusyCommand(data protocol.Array) protocol.DataType {
+	if scriptBusy.Load() == 0 {
+		return nil
+	}
+	elements := data.GetElements()
+	if len(elements) == 2 && strings.EqualFold(elements[1].String(), "kill") {
+		switch strings.ToLower(elements[0].String()) {
+		case "script", "function":
+			return killScript()
+		}
+	}
+	return protocol.NewError(scriptBusyErrMsg)
+}
+
+// killScript asks the busy script to stop. Scripts that already wrote to the datastore
+// can't be killed, since their changes couldn't be undone.
+func killScript() protocol.DataType {
+	id := scriptBusy.Load()
+	if id == 0 {
+		return protocol.NewError(scriptNotBusyErrMsg)
+	}
+	if scriptWrote.Load() {
+		return protocol.NewError(scriptUnkillableErrMsg)
+	}
+	scriptKill.Store(id)
+	return protocol.NewSimpleString("OK")
+}
@@ -2,11 +2,21 @@ package commands
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mhsantos/redis-server/internal/datastore"
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
+const (
+	setSyntaxErrMsg        string = "invalid arguments for command SET. Syntax: SET key value [NX | XX] [GET] [EX seconds | PX milliseconds | EXAT unix-time-seconds | PXAT unix-time-milliseconds | KEEPTTL]"
+	setKeyTypeErrMsg       string = "the KEY parameter for the SET command must be a BulkString. Received a %T instead"
+	setInvalidExpireErrMsg string = "invalid expire time in 'set' command"
+)
+
 func init() {
 	set := setCommand{"set"}
 	registerCommand(set)
@@ -16,19 +26,129 @@ type setCommand struct {
 	name string
 }
 
+// setOptions holds the options parsed from a SET command.
+type setOptions struct {
+	nx, xx, get, keepTTL bool
+	// expireOption is one of EX, PX, EXAT or PXAT, empty if no expiration was informed
+	expireOption string
+	expireValue  int64
+}
+
 func (s setCommand) getName() string {
 	return s.name
 }
 
+// processArguments stores the value in the key. Unless KEEPTTL is informed, any time to
+// live previously associated with the key is discarded, so a plain SET always results in
+// a key without expiration.
 func (s setCommand) processArguments(data protocol.Array) protocol.DataType {
 	elements := data.GetElements()
-	if len(elements) != 3 {
-		return protocol.NewError(fmt.Sprintf("the SET command accepts 3 parameters: SET, KEY and VALUE. Received %d parameters instead", len(elements)))
+	if len(elements) < 3 {
+		return protocol.NewError(setSyntaxErrMsg)
 	}
 	key, ok := elements[1].(protocol.BulkString)
 	if !ok {
-		return protocol.NewError(fmt.Sprintf("the KEY parameter for the SET command must be a BulkString. Received a %T instead", elements[1]))
+		return protocol.NewError(fmt.Sprintf(setKeyTypeErrMsg, elements[1]))
+	}
+	options, errReply := parseSetOptions(elements[3:])
+	if errReply != nil {
+		return errReply
+	}
+	expire, errReply := options.expireAt(setInvalidExpireErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+
+	currentExpire, exists := datastore.GetExpire(key.String())
+	var previous protocol.DataType = protocol.NewNullBulkString()
+	if options.get {
+		existing, ok, err := datastore.Get(key.String())
+		if err != nil {
+			return errorReply(err)
+		}
+		if ok {
+			previous = existing
+		}
+	}
+	if (options.nx && exists) || (options.xx && !exists) {
+		if options.get {
+			return previous
+		}
+		return protocol.NewNullBulkString()
+	}
+	if options.keepTTL {
+		expire = currentExpire
+	}
+	datastore.SetWithExpire(key.String(), elements[2], expire)
+	if options.get {
+		return previous
 	}
-	datastore.Set(key.String(), elements[2])
 	return protocol.NewSimpleString("OK")
 }
+
+func parseSetOptions(arguments []protocol.DataType) (setOptions, protocol.DataType) {
+	options := setOptions{}
+	for i := 0; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		switch option {
+		case "NX":
+			options.nx = true
+		case "XX":
+			options.xx = true
+		case "GET":
+			options.get = true
+		case "KEEPTTL":
+			options.keepTTL = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if options.expireOption != "" || i+1 == len(arguments) {
+				return options, protocol.NewError(setSyntaxErrMsg)
+			}
+			value, err := strconv.ParseInt(arguments[i+1].String(), 10, 64)
+			if err != nil {
+				return options, protocol.NewError(notIntegerErrMsg)
+			}
+			options.expireOption = option
+			options.expireValue = value
+			i++
+		default:
+			return options, protocol.NewError(setSyntaxErrMsg)
+		}
+	}
+	if (options.nx && options.xx) || (options.keepTTL && options.expireOption != "") {
+		return options, protocol.NewError(setSyntaxErrMsg)
+	}
+	return options, nil
+}
+
+// expireAt converts the expiration option to the Unix time in milliseconds the key
+// expires at, or 0 if the key shouldn't expire. invalidErrMsg is the error replied when
+// the expiration isn't valid, which names the command.
+func (o setOptions) expireAt(invalidErrMsg string) (int64, protocol.DataType) {
+	if o.expireOption == "" {
+		return 0, nil
+	}
+	if o.expireValue <= 0 {
+		return 0, protocol.NewError(invalidErrMsg)
+	}
+	var milliseconds int64
+	switch o.expireOption {
+	case "EX":
+		if o.expireValue > (math.MaxInt64-time.Now().UnixMilli())/1000 {
+			return 0, protocol.NewError(invalidErrMsg)
+		}
+		milliseconds = time.Now().UnixMilli() + o.expireValue*1000
+	case "PX":
+		if o.expireValue > math.MaxInt64-time.Now().UnixMilli() {
+			return 0, protocol.NewError(invalidErrMsg)
+		}
+		milliseconds = time.Now().UnixMilli() + o.expireValue
+	case "EXAT":
+		if o.expireValue > math.MaxInt64/1000 {
+			return 0, protocol.NewError(invalidErrMsg)
+		}
+		milliseconds = o.expireValue * 1000
+	case "PXAT":
+		milliseconds = o.expireValue
+	}
+	return milliseconds, nil
+}
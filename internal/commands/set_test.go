@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type setTestCase struct {
+	name        string
+	setupCmds   []string
+	input       string
+	expected    any
+	ttlExpected int
+}
+
+func TestSet(t *testing.T) {
+	stcs := []setTestCase{
+		{
+			name:        "Plain set",
+			input:       "SET plain value",
+			expected:    protocol.NewSimpleString("OK"),
+			ttlExpected: -1,
+		},
+		{
+			name:        "Plain set clears the ttl",
+			setupCmds:   []string{"SET cleared value EX 100"},
+			input:       "SET cleared value",
+			expected:    protocol.NewSimpleString("OK"),
+			ttlExpected: -1,
+		},
+		{
+			name:        "Set with EX",
+			input:       "SET ex value EX 100",
+			expected:    protocol.NewSimpleString("OK"),
+			ttlExpected: 100,
+		},
+		{
+			name:        "Set with PX",
+			input:       "SET px value px 50000",
+			expected:    protocol.NewSimpleString("OK"),
+			ttlExpected: 50,
+		},
+		{
+			name:        "Set with KEEPTTL",
+			setupCmds:   []string{"SET keepttl value EX 100"},
+			input:       "SET keepttl other KEEPTTL",
+			expected:    protocol.NewSimpleString("OK"),
+			ttlExpected: 100,
+		},
+		{
+			name:        "NX on existing key",
+			setupCmds:   []string{"SET nx value"},
+			input:       "SET nx other NX",
+			expected:    protocol.NewNullBulkString(),
+			ttlExpected: -1,
+		},
+		{
+			name:        "XX on missing key",
+			input:       "SET xx value XX",
+			expected:    protocol.NewNullBulkString(),
+			ttlExpected: -2,
+		},
+		{
+			name:        "GET on missing key",
+			input:       "SET get value GET",
+			expected:    protocol.NewNullBulkString(),
+			ttlExpected: -1,
+		},
+		{
+			name:        "NX and XX",
+			input:       "SET both value NX XX",
+			expected:    protocol.NewError(setSyntaxErrMsg),
+			ttlExpected: -2,
+		},
+		{
+			name:        "EX and KEEPTTL",
+			input:       "SET both value EX 10 KEEPTTL",
+			expected:    protocol.NewError(setSyntaxErrMsg),
+			ttlExpected: -2,
+		},
+		{
+			name:        "Negative EX",
+			input:       "SET both value EX -10",
+			expected:    protocol.NewError(setInvalidExpireErrMsg),
+			ttlExpected: -2,
+		},
+		{
+			name:        "Non numeric PX",
+			input:       "SET both value PX soon",
+			expected:    protocol.NewError(notIntegerErrMsg),
+			ttlExpected: -2,
+		},
+	}
+	for _, tc := range stcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if actual != tc.expected {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+			key := parseInline(t, tc.input).GetElements()[1].String()
+			ttl := processInline(t, "TTL "+key)
+			if ttl != protocol.NewInteger(tc.ttlExpected) {
+				t.Fatalf("unexpected ttl. Expected: %d, Actual: %v", tc.ttlExpected, ttl)
+			}
+		})
+	}
+}
+
+func TestSetGetReturnsPreviousValue(t *testing.T) {
+	processInline(t, "SET previous old")
+	actual, ok := processInline(t, "SET previous new GET").(protocol.BulkString)
+	if !ok || actual.String() != "old" {
+		t.Fatalf("unexpected return value. Expected: old, Actual: %v", actual)
+	}
+	current := processInline(t, "GET previous")
+	if current.String() != "new" {
+		t.Fatalf("unexpected value. Expected: new, Actual: %v", current)
+	}
+}
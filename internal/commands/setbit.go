@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	setbitInvalidLengthErrMsg string = "the SETBIT command accepts 4 parameters: SETBIT, KEY, OFFSET and VALUE. Received %d parameters instead"
+	bitOffsetErrMsg           string = "bit offset is not an integer or out of range"
+	bitValueErrMsg            string = "bit is not an integer or out of range"
+)
+
+func init() {
+	setbit := setbitCommand{"setbit"}
+	registerCommand(setbit)
+}
+
+type setbitCommand struct {
+	name string
+}
+
+func (s setbitCommand) getName() string {
+	return s.name
+}
+
+// processArguments sets the bit at the offset of the string stored in the key, growing
+// the string if it's shorter, and returns the previous value of the bit.
+func (s setbitCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(fmt.Sprintf(setbitInvalidLengthErrMsg, len(elements)))
+	}
+	offset, ok := parseBitOffset(elements[2])
+	if !ok {
+		return protocol.NewError(bitOffsetErrMsg)
+	}
+	value, ok := parseInt(elements[3])
+	if !ok || (value != 0 && value != 1) {
+		return protocol.NewError(bitValueErrMsg)
+	}
+	key := elements[1].String()
+	bitmap, _, err := getBitmap(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	bitmap, previous := datastore.SetBit(bitmap, offset, value)
+	datastore.SetKeepTTL(key, protocol.NewBulkString(bitmap))
+	return protocol.NewInteger(previous)
+}
+
+// parseBitOffset parses the offset of a bit in a string, which can't be past the
+// longest string that can be stored.
+func parseBitOffset(argument protocol.DataType) (int, bool) {
+	offset, ok := parseInt(argument)
+	if !ok || offset < 0 || offset >= maxStringSize*8 {
+		return 0, false
+	}
+	return offset, true
+}
+
+// getBitmap returns the bytes of the string stored in the key, which is empty if the key
+// doesn't exist.
+func getBitmap(key string) ([]byte, bool, error) {
+	val, ok, err := datastore.Get(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	return []byte(val.String()), true, nil
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	setexInvalidLengthErrMsg string = "the %s command accepts 4 parameters: %s, KEY, %s and VALUE. Received %d parameters instead"
+	setexInvalidExpireErrMsg string = "invalid expire time in '%s' command"
+)
+
+func init() {
+	registerCommand(setexCommand{name: "setex", expireOption: "EX"})
+	registerCommand(setexCommand{name: "psetex", expireOption: "PX"})
+}
+
+// setexCommand implements SETEX, which stores a value with a time to live in seconds,
+// and PSETEX, which takes it in milliseconds. Both are SET with the matching option.
+type setexCommand struct {
+	name         string
+	expireOption string
+}
+
+func (s setexCommand) getName() string {
+	return s.name
+}
+
+func (s setexCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		name, unit := strings.ToUpper(s.name), "SECONDS"
+		if s.expireOption == "PX" {
+			unit = "MILLISECONDS"
+		}
+		return protocol.NewError(fmt.Sprintf(setexInvalidLengthErrMsg, name, name, unit, len(elements)))
+	}
+	value, err := strconv.ParseInt(elements[2].String(), 10, 64)
+	if err != nil {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	options := setOptions{expireOption: s.expireOption, expireValue: value}
+	expire, errReply := options.expireAt(fmt.Sprintf(setexInvalidExpireErrMsg, s.name))
+	if errReply != nil {
+		return errReply
+	}
+	datastore.SetWithExpire(elements[1].String(), elements[3], expire)
+	return protocol.NewSimpleString("OK")
+}
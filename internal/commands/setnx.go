@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	setnxInvalidLengthErrMsg string = "the SETNX command accepts 3 parameters: SETNX, KEY and VALUE. Received %d parameters instead"
+)
+
+func init() {
+	setnx := setnxCommand{"setnx"}
+	registerCommand(setnx)
+}
+
+type setnxCommand struct {
+	name string
+}
+
+func (s setnxCommand) getName() string {
+	return s.name
+}
+
+// processArguments stores the value in the key only if the key doesn't exist, returning
+// 1 if the value was stored and 0 otherwise.
+func (s setnxCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(fmt.Sprintf(setnxInvalidLengthErrMsg, len(elements)))
+	}
+	key := elements[1].String()
+	if datastore.Exists(key) {
+		return protocol.NewInteger(0)
+	}
+	datastore.Set(key, elements[2])
+	return protocol.NewInteger(1)
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	setrangeInvalidLengthErrMsg string = "the SETRANGE command accepts 4 parameters: SETRANGE, KEY, OFFSET and VALUE. Received %d parameters instead"
+	offsetOutOfRangeErrMsg      string = "offset is out of range"
+)
+
+func init() {
+	setrange := setrangeCommand{"setrange"}
+	registerCommand(setrange)
+}
+
+type setrangeCommand struct {
+	name string
+}
+
+func (s setrangeCommand) getName() string {
+	return s.name
+}
+
+// processArguments overwrites the string stored in the key with the value, starting at
+// the offset, and returns the length of the resulting string. If the string is shorter
+// than the offset it's padded with zero bytes.
+func (s setrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(fmt.Sprintf(setrangeInvalidLengthErrMsg, len(elements)))
+	}
+	offset, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	if offset < 0 {
+		return protocol.NewError(offsetOutOfRangeErrMsg)
+	}
+	key := elements[1].String()
+	val, ok, err := datastore.Get(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var current []byte
+	if ok {
+		current = []byte(val.String())
+	}
+	value := []byte(elements[3].String())
+	// An empty value doesn't change the string nor create the key
+	if len(value) == 0 {
+		return protocol.NewInteger(len(current))
+	}
+	if offset+len(value) > maxStringSize {
+		return protocol.NewError(stringTooLongErrMsg)
+	}
+	result := make([]byte, max(len(current), offset+len(value)))
+	copy(result, current)
+	copy(result[offset:], value)
+	datastore.SetKeepTTL(key, protocol.NewBulkString(result))
+	return protocol.NewInteger(len(result))
+}
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type setTypeTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+// bulkStringSet creates the expected reply for commands that return set members.
+func bulkStringSet(values ...string) protocol.Set {
+	elements := []protocol.DataType{}
+	for _, value := range values {
+		elements = append(elements, protocol.NewBulkString([]byte(value)))
+	}
+	return protocol.NewSet(elements...)
+}
+
+func TestSetCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	stcs := []setTypeTestCase{
+		{
+			name:      "SADD",
+			setupCmds: []string{"SADD sets-sadd 1"},
+			input:     "SADD sets-sadd 1 2 3",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "SMEMBERS",
+			setupCmds: []string{"SADD sets-smembers 3 1 2"},
+			input:     "SMEMBERS sets-smembers",
+			expected:  bulkStringSet("1", "2", "3"),
+		},
+		{
+			name:     "SMEMBERS on missing key",
+			input:    "SMEMBERS sets-missing",
+			expected: bulkStringSet(),
+		},
+		{
+			name:      "SREM",
+			setupCmds: []string{"SADD sets-srem a b"},
+			input:     "SREM sets-srem a c",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "SREM removes empty set",
+			setupCmds: []string{"SADD sets-srem-empty a", "SREM sets-srem-empty a"},
+			input:     "EXISTS sets-srem-empty",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "SCARD",
+			setupCmds: []string{"SADD sets-scard a b c"},
+			input:     "SCARD sets-scard",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "SISMEMBER",
+			setupCmds: []string{"SADD sets-sismember a"},
+			input:     "SISMEMBER sets-sismember a",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "SMISMEMBER",
+			setupCmds: []string{"SADD sets-smismember a b"},
+			input:     "SMISMEMBER sets-smismember b c a",
+			expected:  integerArray(1, 0, 1),
+		},
+		{
+			name:      "SPOP with count larger than the set",
+			setupCmds: []string{"SADD sets-spop 1"},
+			input:     "SPOP sets-spop 5",
+			expected:  bulkStringSet("1"),
+		},
+		{
+			name:      "SPOP removes the members",
+			setupCmds: []string{"SADD sets-spop-remove a", "SPOP sets-spop-remove"},
+			input:     "EXISTS sets-spop-remove",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "SPOP with negative count",
+			setupCmds: []string{"SADD sets-spop-negative a"},
+			input:     "SPOP sets-spop-negative -1",
+			expected:  protocol.NewError(notPositiveErrMsg),
+		},
+		{
+			name:      "SRANDMEMBER with negative count",
+			setupCmds: []string{"SADD sets-srandmember a"},
+			input:     "SRANDMEMBER sets-srandmember -3",
+			expected:  bulkStringArray("a", "a", "a"),
+		},
+		{
+			name:      "SRANDMEMBER with negative count out of range",
+			setupCmds: []string{"SADD sets-srandmember-range a"},
+			input:     "SRANDMEMBER sets-srandmember-range -9223372036854775808",
+			expected:  protocol.NewError(fmt.Sprintf(randomCountErrMsg, maxRandomCount)),
+		},
+		{
+			name:     "SRANDMEMBER on missing key",
+			input:    "SRANDMEMBER sets-missing",
+			expected: protocol.NewNullBulkString(),
+		},
+		{
+			name:      "SMOVE",
+			setupCmds: []string{"SADD sets-smove-src a b", "SMOVE sets-smove-src sets-smove-dst a"},
+			input:     "SMEMBERS sets-smove-dst",
+			expected:  bulkStringSet("a"),
+		},
+		{
+			name:      "SMOVE missing member",
+			setupCmds: []string{"SADD sets-smove-missing a"},
+			input:     "SMOVE sets-smove-missing sets-smove-other b",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "SMOVE to a string",
+			setupCmds: []string{"SADD sets-smove-wrong a", "SET sets-smove-string value"},
+			input:     "SMOVE sets-smove-wrong sets-smove-string a",
+			expected:  wrongType,
+		},
+		{
+			name:      "SINTER",
+			setupCmds: []string{"SADD sets-sinter-a 1 2 3 4", "SADD sets-sinter-b 2 4 6"},
+			input:     "SINTER sets-sinter-a sets-sinter-b",
+			expected:  bulkStringSet("2", "4"),
+		},
+		{
+			name:      "SINTER with missing key",
+			setupCmds: []string{"SADD sets-sinter-c 1"},
+			input:     "SINTER sets-sinter-c sets-missing",
+			expected:  bulkStringSet(),
+		},
+		{
+			name:      "SUNION",
+			setupCmds: []string{"SADD sets-sunion-a 1 3", "SADD sets-sunion-b 2 3"},
+			input:     "SUNION sets-sunion-a sets-missing sets-sunion-b",
+			expected:  bulkStringSet("1", "2", "3"),
+		},
+		{
+			name:      "SDIFF",
+			setupCmds: []string{"SADD sets-sdiff-a 1 2 3", "SADD sets-sdiff-b 2"},
+			input:     "SDIFF sets-sdiff-a sets-sdiff-b",
+			expected:  bulkStringSet("1", "3"),
+		},
+		{
+			name:      "SINTERSTORE",
+			setupCmds: []string{"SADD sets-store-a 1 2 3", "SADD sets-store-b 2 3 4"},
+			input:     "SINTERSTORE sets-store-dst sets-store-a sets-store-b",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "SUNIONSTORE overwrites the destination",
+			setupCmds: []string{"SET sets-unionstore-dst value", "SADD sets-unionstore-a 1", "SUNIONSTORE sets-unionstore-dst sets-unionstore-a"},
+			input:     "SMEMBERS sets-unionstore-dst",
+			expected:  bulkStringSet("1"),
+		},
+		{
+			name:      "SDIFFSTORE with empty result deletes the destination",
+			setupCmds: []string{"SADD sets-diffstore-dst a", "SADD sets-diffstore-a 1", "SDIFFSTORE sets-diffstore-dst sets-diffstore-a sets-diffstore-a"},
+			input:     "EXISTS sets-diffstore-dst",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "SINTERCARD",
+			setupCmds: []string{"SADD sets-card-a a b c d", "SADD sets-card-b b c d e"},
+			input:     "SINTERCARD 2 sets-card-a sets-card-b",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "SINTERCARD with LIMIT",
+			setupCmds: []string{"SADD sets-card-limit-a a b c", "SADD sets-card-limit-b a b c"},
+			input:     "SINTERCARD 2 sets-card-limit-a sets-card-limit-b LIMIT 2",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:     "SINTERCARD with too many keys",
+			input:    "SINTERCARD 3 sets-card-a sets-card-b",
+			expected: protocol.NewError(numkeysTooBigErrMsg),
+		},
+		{
+			name:      "SSCAN",
+			setupCmds: []string{"SADD sets-sscan apple banana avocado"},
+			input:     "SSCAN sets-sscan 0 MATCH a*",
+			expected:  protocol.NewArray(protocol.NewBulkString([]byte("0")), bulkStringArray("apple", "avocado")),
+		},
+		{
+			name:      "Set command on a string",
+			setupCmds: []string{"SET sets-string value"},
+			input:     "SADD sets-string a",
+			expected:  wrongType,
+		},
+		{
+			name:      "Set operation with a string",
+			setupCmds: []string{"SET sets-op-string value"},
+			input:     "SUNION sets-missing sets-op-string",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range stcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSetScanWithDeletes(t *testing.T) {
+	processInline(t, "SADD sets-sscan-deletes sc1 sc2 sc3 sc4 sc5 sc6")
+	returned := scanWithDeletes(t, "SSCAN", "SREM", "sets-sscan-deletes", "")
+	if len(returned) != 6 {
+		t.Fatalf("every member should have been returned, got %v", returned)
+	}
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	setOperationInvalidLengthErrMsg      string = "invalid arguments for command %s. Syntax: %s key [key ...]"
+	setOperationStoreInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s destination key [key ...]"
+)
+
+// setOperation is the operation a command applies to the sets stored in its keys.
+type setOperation int
+
+const (
+	setIntersection setOperation = iota
+	setUnion
+	setDifference
+)
+
+func init() {
+	registerCommand(setOperationCommand{name: "sinter", operation: setIntersection})
+	registerCommand(setOperationCommand{name: "sunion", operation: setUnion})
+	registerCommand(setOperationCommand{name: "sdiff", operation: setDifference})
+	registerCommand(setOperationCommand{name: "sinterstore", operation: setIntersection, store: true})
+	registerCommand(setOperationCommand{name: "sunionstore", operation: setUnion, store: true})
+	registerCommand(setOperationCommand{name: "sdiffstore", operation: setDifference, store: true})
+}
+
+// setOperationCommand implements SINTER, SUNION, SDIFF and their STORE variants, which
+// write the resulting set into the destination key instead of returning it.
+type setOperationCommand struct {
+	name      string
+	operation setOperation
+	store     bool
+}
+
+func (s setOperationCommand) getName() string {
+	return s.name
+}
+
+func (s setOperationCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	minLength := 2
+	errMsg := setOperationInvalidLengthErrMsg
+	if s.store {
+		minLength = 3
+		errMsg = setOperationStoreInvalidLengthErrMsg
+	}
+	if len(elements) < minLength {
+		name := strings.ToUpper(s.name)
+		return protocol.NewError(fmt.Sprintf(errMsg, name, name))
+	}
+	keys := []string{}
+	for _, element := range elements[minLength-1:] {
+		keys = append(keys, element.String())
+	}
+	sets, err := getSets(keys)
+	if err != nil {
+		return errorReply(err)
+	}
+	result := applySetOperation(s.operation, sets)
+	if s.store {
+		datastore.StoreSet(elements[1].String(), result)
+		return protocol.NewInteger(result.Len())
+	}
+	return membersReply(result.Members())
+}
+
+// getSets returns the sets stored in the keys, with nil for the keys that don't exist.
+func getSets(keys []string) ([]*datastore.UnorderedSet, error) {
+	sets := []*datastore.UnorderedSet{}
+	for _, key := range keys {
+		set, _, err := datastore.GetSet(key)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// applySetOperation creates a new set with the result of the operation. Missing keys,
+// which are nil sets, are handled as empty sets.
+func applySetOperation(operation setOperation, sets []*datastore.UnorderedSet) *datastore.UnorderedSet {
+	result := datastore.NewUnorderedSet()
+	switch operation {
+	case setIntersection:
+		if slices.Contains(sets, nil) {
+			return result
+		}
+		// Going through the smallest set does the fewest lookups
+		sets = slices.Clone(sets)
+		slices.SortFunc(sets, func(a, b *datastore.UnorderedSet) int { return a.Len() - b.Len() })
+		for _, member := range sets[0].Members() {
+			if inAllSets(member, sets[1:]) {
+				result.Add(member)
+			}
+		}
+	case setUnion:
+		for _, set := range sets {
+			if set == nil {
+				continue
+			}
+			for _, member := range set.Members() {
+				result.Add(member)
+			}
+		}
+	case setDifference:
+		if sets[0] == nil {
+			return result
+		}
+		for _, member := range sets[0].Members() {
+			if !inAnySet(member, sets[1:]) {
+				result.Add(member)
+			}
+		}
+	}
+	return result
+}
+
+func inAllSets(member string, sets []*datastore.UnorderedSet) bool {
+	for _, set := range sets {
+		if !set.Contains(member) {
+			return false
+		}
+	}
+	return true
+}
+
+func inAnySet(member string, sets []*datastore.UnorderedSet) bool {
+	for _, set := range sets {
+		if set != nil && set.Contains(member) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	sintercardInvalidLengthErrMsg string = "invalid arguments for command SINTERCARD. Syntax: SINTERCARD numkeys key [key ...] [LIMIT limit]"
+	numkeysTooBigErrMsg           string = "Number of keys can't be greater than number of args"
+	negativeLimitErrMsg           string = "LIMIT can't be negative"
+)
+
+func init() {
+	sintercard := sintercardCommand{"sintercard"}
+	registerCommand(sintercard)
+}
+
+type sintercardCommand struct {
+	name string
+}
+
+func (s sintercardCommand) getName() string {
+	return s.name
+}
+
+// processArguments returns the size of the intersection of the sets without creating
+// it. With a LIMIT, counting stops once the limit is reached; a limit of 0 means no
+// limit.
+func (s sintercardCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(sintercardInvalidLengthErrMsg)
+	}
+	numkeys, ok := parseInt(elements[1])
+	if !ok || numkeys <= 0 {
+		return protocol.NewError(numkeysErrMsg)
+	}
+	if numkeys > len(elements)-2 {
+		return protocol.NewError(numkeysTooBigErrMsg)
+	}
+	limit := 0
+	options := elements[2+numkeys:]
+	if len(options) > 0 {
+		if len(options) != 2 || strings.ToUpper(options[0].String()) != "LIMIT" {
+			return protocol.NewError(sintercardInvalidLengthErrMsg)
+		}
+		limit, ok = parseInt(options[1])
+		if !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		if limit < 0 {
+			return protocol.NewError(negativeLimitErrMsg)
+		}
+	}
+	keys := []string{}
+	for _, element := range elements[2 : 2+numkeys] {
+		keys = append(keys, element.String())
+	}
+	sets, err := getSets(keys)
+	if err != nil {
+		return errorReply(err)
+	}
+	if slices.Contains(sets, nil) {
+		return protocol.NewInteger(0)
+	}
+	slices.SortFunc(sets, func(a, b *datastore.UnorderedSet) int { return a.Len() - b.Len() })
+	count := 0
+	for _, member := range sets[0].Members() {
+		if inAllSets(member, sets[1:]) {
+			count++
+			if count == limit {
+				break
+			}
+		}
+	}
+	return protocol.NewInteger(count)
+}
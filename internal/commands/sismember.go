@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	sismemberInvalidLengthErrMsg  string = "invalid arguments for command SISMEMBER. Syntax: SISMEMBER key member"
+	smismemberInvalidLengthErrMsg string = "invalid arguments for command SMISMEMBER. Syntax: SMISMEMBER key member [member ...]"
+)
+
+func init() {
+	sismember := sismemberCommand{"sismember"}
+	registerCommand(sismember)
+	smismember := smismemberCommand{"smismember"}
+	registerCommand(smismember)
+}
+
+type sismemberCommand struct {
+	name string
+}
+
+type smismemberCommand struct {
+	name string
+}
+
+func (s sismemberCommand) getName() string {
+	return s.name
+}
+
+func (s sismemberCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(sismemberInvalidLengthErrMsg)
+	}
+	set, ok, err := datastore.GetSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if ok && set.Contains(elements[2].String()) {
+		return protocol.NewInteger(1)
+	}
+	return protocol.NewInteger(0)
+}
+
+func (s smismemberCommand) getName() string {
+	return s.name
+}
+
+func (s smismemberCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(smismemberInvalidLengthErrMsg)
+	}
+	set, ok, err := datastore.GetSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, member := range elements[2:] {
+		if ok && set.Contains(member.String()) {
+			reply = append(reply, protocol.NewInteger(1))
+		} else {
+			reply = append(reply, protocol.NewInteger(0))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
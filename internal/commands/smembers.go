@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	smembersInvalidLengthErrMsg string = "the %s command accepts 2 parameters: %s and KEY. Received %d parameters instead"
+)
+
+func init() {
+	smembers := smembersCommand{"smembers"}
+	registerCommand(smembers)
+	scard := scardCommand{"scard"}
+	registerCommand(scard)
+}
+
+type smembersCommand struct {
+	name string
+}
+
+type scardCommand struct {
+	name string
+}
+
+func (s smembersCommand) getName() string {
+	return s.name
+}
+
+func (s smembersCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(smembersInvalidLengthErrMsg, "SMEMBERS", "SMEMBERS", len(elements)))
+	}
+	set, ok, err := datastore.GetSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return membersReply(nil)
+	}
+	return membersReply(set.Members())
+}
+
+func (s scardCommand) getName() string {
+	return s.name
+}
+
+func (s scardCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(smembersInvalidLengthErrMsg, "SCARD", "SCARD", len(elements)))
+	}
+	set, ok, err := datastore.GetSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(set.Len())
+}
+
+// membersReply creates the reply for commands returning the members of a set, which is
+// a set for RESP3 clients and an array for RESP2 ones.
+func membersReply(members []string) protocol.Set {
+	elements := []protocol.DataType{}
+	for _, member := range members {
+		elements = append(elements, protocol.NewBulkString([]byte(member)))
+	}
+	return protocol.NewSet(elements...)
+}
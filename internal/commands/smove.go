@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	smoveInvalidLengthErrMsg string = "invalid arguments for command SMOVE. Syntax: SMOVE source destination member"
+)
+
+func init() {
+	smove := smoveCommand{"smove"}
+	registerCommand(smove)
+}
+
+type smoveCommand struct {
+	name string
+}
+
+func (s smoveCommand) getName() string {
+	return s.name
+}
+
+func (s smoveCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(smoveInvalidLengthErrMsg)
+	}
+	source := elements[1].String()
+	destination := elements[2].String()
+	member := elements[3].String()
+	sourceSet, ok, err := datastore.GetSet(source)
+	if err != nil {
+		return errorReply(err)
+	}
+	// The destination type is checked even when there is nothing to move
+	if _, _, err := datastore.GetSet(destination); err != nil {
+		return errorReply(err)
+	}
+	if !ok || !sourceSet.Contains(member) {
+		return protocol.NewInteger(0)
+	}
+	if source == destination {
+		return protocol.NewInteger(1)
+	}
+	sourceSet.Remove(member)
+	if sourceSet.Len() == 0 {
+		datastore.Delete(source)
+	}
+	destinationSet, _ := datastore.GetOrCreateSet(destination)
+	destinationSet.Add(member)
+	return protocol.NewInteger(1)
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	spopInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key [count]"
+)
+
+func init() {
+	registerCommand(spopCommand{name: "spop", remove: true})
+	registerCommand(spopCommand{name: "srandmember"})
+}
+
+// spopCommand implements SPOP, which removes random members from the set, and
+// SRANDMEMBER, which only returns them.
+type spopCommand struct {
+	name   string
+	remove bool
+}
+
+func (s spopCommand) getName() string {
+	return s.name
+}
+
+// processArguments returns random members of the set. A positive count returns up to
+// count distinct members. SRANDMEMBER also accepts a negative count, returning exactly
+// -count members which may repeat, up to maxRandomCount.
+func (s spopCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 || len(elements) > 3 {
+		name := strings.ToUpper(s.name)
+		return protocol.NewError(fmt.Sprintf(spopInvalidLengthErrMsg, name, name))
+	}
+	withCount := len(elements) == 3
+	count := 1
+	if withCount {
+		var ok bool
+		count, ok = parseInt(elements[2])
+		if !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		if s.remove && count < 0 {
+			return protocol.NewError(notPositiveErrMsg)
+		}
+		if count < -maxRandomCount {
+			return protocol.NewError(fmt.Sprintf(randomCountErrMsg, maxRandomCount))
+		}
+	}
+	key := elements[1].String()
+	set, ok, err := datastore.GetSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		if !withCount {
+			return protocol.NewNullBulkString()
+		}
+		if s.remove {
+			return membersReply(nil)
+		}
+		return protocol.NewArray()
+	}
+	members := set.Members()
+	var selected []string
+	if count >= 0 {
+		rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+		selected = members[:min(count, len(members))]
+	} else {
+		for i := 0; i < -count; i++ {
+			selected = append(selected, members[rand.IntN(len(members))])
+		}
+	}
+	if s.remove {
+		for _, member := range selected {
+			set.Remove(member)
+		}
+		if set.Len() == 0 {
+			datastore.Delete(key)
+		}
+	}
+	if !withCount {
+		return protocol.NewBulkString([]byte(selected[0]))
+	}
+	if s.remove {
+		return membersReply(selected)
+	}
+	reply := []protocol.DataType{}
+	for _, member := range selected {
+		reply = append(reply, protocol.NewBulkString([]byte(member)))
+	}
+	return protocol.NewArray(reply...)
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	sremInvalidLengthErrMsg string = "invalid arguments for command SREM. Syntax: SREM key member [member ...]"
+)
+
+func init() {
+	srem := sremCommand{"srem"}
+	registerCommand(srem)
+}
+
+type sremCommand struct {
+	name string
+}
+
+func (s sremCommand) getName() string {
+	return s.name
+}
+
+func (s sremCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(sremInvalidLengthErrMsg)
+	}
+	key := elements[1].String()
+	set, ok, err := datastore.GetSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	removed := 0
+	for _, member := range elements[2:] {
+		if set.Remove(member.String()) {
+			removed++
+		}
+	}
+	if set.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewInteger(removed)
+}
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"strconv"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	sscanInvalidLengthErrMsg string = "invalid arguments for command SSCAN. Syntax: SSCAN key cursor [MATCH pattern] [COUNT count]"
+)
+
+func init() {
+	sscan := sscanCommand{"sscan"}
+	registerCommand(sscan)
+}
+
+type sscanCommand struct {
+	name string
+}
+
+func (s sscanCommand) getName() string {
+	return s.name
+}
+
+// processArguments iterates over the members of the set, in the order of scanPage.
+func (s sscanCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(sscanInvalidLengthErrMsg)
+	}
+	options, errReply := parseScanOptions(elements[2:], sscanInvalidLengthErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	if options.noValues {
+		return protocol.NewError(sscanInvalidLengthErrMsg)
+	}
+	set, ok, err := datastore.GetSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewArray(protocol.NewBulkString([]byte("0")), protocol.NewArray())
+	}
+	page, next := scanPage(set.Members(), options)
+	reply := []protocol.DataType{}
+	for _, member := range page {
+		reply = append(reply, protocol.NewBulkString([]byte(member)))
+	}
+	return protocol.NewArray(protocol.NewBulkString([]byte(strconv.Itoa(next))), protocol.NewArray(reply...))
+}
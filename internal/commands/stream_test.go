@@ -0,0 +1,300 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type streamTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+// streamEntries creates the expected reply for commands that return stream entries,
+// given as an ID followed by its fields and values, one slice per entry.
+func streamEntries(entries ...[]string) protocol.Array {
+	elements := []protocol.DataType{}
+	for _, entry := range entries {
+		elements = append(elements, protocol.NewArray(
+			protocol.NewBulkString([]byte(entry[0])),
+			bulkStringArray(entry[1:]...),
+		))
+	}
+	return protocol.NewArray(elements...)
+}
+
+func TestStreamCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	stcs := []streamTestCase{
+		{
+			name:     "XADD with explicit ID",
+			input:    "XADD stream-xadd 5-3 field value",
+			expected: protocol.NewBulkString([]byte("5-3")),
+		},
+		{
+			name:      "XADD with auto sequence",
+			setupCmds: []string{"XADD stream-xadd-seq 5-3 field value"},
+			input:     "XADD stream-xadd-seq 5-* field value",
+			expected:  protocol.NewBulkString([]byte("5-4")),
+		},
+		{
+			name:      "XADD with smaller ID",
+			setupCmds: []string{"XADD stream-xadd-smaller 5-3 field value"},
+			input:     "XADD stream-xadd-smaller 5-3 field value",
+			expected:  protocol.NewError(xaddSmallerIDErrMsg),
+		},
+		{
+			name:     "XADD with 0-0",
+			input:    "XADD stream-xadd-zero 0-0 field value",
+			expected: protocol.NewError(xaddZeroIDErrMsg),
+		},
+		{
+			name:     "XADD with invalid ID",
+			input:    "XADD stream-xadd-invalid 1-x field value",
+			expected: protocol.NewError(invalidStreamIDErrMsg),
+		},
+		{
+			name:     "XADD with NOMKSTREAM",
+			input:    "XADD stream-xadd-nomk NOMKSTREAM * field value",
+			expected: protocol.NewNullBulkString(),
+		},
+		{
+			name:      "XADD with MAXLEN",
+			setupCmds: append(streamLog("stream-maxlen")[:3], "XADD stream-maxlen MAXLEN 2 3-5 d 4"),
+			input:     "XRANGE stream-maxlen - +",
+			expected:  streamEntries([]string{"2-0", "c", "3"}, []string{"3-5", "d", "4"}),
+		},
+		{
+			name:     "XADD with odd fields",
+			input:    "XADD stream-xadd-odd * field",
+			expected: protocol.NewError(xaddInvalidLengthErrMsg),
+		},
+		{
+			name:      "XRANGE",
+			setupCmds: streamLog("stream-xrange"),
+			input:     "XRANGE stream-xrange 1 2",
+			expected:  streamEntries([]string{"1-1", "a", "1"}, []string{"1-2", "b", "2"}, []string{"2-0", "c", "3"}),
+		},
+		{
+			name:      "XRANGE with exclusive start and COUNT",
+			setupCmds: streamLog("stream-xrange-excl"),
+			input:     "XRANGE stream-xrange-excl (1-1 + COUNT 2",
+			expected:  streamEntries([]string{"1-2", "b", "2"}, []string{"2-0", "c", "3"}),
+		},
+		{
+			name:      "XREVRANGE",
+			setupCmds: streamLog("stream-xrevrange"),
+			input:     "XREVRANGE stream-xrevrange + (2-0",
+			expected:  streamEntries([]string{"3-5", "d", "4"}),
+		},
+		{
+			name:     "XRANGE on a missing key",
+			input:    "XRANGE stream-missing - +",
+			expected: protocol.NewArray(),
+		},
+		{
+			name:      "XLEN",
+			setupCmds: streamLog("stream-xlen"),
+			input:     "XLEN stream-xlen",
+			expected:  protocol.NewInteger(4),
+		},
+		{
+			name:      "XDEL",
+			setupCmds: streamLog("stream-xdel"),
+			input:     "XDEL stream-xdel 1-2 9-9 2-0",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "XDEL keeps the last ID",
+			setupCmds: []string{"XADD stream-xdel-last 1-1 a 1", "XDEL stream-xdel-last 1-1"},
+			input:     "XADD stream-xdel-last 1-1 a 1",
+			expected:  protocol.NewError(xaddSmallerIDErrMsg),
+		},
+		{
+			name:      "XTRIM with MINID",
+			setupCmds: streamLog("stream-xtrim"),
+			input:     "XTRIM stream-xtrim MINID 2",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:     "XTRIM with LIMIT without ~",
+			input:    "XTRIM stream-xtrim-limit MAXLEN 1 LIMIT 10",
+			expected: protocol.NewError(xtrimLimitErrMsg),
+		},
+		{
+			name:     "XGROUP CREATE on a missing key",
+			input:    "XGROUP CREATE stream-missing g $",
+			expected: protocol.NewError(xgroupNoKeyErrMsg),
+		},
+		{
+			name:      "XGROUP CREATE existing group",
+			setupCmds: []string{"XGROUP CREATE stream-group g $ MKSTREAM"},
+			input:     "XGROUP CREATE stream-group g $",
+			expected:  protocol.NewError(xgroupBusyErrMsg),
+		},
+		{
+			name:      "XGROUP CREATECONSUMER",
+			setupCmds: []string{"XGROUP CREATE stream-consumer g $ MKSTREAM", "XGROUP CREATECONSUMER stream-consumer g alice"},
+			input:     "XGROUP CREATECONSUMER stream-consumer g alice",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "XGROUP DESTROY",
+			setupCmds: []string{"XGROUP CREATE stream-destroy g $ MKSTREAM"},
+			input:     "XGROUP DESTROY stream-destroy g",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:     "XACK on a missing key",
+			input:    "XACK stream-missing g 1-1",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:      "XPENDING on a missing group",
+			setupCmds: streamLog("stream-xpending-nogroup"),
+			input:     "XPENDING stream-xpending-nogroup g",
+			expected:  protocol.NewError(fmt.Sprintf(xpendingNoGroupErrMsg, "stream-xpending-nogroup", "g")),
+		},
+		{
+			name:      "XINFO GROUPS",
+			setupCmds: append(streamLog("stream-xinfo"), "XGROUP CREATE stream-xinfo g 1-2 ENTRIESREAD 2"),
+			input:     "XINFO GROUPS stream-xinfo",
+			expected: protocol.NewArray(protocol.NewMap(
+				protocol.NewBulkString([]byte("name")), protocol.NewBulkString([]byte("g")),
+				protocol.NewBulkString([]byte("consumers")), protocol.NewInteger(0),
+				protocol.NewBulkString([]byte("pending")), protocol.NewInteger(0),
+				protocol.NewBulkString([]byte("last-delivered-id")), protocol.NewBulkString([]byte("1-2")),
+				protocol.NewBulkString([]byte("entries-read")), protocol.NewInteger(2),
+				protocol.NewBulkString([]byte("lag")), protocol.NewInteger(2),
+			)),
+		},
+		{
+			name:     "XINFO on a missing key",
+			input:    "XINFO STREAM stream-missing",
+			expected: protocol.NewError(noSuchKeyLowerErrMsg),
+		},
+		{
+			name:      "Stream command on a string",
+			setupCmds: []string{"SET stream-string value"},
+			input:     "XADD stream-string * field value",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range stcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// streamLog returns the commands that add the same four entries to the key.
+func streamLog(key string) []string {
+	return []string{
+		fmt.Sprintf("XADD %s 1-1 a 1", key),
+		fmt.Sprintf("XADD %s 1-2 b 2", key),
+		fmt.Sprintf("XADD %s 2-0 c 3", key),
+		fmt.Sprintf("XADD %s 3-5 d 4", key),
+	}
+}
+
+func TestStreamConsumerGroups(t *testing.T) {
+	client := NewClient()
+	processInline(t, "XADD stream-cg 1-0 a 1")
+	processInline(t, "XADD stream-cg 2-0 b 2")
+	processInline(t, "XGROUP CREATE stream-cg g 0")
+
+	reply := processClientInline(t, client, "XREADGROUP GROUP g alice COUNT 1 STREAMS stream-cg >")
+	expected := protocol.NewArray(protocol.NewArray(
+		protocol.NewBulkString([]byte("stream-cg")),
+		streamEntries([]string{"1-0", "a", "1"}),
+	))
+	if !reflect.DeepEqual(reply, expected) {
+		t.Fatalf("unexpected reply. Expected: %v, Actual: %v", expected, reply)
+	}
+	processClientInline(t, client, "XREADGROUP GROUP g bob STREAMS stream-cg >")
+
+	summary := processInline(t, "XPENDING stream-cg g")
+	expected = protocol.NewArray(
+		protocol.NewInteger(2),
+		protocol.NewBulkString([]byte("1-0")),
+		protocol.NewBulkString([]byte("2-0")),
+		protocol.NewArray(
+			bulkStringArray("alice", "1"),
+			bulkStringArray("bob", "1"),
+		),
+	)
+	if !reflect.DeepEqual(summary, expected) {
+		t.Fatalf("unexpected summary. Expected: %v, Actual: %v", expected, summary)
+	}
+
+	// alice's history holds the entry delivered to her until it's acknowledged
+	history := processClientInline(t, client, "XREADGROUP GROUP g alice STREAMS stream-cg 0")
+	expected = protocol.NewArray(protocol.NewArray(
+		protocol.NewBulkString([]byte("stream-cg")),
+		streamEntries([]string{"1-0", "a", "1"}),
+	))
+	if !reflect.DeepEqual(history, expected) {
+		t.Fatalf("unexpected history. Expected: %v, Actual: %v", expected, history)
+	}
+
+	claimed := processInline(t, "XCLAIM stream-cg g alice 0 2-0 JUSTID")
+	if !reflect.DeepEqual(claimed, bulkStringArray("2-0")) {
+		t.Fatalf("unexpected claimed entries %v", claimed)
+	}
+	autoclaimed := processInline(t, "XAUTOCLAIM stream-cg g bob 0 0 COUNT 1")
+	expected = protocol.NewArray(
+		protocol.NewBulkString([]byte("2-0")),
+		streamEntries([]string{"1-0", "a", "1"}),
+		bulkStringArray(),
+	)
+	if !reflect.DeepEqual(autoclaimed, expected) {
+		t.Fatalf("unexpected autoclaimed entries. Expected: %v, Actual: %v", expected, autoclaimed)
+	}
+	if acked := processInline(t, "XACK stream-cg g 1-0 2-0 3-0"); !reflect.DeepEqual(acked, protocol.NewInteger(2)) {
+		t.Fatalf("unexpected acknowledged entries %v", acked)
+	}
+	if summary := processInline(t, "XPENDING stream-cg g"); !reflect.DeepEqual(summary.(protocol.Array).GetElements()[0], protocol.NewInteger(0)) {
+		t.Fatalf("there shouldn't be pending entries: %v", summary)
+	}
+}
+
+func TestStreamBlockingRead(t *testing.T) {
+	reader, groupReader := NewClient(), NewClient()
+	processInline(t, "XGROUP CREATE stream-block g $ MKSTREAM")
+	if reply := processClientInline(t, reader, "XREAD BLOCK 0 STREAMS stream-block $"); reply != nil {
+		t.Fatalf("client should have blocked, got %v", reply)
+	}
+	if reply := processClientInline(t, groupReader, "XREADGROUP GROUP g alice BLOCK 0 STREAMS stream-block >"); reply != nil {
+		t.Fatalf("client should have blocked, got %v", reply)
+	}
+	processInline(t, "XADD stream-block 1-0 a 1")
+	entries := protocol.NewArray(protocol.NewArray(
+		protocol.NewBulkString([]byte("stream-block")),
+		streamEntries([]string{"1-0", "a", "1"}),
+	))
+	replies := ServeBlockedClients(time.Now())
+	expected := []BlockedReply{{reader, entries}, {groupReader, entries}}
+	if !reflect.DeepEqual(replies, expected) {
+		t.Fatalf("unexpected replies. Expected: %v, Actual: %v", expected, replies)
+	}
+
+	if reply := processClientInline(t, reader, "XREAD COUNT 1 STREAMS stream-block 0"); !reflect.DeepEqual(reply, entries) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+	if reply := processClientInline(t, reader, "XREAD STREAMS stream-block 1-0"); !reflect.DeepEqual(reply, protocol.NewNullArray()) {
+		t.Fatalf("unexpected reply %v", reply)
+	}
+}
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type stringTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+func TestStringCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	stcs := []stringTestCase{
+		{
+			name:      "APPEND",
+			setupCmds: []string{"SET string-append Hello", "APPEND string-append \" World\""},
+			input:     "GET string-append",
+			expected:  protocol.NewBulkString([]byte("Hello World")),
+		},
+		{
+			name:     "APPEND to a missing key",
+			input:    "APPEND string-append-missing abc",
+			expected: protocol.NewInteger(3),
+		},
+		{
+			name:      "APPEND keeps the time to live",
+			setupCmds: []string{"SET string-append-ttl a EX 100", "APPEND string-append-ttl b"},
+			input:     "TTL string-append-ttl",
+			expected:  protocol.NewInteger(100),
+		},
+		{
+			name:      "APPEND to a list",
+			setupCmds: []string{"RPUSH string-append-list a"},
+			input:     "APPEND string-append-list a",
+			expected:  wrongType,
+		},
+		{
+			name:      "GETRANGE",
+			setupCmds: []string{"SET string-getrange \"This is a string\""},
+			input:     "GETRANGE string-getrange 0 3",
+			expected:  protocol.NewBulkString([]byte("This")),
+		},
+		{
+			name:      "GETRANGE with negative offsets",
+			setupCmds: []string{"SET string-getrange-neg \"This is a string\""},
+			input:     "GETRANGE string-getrange-neg -3 -1",
+			expected:  protocol.NewBulkString([]byte("ing")),
+		},
+		{
+			name:      "GETRANGE past the end",
+			setupCmds: []string{"SET string-getrange-end \"This is a string\""},
+			input:     "GETRANGE string-getrange-end 10 100",
+			expected:  protocol.NewBulkString([]byte("string")),
+		},
+		{
+			name:      "GETRANGE with reversed negative offsets",
+			setupCmds: []string{"SET string-getrange-rev \"This is a string\""},
+			input:     "GETRANGE string-getrange-rev -1 -5",
+			expected:  protocol.NewBulkString([]byte{}),
+		},
+		{
+			name:      "GETRANGE before the start",
+			setupCmds: []string{"SET string-getrange-start \"This is a string\""},
+			input:     "GETRANGE string-getrange-start -100 -100",
+			expected:  protocol.NewBulkString([]byte("T")),
+		},
+		{
+			name:     "GETRANGE on a missing key",
+			input:    "GETRANGE string-getrange-missing 0 -1",
+			expected: protocol.NewBulkString([]byte{}),
+		},
+		{
+			name:      "SETRANGE",
+			setupCmds: []string{"SET string-setrange \"Hello World\"", "SETRANGE string-setrange 6 Redis"},
+			input:     "GET string-setrange",
+			expected:  protocol.NewBulkString([]byte("Hello Redis")),
+		},
+		{
+			name:      "SETRANGE pads with zero bytes",
+			setupCmds: []string{"SETRANGE string-setrange-pad 3 ab"},
+			input:     "GET string-setrange-pad",
+			expected:  protocol.NewBulkString([]byte("\x00\x00\x00ab")),
+		},
+		{
+			name:     "SETRANGE with empty value on a missing key",
+			input:    "SETRANGE string-setrange-empty 5 \"\"",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:     "SETRANGE with negative offset",
+			input:    "SETRANGE string-setrange-neg -1 a",
+			expected: protocol.NewError(offsetOutOfRangeErrMsg),
+		},
+		{
+			name:     "SETRANGE past the maximum size",
+			input:    fmt.Sprintf("SETRANGE string-setrange-max %d a", maxStringSize),
+			expected: protocol.NewError(stringTooLongErrMsg),
+		},
+		{
+			name:      "STRLEN",
+			setupCmds: []string{"SET string-strlen \"Hello World\""},
+			input:     "STRLEN string-strlen",
+			expected:  protocol.NewInteger(11),
+		},
+		{
+			name:     "STRLEN on a missing key",
+			input:    "STRLEN string-strlen-missing",
+			expected: protocol.NewInteger(0),
+		},
+		{
+			name:      "MGET",
+			setupCmds: []string{"SET string-mget-a 1", "SET string-mget-b 2", "RPUSH string-mget-list a"},
+			input:     "MGET string-mget-a string-mget-missing string-mget-list string-mget-b",
+			expected: protocol.NewArray(
+				protocol.NewBulkString([]byte("1")),
+				protocol.NewNullBulkString(),
+				protocol.NewNullBulkString(),
+				protocol.NewBulkString([]byte("2")),
+			),
+		},
+		{
+			name:      "MSET",
+			setupCmds: []string{"SET string-mset-a old EX 100", "MSET string-mset-a 1 string-mset-b 2"},
+			input:     "MGET string-mset-a string-mset-b",
+			expected:  bulkStringArray("1", "2"),
+		},
+		{
+			name:     "MSET with odd arguments",
+			input:    "MSET string-mset-odd 1 string-mset-other",
+			expected: protocol.NewError(fmt.Sprintf(msetInvalidLengthErrMsg, "MSET", "MSET")),
+		},
+		{
+			name:     "MSETNX",
+			input:    "MSETNX string-msetnx-a 1 string-msetnx-b 2",
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "MSETNX with an existing key",
+			setupCmds: []string{"SET string-msetnx-existing old", "MSETNX string-msetnx-new 1 string-msetnx-existing 2"},
+			input:     "MGET string-msetnx-new string-msetnx-existing",
+			expected:  protocol.NewArray(protocol.NewNullBulkString(), protocol.NewBulkString([]byte("old"))),
+		},
+		{
+			name:      "GETDEL",
+			setupCmds: []string{"SET string-getdel value"},
+			input:     "GETDEL string-getdel",
+			expected:  protocol.NewBulkString([]byte("value")),
+		},
+		{
+			name:      "GETDEL deletes the key",
+			setupCmds: []string{"SET string-getdel-deleted value", "GETDEL string-getdel-deleted"},
+			input:     "EXISTS string-getdel-deleted",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "GETDEL on a hash",
+			setupCmds: []string{"HSET string-getdel-hash a 1"},
+			input:     "GETDEL string-getdel-hash",
+			expected:  wrongType,
+		},
+		{
+			name:      "GETEX with EX",
+			setupCmds: []string{"SET string-getex value", "GETEX string-getex EX 100"},
+			input:     "TTL string-getex",
+			expected:  protocol.NewInteger(100),
+		},
+		{
+			name:      "GETEX with PXAT",
+			setupCmds: []string{"SET string-getex-pxat value", "GETEX string-getex-pxat PXAT 32503680000000"},
+			input:     "PEXPIRETIME string-getex-pxat",
+			expected:  protocol.NewInteger(32503680000000),
+		},
+		{
+			name:      "GETEX with PERSIST",
+			setupCmds: []string{"SET string-getex-persist value EX 100", "GETEX string-getex-persist PERSIST"},
+			input:     "TTL string-getex-persist",
+			expected:  protocol.NewInteger(-1),
+		},
+		{
+			name:      "GETEX without options",
+			setupCmds: []string{"SET string-getex-plain value EX 100"},
+			input:     "GETEX string-getex-plain",
+			expected:  protocol.NewBulkString([]byte("value")),
+		},
+		{
+			name:     "GETEX with EX and PERSIST",
+			input:    "GETEX string-getex-both EX 10 PERSIST",
+			expected: protocol.NewError(getexSyntaxErrMsg),
+		},
+		{
+			name:     "GETEX with negative EX",
+			input:    "GETEX string-getex-neg EX -1",
+			expected: protocol.NewError(getexInvalidExpireErrMsg),
+		},
+		{
+			name:      "GETSET",
+			setupCmds: []string{"SET string-getset old"},
+			input:     "GETSET string-getset new",
+			expected:  protocol.NewBulkString([]byte("old")),
+		},
+		{
+			name:      "GETSET discards the time to live",
+			setupCmds: []string{"SET string-getset-ttl old EX 100", "GETSET string-getset-ttl new"},
+			input:     "TTL string-getset-ttl",
+			expected:  protocol.NewInteger(-1),
+		},
+		{
+			name:      "SETNX on an existing key",
+			setupCmds: []string{"SET string-setnx old"},
+			input:     "SETNX string-setnx new",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:     "SETNX on a missing key",
+			input:    "SETNX string-setnx-missing new",
+			expected: protocol.NewInteger(1),
+		},
+		{
+			name:      "SETEX",
+			setupCmds: []string{"SETEX string-setex 100 value"},
+			input:     "TTL string-setex",
+			expected:  protocol.NewInteger(100),
+		},
+		{
+			name:      "PSETEX",
+			setupCmds: []string{"PSETEX string-psetex 100000 value"},
+			input:     "TTL string-psetex",
+			expected:  protocol.NewInteger(100),
+		},
+		{
+			name:     "SETEX with zero seconds",
+			input:    "SETEX string-setex-zero 0 value",
+			expected: protocol.NewError(fmt.Sprintf(setexInvalidExpireErrMsg, "setex")),
+		},
+	}
+	for _, tc := range stcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
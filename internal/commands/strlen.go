@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	strlenInvalidLengthErrMsg string = "the STRLEN command accepts 2 parameters: STRLEN and KEY. Received %d parameters instead"
+)
+
+func init() {
+	strlen := strlenCommand{"strlen"}
+	registerCommand(strlen)
+}
+
+type strlenCommand struct {
+	name string
+}
+
+func (s strlenCommand) getName() string {
+	return s.name
+}
+
+func (s strlenCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(strlenInvalidLengthErrMsg, len(elements)))
+	}
+	val, ok, err := datastore.Get(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(len(val.String()))
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	subscribeInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s %s [%s ...]"
+)
+
+// subscriptionKind tells the namespace of a subscription. Sharded channels are a
+// separate namespace from the classic channels, so a message published with SPUBLISH
+// only reaches the clients subscribed with SSUBSCRIBE.
+type subscriptionKind int
+
+const (
+	channelSubscription subscriptionKind = iota
+	patternSubscription
+	shardSubscription
+)
+
+func init() {
+	registerClientCommand(subscribeCommand{name: "subscribe", kind: channelSubscription})
+	registerClientCommand(subscribeCommand{name: "psubscribe", kind: patternSubscription})
+	registerClientCommand(subscribeCommand{name: "ssubscribe", kind: shardSubscription})
+	registerClientCommand(unsubscribeCommand{name: "unsubscribe", kind: channelSubscription})
+	registerClientCommand(unsubscribeCommand{name: "punsubscribe", kind: patternSubscription})
+	registerClientCommand(unsubscribeCommand{name: "sunsubscribe", kind: shardSubscription})
+}
+
+// subscribeCommand implements SUBSCRIBE, PSUBSCRIBE and SSUBSCRIBE, which subscribe the
+// client to channels, to the channels matching glob style patterns or to sharded
+// channels. The client gets a confirmation for each of them, with the number of
+// subscriptions it has.
+type subscribeCommand struct {
+	name string
+	kind subscriptionKind
+}
+
+// unsubscribeCommand implements UNSUBSCRIBE, PUNSUBSCRIBE and SUNSUBSCRIBE, which undo
+// the subscriptions given, or all the subscriptions of the kind when none is given.
+type unsubscribeCommand struct {
+	name string
+	kind subscriptionKind
+}
+
+// replies is the reply of a command that replies with several frames, like SUBSCRIBE
+// with a confirmation for each channel.
+type replies []protocol.DataType
+
+func (r replies) String() string {
+	return protocol.NewArray(r...).String()
+}
+
+func (r replies) Encode() []byte {
+	var buffer []byte
+	for _, reply := range r {
+		buffer = append(buffer, reply.Encode()...)
+	}
+	return buffer
+}
+
+func (s subscribeCommand) getName() string {
+	return s.name
+}
+
+func (s subscribeCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 {
+		name, argument := strings.ToUpper(s.name), "channel"
+		if s.kind == patternSubscription {
+			argument = "pattern"
+		}
+		return protocol.NewError(fmt.Sprintf(subscribeInvalidLengthErrMsg, name, name, argument, argument))
+	}
+	subscribers, subscriptions := client.subscriptionMaps(s.kind)
+	reply := replies{}
+	for _, channel := range elements[1:] {
+		subscribe(subscribers, subscriptions, client, channel.String())
+		reply = append(reply, subscriptionReply(s.name, s.kind, channel, client))
+	}
+	return reply
+}
+
+func (u unsubscribeCommand) getName() string {
+	return u.name
+}
+
+func (u unsubscribeCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	subscribers, subscriptions := client.subscriptionMaps(u.kind)
+	channels := data.GetElements()[1:]
+	if len(channels) == 0 {
+		for _, channel := range slices.Sorted(maps.Keys(subscriptions)) {
+			channels = append(channels, bulkString(channel))
+		}
+	}
+	// Like in Redis, there is still a confirmation when there was nothing to unsubscribe
+	if len(channels) == 0 {
+		return subscriptionReply(u.name, u.kind, protocol.NewNullBulkString(), client)
+	}
+	reply := replies{}
+	for _, channel := range channels {
+		unsubscribe(subscribers, subscriptions, client, channel.String())
+		reply = append(reply, subscriptionReply(u.name, u.kind, channel, client))
+	}
+	return reply
+}
+
+// subscriptionReply is the confirmation of a change to the subscriptions of the client.
+// Like in Redis, the count of the sharded channels is separate from the count of the
+// classic channels and patterns.
+func subscriptionReply(name string, kind subscriptionKind, channel protocol.DataType, client *Client) protocol.Push {
+	count := len(client.channels) + len(client.patterns)
+	if kind == shardSubscription {
+		count = len(client.shardChannels)
+	}
+	return protocol.NewPush(bulkString(name), channel, protocol.NewInteger(count))
+}
+
+// subscriptionMaps returns the subscribers of every channel of the kind and the
+// subscriptions of the client of that kind.
+func (c *Client) subscriptionMaps(kind subscriptionKind) (map[string]map[*Client]struct{}, map[string]struct{}) {
+	switch kind {
+	case patternSubscription:
+		return patternSubscribers, c.patterns
+	case shardSubscription:
+		return shardChannelSubscribers, c.shardChannels
+	default:
+		return channelSubscribers, c.channels
+	}
+}
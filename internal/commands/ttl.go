@@ -1,19 +1,32 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/mhsantos/redis-server/internal/datastore"
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
+const (
+	ttlInvalidLengthErrMsg string = "the %s command accepts 2 parameters: %s and KEY. Received %d parameters instead"
+)
+
 func init() {
-	ttl := ttlCommand{"ttl"}
-	registerCommand(ttl)
+	registerCommand(ttlCommand{name: "ttl", unit: time.Second})
+	registerCommand(ttlCommand{name: "pttl", unit: time.Millisecond})
+	registerCommand(ttlCommand{name: "expiretime", unit: time.Second, absolute: true})
+	registerCommand(ttlCommand{name: "pexpiretime", unit: time.Millisecond, absolute: true})
 }
 
+// ttlCommand implements TTL, PTTL, EXPIRETIME and PEXPIRETIME. The unit is the unit of
+// the reply and absolute tells if the reply is the Unix time the key expires at instead
+// of its remaining time to live.
 type ttlCommand struct {
-	name string
+	name     string
+	unit     time.Duration
+	absolute bool
 }
 
 func (ttl ttlCommand) getName() string {
@@ -23,16 +36,33 @@ func (ttl ttlCommand) getName() string {
 func (ttlc ttlCommand) processArguments(data protocol.Array) protocol.DataType {
 	elements := data.GetElements()
 	if len(elements) != 2 {
-		return protocol.NewError("invalid arguments")
+		name := strings.ToUpper(ttlc.name)
+		return protocol.NewError(fmt.Sprintf(ttlInvalidLengthErrMsg, name, name, len(elements)))
 	}
 	key := elements[1].String()
-	_, currentExpire, ok := datastore.GetWithExpire(key)
+	currentExpire, ok := datastore.GetExpire(key)
 	if !ok {
 		return protocol.NewInteger(-2)
 	}
 	if currentExpire == 0 {
 		return protocol.NewInteger(-1)
 	}
-	ttl := currentExpire - time.Now().Unix()
-	return protocol.NewInteger(int(ttl))
+	return protocol.NewInteger(int(ttlc.remaining(currentExpire)))
+}
+
+// remaining converts the Unix time in milliseconds something expires at to the reply of
+// the command: the time to live or the expiration time, in the unit of the command.
+func (ttlc ttlCommand) remaining(expire int64) int64 {
+	if ttlc.absolute {
+		return expire / int64(ttlc.unit/time.Millisecond)
+	}
+	ttl := expire - time.Now().UnixMilli()
+	if ttl < 0 {
+		ttl = 0
+	}
+	if ttlc.unit == time.Second {
+		// Round to the closest second like Redis does
+		ttl = (ttl + 500) / 1000
+	}
+	return ttl
 }
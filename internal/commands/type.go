@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	typeInvalidLengthErrMsg string = "the TYPE command accepts 2 parameters: TYPE and KEY. Received %d parameters instead"
+)
+
+func init() {
+	typeCmd := typeCommand{"type"}
+	registerCommand(typeCmd)
+}
+
+type typeCommand struct {
+	name string
+}
+
+func (t typeCommand) getName() string {
+	return t.name
+}
+
+// processArguments returns the type of the value stored in the key, or none if the key
+// doesn't exist.
+func (t typeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(typeInvalidLengthErrMsg, len(elements)))
+	}
+	kind, ok := datastore.TypeOf(elements[1].String())
+	if !ok {
+		return protocol.NewSimpleString("none")
+	}
+	return protocol.NewSimpleString(kind.String())
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xackInvalidLengthErrMsg string = "invalid arguments for command XACK. Syntax: XACK key group id [id ...]"
+)
+
+func init() {
+	xack := xackCommand{"xack"}
+	registerCommand(xack)
+}
+
+type xackCommand struct {
+	name string
+}
+
+func (x xackCommand) getName() string {
+	return x.name
+}
+
+func (x xackCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return protocol.NewError(xackInvalidLengthErrMsg)
+	}
+	ids := []datastore.StreamID{}
+	for _, element := range elements[3:] {
+		id, ok := parseStreamID(element.String(), 0)
+		if !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+		ids = append(ids, id)
+	}
+	stream, ok, err := datastore.GetStream(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	group, ok := stream.Group(elements[2].String())
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	acknowledged := 0
+	for _, id := range ids {
+		if group.Ack(id) {
+			acknowledged++
+		}
+	}
+	return protocol.NewInteger(acknowledged)
+}
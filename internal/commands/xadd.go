@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xaddInvalidLengthErrMsg string = "wrong number of arguments for 'xadd' command"
+	xaddSmallerIDErrMsg     string = "The ID specified in XADD is equal or smaller than the target stream top item"
+	xaddZeroIDErrMsg        string = "The ID specified in XADD must be greater than 0-0"
+	xaddSyntaxErrMsg        string = "invalid arguments for command XADD. Syntax: XADD key [NOMKSTREAM] [<MAXLEN | MINID> [= | ~] threshold [LIMIT count]] <* | id> field value [field value ...]"
+)
+
+func init() {
+	xadd := xaddCommand{"xadd"}
+	registerCommand(xadd)
+}
+
+type xaddCommand struct {
+	name string
+}
+
+func (x xaddCommand) getName() string {
+	return x.name
+}
+
+func (x xaddCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	noMkStream := false
+	var trim *streamTrim
+	i := 2
+options:
+	for ; i < len(elements); i++ {
+		switch strings.ToUpper(elements[i].String()) {
+		case "NOMKSTREAM":
+			noMkStream = true
+		case "MAXLEN", "MINID":
+			parsed, next, errReply := parseStreamTrim(elements, i, xaddSyntaxErrMsg)
+			if errReply != nil {
+				return errReply
+			}
+			trim = &parsed
+			i = next - 1
+		default:
+			break options
+		}
+	}
+	fields := elements[min(i+1, len(elements)):]
+	if len(elements) < 5 || len(fields) == 0 || len(fields)%2 != 0 {
+		return protocol.NewError(xaddInvalidLengthErrMsg)
+	}
+	idArgument := elements[i].String()
+	if idArgument != "*" {
+		if _, ok := parseStreamID(strings.TrimSuffix(idArgument, "-*"), 0); !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+	}
+	key := elements[1].String()
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		if noMkStream {
+			return protocol.NewNullBulkString()
+		}
+		stream = datastore.NewStream()
+	}
+	id, errReply := nextStreamID(stream, idArgument)
+	if errReply != nil {
+		return errReply
+	}
+	if !ok {
+		stream, _ = datastore.GetOrCreateStream(key)
+	}
+	values := [][]byte{}
+	for _, field := range fields {
+		values = append(values, []byte(field.String()))
+	}
+	stream.Append(id, values)
+	if trim != nil {
+		trim.apply(stream)
+	}
+	signalKeyAsReady(key)
+	return protocol.NewBulkString([]byte(id.String()))
+}
+
+// nextStreamID returns the ID of a new entry given as * to generate it, ms-* to only
+// generate the sequence, or as an explicit ID, which must be greater than the last one.
+func nextStreamID(stream *datastore.Stream, argument string) (datastore.StreamID, protocol.DataType) {
+	lastID := stream.LastID()
+	if argument == "*" {
+		id, ok := stream.NextID(time.Now().UnixMilli())
+		if !ok {
+			return id, protocol.NewError(xaddSmallerIDErrMsg)
+		}
+		return id, nil
+	}
+	if ms, found := strings.CutSuffix(argument, "-*"); found {
+		id, _ := parseStreamID(ms, 0)
+		if id.Ms == lastID.Ms && lastID != (datastore.StreamID{}) {
+			next, ok := lastID.Next()
+			if !ok || next.Ms != id.Ms {
+				return id, protocol.NewError(xaddSmallerIDErrMsg)
+			}
+			return next, nil
+		}
+		if id.Ms < lastID.Ms {
+			return id, protocol.NewError(xaddSmallerIDErrMsg)
+		}
+		if id.Ms == 0 {
+			id.Seq = 1
+		}
+		return id, nil
+	}
+	id, _ := parseStreamID(argument, 0)
+	if id == (datastore.StreamID{}) {
+		return id, protocol.NewError(xaddZeroIDErrMsg)
+	}
+	if id.Compare(lastID) <= 0 {
+		return id, protocol.NewError(xaddSmallerIDErrMsg)
+	}
+	return id, nil
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xautoclaimInvalidLengthErrMsg string = "invalid arguments for command XAUTOCLAIM. Syntax: XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]"
+	xautoclaimCountErrMsg         string = "COUNT must be > 0"
+	defaultAutoclaimCount         int    = 100
+	// autoclaimAttemptsFactor limits the pending entries scanned to this many times the
+	// count, like in Redis, so a long list of entries not idle enough doesn't stall
+	// the server.
+	autoclaimAttemptsFactor int = 10
+)
+
+func init() {
+	xautoclaim := xautoclaimCommand{"xautoclaim"}
+	registerCommand(xautoclaim)
+}
+
+type xautoclaimCommand struct {
+	name string
+}
+
+func (x xautoclaimCommand) getName() string {
+	return x.name
+}
+
+// processArguments claims up to count pending entries with IDs from start that are idle
+// for at least min-idle-time milliseconds, like XCLAIM. The reply is the ID to start the
+// next call from, which is 0-0 when the whole list was scanned, the claimed entries and
+// the IDs of the entries deleted from the stream, which are removed from the list.
+func (x xautoclaimCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 6 {
+		return protocol.NewError(xautoclaimInvalidLengthErrMsg)
+	}
+	minIdle, err := strconv.ParseInt(elements[4].String(), 10, 64)
+	if err != nil {
+		return protocol.NewError(invalidMinIdleErrMsg)
+	}
+	minIdle = max(minIdle, 0)
+	start, ok := parseRangeStreamID(elements[5].String(), true)
+	if !ok || start == nil {
+		return protocol.NewError(invalidStreamIDErrMsg)
+	}
+	count := defaultAutoclaimCount
+	justID := false
+	for i := 6; i < len(elements); i++ {
+		switch strings.ToUpper(elements[i].String()) {
+		case "JUSTID":
+			justID = true
+		case "COUNT":
+			if i+1 == len(elements) {
+				return protocol.NewError(xautoclaimInvalidLengthErrMsg)
+			}
+			count, ok = parseInt(elements[i+1])
+			if !ok {
+				return protocol.NewError(notIntegerErrMsg)
+			}
+			if count < 1 || count > (1<<31)/autoclaimAttemptsFactor {
+				return protocol.NewError(xautoclaimCountErrMsg)
+			}
+			i++
+		default:
+			return protocol.NewError(xautoclaimInvalidLengthErrMsg)
+		}
+	}
+	key, groupName := elements[1].String(), elements[2].String()
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var group *datastore.ConsumerGroup
+	if ok {
+		group, ok = stream.Group(groupName)
+	}
+	if !ok {
+		return protocol.NewError(fmt.Sprintf(noGroupErrMsg, groupName, key))
+	}
+	now := time.Now().UnixMilli()
+	consumer, _ := group.CreateConsumer(elements[3].String(), now)
+	consumer.Touch(now, false)
+	claimed := []protocol.DataType{}
+	deleted := []protocol.DataType{}
+	next := datastore.StreamID{}
+	attempts := count * autoclaimAttemptsFactor
+	for _, pending := range group.PendingRange(*start, datastore.MaxStreamID) {
+		if len(claimed) == count || attempts == 0 {
+			next = pending.ID
+			break
+		}
+		attempts--
+		if now-pending.DeliveryTime < minIdle {
+			continue
+		}
+		entry, exists := stream.Get(pending.ID)
+		if !exists {
+			group.Ack(pending.ID)
+			deleted = append(deleted, protocol.NewBulkString([]byte(pending.ID.String())))
+			continue
+		}
+		group.Claim(consumer, pending.ID)
+		pending.DeliveryTime = now
+		if !justID {
+			pending.DeliveryCount++
+		}
+		consumer.Touch(now, true)
+		if justID {
+			claimed = append(claimed, protocol.NewBulkString([]byte(pending.ID.String())))
+		} else {
+			claimed = append(claimed, streamEntryReply(entry))
+		}
+	}
+	return protocol.NewArray(protocol.NewBulkString([]byte(next.String())), protocol.NewArray(claimed...), protocol.NewArray(deleted...))
+}
@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xclaimInvalidLengthErrMsg string = "invalid arguments for command XCLAIM. Syntax: XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid]"
+	invalidMinIdleErrMsg      string = "Invalid min-idle-time argument for XCLAIM"
+	invalidIdleErrMsg         string = "Invalid IDLE option argument for XCLAIM"
+	invalidTimeErrMsg         string = "Invalid TIME option argument for XCLAIM"
+	invalidRetryCountErrMsg   string = "Invalid RETRYCOUNT option argument for XCLAIM"
+)
+
+func init() {
+	xclaim := xclaimCommand{"xclaim"}
+	registerCommand(xclaim)
+}
+
+type xclaimCommand struct {
+	name string
+}
+
+// xclaimOptions holds the options of XCLAIM. deliveryTime and retryCount are -1 when
+// not informed.
+type xclaimOptions struct {
+	deliveryTime int64
+	retryCount   int
+	force        bool
+	justID       bool
+	lastID       *datastore.StreamID
+}
+
+func (x xclaimCommand) getName() string {
+	return x.name
+}
+
+// processArguments moves pending entries idle for at least min-idle-time milliseconds
+// to the consumer, returning the claimed entries. Entries deleted from the stream are
+// removed from the pending entries list instead.
+func (x xclaimCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 6 {
+		return protocol.NewError(xclaimInvalidLengthErrMsg)
+	}
+	minIdle, err := strconv.ParseInt(elements[4].String(), 10, 64)
+	if err != nil {
+		return protocol.NewError(invalidMinIdleErrMsg)
+	}
+	minIdle = max(minIdle, 0)
+	ids := []datastore.StreamID{}
+	i := 5
+	for ; i < len(elements); i++ {
+		id, ok := parseStreamID(elements[i].String(), 0)
+		if !ok {
+			break
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return protocol.NewError(invalidStreamIDErrMsg)
+	}
+	now := time.Now().UnixMilli()
+	options, errReply := parseXclaimOptions(elements[i:], now)
+	if errReply != nil {
+		return errReply
+	}
+	key, groupName := elements[1].String(), elements[2].String()
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var group *datastore.ConsumerGroup
+	if ok {
+		group, ok = stream.Group(groupName)
+	}
+	if !ok {
+		return protocol.NewError(fmt.Sprintf(noGroupErrMsg, groupName, key))
+	}
+	if options.lastID != nil && options.lastID.Compare(group.LastID()) > 0 {
+		group.SetLastID(*options.lastID, group.EntriesRead())
+	}
+	consumer, _ := group.CreateConsumer(elements[3].String(), now)
+	consumer.Touch(now, false)
+	reply := []protocol.DataType{}
+	for _, id := range ids {
+		pending, ok := group.Pending(id)
+		if !ok {
+			// FORCE creates the pending entry if the entry exists in the stream
+			if _, exists := stream.Get(id); !options.force || !exists {
+				continue
+			}
+		} else if now-pending.DeliveryTime < minIdle {
+			continue
+		}
+		entry, exists := stream.Get(id)
+		if !exists {
+			group.Ack(id)
+			continue
+		}
+		pending = group.Claim(consumer, id)
+		pending.DeliveryTime = options.deliveryTime
+		if options.retryCount >= 0 {
+			pending.DeliveryCount = options.retryCount
+		} else if !options.justID {
+			pending.DeliveryCount++
+		}
+		consumer.Touch(now, true)
+		if options.justID {
+			reply = append(reply, protocol.NewBulkString([]byte(id.String())))
+		} else {
+			reply = append(reply, streamEntryReply(entry))
+		}
+	}
+	return protocol.NewArray(reply...)
+}
+
+func parseXclaimOptions(arguments []protocol.DataType, now int64) (xclaimOptions, protocol.DataType) {
+	options := xclaimOptions{deliveryTime: now, retryCount: -1}
+	for i := 0; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		hasValue := i+1 < len(arguments)
+		switch {
+		case option == "FORCE":
+			options.force = true
+		case option == "JUSTID":
+			options.justID = true
+		case option == "IDLE" && hasValue:
+			idle, err := strconv.ParseInt(arguments[i+1].String(), 10, 64)
+			if err != nil {
+				return options, protocol.NewError(invalidIdleErrMsg)
+			}
+			options.deliveryTime = now - idle
+			i++
+		case option == "TIME" && hasValue:
+			deliveryTime, err := strconv.ParseInt(arguments[i+1].String(), 10, 64)
+			if err != nil {
+				return options, protocol.NewError(invalidTimeErrMsg)
+			}
+			options.deliveryTime = deliveryTime
+			i++
+		case option == "RETRYCOUNT" && hasValue:
+			retryCount, ok := parseInt(arguments[i+1])
+			if !ok || retryCount < 0 {
+				return options, protocol.NewError(invalidRetryCountErrMsg)
+			}
+			options.retryCount = retryCount
+			i++
+		case option == "LASTID" && hasValue:
+			lastID, ok := parseStreamID(arguments[i+1].String(), 0)
+			if !ok {
+				return options, protocol.NewError(invalidStreamIDErrMsg)
+			}
+			options.lastID = &lastID
+			i++
+		default:
+			return options, protocol.NewError(xclaimInvalidLengthErrMsg)
+		}
+	}
+	// Like Redis, a delivery time in the future is replaced by the current time
+	options.deliveryTime = min(options.deliveryTime, now)
+	return options, nil
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xdelInvalidLengthErrMsg string = "invalid arguments for command XDEL. Syntax: XDEL key id [id ...]"
+)
+
+func init() {
+	xdel := xdelCommand{"xdel"}
+	registerCommand(xdel)
+}
+
+type xdelCommand struct {
+	name string
+}
+
+func (x xdelCommand) getName() string {
+	return x.name
+}
+
+func (x xdelCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(xdelInvalidLengthErrMsg)
+	}
+	ids := []datastore.StreamID{}
+	for _, element := range elements[2:] {
+		id, ok := parseStreamID(element.String(), 0)
+		if !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+		ids = append(ids, id)
+	}
+	stream, ok, err := datastore.GetStream(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	deleted := 0
+	for _, id := range ids {
+		if stream.Delete(id) {
+			deleted++
+		}
+	}
+	return protocol.NewInteger(deleted)
+}
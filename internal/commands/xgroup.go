@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xgroupInvalidLengthErrMsg string = "invalid arguments for command XGROUP. Syntax: XGROUP <CREATE | SETID | DESTROY | CREATECONSUMER | DELCONSUMER> key group [arguments ...]"
+	xgroupUnknownErrMsg       string = "unknown subcommand '%s'. Try XGROUP HELP."
+	xgroupNoKeyErrMsg         string = "The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically."
+	xgroupBusyErrMsg          string = "BUSYGROUP Consumer Group name already exists"
+	noGroupErrMsg             string = "NOGROUP No such consumer group '%s' for key name '%s'"
+	entriesReadErrMsg         string = "value for ENTRIESREAD must be positive or -1"
+)
+
+func init() {
+	xgroup := xgroupCommand{"xgroup"}
+	registerCommand(xgroup)
+}
+
+type xgroupCommand struct {
+	name string
+}
+
+func (x xgroupCommand) getName() string {
+	return x.name
+}
+
+// processArguments manages the consumer groups of a stream with the subcommands
+// CREATE, SETID, DESTROY, CREATECONSUMER and DELCONSUMER.
+func (x xgroupCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return protocol.NewError(xgroupInvalidLengthErrMsg)
+	}
+	subcommand := strings.ToUpper(elements[1].String())
+	key := elements[2].String()
+	groupName := elements[3].String()
+	switch subcommand {
+	case "CREATE", "SETID", "DESTROY", "CREATECONSUMER", "DELCONSUMER":
+	default:
+		return protocol.NewError(fmt.Sprintf(xgroupUnknownErrMsg, elements[1].String()))
+	}
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if subcommand == "CREATE" {
+		return x.create(key, stream, ok, groupName, elements[4:])
+	}
+	if !ok {
+		return protocol.NewError(xgroupNoKeyErrMsg)
+	}
+	if subcommand == "DESTROY" {
+		if len(elements) != 4 {
+			return protocol.NewError(xgroupInvalidLengthErrMsg)
+		}
+		if stream.DestroyGroup(groupName) {
+			return protocol.NewInteger(1)
+		}
+		return protocol.NewInteger(0)
+	}
+	group, ok := stream.Group(groupName)
+	if !ok {
+		return protocol.NewError(fmt.Sprintf(noGroupErrMsg, groupName, key))
+	}
+	switch subcommand {
+	case "SETID":
+		if len(elements) < 5 {
+			return protocol.NewError(xgroupInvalidLengthErrMsg)
+		}
+		lastID, entriesRead, errReply := parseGroupID(stream, elements[4:])
+		if errReply != nil {
+			return errReply
+		}
+		group.SetLastID(lastID, entriesRead)
+		return protocol.NewSimpleString("OK")
+	case "CREATECONSUMER":
+		if len(elements) != 5 {
+			return protocol.NewError(xgroupInvalidLengthErrMsg)
+		}
+		if _, created := group.CreateConsumer(elements[4].String(), time.Now().UnixMilli()); created {
+			return protocol.NewInteger(1)
+		}
+		return protocol.NewInteger(0)
+	default:
+		if len(elements) != 5 {
+			return protocol.NewError(xgroupInvalidLengthErrMsg)
+		}
+		pending, _ := group.DeleteConsumer(elements[4].String())
+		return protocol.NewInteger(pending)
+	}
+}
+
+// create implements XGROUP CREATE key group <id | $> [MKSTREAM] [ENTRIESREAD entries_read].
+func (x xgroupCommand) create(key string, stream *datastore.Stream, exists bool, groupName string, arguments []protocol.DataType) protocol.DataType {
+	if len(arguments) == 0 {
+		return protocol.NewError(xgroupInvalidLengthErrMsg)
+	}
+	mkStream := false
+	rest := []protocol.DataType{arguments[0]}
+	for _, argument := range arguments[1:] {
+		if strings.ToUpper(argument.String()) == "MKSTREAM" {
+			mkStream = true
+		} else {
+			rest = append(rest, argument)
+		}
+	}
+	if !exists {
+		if !mkStream {
+			return protocol.NewError(xgroupNoKeyErrMsg)
+		}
+		stream = datastore.NewStream()
+	}
+	lastID, entriesRead, errReply := parseGroupID(stream, rest)
+	if errReply != nil {
+		return errReply
+	}
+	if !exists {
+		stream, _ = datastore.GetOrCreateStream(key)
+	}
+	if _, created := stream.CreateGroup(groupName, lastID, entriesRead); !created {
+		return protocol.NewError(xgroupBusyErrMsg)
+	}
+	return protocol.NewSimpleString("OK")
+}
+
+// parseGroupID parses the ID a group is created or moved to, which is $ for the last ID
+// of the stream, followed by an optional ENTRIESREAD. When it's not informed, the
+// entries read are only known for $ and for 0 on a stream without deletions.
+func parseGroupID(stream *datastore.Stream, arguments []protocol.DataType) (datastore.StreamID, int64, protocol.DataType) {
+	var lastID datastore.StreamID
+	entriesRead := int64(-1)
+	if arguments[0].String() == "$" {
+		lastID = stream.LastID()
+		entriesRead = stream.EntriesAdded()
+	} else {
+		id, ok := parseStreamID(arguments[0].String(), 0)
+		if !ok {
+			return lastID, 0, protocol.NewError(invalidStreamIDErrMsg)
+		}
+		lastID = id
+		if id == (datastore.StreamID{}) && stream.MaxDeletedID() == (datastore.StreamID{}) {
+			entriesRead = 0
+		}
+	}
+	switch {
+	case len(arguments) == 1:
+	case len(arguments) == 3 && strings.ToUpper(arguments[1].String()) == "ENTRIESREAD":
+		value, err := strconv.ParseInt(arguments[2].String(), 10, 64)
+		if err != nil {
+			return lastID, 0, protocol.NewError(notIntegerErrMsg)
+		}
+		if value < -1 {
+			return lastID, 0, protocol.NewError(entriesReadErrMsg)
+		}
+		entriesRead = value
+	default:
+		return lastID, 0, protocol.NewError(xgroupInvalidLengthErrMsg)
+	}
+	return lastID, entriesRead, nil
+}
@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xinfoInvalidLengthErrMsg string = "invalid arguments for command XINFO. Syntax: XINFO <STREAM key [FULL [COUNT count]] | GROUPS key | CONSUMERS key group>"
+	xinfoUnknownErrMsg       string = "unknown subcommand '%s'. Try XINFO HELP."
+	noSuchKeyLowerErrMsg     string = "no such key"
+	defaultXinfoFullCount    int    = 10
+)
+
+func init() {
+	xinfo := xinfoCommand{"xinfo"}
+	registerCommand(xinfo)
+}
+
+type xinfoCommand struct {
+	name string
+}
+
+func (x xinfoCommand) getName() string {
+	return x.name
+}
+
+// processArguments returns information about a stream with the subcommand STREAM, its
+// consumer groups with GROUPS, or the consumers of a group with CONSUMERS.
+func (x xinfoCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(xinfoInvalidLengthErrMsg)
+	}
+	subcommand := strings.ToUpper(elements[1].String())
+	switch subcommand {
+	case "STREAM", "GROUPS", "CONSUMERS":
+	default:
+		return protocol.NewError(fmt.Sprintf(xinfoUnknownErrMsg, elements[1].String()))
+	}
+	key := elements[2].String()
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewError(noSuchKeyLowerErrMsg)
+	}
+	now := time.Now().UnixMilli()
+	switch subcommand {
+	case "STREAM":
+		return x.streamInfo(stream, elements[3:], now)
+	case "GROUPS":
+		if len(elements) != 3 {
+			return protocol.NewError(xinfoInvalidLengthErrMsg)
+		}
+		groups := []protocol.DataType{}
+		for _, group := range stream.Groups() {
+			groups = append(groups, groupInfo(stream, group))
+		}
+		return protocol.NewArray(groups...)
+	default:
+		if len(elements) != 4 {
+			return protocol.NewError(xinfoInvalidLengthErrMsg)
+		}
+		groupName := elements[3].String()
+		group, ok := stream.Group(groupName)
+		if !ok {
+			return protocol.NewError(fmt.Sprintf(noGroupErrMsg, groupName, key))
+		}
+		consumers := []protocol.DataType{}
+		for _, consumer := range group.Consumers() {
+			inactive := -1
+			if consumer.ActiveTime() >= 0 {
+				inactive = int(now - consumer.ActiveTime())
+			}
+			consumers = append(consumers, protocol.NewMap(
+				bulkString("name"), bulkString(consumer.Name()),
+				bulkString("pending"), protocol.NewInteger(consumer.PendingCount()),
+				bulkString("idle"), protocol.NewInteger(int(now-consumer.SeenTime())),
+				bulkString("inactive"), protocol.NewInteger(inactive),
+			))
+		}
+		return protocol.NewArray(consumers...)
+	}
+}
+
+// streamInfo implements XINFO STREAM key [FULL [COUNT count]]. FULL replaces the first
+// and last entries with up to count entries, and the number of groups with the groups
+// and their pending entries and consumers. A count of 0 means everything.
+func (x xinfoCommand) streamInfo(stream *datastore.Stream, arguments []protocol.DataType, now int64) protocol.DataType {
+	full := false
+	count := defaultXinfoFullCount
+	switch {
+	case len(arguments) == 0:
+	case len(arguments) == 1 && strings.ToUpper(arguments[0].String()) == "FULL":
+		full = true
+	case len(arguments) == 3 && strings.ToUpper(arguments[0].String()) == "FULL" && strings.ToUpper(arguments[1].String()) == "COUNT":
+		full = true
+		var ok bool
+		if count, ok = parseInt(arguments[2]); !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+	default:
+		return protocol.NewError(xinfoInvalidLengthErrMsg)
+	}
+	firstID := datastore.StreamID{}
+	if first, ok := stream.First(); ok {
+		firstID = first.ID
+	}
+	info := []protocol.DataType{
+		bulkString("length"), protocol.NewInteger(stream.Len()),
+		bulkString("last-generated-id"), bulkString(stream.LastID().String()),
+		bulkString("max-deleted-entry-id"), bulkString(stream.MaxDeletedID().String()),
+		bulkString("entries-added"), protocol.NewInteger(int(stream.EntriesAdded())),
+		bulkString("recorded-first-entry-id"), bulkString(firstID.String()),
+	}
+	if !full {
+		info = append(info, bulkString("groups"), protocol.NewInteger(len(stream.Groups())))
+		info = append(info, bulkString("first-entry"), optionalEntryReply(stream.First()))
+		info = append(info, bulkString("last-entry"), optionalEntryReply(stream.Last()))
+		return protocol.NewMap(info...)
+	}
+	info = append(info, bulkString("entries"), streamEntriesReply(stream.Range(datastore.StreamID{}, datastore.MaxStreamID, count, false)))
+	groups := []protocol.DataType{}
+	for _, group := range stream.Groups() {
+		pending := []protocol.DataType{}
+		for _, entry := range limitPending(group.PendingRange(datastore.StreamID{}, datastore.MaxStreamID), count) {
+			pending = append(pending, protocol.NewArray(
+				bulkString(entry.ID.String()),
+				bulkString(entry.Consumer().Name()),
+				protocol.NewInteger(int(entry.DeliveryTime)),
+				protocol.NewInteger(entry.DeliveryCount),
+			))
+		}
+		consumers := []protocol.DataType{}
+		for _, consumer := range group.Consumers() {
+			consumerPending := []protocol.DataType{}
+			for _, entry := range limitPending(consumer.PendingRange(datastore.StreamID{}, datastore.MaxStreamID), count) {
+				consumerPending = append(consumerPending, protocol.NewArray(
+					bulkString(entry.ID.String()),
+					protocol.NewInteger(int(entry.DeliveryTime)),
+					protocol.NewInteger(entry.DeliveryCount),
+				))
+			}
+			consumers = append(consumers, protocol.NewMap(
+				bulkString("name"), bulkString(consumer.Name()),
+				bulkString("seen-time"), protocol.NewInteger(int(consumer.SeenTime())),
+				bulkString("active-time"), protocol.NewInteger(int(consumer.ActiveTime())),
+				bulkString("pel-count"), protocol.NewInteger(consumer.PendingCount()),
+				bulkString("pending"), protocol.NewArray(consumerPending...),
+			))
+		}
+		groupElements := groupInfo(stream, group).GetElements()
+		groupElements = append(groupElements[:2], groupElements[4:]...)
+		groupElements = append(groupElements,
+			bulkString("pel-count"), protocol.NewInteger(group.PendingCount()),
+			bulkString("pending"), protocol.NewArray(pending...),
+			bulkString("consumers"), protocol.NewArray(consumers...),
+		)
+		groups = append(groups, protocol.NewMap(groupElements...))
+	}
+	info = append(info, bulkString("groups"), protocol.NewArray(groups...))
+	return protocol.NewMap(info...)
+}
+
+// groupInfo returns the information about a group replied by XINFO GROUPS. The lag is
+// how many entries were added to the stream and not read by the group yet, when known.
+func groupInfo(stream *datastore.Stream, group *datastore.ConsumerGroup) protocol.Map {
+	var entriesRead, lag protocol.DataType = protocol.NewNullBulkString(), protocol.NewNullBulkString()
+	switch {
+	case group.LastID() == stream.LastID() && group.EntriesRead() >= 0:
+		entriesRead = protocol.NewInteger(int(group.EntriesRead()))
+		lag = protocol.NewInteger(0)
+	case group.EntriesRead() >= 0:
+		entriesRead = protocol.NewInteger(int(group.EntriesRead()))
+		lag = protocol.NewInteger(int(stream.EntriesAdded() - group.EntriesRead()))
+	}
+	return protocol.NewMap(
+		bulkString("name"), bulkString(group.Name()),
+		bulkString("consumers"), protocol.NewInteger(len(group.Consumers())),
+		bulkString("pending"), protocol.NewInteger(group.PendingCount()),
+		bulkString("last-delivered-id"), bulkString(group.LastID().String()),
+		bulkString("entries-read"), entriesRead,
+		bulkString("lag"), lag,
+	)
+}
+
+func optionalEntryReply(entry datastore.StreamEntry, ok bool) protocol.DataType {
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return streamEntryReply(entry)
+}
+
+func limitPending(entries []*datastore.PendingEntry, count int) []*datastore.PendingEntry {
+	if count > 0 && len(entries) > count {
+		return entries[:count]
+	}
+	return entries
+}
+
+func bulkString(value string) protocol.BulkString {
+	return protocol.NewBulkString([]byte(value))
+}
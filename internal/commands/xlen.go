@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xlenInvalidLengthErrMsg string = "the XLEN command accepts 2 parameters: XLEN and KEY. Received %d parameters instead"
+)
+
+func init() {
+	xlen := xlenCommand{"xlen"}
+	registerCommand(xlen)
+}
+
+type xlenCommand struct {
+	name string
+}
+
+func (x xlenCommand) getName() string {
+	return x.name
+}
+
+func (x xlenCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(xlenInvalidLengthErrMsg, len(elements)))
+	}
+	stream, ok, err := datastore.GetStream(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(stream.Len())
+}
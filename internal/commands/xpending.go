@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xpendingInvalidLengthErrMsg string = "invalid arguments for command XPENDING. Syntax: XPENDING key group [[IDLE min-idle-time] start end count [consumer]]"
+	xpendingNoGroupErrMsg       string = "NOGROUP No such key '%s' or consumer group '%s'"
+)
+
+func init() {
+	xpending := xpendingCommand{"xpending"}
+	registerCommand(xpending)
+}
+
+type xpendingCommand struct {
+	name string
+}
+
+func (x xpendingCommand) getName() string {
+	return x.name
+}
+
+// processArguments returns a summary of the pending entries of the group, or the
+// pending entries in a range of IDs, optionally only the ones idle for at least
+// min-idle-time milliseconds or delivered to a consumer.
+func (x xpendingCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(xpendingInvalidLengthErrMsg)
+	}
+	arguments := elements[3:]
+	var minIdle int64
+	if len(arguments) > 0 && strings.ToUpper(arguments[0].String()) == "IDLE" {
+		if len(arguments) < 2 {
+			return protocol.NewError(xpendingInvalidLengthErrMsg)
+		}
+		var err error
+		minIdle, err = strconv.ParseInt(arguments[1].String(), 10, 64)
+		if err != nil {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		arguments = arguments[2:]
+		if len(arguments) == 0 {
+			return protocol.NewError(xpendingInvalidLengthErrMsg)
+		}
+	}
+	if len(arguments) != 0 && len(arguments) != 3 && len(arguments) != 4 {
+		return protocol.NewError(xpendingInvalidLengthErrMsg)
+	}
+	var start, end *datastore.StreamID
+	count := 0
+	if len(arguments) > 0 {
+		var ok bool
+		if start, ok = parseRangeStreamID(arguments[0].String(), true); !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+		if end, ok = parseRangeStreamID(arguments[1].String(), false); !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+		if count, ok = parseInt(arguments[2]); !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+	}
+	key, groupName := elements[1].String(), elements[2].String()
+	stream, ok, err := datastore.GetStream(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var group *datastore.ConsumerGroup
+	if ok {
+		group, ok = stream.Group(groupName)
+	}
+	if !ok {
+		return protocol.NewError(fmt.Sprintf(xpendingNoGroupErrMsg, key, groupName))
+	}
+	if len(arguments) == 0 {
+		return pendingSummary(group)
+	}
+	reply := []protocol.DataType{}
+	if start == nil || end == nil || count <= 0 {
+		return protocol.NewArray()
+	}
+	var pending []*datastore.PendingEntry
+	if len(arguments) == 4 {
+		consumer, ok := group.Consumer(arguments[3].String())
+		if !ok {
+			return protocol.NewArray()
+		}
+		pending = consumer.PendingRange(*start, *end)
+	} else {
+		pending = group.PendingRange(*start, *end)
+	}
+	now := time.Now().UnixMilli()
+	for _, entry := range pending {
+		if len(reply) == count {
+			break
+		}
+		idle := now - entry.DeliveryTime
+		if idle < minIdle {
+			continue
+		}
+		reply = append(reply, protocol.NewArray(
+			protocol.NewBulkString([]byte(entry.ID.String())),
+			protocol.NewBulkString([]byte(entry.Consumer().Name())),
+			protocol.NewInteger(int(idle)),
+			protocol.NewInteger(entry.DeliveryCount),
+		))
+	}
+	return protocol.NewArray(reply...)
+}
+
+// pendingSummary returns the number of pending entries of the group, the lowest and
+// highest of their IDs and how many are pending for each consumer.
+func pendingSummary(group *datastore.ConsumerGroup) protocol.DataType {
+	if group.PendingCount() == 0 {
+		return protocol.NewArray(protocol.NewInteger(0), protocol.NewNullBulkString(), protocol.NewNullBulkString(), protocol.NewNullArray())
+	}
+	pending := group.PendingRange(datastore.StreamID{}, datastore.MaxStreamID)
+	consumers := []protocol.DataType{}
+	for _, consumer := range group.Consumers() {
+		if consumer.PendingCount() > 0 {
+			consumers = append(consumers, protocol.NewArray(
+				protocol.NewBulkString([]byte(consumer.Name())),
+				protocol.NewBulkString([]byte(strconv.Itoa(consumer.PendingCount()))),
+			))
+		}
+	}
+	return protocol.NewArray(
+		protocol.NewInteger(len(pending)),
+		protocol.NewBulkString([]byte(pending[0].ID.String())),
+		protocol.NewBulkString([]byte(pending[len(pending)-1].ID.String())),
+		protocol.NewArray(consumers...),
+	)
+}
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xrangeInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key %s [COUNT count]"
+	invalidStreamIDErrMsg     string = "Invalid stream ID specified as stream command argument"
+)
+
+func init() {
+	registerCommand(xrangeCommand{name: "xrange"})
+	registerCommand(xrangeCommand{name: "xrevrange", rev: true})
+}
+
+// xrangeCommand implements XRANGE and XREVRANGE, which takes the end of the range
+// before the start and returns the entries in reverse order.
+type xrangeCommand struct {
+	name string
+	rev  bool
+}
+
+func (x xrangeCommand) getName() string {
+	return x.name
+}
+
+func (x xrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 && len(elements) != 6 {
+		return x.syntaxError()
+	}
+	first, second := elements[2], elements[3]
+	if x.rev {
+		first, second = second, first
+	}
+	start, ok := parseRangeStreamID(first.String(), true)
+	if !ok {
+		return protocol.NewError(invalidStreamIDErrMsg)
+	}
+	end, ok := parseRangeStreamID(second.String(), false)
+	if !ok {
+		return protocol.NewError(invalidStreamIDErrMsg)
+	}
+	count := -1
+	if len(elements) == 6 {
+		if strings.ToUpper(elements[4].String()) != "COUNT" {
+			return x.syntaxError()
+		}
+		count, ok = parseInt(elements[5])
+		if !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+	}
+	stream, exists, err := datastore.GetStream(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	// An exclusive bound at the lowest or highest ID leaves nothing in the range
+	if !exists || count == 0 || start == nil || end == nil {
+		return protocol.NewArray()
+	}
+	return streamEntriesReply(stream.Range(*start, *end, count, x.rev))
+}
+
+func (x xrangeCommand) syntaxError() protocol.DataType {
+	name := strings.ToUpper(x.name)
+	arguments := "start end"
+	if x.rev {
+		arguments = "end start"
+	}
+	return protocol.NewError(fmt.Sprintf(xrangeInvalidLengthErrMsg, name, name, arguments))
+}
+
+// parseStreamID parses an ID given as ms-seq, or only as ms, in which case the sequence
+// is the one informed.
+func parseStreamID(argument string, seq uint64) (datastore.StreamID, bool) {
+	msPart, seqPart, hasSeq := strings.Cut(argument, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return datastore.StreamID{}, false
+	}
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqPart, 10, 64)
+		if err != nil {
+			return datastore.StreamID{}, false
+		}
+	}
+	return datastore.StreamID{Ms: ms, Seq: seq}, true
+}
+
+// parseRangeStreamID parses the start or end of a range of IDs, which can also be - for
+// the lowest ID, + for the highest ID, or an ID prefixed by ( to exclude it. The ID is
+// nil if excluding it leaves nothing in the range.
+func parseRangeStreamID(argument string, start bool) (*datastore.StreamID, bool) {
+	switch argument {
+	case "-":
+		return &datastore.StreamID{}, true
+	case "+":
+		return &datastore.MaxStreamID, true
+	}
+	exclusive := strings.HasPrefix(argument, "(")
+	if exclusive {
+		argument = argument[1:]
+	}
+	var seq uint64
+	if !start {
+		seq = datastore.MaxStreamID.Seq
+	}
+	id, ok := parseStreamID(argument, seq)
+	if !ok {
+		return nil, false
+	}
+	if exclusive {
+		if start {
+			id, ok = id.Next()
+		} else {
+			id, ok = id.Prev()
+		}
+		if !ok {
+			return nil, true
+		}
+	}
+	return &id, true
+}
+
+// streamEntryReply creates the reply for an entry: its ID and its fields and values.
+func streamEntryReply(entry datastore.StreamEntry) protocol.Array {
+	return protocol.NewArray(protocol.NewBulkString([]byte(entry.ID.String())), bulkStrings(entry.Fields))
+}
+
+func streamEntriesReply(entries []datastore.StreamEntry) protocol.Array {
+	reply := []protocol.DataType{}
+	for _, entry := range entries {
+		reply = append(reply, streamEntryReply(entry))
+	}
+	return protocol.NewArray(reply...)
+}
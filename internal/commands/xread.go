@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xreadInvalidLengthErrMsg string = "invalid arguments for command XREAD. Syntax: XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]"
+	xreadUnbalancedErrMsg    string = "Unbalanced '%s' list of streams: for each stream key an ID or '%s' must be specified."
+	timeoutNotIntegerErrMsg  string = "timeout is not an integer or out of range"
+)
+
+func init() {
+	xread := xreadCommand{"xread"}
+	registerClientCommand(xread)
+}
+
+type xreadCommand struct {
+	name string
+}
+
+// streamReadOptions holds the options shared by XREAD and XREADGROUP.
+type streamReadOptions struct {
+	count   int
+	block   bool
+	timeout time.Duration
+	noAck   bool
+	keys    []string
+	ids     []string
+}
+
+func (x xreadCommand) getName() string {
+	return x.name
+}
+
+// processClientArguments reads the entries after the informed IDs from each stream. The
+// ID $ means the last ID of the stream, so only entries added from now on are read, and
+// + means the last entry is read. With BLOCK, the client waits for new entries if no
+// stream has entries after its ID.
+func (x xreadCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	options, errReply := parseStreamReadOptions(elements[1:], false, xreadInvalidLengthErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	after := make(map[string]datastore.StreamID)
+	for i, key := range options.keys {
+		stream, ok, err := datastore.GetStream(key)
+		if err != nil {
+			return errorReply(err)
+		}
+		switch options.ids[i] {
+		case "$":
+			if ok {
+				after[key] = stream.LastID()
+			}
+		case "+":
+			if last, found := stream.Last(); ok && found {
+				after[key], _ = last.ID.Prev()
+			}
+		default:
+			id, valid := parseStreamID(options.ids[i], 0)
+			if !valid {
+				return protocol.NewError(invalidStreamIDErrMsg)
+			}
+			after[key] = id
+		}
+	}
+	serve := func(key string) (protocol.DataType, bool) {
+		stream, ok, err := datastore.GetStream(key)
+		if err != nil || !ok {
+			return nil, false
+		}
+		start, ok := after[key].Next()
+		if !ok {
+			return nil, false
+		}
+		entries := stream.Range(start, datastore.MaxStreamID, options.count, false)
+		if len(entries) == 0 {
+			return nil, false
+		}
+		return protocol.NewArray(protocol.NewBulkString([]byte(key)), streamEntriesReply(entries)), true
+	}
+	reply := []protocol.DataType{}
+	for _, key := range options.keys {
+		if streamReply, ok := serve(key); ok {
+			reply = append(reply, streamReply)
+		}
+	}
+	if len(reply) > 0 {
+		return protocol.NewArray(reply...)
+	}
+	if !options.block {
+		return protocol.NewNullArray()
+	}
+	return serveOrBlock(client, options.keys, options.timeout, func(key string) (protocol.DataType, bool) {
+		streamReply, ok := serve(key)
+		if !ok {
+			return nil, false
+		}
+		return protocol.NewArray(streamReply), true
+	}, protocol.NewNullArray())
+}
+
+// parseStreamReadOptions parses the arguments of XREAD, or the ones after the group and
+// consumer of XREADGROUP, which also accepts NOACK.
+func parseStreamReadOptions(arguments []protocol.DataType, group bool, syntaxErrMsg string) (streamReadOptions, protocol.DataType) {
+	options := streamReadOptions{count: -1}
+	for i := 0; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		switch {
+		case option == "COUNT" && i+1 < len(arguments):
+			count, ok := parseInt(arguments[i+1])
+			if !ok {
+				return options, protocol.NewError(notIntegerErrMsg)
+			}
+			options.count = count
+			i++
+		case option == "BLOCK" && i+1 < len(arguments):
+			ms, err := strconv.ParseInt(arguments[i+1].String(), 10, 64)
+			if err != nil || ms > int64(time.Duration(1<<63-1)/time.Millisecond) {
+				return options, protocol.NewError(timeoutNotIntegerErrMsg)
+			}
+			if ms < 0 {
+				return options, protocol.NewError(timeoutNegativeErrMsg)
+			}
+			options.block = true
+			options.timeout = time.Duration(ms) * time.Millisecond
+			i++
+		case option == "NOACK" && group:
+			options.noAck = true
+		case option == "STREAMS":
+			streams := arguments[i+1:]
+			if len(streams) == 0 || len(streams)%2 != 0 {
+				name, special := "xread", "$"
+				if group {
+					name, special = "xreadgroup", ">"
+				}
+				return options, protocol.NewError(fmt.Sprintf(xreadUnbalancedErrMsg, name, special))
+			}
+			for _, key := range streams[:len(streams)/2] {
+				options.keys = append(options.keys, key.String())
+			}
+			for _, id := range streams[len(streams)/2:] {
+				options.ids = append(options.ids, id.String())
+			}
+			return options, nil
+		default:
+			return options, protocol.NewError(syntaxErrMsg)
+		}
+	}
+	return options, protocol.NewError(syntaxErrMsg)
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xreadgroupInvalidLengthErrMsg string = "invalid arguments for command XREADGROUP. Syntax: XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]"
+	xreadgroupNoGroupErrMsg       string = "NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option"
+	xreadgroupGroupDeletedErrMsg  string = "NOGROUP the consumer group this client was blocked on no longer exists"
+)
+
+func init() {
+	xreadgroup := xreadgroupCommand{"xreadgroup"}
+	registerClientCommand(xreadgroup)
+}
+
+type xreadgroupCommand struct {
+	name string
+}
+
+func (x xreadgroupCommand) getName() string {
+	return x.name
+}
+
+// processClientArguments reads entries from streams on behalf of a consumer of a group.
+// The ID > reads the entries never delivered to the group, adding them to the pending
+// entries list of the consumer unless NOACK is informed. Any other ID reads the history
+// of the consumer: its pending entries after the ID. Only reads of new entries block.
+func (x xreadgroupCommand) processClientArguments(client *Client, data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 7 || strings.ToUpper(elements[1].String()) != "GROUP" {
+		return protocol.NewError(xreadgroupInvalidLengthErrMsg)
+	}
+	groupName := elements[2].String()
+	consumerName := elements[3].String()
+	options, errReply := parseStreamReadOptions(elements[4:], true, xreadgroupInvalidLengthErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	history := make(map[string]datastore.StreamID)
+	for i, key := range options.keys {
+		if options.ids[i] == ">" {
+			continue
+		}
+		id, ok := parseStreamID(options.ids[i], 0)
+		if !ok {
+			return protocol.NewError(invalidStreamIDErrMsg)
+		}
+		history[key] = id
+	}
+	for _, key := range options.keys {
+		stream, ok, err := datastore.GetStream(key)
+		if err != nil {
+			return errorReply(err)
+		}
+		if !ok {
+			return protocol.NewError(fmt.Sprintf(xreadgroupNoGroupErrMsg, key, groupName))
+		}
+		if _, ok := stream.Group(groupName); !ok {
+			return protocol.NewError(fmt.Sprintf(xreadgroupNoGroupErrMsg, key, groupName))
+		}
+	}
+	read := func(key string) (protocol.DataType, bool) {
+		stream, ok, err := datastore.GetStream(key)
+		if err != nil || !ok {
+			return protocol.NewError(xreadgroupGroupDeletedErrMsg), true
+		}
+		group, ok := stream.Group(groupName)
+		if !ok {
+			return protocol.NewError(xreadgroupGroupDeletedErrMsg), true
+		}
+		now := time.Now().UnixMilli()
+		consumer, _ := group.CreateConsumer(consumerName, now)
+		var reply protocol.Array
+		if id, ok := history[key]; ok {
+			reply = readConsumerHistory(stream, group, consumer, id, options.count, now)
+		} else {
+			entries := readNewEntries(stream, group, consumer, options.count, options.noAck, now)
+			if len(entries) == 0 {
+				consumer.Touch(now, false)
+				return nil, false
+			}
+			reply = streamEntriesReply(entries)
+		}
+		return protocol.NewArray(protocol.NewBulkString([]byte(key)), reply), true
+	}
+	reply := []protocol.DataType{}
+	for _, key := range options.keys {
+		if streamReply, ok := read(key); ok {
+			reply = append(reply, streamReply)
+		}
+	}
+	if len(reply) > 0 {
+		return protocol.NewArray(reply...)
+	}
+	if !options.block {
+		return protocol.NewNullArray()
+	}
+	return serveOrBlock(client, options.keys, options.timeout, func(key string) (protocol.DataType, bool) {
+		streamReply, ok := read(key)
+		if !ok {
+			return nil, false
+		}
+		if _, isError := streamReply.(protocol.Error); isError {
+			return streamReply, true
+		}
+		return protocol.NewArray(streamReply), true
+	}, protocol.NewNullArray())
+}
+
+// readNewEntries delivers to the consumer up to count entries never delivered to the
+// group before.
+func readNewEntries(stream *datastore.Stream, group *datastore.ConsumerGroup, consumer *datastore.Consumer, count int, noAck bool, now int64) []datastore.StreamEntry {
+	start, ok := group.LastID().Next()
+	if !ok {
+		return nil
+	}
+	entries := stream.Range(start, datastore.MaxStreamID, count, false)
+	for _, entry := range entries {
+		group.MarkRead(entry.ID)
+		if !noAck {
+			group.Deliver(consumer, entry.ID, now)
+		}
+	}
+	if len(entries) > 0 {
+		consumer.Touch(now, true)
+	}
+	return entries
+}
+
+// readConsumerHistory delivers again to the consumer up to count of its pending entries
+// with IDs greater than id. Entries deleted from the stream are replied with no fields.
+func readConsumerHistory(stream *datastore.Stream, group *datastore.ConsumerGroup, consumer *datastore.Consumer, id datastore.StreamID, count int, now int64) protocol.Array {
+	reply := []protocol.DataType{}
+	consumer.Touch(now, true)
+	start, ok := id.Next()
+	if !ok {
+		return protocol.NewArray()
+	}
+	for _, pending := range consumer.PendingRange(start, datastore.MaxStreamID) {
+		if count > 0 && len(reply) == count {
+			break
+		}
+		entry, ok := stream.Get(pending.ID)
+		if !ok {
+			reply = append(reply, protocol.NewArray(protocol.NewBulkString([]byte(pending.ID.String())), protocol.NewNullArray()))
+			continue
+		}
+		group.Deliver(consumer, pending.ID, now)
+		reply = append(reply, streamEntryReply(entry))
+	}
+	return protocol.NewArray(reply...)
+}
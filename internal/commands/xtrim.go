@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	xtrimInvalidLengthErrMsg string = "invalid arguments for command XTRIM. Syntax: XTRIM key <MAXLEN | MINID> [= | ~] threshold [LIMIT count]"
+	xtrimMaxLenErrMsg        string = "The MAXLEN argument must be >= 0."
+	xtrimLimitErrMsg         string = "syntax error, LIMIT cannot be used without the special ~ option"
+	xtrimNegativeLimitErrMsg string = "The LIMIT argument must be >= 0."
+	// defaultTrimLimit is how many entries an approximate trim removes at most when no
+	// LIMIT is informed, 100 times the entries of a chunk like in Redis.
+	defaultTrimLimit int = 100 * 100
+)
+
+func init() {
+	xtrim := xtrimCommand{"xtrim"}
+	registerCommand(xtrim)
+}
+
+type xtrimCommand struct {
+	name string
+}
+
+// streamTrim holds the trimming options of XADD and XTRIM.
+type streamTrim struct {
+	byMinID bool
+	maxLen  int
+	minID   datastore.StreamID
+	approx  bool
+	limit   int
+}
+
+func (x xtrimCommand) getName() string {
+	return x.name
+}
+
+func (x xtrimCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return protocol.NewError(xtrimInvalidLengthErrMsg)
+	}
+	trim, next, errReply := parseStreamTrim(elements, 2, xtrimInvalidLengthErrMsg)
+	if errReply != nil {
+		return errReply
+	}
+	if next != len(elements) {
+		return protocol.NewError(xtrimInvalidLengthErrMsg)
+	}
+	stream, ok, err := datastore.GetStream(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(trim.apply(stream))
+}
+
+// parseStreamTrim parses the trimming options starting at the MAXLEN or MINID argument
+// at index i, returning the index of the argument after them.
+func parseStreamTrim(elements []protocol.DataType, i int, syntaxErrMsg string) (streamTrim, int, protocol.DataType) {
+	var trim streamTrim
+	trim.byMinID = strings.ToUpper(elements[i].String()) == "MINID"
+	i++
+	if i < len(elements) && (elements[i].String() == "=" || elements[i].String() == "~") {
+		trim.approx = elements[i].String() == "~"
+		i++
+	}
+	if i >= len(elements) {
+		return trim, i, protocol.NewError(syntaxErrMsg)
+	}
+	if trim.byMinID {
+		id, ok := parseStreamID(elements[i].String(), 0)
+		if !ok {
+			return trim, i, protocol.NewError(invalidStreamIDErrMsg)
+		}
+		trim.minID = id
+	} else {
+		maxLen, err := strconv.ParseInt(elements[i].String(), 10, 64)
+		if err != nil {
+			return trim, i, protocol.NewError(notIntegerErrMsg)
+		}
+		if maxLen < 0 {
+			return trim, i, protocol.NewError(xtrimMaxLenErrMsg)
+		}
+		trim.maxLen = int(maxLen)
+	}
+	i++
+	if trim.approx {
+		trim.limit = defaultTrimLimit
+	}
+	if i+1 < len(elements) && strings.ToUpper(elements[i].String()) == "LIMIT" {
+		limit, ok := parseInt(elements[i+1])
+		if !ok {
+			return trim, i, protocol.NewError(notIntegerErrMsg)
+		}
+		if limit < 0 {
+			return trim, i, protocol.NewError(xtrimNegativeLimitErrMsg)
+		}
+		if !trim.approx {
+			return trim, i, protocol.NewError(xtrimLimitErrMsg)
+		}
+		trim.limit = limit
+		i += 2
+	}
+	return trim, i, nil
+}
+
+// apply trims the stream, returning how many entries were removed.
+func (t streamTrim) apply(stream *datastore.Stream) int {
+	if t.byMinID {
+		return stream.TrimMinID(t.minID, t.approx, t.limit)
+	}
+	return stream.TrimMaxLen(t.maxLen, t.approx, t.limit)
+}
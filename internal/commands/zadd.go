@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"math"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zaddInvalidLengthErrMsg    string = "invalid arguments for command ZADD. Syntax: ZADD key [NX | XX] [GT | LT] [CH] [INCR] score member [score member ...]"
+	zincrbyInvalidLengthErrMsg string = "invalid arguments for command ZINCRBY. Syntax: ZINCRBY key increment member"
+	zaddNXXXCompatErrMsg       string = "XX and NX options at the same time are not compatible"
+	zaddGTLTNXCompatErrMsg     string = "GT, LT, and/or NX options at the same time are not compatible"
+	zaddIncrPairsErrMsg        string = "INCR option supports a single increment-element pair"
+	scoreNaNErrMsg             string = "resulting score is not a number (NaN)"
+)
+
+func init() {
+	zadd := zaddCommand{"zadd"}
+	registerCommand(zadd)
+	zincrby := zincrbyCommand{"zincrby"}
+	registerCommand(zincrby)
+}
+
+type zaddCommand struct {
+	name string
+}
+
+type zincrbyCommand struct {
+	name string
+}
+
+// zaddOptions holds the options of ZADD. NX only adds new members, XX only updates
+// existing members, GT and LT only update the score if the new one is greater or lower,
+// CH counts the updated members in the reply and INCR increments the score instead of
+// replacing it.
+type zaddOptions struct {
+	nx, xx, gt, lt, ch, incr bool
+}
+
+func (z zaddCommand) getName() string {
+	return z.name
+}
+
+func (z zaddCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return protocol.NewError(zaddInvalidLengthErrMsg)
+	}
+	var options zaddOptions
+	i := 2
+flags:
+	for ; i < len(elements); i++ {
+		switch strings.ToUpper(elements[i].String()) {
+		case "NX":
+			options.nx = true
+		case "XX":
+			options.xx = true
+		case "GT":
+			options.gt = true
+		case "LT":
+			options.lt = true
+		case "CH":
+			options.ch = true
+		case "INCR":
+			options.incr = true
+		default:
+			break flags
+		}
+	}
+	pairs := elements[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return protocol.NewError(zaddInvalidLengthErrMsg)
+	}
+	if options.nx && options.xx {
+		return protocol.NewError(zaddNXXXCompatErrMsg)
+	}
+	if (options.gt && options.lt) || (options.nx && (options.gt || options.lt)) {
+		return protocol.NewError(zaddGTLTNXCompatErrMsg)
+	}
+	if options.incr && len(pairs) > 2 {
+		return protocol.NewError(zaddIncrPairsErrMsg)
+	}
+	scores := []float64{}
+	for j := 0; j < len(pairs); j += 2 {
+		score, ok := parseScore(pairs[j])
+		if !ok {
+			return protocol.NewError(notFloatErrMsg)
+		}
+		scores = append(scores, score)
+	}
+	key := elements[1].String()
+	zset, err := datastore.GetOrCreateSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	added, changed := 0, 0
+	var incrScore protocol.DataType = protocol.NewNullBulkString()
+	for j, score := range scores {
+		member := pairs[j*2+1].String()
+		current, exists := zset.Score(member)
+		if (exists && options.nx) || (!exists && options.xx) {
+			continue
+		}
+		if options.incr {
+			score += current
+			if math.IsNaN(score) {
+				if zset.Len() == 0 {
+					datastore.Delete(key)
+				}
+				return protocol.NewError(scoreNaNErrMsg)
+			}
+		}
+		if exists && ((options.gt && score <= current) || (options.lt && score >= current)) {
+			continue
+		}
+		incrScore = protocol.NewDouble(score)
+		if !exists {
+			added++
+		} else if score != current {
+			changed++
+		}
+		zset.Add(member, score)
+	}
+	if zset.Len() == 0 {
+		datastore.Delete(key)
+	}
+	if options.incr {
+		return incrScore
+	}
+	if options.ch {
+		return protocol.NewInteger(added + changed)
+	}
+	return protocol.NewInteger(added)
+}
+
+func (z zincrbyCommand) getName() string {
+	return z.name
+}
+
+func (z zincrbyCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(zincrbyInvalidLengthErrMsg)
+	}
+	increment, ok := parseScore(elements[2])
+	if !ok {
+		return protocol.NewError(notFloatErrMsg)
+	}
+	zset, err := datastore.GetOrCreateSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	member := elements[3].String()
+	current, _ := zset.Score(member)
+	score := current + increment
+	if math.IsNaN(score) {
+		if zset.Len() == 0 {
+			datastore.Delete(elements[1].String())
+		}
+		return protocol.NewError(scoreNaNErrMsg)
+	}
+	zset.Add(member, score)
+	return protocol.NewDouble(score)
+}
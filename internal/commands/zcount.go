@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zcountInvalidLengthErrMsg    string = "invalid arguments for command ZCOUNT. Syntax: ZCOUNT key min max"
+	zlexcountInvalidLengthErrMsg string = "invalid arguments for command ZLEXCOUNT. Syntax: ZLEXCOUNT key min max"
+)
+
+func init() {
+	zcount := zcountCommand{"zcount"}
+	registerCommand(zcount)
+	zlexcount := zlexcountCommand{"zlexcount"}
+	registerCommand(zlexcount)
+}
+
+type zcountCommand struct {
+	name string
+}
+
+type zlexcountCommand struct {
+	name string
+}
+
+func (z zcountCommand) getName() string {
+	return z.name
+}
+
+func (z zcountCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(zcountInvalidLengthErrMsg)
+	}
+	scoreRange, errReply := parseScoreRange(elements[2], elements[3], false)
+	if errReply != nil {
+		return errReply
+	}
+	zset, ok, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	start, end := zset.ScoreRanks(scoreRange)
+	return protocol.NewInteger(end - start)
+}
+
+func (z zlexcountCommand) getName() string {
+	return z.name
+}
+
+func (z zlexcountCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		return protocol.NewError(zlexcountInvalidLengthErrMsg)
+	}
+	lexRange, errReply := parseLexRange(elements[2], elements[3], false)
+	if errReply != nil {
+		return errReply
+	}
+	zset, ok, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	start, end := zset.LexRanks(lexRange)
+	return protocol.NewInteger(end - start)
+}
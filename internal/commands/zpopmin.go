@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zpopInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key [count]"
+)
+
+func init() {
+	registerCommand(zpopCommand{name: "zpopmin"})
+	registerCommand(zpopCommand{name: "zpopmax", max: true})
+}
+
+// zpopCommand implements ZPOPMIN and ZPOPMAX, which remove the members with the lowest
+// or highest scores.
+type zpopCommand struct {
+	name string
+	max  bool
+}
+
+func (z zpopCommand) getName() string {
+	return z.name
+}
+
+func (z zpopCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 2 || len(elements) > 3 {
+		name := strings.ToUpper(z.name)
+		return protocol.NewError(fmt.Sprintf(zpopInvalidLengthErrMsg, name, name))
+	}
+	count := 1
+	if len(elements) == 3 {
+		var ok bool
+		count, ok = parseInt(elements[2])
+		if !ok {
+			return protocol.NewError(notIntegerErrMsg)
+		}
+		if count < 0 {
+			return protocol.NewError(notPositiveErrMsg)
+		}
+	}
+	key := elements[1].String()
+	zset, ok, err := datastore.GetSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewArray()
+	}
+	entries := zsetPop(zset, count, z.max)
+	if zset.Len() == 0 {
+		datastore.Delete(key)
+	}
+	if len(elements) == 2 {
+		// Without a count the entry isn't nested, even for RESP3 clients
+		return protocol.NewArray(scoredMembers(entries)...)
+	}
+	return entriesReply(entries, true)
+}
+
+// zsetPop removes up to count entries with the lowest or highest scores, returning them
+// in the order they were removed.
+func zsetPop(zset *datastore.SortedSet, count int, highest bool) []datastore.ZEntry {
+	start, end := 0, min(count, zset.Len())
+	if highest {
+		start, end = zset.Len()-end, zset.Len()
+	}
+	entries := orderEntries(zset.Range(start, end), highest)
+	zset.RemoveRange(start, end)
+	return entries
+}
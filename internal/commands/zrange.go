@@ -0,0 +1,329 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zrangeInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s"
+	zrangeLimitErrMsg         string = "syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX"
+	zrangeWithScoresErrMsg    string = "syntax error, WITHSCORES not supported in combination with BYLEX"
+	zrangeScoreRangeErrMsg    string = "min or max is not a float"
+	zrangeLexRangeErrMsg      string = "min or max not valid string range item"
+	zrangeSyntax              string = "ZRANGE key start stop [BYSCORE | BYLEX] [REV] [LIMIT offset count] [WITHSCORES]"
+	zrangestoreSyntax         string = "ZRANGESTORE dst src min max [BYSCORE | BYLEX] [REV] [LIMIT offset count]"
+	zrevrangeSyntax           string = "ZREVRANGE key start stop [WITHSCORES]"
+	zrangebyscoreSyntax       string = "ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]"
+	zrevrangebyscoreSyntax    string = "ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count]"
+	zrangebylexSyntax         string = "ZRANGEBYLEX key min max [LIMIT offset count]"
+	zrevrangebylexSyntax      string = "ZREVRANGEBYLEX key max min [LIMIT offset count]"
+)
+
+// zrangeBy is how the start and stop arguments of a range of a sorted set are read.
+type zrangeBy int
+
+const (
+	byRank zrangeBy = iota
+	byScore
+	byLex
+)
+
+func init() {
+	registerCommand(zrangeCommand{name: "zrange", syntax: zrangeSyntax, options: true})
+	registerCommand(zrangeCommand{name: "zrangestore", syntax: zrangestoreSyntax, options: true, store: true})
+	registerCommand(zrangeCommand{name: "zrevrange", syntax: zrevrangeSyntax, rev: true})
+	registerCommand(zrangeCommand{name: "zrangebyscore", syntax: zrangebyscoreSyntax, by: byScore})
+	registerCommand(zrangeCommand{name: "zrevrangebyscore", syntax: zrevrangebyscoreSyntax, by: byScore, rev: true})
+	registerCommand(zrangeCommand{name: "zrangebylex", syntax: zrangebylexSyntax, by: byLex})
+	registerCommand(zrangeCommand{name: "zrevrangebylex", syntax: zrevrangebylexSyntax, by: byLex, rev: true})
+}
+
+// zrangeCommand implements ZRANGE, which unifies all the ways of reading a range of a
+// sorted set, ZRANGESTORE, which stores the range in a new key, and the older commands
+// that read one type of range, like ZRANGEBYSCORE. The older commands are the unified
+// ZRANGE with the type of range and the direction fixed.
+type zrangeCommand struct {
+	name   string
+	syntax string
+	by     zrangeBy
+	rev    bool
+	// options tells if BYSCORE, BYLEX and REV are accepted
+	options bool
+	store   bool
+}
+
+// zrangeRequest holds the parsed arguments of a range of a sorted set. Only the range
+// matching by is set.
+type zrangeRequest struct {
+	by         zrangeBy
+	rev        bool
+	withScores bool
+	limited    bool
+	offset     int
+	count      int
+	first      int
+	last       int
+	scoreRange datastore.ScoreRange
+	lexRange   datastore.LexRange
+}
+
+func (z zrangeCommand) getName() string {
+	return z.name
+}
+
+func (z zrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	arguments := elements[1:]
+	var destination string
+	if z.store {
+		if len(arguments) == 0 {
+			return z.syntaxError()
+		}
+		destination = arguments[0].String()
+		arguments = arguments[1:]
+	}
+	if len(arguments) < 3 {
+		return z.syntaxError()
+	}
+	request, errReply := z.parseRequest(arguments[1:])
+	if errReply != nil {
+		return errReply
+	}
+	key := arguments[0].String()
+	zset, ok, err := datastore.GetSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	var entries []datastore.ZEntry
+	if ok {
+		entries = request.entries(zset)
+	}
+	if z.store {
+		result := datastore.NewSortedSet()
+		for _, entry := range entries {
+			result.Add(entry.Member, entry.Score)
+		}
+		datastore.StoreSortedSet(destination, result)
+		return protocol.NewInteger(result.Len())
+	}
+	return entriesReply(entries, request.withScores)
+}
+
+func (z zrangeCommand) parseRequest(arguments []protocol.DataType) (zrangeRequest, protocol.DataType) {
+	request := zrangeRequest{
+		by:    z.by,
+		rev:   z.rev,
+		count: -1,
+	}
+	for i := 2; i < len(arguments); i++ {
+		option := strings.ToUpper(arguments[i].String())
+		switch {
+		case option == "WITHSCORES" && !z.store:
+			request.withScores = true
+		case option == "LIMIT" && i+2 < len(arguments):
+			offset, ok := parseInt(arguments[i+1])
+			if !ok {
+				return request, protocol.NewError(notIntegerErrMsg)
+			}
+			count, ok := parseInt(arguments[i+2])
+			if !ok {
+				return request, protocol.NewError(notIntegerErrMsg)
+			}
+			request.limited = true
+			request.offset = offset
+			request.count = count
+			i += 2
+		case option == "BYSCORE" && z.options && request.by == byRank:
+			request.by = byScore
+		case option == "BYLEX" && z.options && request.by == byRank:
+			request.by = byLex
+		case option == "REV" && z.options:
+			request.rev = true
+		default:
+			return request, z.syntaxError()
+		}
+	}
+	if request.limited && request.by == byRank {
+		return request, protocol.NewError(zrangeLimitErrMsg)
+	}
+	if request.withScores && request.by == byLex {
+		return request, protocol.NewError(zrangeWithScoresErrMsg)
+	}
+	// Like in Redis, the range is given from max to min for reversed ranges by score or
+	// lex, and from start to stop counting from the end for reversed ranges by rank
+	var errReply protocol.DataType
+	switch request.by {
+	case byRank:
+		var ok bool
+		if request.first, ok = parseInt(arguments[0]); !ok {
+			return request, protocol.NewError(notIntegerErrMsg)
+		}
+		if request.last, ok = parseInt(arguments[1]); !ok {
+			return request, protocol.NewError(notIntegerErrMsg)
+		}
+	case byScore:
+		request.scoreRange, errReply = parseScoreRange(arguments[0], arguments[1], request.rev)
+	case byLex:
+		request.lexRange, errReply = parseLexRange(arguments[0], arguments[1], request.rev)
+	}
+	return request, errReply
+}
+
+func (z zrangeCommand) syntaxError() protocol.DataType {
+	return protocol.NewError(fmt.Sprintf(zrangeInvalidLengthErrMsg, strings.ToUpper(z.name), z.syntax))
+}
+
+// entries returns the entries of the sorted set in the range, in the order they are
+// replied.
+func (r zrangeRequest) entries(zset *datastore.SortedSet) []datastore.ZEntry {
+	var start, end int
+	switch r.by {
+	case byRank:
+		start, end = rankRange(r.first, r.last, zset.Len())
+		if r.rev {
+			start, end = zset.Len()-end, zset.Len()-start
+		}
+		return orderEntries(zset.Range(start, end), r.rev)
+	case byScore:
+		start, end = zset.ScoreRanks(r.scoreRange)
+	case byLex:
+		start, end = zset.LexRanks(r.lexRange)
+	}
+	if r.offset < 0 {
+		return []datastore.ZEntry{}
+	}
+	// The offset and count are applied from the end of the range for reversed ranges
+	if r.rev {
+		end -= r.offset
+		if r.count >= 0 {
+			start = max(start, end-r.count)
+		}
+	} else {
+		start += r.offset
+		if r.count >= 0 {
+			end = min(end, start+r.count)
+		}
+	}
+	return orderEntries(zset.Range(start, end), r.rev)
+}
+
+// rankRange converts the start and stop indexes of a range, which can be negative to
+// count from the end, to the ranks from start, inclusive, to end, exclusive.
+func rankRange(start, stop, length int) (int, int) {
+	if start < 0 {
+		start = max(length+start, 0)
+	}
+	if stop < 0 {
+		stop = length + stop
+	}
+	stop = min(stop, length-1)
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
+
+func orderEntries(entries []datastore.ZEntry, rev bool) []datastore.ZEntry {
+	if rev {
+		slices.Reverse(entries)
+	}
+	return entries
+}
+
+// entriesReply creates the reply with the members, paired with their scores when
+// withScores is set.
+func entriesReply(entries []datastore.ZEntry, withScores bool) protocol.DataType {
+	if withScores {
+		return protocol.NewPairs(scoredMembers(entries)...)
+	}
+	reply := []protocol.DataType{}
+	for _, entry := range entries {
+		reply = append(reply, protocol.NewBulkString([]byte(entry.Member)))
+	}
+	return protocol.NewArray(reply...)
+}
+
+// scoredMembers returns the members of the entries, each one followed by its score.
+func scoredMembers(entries []datastore.ZEntry) []protocol.DataType {
+	elements := []protocol.DataType{}
+	for _, entry := range entries {
+		elements = append(elements, protocol.NewBulkString([]byte(entry.Member)), protocol.NewDouble(entry.Score))
+	}
+	return elements
+}
+
+// parseScore parses a score, which can be -inf or +inf but not NaN.
+func parseScore(argument protocol.DataType) (float64, bool) {
+	score, err := strconv.ParseFloat(argument.String(), 64)
+	if err != nil || math.IsNaN(score) {
+		return 0, false
+	}
+	return score, true
+}
+
+// parseScoreRange parses the min and max of a range of scores. Each of them is
+// exclusive if prefixed by (. For reversed ranges the max comes first.
+func parseScoreRange(first, second protocol.DataType, rev bool) (datastore.ScoreRange, protocol.DataType) {
+	if rev {
+		first, second = second, first
+	}
+	var r datastore.ScoreRange
+	var ok bool
+	r.Min, r.MinExclusive, ok = parseScoreBound(first.String())
+	if !ok {
+		return r, protocol.NewError(zrangeScoreRangeErrMsg)
+	}
+	r.Max, r.MaxExclusive, ok = parseScoreBound(second.String())
+	if !ok {
+		return r, protocol.NewError(zrangeScoreRangeErrMsg)
+	}
+	return r, nil
+}
+
+func parseScoreBound(bound string) (float64, bool, bool) {
+	exclusive := strings.HasPrefix(bound, "(")
+	if exclusive {
+		bound = bound[1:]
+	}
+	score, ok := parseScore(protocol.NewBulkString([]byte(bound)))
+	return score, exclusive, ok
+}
+
+// parseLexRange parses the min and max of a range of members. Each of them is - or +,
+// or a member prefixed by [ if inclusive or ( if exclusive. For reversed ranges the max
+// comes first.
+func parseLexRange(first, second protocol.DataType, rev bool) (datastore.LexRange, protocol.DataType) {
+	if rev {
+		first, second = second, first
+	}
+	var r datastore.LexRange
+	var ok bool
+	if r.Min, ok = parseLexBound(first.String()); !ok {
+		return r, protocol.NewError(zrangeLexRangeErrMsg)
+	}
+	if r.Max, ok = parseLexBound(second.String()); !ok {
+		return r, protocol.NewError(zrangeLexRangeErrMsg)
+	}
+	return r, nil
+}
+
+func parseLexBound(bound string) (datastore.LexBound, bool) {
+	switch {
+	case bound == "-":
+		return datastore.LexBound{Infinity: -1}, true
+	case bound == "+":
+		return datastore.LexBound{Infinity: 1}, true
+	case strings.HasPrefix(bound, "["):
+		return datastore.LexBound{Value: bound[1:]}, true
+	case strings.HasPrefix(bound, "("):
+		return datastore.LexBound{Value: bound[1:], Exclusive: true}, true
+	}
+	return datastore.LexBound{}, false
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zrankInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key member [WITHSCORE]"
+)
+
+func init() {
+	registerCommand(zrankCommand{name: "zrank"})
+	registerCommand(zrankCommand{name: "zrevrank", rev: true})
+}
+
+// zrankCommand implements ZRANK and ZREVRANK, which returns the rank counting from the
+// highest score.
+type zrankCommand struct {
+	name string
+	rev  bool
+}
+
+func (z zrankCommand) getName() string {
+	return z.name
+}
+
+func (z zrankCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	name := strings.ToUpper(z.name)
+	if len(elements) < 3 || len(elements) > 4 {
+		return protocol.NewError(fmt.Sprintf(zrankInvalidLengthErrMsg, name, name))
+	}
+	withScore := len(elements) == 4
+	if withScore && strings.ToUpper(elements[3].String()) != "WITHSCORE" {
+		return protocol.NewError(fmt.Sprintf(zrankInvalidLengthErrMsg, name, name))
+	}
+	var notFound protocol.DataType = protocol.NewNullBulkString()
+	if withScore {
+		notFound = protocol.NewNullArray()
+	}
+	zset, ok, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return notFound
+	}
+	member := elements[2].String()
+	rank, ok := zset.Rank(member)
+	if !ok {
+		return notFound
+	}
+	if z.rev {
+		rank = zset.Len() - 1 - rank
+	}
+	if withScore {
+		score, _ := zset.Score(member)
+		return protocol.NewArray(protocol.NewInteger(rank), protocol.NewDouble(score))
+	}
+	return protocol.NewInteger(rank)
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zremInvalidLengthErrMsg string = "invalid arguments for command ZREM. Syntax: ZREM key member [member ...]"
+)
+
+func init() {
+	zrem := zremCommand{"zrem"}
+	registerCommand(zrem)
+}
+
+type zremCommand struct {
+	name string
+}
+
+func (z zremCommand) getName() string {
+	return z.name
+}
+
+func (z zremCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(zremInvalidLengthErrMsg)
+	}
+	key := elements[1].String()
+	zset, ok, err := datastore.GetSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	removed := 0
+	for _, member := range elements[2:] {
+		if zset.Remove(member.String()) {
+			removed++
+		}
+	}
+	if zset.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewInteger(removed)
+}
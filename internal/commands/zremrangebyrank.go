@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zremrangeInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s key %s"
+)
+
+func init() {
+	registerCommand(zremrangeCommand{name: "zremrangebyrank", by: byRank})
+	registerCommand(zremrangeCommand{name: "zremrangebyscore", by: byScore})
+	registerCommand(zremrangeCommand{name: "zremrangebylex", by: byLex})
+}
+
+// zremrangeCommand implements ZREMRANGEBYRANK, ZREMRANGEBYSCORE and ZREMRANGEBYLEX,
+// which remove the members in a range read like in ZRANGE.
+type zremrangeCommand struct {
+	name string
+	by   zrangeBy
+}
+
+func (z zremrangeCommand) getName() string {
+	return z.name
+}
+
+func (z zremrangeCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 4 {
+		arguments := "min max"
+		if z.by == byRank {
+			arguments = "start stop"
+		}
+		name := strings.ToUpper(z.name)
+		return protocol.NewError(fmt.Sprintf(zremrangeInvalidLengthErrMsg, name, name, arguments))
+	}
+	request, errReply := zrangeCommand{by: z.by}.parseRequest(elements[2:])
+	if errReply != nil {
+		return errReply
+	}
+	key := elements[1].String()
+	zset, ok, err := datastore.GetSortedSet(key)
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	removed := 0
+	for _, entry := range request.entries(zset) {
+		zset.Remove(entry.Member)
+		removed++
+	}
+	if zset.Len() == 0 {
+		datastore.Delete(key)
+	}
+	return protocol.NewInteger(removed)
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zscoreInvalidLengthErrMsg  string = "invalid arguments for command ZSCORE. Syntax: ZSCORE key member"
+	zmscoreInvalidLengthErrMsg string = "invalid arguments for command ZMSCORE. Syntax: ZMSCORE key member [member ...]"
+	zcardInvalidLengthErrMsg   string = "the ZCARD command accepts 2 parameters: ZCARD and KEY. Received %d parameters instead"
+)
+
+func init() {
+	zscore := zscoreCommand{"zscore"}
+	registerCommand(zscore)
+	zmscore := zmscoreCommand{"zmscore"}
+	registerCommand(zmscore)
+	zcard := zcardCommand{"zcard"}
+	registerCommand(zcard)
+}
+
+type zscoreCommand struct {
+	name string
+}
+
+type zmscoreCommand struct {
+	name string
+}
+
+type zcardCommand struct {
+	name string
+}
+
+func (z zscoreCommand) getName() string {
+	return z.name
+}
+
+func (z zscoreCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 3 {
+		return protocol.NewError(zscoreInvalidLengthErrMsg)
+	}
+	zset, ok, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	score, ok := zset.Score(elements[2].String())
+	if !ok {
+		return protocol.NewNullBulkString()
+	}
+	return protocol.NewDouble(score)
+}
+
+func (z zmscoreCommand) getName() string {
+	return z.name
+}
+
+func (z zmscoreCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 3 {
+		return protocol.NewError(zmscoreInvalidLengthErrMsg)
+	}
+	zset, exists, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	reply := []protocol.DataType{}
+	for _, member := range elements[2:] {
+		var score float64
+		ok := false
+		if exists {
+			score, ok = zset.Score(member.String())
+		}
+		if ok {
+			reply = append(reply, protocol.NewDouble(score))
+		} else {
+			reply = append(reply, protocol.NewNullBulkString())
+		}
+	}
+	return protocol.NewArray(reply...)
+}
+
+func (z zcardCommand) getName() string {
+	return z.name
+}
+
+func (z zcardCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) != 2 {
+		return protocol.NewError(fmt.Sprintf(zcardInvalidLengthErrMsg, len(elements)))
+	}
+	zset, ok, err := datastore.GetSortedSet(elements[1].String())
+	if err != nil {
+		return errorReply(err)
+	}
+	if !ok {
+		return protocol.NewInteger(0)
+	}
+	return protocol.NewInteger(zset.Len())
+}
@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+type zsetTestCase struct {
+	name      string
+	setupCmds []string
+	input     string
+	expected  protocol.DataType
+}
+
+// membersWithScores creates the expected reply for commands that return members with
+// their scores, given as member and score pairs.
+func membersWithScores(pairs ...any) protocol.Pairs {
+	elements := []protocol.DataType{}
+	for i := 0; i < len(pairs); i += 2 {
+		elements = append(elements, protocol.NewBulkString([]byte(pairs[i].(string))))
+		elements = append(elements, protocol.NewDouble(pairs[i+1].(float64)))
+	}
+	return protocol.NewPairs(elements...)
+}
+
+func TestSortedSetCommands(t *testing.T) {
+	wrongType := protocol.NewError(datastore.ErrWrongType.Error())
+	board := "ZADD zset-board 1 a 2 b 3 c 4 d 5 e"
+	lex := "ZADD zset-lex 0 a 0 b 0 c 0 d 0 e"
+	ztcs := []zsetTestCase{
+		{
+			name:     "ZADD",
+			input:    "ZADD zset-zadd 1 a 2 b 1 a",
+			expected: protocol.NewInteger(2),
+		},
+		{
+			name:      "ZADD with CH",
+			setupCmds: []string{"ZADD zset-zadd-ch 1 a"},
+			input:     "ZADD zset-zadd-ch CH 2 a 1 b",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "ZADD with XX",
+			setupCmds: []string{"ZADD zset-zadd-xx 1 a", "ZADD zset-zadd-xx XX 5 a 1 b"},
+			input:     "ZRANGE zset-zadd-xx 0 -1 WITHSCORES",
+			expected:  membersWithScores("a", 5.0),
+		},
+		{
+			name:      "ZADD with NX",
+			setupCmds: []string{"ZADD zset-zadd-nx 1 a", "ZADD zset-zadd-nx NX 5 a 2 b"},
+			input:     "ZRANGE zset-zadd-nx 0 -1 WITHSCORES",
+			expected:  membersWithScores("a", 1.0, "b", 2.0),
+		},
+		{
+			name:      "ZADD with GT",
+			setupCmds: []string{"ZADD zset-zadd-gt 5 a 5 b", "ZADD zset-zadd-gt GT 3 a 7 b"},
+			input:     "ZRANGE zset-zadd-gt 0 -1 WITHSCORES",
+			expected:  membersWithScores("a", 5.0, "b", 7.0),
+		},
+		{
+			name:      "ZADD with INCR",
+			setupCmds: []string{"ZADD zset-zadd-incr 1.5 a"},
+			input:     "ZADD zset-zadd-incr INCR 2 a",
+			expected:  protocol.NewDouble(3.5),
+		},
+		{
+			name:      "ZADD with INCR aborted",
+			setupCmds: []string{"ZADD zset-zadd-incr-nx 1 a"},
+			input:     "ZADD zset-zadd-incr-nx NX INCR 2 a",
+			expected:  protocol.NewNullBulkString(),
+		},
+		{
+			name:     "ZADD with NX and XX",
+			input:    "ZADD zset-zadd-wrong NX XX 1 a",
+			expected: protocol.NewError(zaddNXXXCompatErrMsg),
+		},
+		{
+			name:     "ZADD with GT and NX",
+			input:    "ZADD zset-zadd-wrong NX GT 1 a",
+			expected: protocol.NewError(zaddGTLTNXCompatErrMsg),
+		},
+		{
+			name:     "ZADD with invalid score",
+			input:    "ZADD zset-zadd-wrong nan a",
+			expected: protocol.NewError(notFloatErrMsg),
+		},
+		{
+			name:      "ZINCRBY",
+			setupCmds: []string{"ZADD zset-zincrby 1 a"},
+			input:     "ZINCRBY zset-zincrby -3 a",
+			expected:  protocol.NewDouble(-2),
+		},
+		{
+			name:      "ZSCORE",
+			setupCmds: []string{board},
+			input:     "ZSCORE zset-board c",
+			expected:  protocol.NewDouble(3),
+		},
+		{
+			name:      "ZMSCORE",
+			setupCmds: []string{board},
+			input:     "ZMSCORE zset-board a x",
+			expected:  protocol.NewArray(protocol.NewDouble(1), protocol.NewNullBulkString()),
+		},
+		{
+			name:      "ZCARD",
+			setupCmds: []string{board},
+			input:     "ZCARD zset-board",
+			expected:  protocol.NewInteger(5),
+		},
+		{
+			name:      "ZRANGE by rank",
+			setupCmds: []string{board},
+			input:     "ZRANGE zset-board 1 -2",
+			expected:  bulkStringArray("b", "c", "d"),
+		},
+		{
+			name:      "ZRANGE by rank reversed",
+			setupCmds: []string{board},
+			input:     "ZRANGE zset-board 0 1 REV",
+			expected:  bulkStringArray("e", "d"),
+		},
+		{
+			name:      "ZREVRANGE",
+			setupCmds: []string{board},
+			input:     "ZREVRANGE zset-board 3 10",
+			expected:  bulkStringArray("b", "a"),
+		},
+		{
+			name:      "ZRANGE BYSCORE",
+			setupCmds: []string{board},
+			input:     "ZRANGE zset-board (2 +inf BYSCORE LIMIT 1 2",
+			expected:  bulkStringArray("d", "e"),
+		},
+		{
+			name:      "ZRANGE BYSCORE REV",
+			setupCmds: []string{board},
+			input:     "ZRANGE zset-board 4 -inf BYSCORE REV LIMIT 1 2 WITHSCORES",
+			expected:  membersWithScores("c", 3.0, "b", 2.0),
+		},
+		{
+			name:      "ZRANGEBYSCORE",
+			setupCmds: []string{board},
+			input:     "ZRANGEBYSCORE zset-board 2 (4",
+			expected:  bulkStringArray("b", "c"),
+		},
+		{
+			name:      "ZREVRANGEBYSCORE",
+			setupCmds: []string{board},
+			input:     "ZREVRANGEBYSCORE zset-board +inf 4",
+			expected:  bulkStringArray("e", "d"),
+		},
+		{
+			name:      "ZRANGE BYLEX",
+			setupCmds: []string{lex},
+			input:     "ZRANGE zset-lex [b (e BYLEX",
+			expected:  bulkStringArray("b", "c", "d"),
+		},
+		{
+			name:      "ZREVRANGEBYLEX",
+			setupCmds: []string{lex},
+			input:     "ZREVRANGEBYLEX zset-lex + (c LIMIT 0 1",
+			expected:  bulkStringArray("e"),
+		},
+		{
+			name:      "ZRANGE with LIMIT by rank",
+			setupCmds: []string{board},
+			input:     "ZRANGE zset-board 0 -1 LIMIT 0 1",
+			expected:  protocol.NewError(zrangeLimitErrMsg),
+		},
+		{
+			name:     "ZRANGE with invalid score range",
+			input:    "ZRANGE zset-missing a 1 BYSCORE",
+			expected: protocol.NewError(zrangeScoreRangeErrMsg),
+		},
+		{
+			name:     "ZRANGE with invalid lex range",
+			input:    "ZRANGE zset-missing a b BYLEX",
+			expected: protocol.NewError(zrangeLexRangeErrMsg),
+		},
+		{
+			name:      "ZRANGESTORE",
+			setupCmds: []string{board, "ZRANGESTORE zset-rangestore zset-board 3 5 BYSCORE"},
+			input:     "ZRANGE zset-rangestore 0 -1",
+			expected:  bulkStringArray("c", "d", "e"),
+		},
+		{
+			name:      "ZRANK",
+			setupCmds: []string{board},
+			input:     "ZRANK zset-board c",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "ZREVRANK WITHSCORE",
+			setupCmds: []string{board},
+			input:     "ZREVRANK zset-board d WITHSCORE",
+			expected:  protocol.NewArray(protocol.NewInteger(1), protocol.NewDouble(4)),
+		},
+		{
+			name:      "ZRANK missing member",
+			setupCmds: []string{board},
+			input:     "ZRANK zset-board x",
+			expected:  protocol.NewNullBulkString(),
+		},
+		{
+			name:      "ZCOUNT",
+			setupCmds: []string{board},
+			input:     "ZCOUNT zset-board (1 3",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "ZLEXCOUNT",
+			setupCmds: []string{lex},
+			input:     "ZLEXCOUNT zset-lex - [c",
+			expected:  protocol.NewInteger(3),
+		},
+		{
+			name:      "ZPOPMIN",
+			setupCmds: []string{"ZADD zset-popmin 1 a 2 b 3 c"},
+			input:     "ZPOPMIN zset-popmin 2",
+			expected:  membersWithScores("a", 1.0, "b", 2.0),
+		},
+		{
+			name:      "ZPOPMAX removes empty sorted set",
+			setupCmds: []string{"ZADD zset-popmax 1 a", "ZPOPMAX zset-popmax"},
+			input:     "EXISTS zset-popmax",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "ZREM",
+			setupCmds: []string{"ZADD zset-zrem 1 a 2 b"},
+			input:     "ZREM zset-zrem a x",
+			expected:  protocol.NewInteger(1),
+		},
+		{
+			name:      "ZREMRANGEBYRANK",
+			setupCmds: []string{"ZADD zset-remrank 1 a 2 b 3 c 4 d", "ZREMRANGEBYRANK zset-remrank 0 1"},
+			input:     "ZRANGE zset-remrank 0 -1",
+			expected:  bulkStringArray("c", "d"),
+		},
+		{
+			name:      "ZREMRANGEBYSCORE",
+			setupCmds: []string{"ZADD zset-remscore 1 a 2 b 3 c 4 d"},
+			input:     "ZREMRANGEBYSCORE zset-remscore (1 3",
+			expected:  protocol.NewInteger(2),
+		},
+		{
+			name:      "ZREMRANGEBYLEX",
+			setupCmds: []string{"ZADD zset-remlex 0 a 0 b 0 c", "ZREMRANGEBYLEX zset-remlex - +"},
+			input:     "EXISTS zset-remlex",
+			expected:  protocol.NewInteger(0),
+		},
+		{
+			name:      "ZUNIONSTORE with WEIGHTS",
+			setupCmds: []string{"ZADD zset-union-a 1 a 2 b", "ZADD zset-union-b 3 b 4 c", "ZUNIONSTORE zset-union 2 zset-union-a zset-union-b WEIGHTS 2 1"},
+			input:     "ZRANGE zset-union 0 -1 WITHSCORES",
+			expected:  membersWithScores("a", 2.0, "c", 4.0, "b", 7.0),
+		},
+		{
+			name:      "ZINTERSTORE with AGGREGATE",
+			setupCmds: []string{"ZADD zset-inter-a 1 a 2 b", "ZADD zset-inter-b 3 b 4 c", "ZINTERSTORE zset-inter 2 zset-inter-a zset-inter-b AGGREGATE MAX"},
+			input:     "ZRANGE zset-inter 0 -1 WITHSCORES",
+			expected:  membersWithScores("b", 3.0),
+		},
+		{
+			name:      "ZUNIONSTORE with a set",
+			setupCmds: []string{"ZADD zset-union-set-a 5 a", "SADD zset-union-set-b a b", "ZUNIONSTORE zset-union-set 2 zset-union-set-a zset-union-set-b"},
+			input:     "ZRANGE zset-union-set 0 -1 WITHSCORES",
+			expected:  membersWithScores("b", 1.0, "a", 6.0),
+		},
+		{
+			name:     "ZUNIONSTORE with no keys",
+			input:    "ZUNIONSTORE zset-union-none 0 zset-board",
+			expected: protocol.NewError("at least 1 input key is needed for 'zunionstore' command"),
+		},
+		{
+			name:      "Sorted set command on a string",
+			setupCmds: []string{"SET zset-string value"},
+			input:     "ZADD zset-string 1 a",
+			expected:  wrongType,
+		},
+	}
+	for _, tc := range ztcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, cmd := range tc.setupCmds {
+				processInline(t, cmd)
+			}
+			actual := processInline(t, tc.input)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestScoresAreNestedForRESP3(t *testing.T) {
+	client := NewClient()
+	processClientInline(t, client, "HELLO 3")
+	processClientInline(t, client, "ZADD zset-resp3 1 a 2 b 3 c")
+	pair := func(member string, score float64) protocol.Array {
+		return protocol.NewArray(bulkString(member), protocol.NewDouble(score))
+	}
+	if reply := processClientInline(t, client, "ZRANGE zset-resp3 0 1 WITHSCORES"); !reflect.DeepEqual(reply, protocol.NewArray(pair("a", 1), pair("b", 2))) {
+		t.Fatalf("unexpected reply to ZRANGE: %v", reply)
+	}
+	if reply := processClientInline(t, client, "ZPOPMAX zset-resp3 1"); !reflect.DeepEqual(reply, protocol.NewArray(pair("c", 3))) {
+		t.Fatalf("unexpected reply to ZPOPMAX with a count: %v", reply)
+	}
+	if reply := processClientInline(t, client, "ZPOPMIN zset-resp3"); !reflect.DeepEqual(reply, protocol.NewArray(bulkString("a"), protocol.NewDouble(1))) {
+		t.Fatalf("unexpected reply to ZPOPMIN: %v", reply)
+	}
+}
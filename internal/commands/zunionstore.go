@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/mhsantos/redis-server/internal/datastore"
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+const (
+	zsetOperationInvalidLengthErrMsg string = "invalid arguments for command %s. Syntax: %s destination numkeys key [key ...] [WEIGHTS weight [weight ...]] [AGGREGATE SUM | MIN | MAX]"
+	zsetOperationNumkeysErrMsg       string = "at least 1 input key is needed for '%s' command"
+	weightNotFloatErrMsg             string = "weight value is not a float"
+)
+
+func init() {
+	registerCommand(zsetOperationCommand{name: "zunionstore", union: true})
+	registerCommand(zsetOperationCommand{name: "zinterstore"})
+}
+
+// zsetOperationCommand implements ZUNIONSTORE and ZINTERSTORE. The scores of the
+// members in more than one input are combined with the AGGREGATE function after being
+// multiplied by the weight of their input. Sets are accepted as inputs, with all the
+// members scored 1.
+type zsetOperationCommand struct {
+	name  string
+	union bool
+}
+
+func (z zsetOperationCommand) getName() string {
+	return z.name
+}
+
+func (z zsetOperationCommand) processArguments(data protocol.Array) protocol.DataType {
+	elements := data.GetElements()
+	if len(elements) < 4 {
+		return z.syntaxError()
+	}
+	numkeys, ok := parseInt(elements[2])
+	if !ok {
+		return protocol.NewError(notIntegerErrMsg)
+	}
+	if numkeys <= 0 {
+		return protocol.NewError(fmt.Sprintf(zsetOperationNumkeysErrMsg, z.name))
+	}
+	if numkeys > len(elements)-3 {
+		return z.syntaxError()
+	}
+	keys := elements[3 : 3+numkeys]
+	weights := make([]float64, numkeys)
+	for i := range weights {
+		weights[i] = 1
+	}
+	aggregate := "SUM"
+	options := elements[3+numkeys:]
+	for i := 0; i < len(options); i++ {
+		switch strings.ToUpper(options[i].String()) {
+		case "WEIGHTS":
+			if i+numkeys >= len(options) {
+				return z.syntaxError()
+			}
+			for j := range weights {
+				weight, ok := parseScore(options[i+1+j])
+				if !ok {
+					return protocol.NewError(weightNotFloatErrMsg)
+				}
+				weights[j] = weight
+			}
+			i += numkeys
+		case "AGGREGATE":
+			if i+1 == len(options) {
+				return z.syntaxError()
+			}
+			aggregate = strings.ToUpper(options[i+1].String())
+			if aggregate != "SUM" && aggregate != "MIN" && aggregate != "MAX" {
+				return z.syntaxError()
+			}
+			i++
+		default:
+			return z.syntaxError()
+		}
+	}
+	inputs := []map[string]float64{}
+	for i, key := range keys {
+		scores, err := weightedScores(key.String(), weights[i])
+		if err != nil {
+			return errorReply(err)
+		}
+		inputs = append(inputs, scores)
+	}
+	result := datastore.NewSortedSet()
+	for member, score := range combineScores(inputs, aggregate, z.union) {
+		result.Add(member, score)
+	}
+	datastore.StoreSortedSet(elements[1].String(), result)
+	return protocol.NewInteger(result.Len())
+}
+
+func (z zsetOperationCommand) syntaxError() protocol.DataType {
+	name := strings.ToUpper(z.name)
+	return protocol.NewError(fmt.Sprintf(zsetOperationInvalidLengthErrMsg, name, name))
+}
+
+// weightedScores returns the scores of the members of the sorted set or set stored in
+// the key multiplied by the weight. A missing key has no members.
+func weightedScores(key string, weight float64) (map[string]float64, error) {
+	scores := make(map[string]float64)
+	zset, ok, err := datastore.GetSortedSet(key)
+	if errors.Is(err, datastore.ErrWrongType) {
+		set, _, err := datastore.GetSet(key)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range set.Members() {
+			scores[member] = weight
+		}
+		return scores, nil
+	}
+	if ok {
+		for _, entry := range zset.Range(0, zset.Len()) {
+			scores[entry.Member] = multiplyScore(entry.Score, weight)
+		}
+	}
+	return scores, nil
+}
+
+// combineScores returns the members in any of the inputs, for unions, or in all of
+// them, for intersections, with their scores aggregated.
+func combineScores(inputs []map[string]float64, aggregate string, union bool) map[string]float64 {
+	result := make(map[string]float64)
+	for i, input := range inputs {
+		for member, score := range input {
+			if _, done := result[member]; done {
+				continue
+			}
+			if !union && i > 0 {
+				// Every member of the intersection is in the first input
+				break
+			}
+			inAll := true
+			for _, other := range inputs[i+1:] {
+				otherScore, ok := other[member]
+				if !ok {
+					inAll = false
+					continue
+				}
+				score = aggregateScores(score, otherScore, aggregate)
+			}
+			if union || inAll {
+				result[member] = score
+			}
+		}
+	}
+	return result
+}
+
+func aggregateScores(a, b float64, aggregate string) float64 {
+	switch aggregate {
+	case "MIN":
+		return math.Min(a, b)
+	case "MAX":
+		return math.Max(a, b)
+	}
+	sum := a + b
+	// Like Redis, adding infinities of opposite signs results in 0 instead of NaN
+	if math.IsNaN(sum) {
+		return 0
+	}
+	return sum
+}
+
+// multiplyScore multiplies the score by the weight, resulting in 0 instead of NaN like
+// Redis does for infinite scores multiplied by 0.
+func multiplyScore(score, weight float64) float64 {
+	result := score * weight
+	if math.IsNaN(result) {
+		return 0
+	}
+	return result
+}
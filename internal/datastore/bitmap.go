@@ -0,0 +1,220 @@
+package datastore
+
+import (
+	"math/bits"
+)
+
+// The bitmap operations work on the bytes of a string. Like in Redis, bit 0 is the most
+// significant bit of the first byte, and the string is taken as padded with zero bytes
+// past its end, so reading past it returns zeros and writing past it grows the string.
+
+// BitOperation is one of the operations of BITOP.
+type BitOperation int
+
+const (
+	BitAnd BitOperation = iota
+	BitOr
+	BitXor
+	BitNot
+	// BitDiff sets the bits set in the first bitmap and in none of the others
+	BitDiff
+)
+
+// OverflowPolicy is how BITFIELD handles values that don't fit in their type.
+type OverflowPolicy int
+
+const (
+	// OverflowWrap keeps the lowest bits of the value, like integer arithmetic in Go
+	OverflowWrap OverflowPolicy = iota
+	// OverflowSat saturates the value to the lowest or highest value of the type
+	OverflowSat
+	// OverflowFail discards the operation
+	OverflowFail
+)
+
+// BitfieldType is the type of an integer stored in a bitmap, like i5 or u8. Signed
+// types are up to 64 bits wide and unsigned types up to 63, so every value fits in an
+// int64.
+type BitfieldType struct {
+	Signed bool
+	Width  int
+}
+
+// GetBit returns the bit at the offset.
+func GetBit(bitmap []byte, offset int) int {
+	i := offset / 8
+	if i >= len(bitmap) {
+		return 0
+	}
+	return int(bitmap[i]>>(7-offset%8)) & 1
+}
+
+// SetBit sets the bit at the offset to the value, 0 or 1, growing the bitmap if needed.
+// It returns the bitmap and the previous value of the bit.
+func SetBit(bitmap []byte, offset int, value int) ([]byte, int) {
+	bitmap = grow(bitmap, offset/8+1)
+	previous := GetBit(bitmap, offset)
+	mask := byte(1) << (7 - offset%8)
+	if value == 1 {
+		bitmap[offset/8] |= mask
+	} else {
+		bitmap[offset/8] &^= mask
+	}
+	return bitmap, previous
+}
+
+// BitCount returns how many bits are set from the bit offset start, inclusive, to end,
+// exclusive.
+func BitCount(bitmap []byte, start, end int) int {
+	end = min(end, len(bitmap)*8)
+	count := 0
+	for offset := start; offset < end; {
+		// Whole bytes are counted at once
+		if offset%8 == 0 && offset+8 <= end {
+			count += bits.OnesCount8(bitmap[offset/8])
+			offset += 8
+			continue
+		}
+		count += GetBit(bitmap, offset)
+		offset++
+	}
+	return count
+}
+
+// BitPos returns the offset of the first bit with the value, 0 or 1, from the bit offset
+// start, inclusive, to end, exclusive, or -1 if there's none.
+func BitPos(bitmap []byte, value int, start, end int) int {
+	end = min(end, len(bitmap)*8)
+	// Bytes with all the bits different from the value are skipped at once
+	skip := byte(0)
+	if value == 0 {
+		skip = 0xff
+	}
+	for offset := start; offset < end; {
+		if offset%8 == 0 && offset+8 <= end && bitmap[offset/8] == skip {
+			offset += 8
+			continue
+		}
+		if GetBit(bitmap, offset) == value {
+			return offset
+		}
+		offset++
+	}
+	return -1
+}
+
+// BitOp applies the operation to the bitmaps, returning the result, which is as long as
+// the longest of them. BitNot takes a single bitmap and BitDiff at least two.
+func BitOp(operation BitOperation, bitmaps [][]byte) []byte {
+	length := 0
+	for _, bitmap := range bitmaps {
+		length = max(length, len(bitmap))
+	}
+	result := grow(nil, length)
+	for i := range result {
+		first := byteAt(bitmaps[0], i)
+		switch operation {
+		case BitNot:
+			result[i] = ^first
+			continue
+		}
+		value := first
+		others := byte(0)
+		for _, bitmap := range bitmaps[1:] {
+			b := byteAt(bitmap, i)
+			switch operation {
+			case BitAnd:
+				value &= b
+			case BitOr:
+				value |= b
+			case BitXor:
+				value ^= b
+			case BitDiff:
+				others |= b
+			}
+		}
+		if operation == BitDiff {
+			value = first &^ others
+		}
+		result[i] = value
+	}
+	return result
+}
+
+// GetBitfield returns the integer of the type stored at the bit offset.
+func GetBitfield(bitmap []byte, offset int, t BitfieldType) int64 {
+	var value uint64
+	for i := 0; i < t.Width; i++ {
+		value = value<<1 | uint64(GetBit(bitmap, offset+i))
+	}
+	if t.Signed && t.Width < 64 && value&(1<<(t.Width-1)) != 0 {
+		// Sign extension of the negative values
+		value |= ^uint64(0) << t.Width
+	}
+	return int64(value)
+}
+
+// SetBitfield stores the integer with the type at the bit offset, growing the bitmap if
+// needed. Only the lowest bits of the value that fit in the type are stored.
+func SetBitfield(bitmap []byte, offset int, t BitfieldType, value int64) []byte {
+	bitmap = grow(bitmap, (offset+t.Width+7)/8)
+	for i := 0; i < t.Width; i++ {
+		bitmap, _ = SetBit(bitmap, offset+i, int(uint64(value)>>(t.Width-1-i))&1)
+	}
+	return bitmap
+}
+
+// Add returns the result of adding the increment to the value, handling the results
+// that don't fit in the type with the policy. The bool is false if the result doesn't
+// fit and the policy is OverflowFail.
+func (t BitfieldType) Add(value, increment int64, policy OverflowPolicy) (int64, bool) {
+	lowest, highest := t.limits()
+	overflow := increment > 0 && value > highest-increment
+	// lowest-increment is higher than any int64 if it wraps around
+	underflow := increment < 0 && (lowest-increment < lowest || value < lowest-increment)
+	if !overflow && !underflow {
+		return value + increment, true
+	}
+	switch policy {
+	case OverflowSat:
+		if overflow {
+			return highest, true
+		}
+		return lowest, true
+	case OverflowFail:
+		return 0, false
+	}
+	// The sum is computed with unsigned integers, which wrap around, and then the bits
+	// that don't fit in the type are discarded
+	wrapped := uint64(value) + uint64(increment)
+	if t.Width == 64 {
+		return int64(wrapped), true
+	}
+	wrapped &= 1<<t.Width - 1
+	if t.Signed && wrapped&(1<<(t.Width-1)) != 0 {
+		wrapped |= ^uint64(0) << t.Width
+	}
+	return int64(wrapped), true
+}
+
+func (t BitfieldType) limits() (int64, int64) {
+	if t.Signed {
+		return -1 << (t.Width - 1), 1<<(t.Width-1) - 1
+	}
+	return 0, 1<<t.Width - 1
+}
+
+func byteAt(bitmap []byte, i int) byte {
+	if i < len(bitmap) {
+		return bitmap[i]
+	}
+	return 0
+}
+
+// grow returns the bitmap padded with zero bytes to the length, if it's shorter.
+func grow(bitmap []byte, length int) []byte {
+	if len(bitmap) >= length {
+		return bitmap
+	}
+	return append(bitmap, make([]byte, length-len(bitmap))...)
+}
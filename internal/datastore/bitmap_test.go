@@ -0,0 +1,101 @@
+package datastore
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestBitmapBits(t *testing.T) {
+	bitmap, previous := SetBit(nil, 10, 1)
+	if len(bitmap) != 2 || previous != 0 {
+		t.Fatalf("unexpected bitmap %v or previous bit %d", bitmap, previous)
+	}
+	if bitmap[1] != 0b00100000 || GetBit(bitmap, 10) != 1 || GetBit(bitmap, 100) != 0 {
+		t.Fatalf("unexpected bitmap %08b", bitmap)
+	}
+	bitmap, previous = SetBit(bitmap, 10, 0)
+	if previous != 1 || GetBit(bitmap, 10) != 0 {
+		t.Fatalf("the bit should have been cleared")
+	}
+
+	bitmap = []byte{0xff, 0xf0, 0x00}
+	if count := BitCount(bitmap, 0, 24); count != 12 {
+		t.Fatalf("unexpected count %d", count)
+	}
+	if count := BitCount(bitmap, 5, 10); count != 5 {
+		t.Fatalf("unexpected count %d", count)
+	}
+	if pos := BitPos(bitmap, 0, 0, 24); pos != 12 {
+		t.Fatalf("unexpected position %d", pos)
+	}
+	if pos := BitPos(bitmap, 1, 12, 24); pos != -1 {
+		t.Fatalf("unexpected position %d", pos)
+	}
+}
+
+func TestBitOp(t *testing.T) {
+	a, b, c := []byte{0b1100, 0xff}, []byte{0b1010}, []byte{0b0001}
+	tcs := []struct {
+		name      string
+		operation BitOperation
+		bitmaps   [][]byte
+		expected  []byte
+	}{
+		{"AND", BitAnd, [][]byte{a, b}, []byte{0b1000, 0x00}},
+		{"OR", BitOr, [][]byte{a, b, c}, []byte{0b1111, 0xff}},
+		{"XOR", BitXor, [][]byte{a, b}, []byte{0b0110, 0xff}},
+		{"NOT", BitNot, [][]byte{b}, []byte{0b11110101}},
+		{"DIFF", BitDiff, [][]byte{a, b, c}, []byte{0b0100, 0xff}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := BitOp(tc.operation, tc.bitmaps); !slices.Equal(actual, tc.expected) {
+				t.Fatalf("unexpected result. Expected: %08b, Actual: %08b", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestBitfield(t *testing.T) {
+	i5, u4, i64 := BitfieldType{Signed: true, Width: 5}, BitfieldType{Width: 4}, BitfieldType{Signed: true, Width: 64}
+	bitmap := SetBitfield(nil, 3, i5, -3)
+	if got := GetBitfield(bitmap, 3, i5); got != -3 {
+		t.Fatalf("unexpected signed value %d", got)
+	}
+	if got := GetBitfield(bitmap, 3, u4); got != 0b1110 {
+		t.Fatalf("unexpected unsigned value %d", got)
+	}
+	bitmap = SetBitfield(bitmap, 8, i64, math.MinInt64)
+	if got := GetBitfield(bitmap, 8, i64); got != math.MinInt64 {
+		t.Fatalf("unexpected 64 bit value %d", got)
+	}
+
+	tcs := []struct {
+		name      string
+		fieldType BitfieldType
+		value     int64
+		increment int64
+		policy    OverflowPolicy
+		expected  int64
+		ok        bool
+	}{
+		{"signed wrap", i5, 15, 1, OverflowWrap, -16, true},
+		{"signed sat", i5, 15, 100, OverflowSat, 15, true},
+		{"signed fail", i5, -16, -1, OverflowFail, 0, false},
+		{"unsigned wrap", u4, 15, 3, OverflowWrap, 2, true},
+		{"unsigned sat underflow", u4, 1, -5, OverflowSat, 0, true},
+		{"unsigned lowest increment", u4, 1, math.MinInt64, OverflowSat, 0, true},
+		{"64 bit wrap", i64, math.MaxInt64, 1, OverflowWrap, math.MinInt64, true},
+		{"64 bit fail", i64, math.MinInt64, -1, OverflowFail, 0, false},
+		{"no overflow", i5, -10, 5, OverflowFail, -5, true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := tc.fieldType.Add(tc.value, tc.increment, tc.policy)
+			if actual != tc.expected || ok != tc.ok {
+				t.Fatalf("unexpected result. Expected: %d %t, Actual: %d %t", tc.expected, tc.ok, actual, ok)
+			}
+		})
+	}
+}
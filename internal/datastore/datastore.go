@@ -1,6 +1,8 @@
 package datastore
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/mhsantos/redis-server/internal/protocol"
@@ -8,46 +10,180 @@ import (
 
 var (
 	store map[string]Value = make(map[string]Value)
+	// expires holds the keys of store that have an expiration set, so the active expire
+	// cycle only needs to sample keys that can actually expire.
+	expires map[string]struct{} = make(map[string]struct{})
 )
 
+// ErrWrongType is returned when a key is accessed as a type different from the one it
+// holds, for example reading a list as a string.
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
+// ErrNotInteger is returned when a string is read as an integer but it doesn't hold a
+// 64 bit integer.
+var ErrNotInteger = errors.New("value is not an integer or out of range")
+
+// Value is a value stored in the datastore. Strings are stored as an int64 when they
+// hold an integer, the int encoding, or as a protocol.DataType otherwise. The other
+// types are stored as a pointer to their structure, like *List or *Hash, with kind
+// telling which one it is. The expire is the Unix time in milliseconds the value
+// expires at, or 0 if it never expires. The version changes on every write to the key,
+// see KeyVersion.
 type Value struct {
-	value  protocol.DataType
-	expire int64
+	value   any
+	kind    ObjectType
+	expire  int64
+	version uint64
 }
 
-func Get(key string) (protocol.DataType, bool) {
+// lookup returns the value stored in the key, removing it if it's expired. Every access
+// to a key goes through it, so it also writes the key to the background save in
+// progress before the caller can modify it.
+func lookup(key string) (Value, bool) {
+	snapshotKey(key)
 	val, ok := store[key]
 	if !ok {
-		return nil, false
+		return Value{}, false
 	}
 	if val.IsExpired() {
-		delete(store, key)
-		return nil, false
+		expireKey(key)
+		stats.LazyExpiredKeys++
+		return Value{}, false
+	}
+	return val, true
+}
+
+// Get returns the string stored in the key. It returns ErrWrongType if the key holds
+// a value of another type.
+func Get(key string) (protocol.DataType, bool, error) {
+	val, ok, err := lookupType(key, TypeString)
+	if !ok {
+		return nil, false, err
+	}
+	if number, ok := val.value.(int64); ok {
+		return protocol.NewBulkString([]byte(strconv.FormatInt(number, 10))), true, nil
+	}
+	return val.value.(protocol.DataType), true, nil
+}
+
+// GetInteger returns the string stored in the key as an integer. It returns
+// ErrNotInteger if the string isn't a 64 bit integer, and ErrWrongType if the key holds
+// a value of another type.
+func GetInteger(key string) (int64, bool, error) {
+	val, ok, err := lookupType(key, TypeString)
+	if !ok {
+		return 0, false, err
+	}
+	if number, ok := val.value.(int64); ok {
+		return number, true, nil
+	}
+	number, err := strconv.ParseInt(val.value.(protocol.DataType).String(), 10, 64)
+	if err != nil {
+		return 0, false, ErrNotInteger
 	}
-	return val.value, ok
+	return number, true, nil
 }
 
+// SetInteger stores the integer in the key keeping its expiration, if any, like
+// SetKeepTTL.
+func SetInteger(key string, number int64) {
+	setKeepTTL(key, number)
+}
+
+// Set stores the value in the key, discarding any expiration previously set for it.
 func Set(key string, value protocol.DataType) {
+	snapshotKey(key)
 	val := Value{
-		value: value,
+		value:   stringValue(value),
+		kind:    TypeString,
+		version: nextVersion(),
 	}
 	store[key] = val
+	delete(expires, key)
+}
+
+// SetKeepTTL stores the string in the key keeping its expiration, if any. It's used by
+// commands that modify a string, like INCR, which unlike SET don't discard the time to
+// live of the key.
+func SetKeepTTL(key string, value protocol.DataType) {
+	setKeepTTL(key, stringValue(value))
+}
+
+func setKeepTTL(key string, value any) {
+	val, _ := lookup(key)
+	if val.expire == 0 {
+		delete(expires, key)
+	}
+	store[key] = Value{
+		value:   value,
+		kind:    TypeString,
+		expire:  val.expire,
+		version: nextVersion(),
+	}
 }
 
+// Delete removes the key, whatever the type of its value, returning whether it existed.
 func Delete(key string) bool {
-	if _, ok := Get(key); ok {
+	if _, ok := lookup(key); ok {
 		delete(store, key)
+		delete(expires, key)
+		dirty++
+		keyDeleted(key)
 		return true
 	}
 	return false
 }
 
+// Exists tells if the key holds a value of any type.
+func Exists(key string) bool {
+	_, ok := lookup(key)
+	return ok
+}
+
+// SetWithExpire stores the value in the key with the Unix time in milliseconds it expires
+// at. An expire of 0 means the key never expires.
 func SetWithExpire(key string, value protocol.DataType, expire int64) {
+	snapshotKey(key)
 	val := Value{
-		value:  value,
-		expire: expire,
+		value:   stringValue(value),
+		kind:    TypeString,
+		expire:  expire,
+		version: nextVersion(),
 	}
 	store[key] = val
+	if expire > 0 {
+		expires[key] = struct{}{}
+	} else {
+		delete(expires, key)
+	}
+}
+
+// GetExpire returns the Unix time in milliseconds the key expires at, or 0 if it has no
+// expiration. The bool is false if the key doesn't exist.
+func GetExpire(key string) (int64, bool) {
+	val, ok := lookup(key)
+	if !ok {
+		return 0, false
+	}
+	return val.expire, true
+}
+
+// SetExpire changes the expiration of an existing key, whatever the type of its value.
+// An expire of 0 removes the expiration. It returns false if the key doesn't exist.
+func SetExpire(key string, expire int64) bool {
+	val, ok := lookup(key)
+	if !ok {
+		return false
+	}
+	val.expire = expire
+	val.version = nextVersion()
+	store[key] = val
+	if expire > 0 {
+		expires[key] = struct{}{}
+	} else {
+		delete(expires, key)
+	}
+	return true
 }
 
 func (v Value) IsExpireSet() bool {
@@ -58,17 +194,23 @@ func (v Value) IsExpired() bool {
 	if v.expire == 0 {
 		return false
 	}
-	return time.Now().Unix() > v.expire
+	return time.Now().UnixMilli() > v.expire
 }
 
-func GetWithExpire(key string) (protocol.DataType, int64, bool) {
-	val, ok := store[key]
-	if !ok {
-		return nil, 0, false
+// stringValue returns how the string is stored: as an int64 if it's the canonical
+// representation of an integer, so counters aren't parsed on every increment, or as is
+// otherwise.
+func stringValue(value protocol.DataType) any {
+	if number, ok := parseInteger(value.String()); ok {
+		return number
 	}
-	if val.IsExpired() {
-		delete(store, key)
-		return nil, 0, false
-	}
-	return val.value, val.expire, true
+	return value
+}
+
+func expireKey(key string) {
+	snapshotKey(key)
+	delete(store, key)
+	delete(expires, key)
+	dirty++
+	keyDeleted(key)
 }
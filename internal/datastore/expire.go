@@ -0,0 +1,84 @@
+package datastore
+
+import (
+	"time"
+)
+
+const (
+	// activeExpireSampleSize is the number of keys with an expiration sampled on each
+	// iteration of the active expire cycle.
+	activeExpireSampleSize = 20
+	// activeExpireAcceptableStale is the percentage of expired keys in a sample below
+	// which the cycle stops, since most of the remaining keys are likely still valid.
+	activeExpireAcceptableStale = 10
+	// activeExpireTimeCheckInterval is how many iterations run between checks of the
+	// time budget, so the cycle doesn't call time.Now on every iteration.
+	activeExpireTimeCheckInterval = 16
+)
+
+// ExpireStats holds counters about the keys removed from the datastore because their
+// time to live was over.
+type ExpireStats struct {
+	// ActiveExpiredKeys is the number of keys removed by the active expire cycle.
+	ActiveExpiredKeys int64
+	// LazyExpiredKeys is the number of keys removed when they were accessed.
+	LazyExpiredKeys int64
+	// Cycles is the number of times the active expire cycle ran.
+	Cycles int64
+	// TimeLimitReached is the number of cycles stopped because they used all their time
+	// budget, which means there are more expired keys than the cycle can keep up with.
+	TimeLimitReached int64
+	// CycleTime is the time spent running the active expire cycle.
+	CycleTime time.Duration
+}
+
+var stats ExpireStats
+
+// Stats returns the expiration counters. Like the rest of the datastore, it must be
+// called from the task loop.
+func Stats() ExpireStats {
+	return stats
+}
+
+// ActiveExpireCycle removes expired keys that were never accessed again, which the lazy
+// expiration on Get would keep in memory forever. It follows the Redis algorithm: it
+// samples keys with an expiration, removes the expired ones and repeats while more than
+// activeExpireAcceptableStale percent of the sample was expired, until the time budget
+// is used. It returns the number of keys removed.
+//
+// The datastore isn't safe for concurrent use, so this must be called from the task
+// loop, between the execution of two commands.
+func ActiveExpireCycle(budget time.Duration) int {
+	start := time.Now()
+	stats.Cycles++
+	removed := 0
+	for iteration := 1; ; iteration++ {
+		if len(expires) == 0 {
+			break
+		}
+		sampled, expired := 0, 0
+		now := time.Now().UnixMilli()
+		// Map iteration starts at a random position, which makes this a random sample
+		for key := range expires {
+			if sampled == activeExpireSampleSize {
+				break
+			}
+			sampled++
+			if val := store[key]; val.expire > 0 && now > val.expire {
+				expireKey(key)
+				expired++
+			}
+		}
+		removed += expired
+		if expired*100/sampled <= activeExpireAcceptableStale {
+			break
+		}
+		if iteration%activeExpireTimeCheckInterval == 0 && time.Since(start) > budget {
+			stats.TimeLimitReached++
+			break
+		}
+	}
+	stats.ActiveExpiredKeys += int64(removed)
+	stats.CycleTime += time.Since(start)
+	return removed
+}
@@ -0,0 +1,55 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func TestActiveExpireCycle(t *testing.T) {
+	past := time.Now().Add(-time.Second).UnixMilli()
+	future := time.Now().Add(time.Hour).UnixMilli()
+	for i := 0; i < 500; i++ {
+		SetWithExpire(fmt.Sprintf("expired-%d", i), protocol.NewSimpleString("value"), past)
+	}
+	for i := 0; i < 10; i++ {
+		SetWithExpire(fmt.Sprintf("valid-%d", i), protocol.NewSimpleString("value"), future)
+		Set(fmt.Sprintf("persistent-%d", i), protocol.NewSimpleString("value"))
+	}
+	before := Stats()
+
+	removed := 0
+	for i := 0; i < 10 && len(expires) > 10; i++ {
+		removed += ActiveExpireCycle(time.Second)
+	}
+
+	if removed != 500 {
+		t.Fatalf("unexpected number of removed keys. Expected: 500, Actual: %d", removed)
+	}
+	if len(store) != 20 || len(expires) != 10 {
+		t.Fatalf("unexpected number of remaining keys. Store: %d, expires: %d", len(store), len(expires))
+	}
+	after := Stats()
+	if after.ActiveExpiredKeys-before.ActiveExpiredKeys != 500 {
+		t.Fatalf("unexpected stats %+v", after)
+	}
+}
+
+func TestExpiresTracking(t *testing.T) {
+	future := time.Now().Add(time.Hour).UnixMilli()
+	SetWithExpire("tracked", protocol.NewSimpleString("value"), future)
+	if _, ok := expires["tracked"]; !ok {
+		t.Fatalf("key with expiration should be tracked")
+	}
+	Set("tracked", protocol.NewSimpleString("other"))
+	if _, ok := expires["tracked"]; ok {
+		t.Fatalf("key without expiration shouldn't be tracked")
+	}
+	SetWithExpire("tracked", protocol.NewSimpleString("value"), future)
+	Delete("tracked")
+	if _, ok := expires["tracked"]; ok {
+		t.Fatalf("deleted key shouldn't be tracked")
+	}
+}
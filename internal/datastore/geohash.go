@@ -0,0 +1,243 @@
+package datastore
+
+import (
+	"math"
+)
+
+// The geo commands store each location in a sorted set, with a 52 bit geohash of its
+// longitude and latitude as the score, like Redis. A geohash interleaves the bits of the
+// longitude and latitude, so the locations in the same area of the map have scores in
+// the same range, and a search only needs to read a few ranges of the sorted set.
+const (
+	GeoLongitudeMin = -180
+	GeoLongitudeMax = 180
+	// The latitudes are limited to the ones the Web Mercator projection covers
+	GeoLatitudeMin = -85.05112878
+	GeoLatitudeMax = 85.05112878
+	// GeoStepMax is how many bits of the longitude and of the latitude a geohash has
+	GeoStepMax = 26
+	// earthRadius is the radius of the Earth in meters used by Redis
+	earthRadius = 6372797.560856
+	// mercatorMax is half of the circumference of the Earth in the Web Mercator
+	// projection, in meters
+	mercatorMax = 20037726.37
+)
+
+// geohashAlphabet is the alphabet of the standard string representation of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeoPoint is a location given by its longitude and latitude in degrees.
+type GeoPoint struct {
+	Longitude, Latitude float64
+}
+
+// GeoShape is the area of a search: a circle with the radius, or a box with the width
+// and height if Width is set, centered in the point. The sizes are in meters.
+type GeoShape struct {
+	Center        GeoPoint
+	Radius        float64
+	Width, Height float64
+}
+
+// ValidGeoPoint tells if the point can be encoded as a geohash.
+func ValidGeoPoint(point GeoPoint) bool {
+	return point.Longitude >= GeoLongitudeMin && point.Longitude <= GeoLongitudeMax &&
+		point.Latitude >= GeoLatitudeMin && point.Latitude <= GeoLatitudeMax
+}
+
+// GeohashEncode returns the 52 bit geohash of the point, used as its score.
+func GeohashEncode(point GeoPoint) uint64 {
+	return geohashEncode(point, GeoLatitudeMin, GeoLatitudeMax, GeoStepMax)
+}
+
+// GeohashDecode returns the point at the center of the area of the geohash.
+func GeohashDecode(hash uint64) GeoPoint {
+	lonIndex, latIndex := deinterleave(hash)
+	lonMin, lonMax := cellRange(lonIndex, GeoLongitudeMin, GeoLongitudeMax, GeoStepMax)
+	latMin, latMax := cellRange(latIndex, GeoLatitudeMin, GeoLatitudeMax, GeoStepMax)
+	return GeoPoint{
+		Longitude: min(max((lonMin+lonMax)/2, GeoLongitudeMin), GeoLongitudeMax),
+		Latitude:  min(max((latMin+latMax)/2, GeoLatitudeMin), GeoLatitudeMax),
+	}
+}
+
+// GeohashString returns the standard 11 characters representation of the point, which
+// unlike the scores uses latitudes from -90 to 90.
+func GeohashString(point GeoPoint) string {
+	hash := geohashEncode(point, -90, 90, GeoStepMax)
+	buffer := make([]byte, 11)
+	for i := range buffer {
+		// The last character only has 2 bits of the hash, so like Redis it's always 0
+		index := 0
+		if i < 10 {
+			index = int(hash>>(52-(i+1)*5)) & 0x1f
+		}
+		buffer[i] = geohashAlphabet[index]
+	}
+	return string(buffer)
+}
+
+// GeoDistance returns the distance in meters between the points, using the haversine
+// formula.
+func GeoDistance(a, b GeoPoint) float64 {
+	lat1, lat2 := degreesToRadians(a.Latitude), degreesToRadians(b.Latitude)
+	u := math.Sin((lat2 - lat1) / 2)
+	v := math.Sin(degreesToRadians(b.Longitude-a.Longitude) / 2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1)*math.Cos(lat2)*v*v))
+}
+
+// Contains tells if the point is in the shape, returning its distance to the center.
+func (s GeoShape) Contains(point GeoPoint) (float64, bool) {
+	if s.Width == 0 {
+		distance := GeoDistance(s.Center, point)
+		return distance, distance <= s.Radius
+	}
+	// The latitude distance is cheaper, so it's checked first
+	latDistance := 2 * earthRadius * math.Abs(math.Sin(degreesToRadians(point.Latitude-s.Center.Latitude)/2))
+	if latDistance > s.Height/2 {
+		return 0, false
+	}
+	lonDistance := GeoDistance(GeoPoint{s.Center.Longitude, point.Latitude}, point)
+	if lonDistance > s.Width/2 {
+		return 0, false
+	}
+	return GeoDistance(s.Center, point), true
+}
+
+// ScoreRanges returns the ranges of scores, from min, inclusive, to max, exclusive,
+// holding all the points that can be in the shape: the area of the geohash of the
+// center and its 8 neighbors, with the largest precision that still covers the shape.
+// The points in the ranges still need to be checked with Contains.
+func (s GeoShape) ScoreRanges() [][2]uint64 {
+	radius, height, width := s.Radius, s.Radius*2, s.Radius*2
+	if s.Width != 0 {
+		height, width = s.Height, s.Width
+		radius = math.Sqrt(width*width+height*height) / 2
+	}
+	minLon, maxLon, minLat, maxLat := s.boundingBox(width, height)
+	step := estimateSteps(radius, s.Center.Latitude)
+	for ; step > 1; step-- {
+		cellLon := float64(GeoLongitudeMax-GeoLongitudeMin) / float64(uint64(1)<<step)
+		cellLat := (GeoLatitudeMax - GeoLatitudeMin) / float64(uint64(1)<<step)
+		lonIndex, latIndex := deinterleave(geohashEncode(s.Center, GeoLatitudeMin, GeoLatitudeMax, step))
+		cellMinLon, _ := cellRange(lonIndex, GeoLongitudeMin, GeoLongitudeMax, step)
+		cellMinLat, _ := cellRange(latIndex, GeoLatitudeMin, GeoLatitudeMax, step)
+		// The neighbors cover one more cell in every direction
+		if minLon >= cellMinLon-cellLon && maxLon <= cellMinLon+2*cellLon &&
+			minLat >= cellMinLat-cellLat && maxLat <= cellMinLat+2*cellLat {
+			break
+		}
+	}
+	lonIndex, latIndex := deinterleave(geohashEncode(s.Center, GeoLatitudeMin, GeoLatitudeMax, step))
+	cells := uint64(1) << step
+	shift := 2 * (GeoStepMax - step)
+	seen := make(map[uint64]bool)
+	ranges := [][2]uint64{}
+	for _, dLat := range []int{-1, 0, 1} {
+		lat := int64(latIndex) + int64(dLat)
+		if lat < 0 || lat >= int64(cells) {
+			continue
+		}
+		for _, dLon := range []int{-1, 0, 1} {
+			// Longitudes wrap around the antimeridian
+			lon := (int64(lonIndex) + int64(dLon) + int64(cells)) % int64(cells)
+			hash := interleave(uint64(lon), uint64(lat))
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			ranges = append(ranges, [2]uint64{hash << shift, (hash + 1) << shift})
+		}
+	}
+	return ranges
+}
+
+// boundingBox returns the longitudes and latitudes enclosing a box of the width and
+// height centered in the center of the shape.
+func (s GeoShape) boundingBox(width, height float64) (float64, float64, float64, float64) {
+	lat := s.Center.Latitude
+	latDelta := radiansToDegrees(height / 2 / earthRadius)
+	lonDeltaTop := radiansToDegrees(width / 2 / earthRadius / math.Cos(degreesToRadians(lat+latDelta)))
+	lonDeltaBottom := radiansToDegrees(width / 2 / earthRadius / math.Cos(degreesToRadians(lat-latDelta)))
+	lonDelta := max(lonDeltaTop, lonDeltaBottom)
+	return s.Center.Longitude - lonDelta, s.Center.Longitude + lonDelta, lat - latDelta, lat + latDelta
+}
+
+// estimateSteps returns the precision of the geohashes whose areas are about the size
+// of the radius, the same estimate as Redis.
+func estimateSteps(radius, latitude float64) int {
+	if radius == 0 {
+		return GeoStepMax
+	}
+	step := 1
+	for radius < mercatorMax {
+		radius *= 2
+		step++
+	}
+	step -= 2
+	// The areas get narrower towards the poles
+	if latitude > 66 || latitude < -66 {
+		step--
+		if latitude > 80 || latitude < -80 {
+			step--
+		}
+	}
+	return min(max(step, 1), GeoStepMax)
+}
+
+// geohashEncode returns the geohash of the point with step bits for the longitude and
+// for the latitude, with the latitudes from latMin to latMax.
+func geohashEncode(point GeoPoint, latMin, latMax float64, step int) uint64 {
+	cells := float64(uint64(1) << step)
+	lonOffset := (point.Longitude - GeoLongitudeMin) / (GeoLongitudeMax - GeoLongitudeMin)
+	latOffset := (point.Latitude - latMin) / (latMax - latMin)
+	lonIndex := min(uint64(lonOffset*cells), uint64(cells)-1)
+	latIndex := min(uint64(latOffset*cells), uint64(cells)-1)
+	return interleave(lonIndex, latIndex)
+}
+
+// cellRange returns the lowest and highest coordinates of the cell with the index.
+func cellRange(index uint64, low, high float64, step int) (float64, float64) {
+	scale := (high - low) / float64(uint64(1)<<step)
+	return low + float64(index)*scale, low + float64(index+1)*scale
+}
+
+// interleave returns the bits of the longitude and latitude indexes interleaved, with
+// the latitude in the even bits and the longitude in the odd bits.
+func interleave(lonIndex, latIndex uint64) uint64 {
+	return spread(latIndex) | spread(lonIndex)<<1
+}
+
+func deinterleave(hash uint64) (uint64, uint64) {
+	return squash(hash >> 1), squash(hash)
+}
+
+// spread moves the lowest 32 bits of x to the even bits.
+func spread(x uint64) uint64 {
+	x &= 0xffffffff
+	x = (x | x<<16) & 0x0000ffff0000ffff
+	x = (x | x<<8) & 0x00ff00ff00ff00ff
+	x = (x | x<<4) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// squash moves the even bits of x to the lowest 32 bits, undoing spread.
+func squash(x uint64) uint64 {
+	x &= 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0f0f0f0f0f0f0f0f
+	x = (x | x>>4) & 0x00ff00ff00ff00ff
+	x = (x | x>>8) & 0x0000ffff0000ffff
+	x = (x | x>>16) & 0x00000000ffffffff
+	return x
+}
+
+func degreesToRadians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func radiansToDegrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
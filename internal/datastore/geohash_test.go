@@ -0,0 +1,83 @@
+package datastore
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+var (
+	palermo = GeoPoint{13.361389, 38.115556}
+	catania = GeoPoint{15.087269, 37.502669}
+)
+
+func TestGeohashEncodeDecode(t *testing.T) {
+	// Redis scores the example locations of its documentation with these geohashes
+	if hash := GeohashEncode(palermo); hash != 3479099956230698 {
+		t.Fatalf("unexpected geohash %d", hash)
+	}
+	if hash := GeohashEncode(catania); hash != 3479447370796909 {
+		t.Fatalf("unexpected geohash %d", hash)
+	}
+	for i := 0; i < 1000; i++ {
+		point := GeoPoint{
+			Longitude: rand.Float64()*360 - 180,
+			Latitude:  rand.Float64()*(GeoLatitudeMax*2) - GeoLatitudeMax,
+		}
+		decoded := GeohashDecode(GeohashEncode(point))
+		if GeoDistance(point, decoded) > 1 {
+			t.Fatalf("decoded %v too far from %v", decoded, point)
+		}
+	}
+}
+
+func TestGeohashString(t *testing.T) {
+	if s := GeohashString(palermo); s != "sqc8b49rny0" {
+		t.Fatalf("unexpected geohash %s", s)
+	}
+	if s := GeohashString(catania); s != "sqdtr74hyu0" {
+		t.Fatalf("unexpected geohash %s", s)
+	}
+}
+
+func TestGeoDistance(t *testing.T) {
+	distance := GeoDistance(GeohashDecode(GeohashEncode(palermo)), GeohashDecode(GeohashEncode(catania)))
+	if math.Abs(distance-166274.1516) > 0.001 {
+		t.Fatalf("unexpected distance %f", distance)
+	}
+}
+
+func TestGeoShapeScoreRanges(t *testing.T) {
+	shapes := []GeoShape{
+		{Center: palermo, Radius: 200000},
+		{Center: palermo, Radius: 10},
+		{Center: GeoPoint{179.9, 0}, Radius: 50000},
+		{Center: GeoPoint{-20, 84}, Width: 400000, Height: 100000},
+	}
+	for _, shape := range shapes {
+		ranges := shape.ScoreRanges()
+		// Every point found in the shape must be in one of the ranges
+		for i := 0; i < 10000; i++ {
+			point := GeoPoint{
+				Longitude: rand.Float64()*360 - 180,
+				Latitude:  rand.Float64()*(GeoLatitudeMax*2) - GeoLatitudeMax,
+			}
+			// Most random points are far away, so half of them are taken close to the center
+			if i%2 == 0 {
+				point.Longitude = math.Mod(shape.Center.Longitude+rand.Float64()*8-4+540, 360) - 180
+				point.Latitude = min(max(shape.Center.Latitude+rand.Float64()*4-2, GeoLatitudeMin), GeoLatitudeMax)
+			}
+			hash := GeohashEncode(point)
+			if _, ok := shape.Contains(GeohashDecode(hash)); !ok {
+				continue
+			}
+			found := false
+			for _, r := range ranges {
+				found = found || (hash >= r[0] && hash < r[1])
+			}
+			if !found {
+				t.Fatalf("%v in %v is not in the ranges %v", point, shape, ranges)
+			}
+		}
+	}
+}
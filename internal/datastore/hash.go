@@ -0,0 +1,151 @@
+package datastore
+
+import (
+	"time"
+)
+
+// Hash is a map of fields to values. Each field can have its own expiration, using the
+// same model as the keys: the Unix time in milliseconds the field expires at, or 0 if
+// the field never expires. Expired fields are removed when the hash is accessed.
+type Hash struct {
+	changeCounter
+	fields map[string]hashField
+	// expiring holds the fields that have an expiration set, so removing the expired
+	// ones doesn't need to go through all the fields.
+	expiring map[string]struct{}
+}
+
+type hashField struct {
+	value  []byte
+	expire int64
+}
+
+func NewHash() *Hash {
+	return &Hash{
+		fields:   make(map[string]hashField),
+		expiring: make(map[string]struct{}),
+	}
+}
+
+// GetHash returns the hash stored in the key. It returns ErrWrongType if the key holds
+// a value of another type. A hash whose fields all expired is removed.
+func GetHash(key string) (*Hash, bool, error) {
+	val, ok, err := lookupType(key, TypeHash)
+	if !ok {
+		return nil, false, err
+	}
+	hash := val.value.(*Hash)
+	hash.removeExpired()
+	if hash.Len() == 0 {
+		expireKey(key)
+		return nil, false, nil
+	}
+	return hash, true, nil
+}
+
+// GetOrCreateHash returns the hash stored in the key, storing a new empty hash if the
+// key doesn't exist. Hashes are modified in place, and commands must delete the key
+// once the hash is empty.
+func GetOrCreateHash(key string) (*Hash, error) {
+	hash, ok, err := GetHash(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		hash = NewHash()
+		store[key] = Value{value: hash, kind: TypeHash, version: nextVersion()}
+	}
+	return hash, nil
+}
+
+func (h *Hash) Len() int {
+	return len(h.fields)
+}
+
+func (h *Hash) Get(field string) ([]byte, bool) {
+	f, ok := h.lookup(field)
+	return f.value, ok
+}
+
+// Set stores the value in the field, discarding any expiration previously set for it.
+// It returns true if the field is new.
+func (h *Hash) Set(field string, value []byte) bool {
+	_, exists := h.lookup(field)
+	h.fields[field] = hashField{value: value}
+	delete(h.expiring, field)
+	h.changed()
+	return !exists
+}
+
+func (h *Hash) Delete(field string) bool {
+	if _, ok := h.lookup(field); !ok {
+		return false
+	}
+	delete(h.fields, field)
+	delete(h.expiring, field)
+	h.changed()
+	return true
+}
+
+// Fields returns the names of all the fields, in no particular order.
+func (h *Hash) Fields() []string {
+	h.removeExpired()
+	fields := make([]string, 0, len(h.fields))
+	for field := range h.fields {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// GetExpire returns the Unix time in milliseconds the field expires at, or 0 if it has
+// no expiration. The bool is false if the field doesn't exist.
+func (h *Hash) GetExpire(field string) (int64, bool) {
+	f, ok := h.lookup(field)
+	return f.expire, ok
+}
+
+// SetExpire changes the expiration of an existing field. An expire of 0 removes the
+// expiration. It returns false if the field doesn't exist.
+func (h *Hash) SetExpire(field string, expire int64) bool {
+	f, ok := h.lookup(field)
+	if !ok {
+		return false
+	}
+	f.expire = expire
+	h.fields[field] = f
+	h.changed()
+	if expire > 0 {
+		h.expiring[field] = struct{}{}
+	} else {
+		delete(h.expiring, field)
+	}
+	return true
+}
+
+func (h *Hash) lookup(field string) (hashField, bool) {
+	f, ok := h.fields[field]
+	if !ok {
+		return hashField{}, false
+	}
+	if f.expire > 0 && time.Now().UnixMilli() > f.expire {
+		delete(h.fields, field)
+		delete(h.expiring, field)
+		h.changed()
+		return hashField{}, false
+	}
+	return f, true
+}
+
+func (h *Hash) removeExpired() {
+	if len(h.expiring) == 0 {
+		return
+	}
+	now := time.Now().UnixMilli()
+	for field := range h.expiring {
+		if now > h.fields[field].expire {
+			delete(h.fields, field)
+			delete(h.expiring, field)
+			h.changed()
+		}
+	}
+}
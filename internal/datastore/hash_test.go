@@ -0,0 +1,51 @@
+package datastore
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func TestHashFieldExpiration(t *testing.T) {
+	hash := NewHash()
+	hash.Set("a", []byte("1"))
+	hash.Set("b", []byte("2"))
+	hash.Set("c", []byte("3"))
+	hash.SetExpire("a", time.Now().Add(-time.Second).UnixMilli())
+	hash.SetExpire("b", time.Now().Add(time.Hour).UnixMilli())
+	if _, ok := hash.Get("a"); ok {
+		t.Fatalf("expired field should not be returned")
+	}
+	if expire, ok := hash.GetExpire("b"); !ok || expire == 0 {
+		t.Fatalf("field b should have an expiration")
+	}
+	if hash.Set("b", []byte("4")) {
+		t.Fatalf("overwriting field b should not report a new field")
+	}
+	if expire, _ := hash.GetExpire("b"); expire != 0 {
+		t.Fatalf("overwriting field b should discard its expiration")
+	}
+	fields := hash.Fields()
+	slices.Sort(fields)
+	if !slices.Equal(fields, []string{"b", "c"}) || len(hash.expiring) != 0 {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
+
+func TestGetHashRemovesExpiredHash(t *testing.T) {
+	hash, err := GetOrCreateHash("hash-expired")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash.Set("a", []byte("1"))
+	hash.SetExpire("a", time.Now().Add(-time.Second).UnixMilli())
+	if _, ok, _ := GetHash("hash-expired"); ok || Exists("hash-expired") {
+		t.Fatalf("a hash with all the fields expired should be removed")
+	}
+	Set("hash-string", protocol.NewBulkString([]byte("value")))
+	if _, _, err := GetHash("hash-string"); err != ErrWrongType {
+		t.Fatalf("expected ErrWrongType, got %v", err)
+	}
+}
@@ -0,0 +1,347 @@
+package datastore
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+// The HyperLogLog uses the same parameters and string representation as Redis, so the
+// strings can be exchanged with it: 2^14 registers of 6 bits indexed by the lowest 14
+// bits of the MurmurHash64A of the element.
+const (
+	hllP           = 14
+	hllQ           = 64 - hllP
+	hllRegisters   = 1 << hllP
+	hllBits        = 6
+	hllRegisterMax = 1<<hllBits - 1
+	hllHeaderSize  = 16
+	hllDenseSize   = hllHeaderSize + (hllRegisters*hllBits+7)/8
+	hllDense       = 0
+	hllSparse      = 1
+	hllSeed        = 0xadc83b19
+	// hllSparseMaxBytes is the longest string kept with the sparse encoding, the same
+	// default as the hll-sparse-max-bytes option of Redis
+	hllSparseMaxBytes = 3000
+	// hllSparseMaxValue is the highest register value the sparse encoding can hold
+	hllSparseMaxValue = 32
+	hllAlphaInf       = 0.721347520444481703680
+)
+
+// The opcodes of the sparse encoding. ZERO is a run of 1 to 64 registers set to 0, XZERO
+// a run of 1 to 16384 registers set to 0, in two bytes, and VAL a run of 1 to 4
+// registers set to a value from 1 to 32.
+const (
+	hllOpZero  = 0x00
+	hllOpXZero = 0x40
+	hllOpVal   = 0x80
+)
+
+var (
+	// ErrNotHyperLogLog is returned when a string that isn't a HyperLogLog is read as one.
+	ErrNotHyperLogLog = errors.New("WRONGTYPE Key is not a valid HyperLogLog string value.")
+	// ErrCorruptedHyperLogLog is returned when a HyperLogLog has a valid header but its
+	// registers can't be decoded.
+	ErrCorruptedHyperLogLog = errors.New("INVALIDOBJ Corrupted HLL object detected")
+)
+
+// HyperLogLog estimates the number of unique elements added to it using a fixed amount
+// of memory. The registers are kept decoded while in use and encoded to a string when
+// stored, with the sparse encoding while few registers are set and the dense encoding
+// once the sparse one gets too long. Like in Redis, a HyperLogLog is never converted
+// back to the sparse encoding.
+type HyperLogLog struct {
+	registers [hllRegisters]uint8
+	sparse    bool
+	// cardinality is the last estimate, valid until a register changes
+	cardinality      int64
+	cardinalityValid bool
+}
+
+// NewHyperLogLog creates an empty HyperLogLog with the sparse encoding.
+func NewHyperLogLog() *HyperLogLog {
+	return &HyperLogLog{sparse: true, cardinalityValid: true}
+}
+
+// GetHyperLogLog returns the HyperLogLog stored in the key. It returns ErrWrongType if
+// the key doesn't hold a string and ErrNotHyperLogLog if the string isn't a HyperLogLog.
+func GetHyperLogLog(key string) (*HyperLogLog, bool, error) {
+	val, ok, err := Get(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	hll, err := ParseHyperLogLog([]byte(val.String()))
+	if err != nil {
+		return nil, false, err
+	}
+	return hll, true, nil
+}
+
+// StoreHyperLogLog stores the HyperLogLog in the key as a string, keeping the expiration
+// of the key, if any.
+func StoreHyperLogLog(key string, hll *HyperLogLog) {
+	SetKeepTTL(key, protocol.NewBulkString(hll.Bytes()))
+}
+
+// ParseHyperLogLog decodes a HyperLogLog from its string representation.
+func ParseHyperLogLog(data []byte) (*HyperLogLog, error) {
+	if len(data) < hllHeaderSize || string(data[:4]) != "HYLL" || data[4] > hllSparse {
+		return nil, ErrNotHyperLogLog
+	}
+	if data[4] == hllDense && len(data) != hllDenseSize {
+		return nil, ErrNotHyperLogLog
+	}
+	hll := &HyperLogLog{sparse: data[4] == hllSparse}
+	card := binary.LittleEndian.Uint64(data[8:hllHeaderSize])
+	// The most significant bit of the cached cardinality tells it's no longer valid
+	hll.cardinalityValid = card&(1<<63) == 0
+	hll.cardinality = int64(card &^ (1 << 63))
+	registers := data[hllHeaderSize:]
+	if !hll.sparse {
+		for i := range hll.registers {
+			hll.registers[i] = denseRegister(registers, i)
+		}
+		return hll, nil
+	}
+	index := 0
+	for i := 0; i < len(registers); i++ {
+		op := registers[i]
+		switch {
+		case op&0xc0 == hllOpZero:
+			index += int(op&0x3f) + 1
+		case op&0xc0 == hllOpXZero:
+			if i+1 == len(registers) {
+				return nil, ErrCorruptedHyperLogLog
+			}
+			index += (int(op&0x3f)<<8 | int(registers[i+1])) + 1
+			i++
+		default:
+			value := (op>>2)&0x1f + 1
+			run := int(op&0x03) + 1
+			if index+run > hllRegisters {
+				return nil, ErrCorruptedHyperLogLog
+			}
+			for j := 0; j < run; j++ {
+				hll.registers[index+j] = value
+			}
+			index += run
+		}
+		if index > hllRegisters {
+			return nil, ErrCorruptedHyperLogLog
+		}
+	}
+	if index != hllRegisters {
+		return nil, ErrCorruptedHyperLogLog
+	}
+	return hll, nil
+}
+
+// IsSparse tells if the HyperLogLog is using the sparse encoding.
+func (h *HyperLogLog) IsSparse() bool {
+	return h.sparse
+}
+
+// Add adds the element, returning true if a register changed, which means the
+// estimated cardinality may have changed.
+func (h *HyperLogLog) Add(element []byte) bool {
+	hash := murmurHash64A(element, hllSeed)
+	index := hash & (hllRegisters - 1)
+	// The count is the position of the first bit set after the index bits, with a bit
+	// set past the hash so it's at most hllQ+1
+	hash = hash>>hllP | 1<<hllQ
+	count := uint8(bits.TrailingZeros64(hash) + 1)
+	if count <= h.registers[index] {
+		return false
+	}
+	h.registers[index] = count
+	h.cardinalityValid = false
+	return true
+}
+
+// Merge sets each register to the highest of its value and the value in the other
+// HyperLogLog. The result uses the dense encoding if the other HyperLogLog does.
+func (h *HyperLogLog) Merge(other *HyperLogLog) {
+	for i, value := range other.registers {
+		if value > h.registers[i] {
+			h.registers[i] = value
+			h.cardinalityValid = false
+		}
+	}
+	if !other.sparse {
+		h.sparse = false
+	}
+}
+
+// IsCountCached tells if the estimate returned by Count is cached, which means it won't
+// be computed again and the cache won't change.
+func (h *HyperLogLog) IsCountCached() bool {
+	return h.cardinalityValid
+}
+
+// Count returns the estimated number of unique elements added, using the estimator of
+// Redis, which is based on "New cardinality estimation algorithms for HyperLogLog
+// sketches" by Otmar Ertl. The estimate is cached until a register changes.
+func (h *HyperLogLog) Count() int64 {
+	if h.cardinalityValid {
+		return h.cardinality
+	}
+	var histogram [hllQ + 2]int
+	for _, value := range h.registers {
+		histogram[value]++
+	}
+	m := float64(hllRegisters)
+	z := m * hllTau((m-float64(histogram[hllQ+1]))/m)
+	for j := hllQ; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * hllSigma(float64(histogram[0])/m)
+	h.cardinality = int64(math.Round(hllAlphaInf * m * m / z))
+	h.cardinalityValid = true
+	return h.cardinality
+}
+
+// Bytes encodes the HyperLogLog to its string representation. A sparse HyperLogLog
+// that no longer fits in the sparse encoding is converted to the dense encoding.
+func (h *HyperLogLog) Bytes() []byte {
+	if h.sparse {
+		if data, ok := h.sparseBytes(); ok {
+			return data
+		}
+		h.sparse = false
+	}
+	data := make([]byte, hllDenseSize)
+	h.writeHeader(data, hllDense)
+	for i, value := range h.registers {
+		setDenseRegister(data[hllHeaderSize:], i, value)
+	}
+	return data
+}
+
+// sparseBytes encodes the registers with the sparse encoding. The bool is false if a
+// register is too high for it or the result is longer than hllSparseMaxBytes.
+func (h *HyperLogLog) sparseBytes() ([]byte, bool) {
+	data := make([]byte, hllHeaderSize, hllSparseMaxBytes)
+	h.writeHeader(data, hllSparse)
+	for i := 0; i < hllRegisters; {
+		value := h.registers[i]
+		run := 1
+		for i+run < hllRegisters && h.registers[i+run] == value {
+			run++
+		}
+		i += run
+		switch {
+		case value == 0 && run > 64:
+			data = append(data, hllOpXZero|byte((run-1)>>8), byte(run-1))
+		case value == 0:
+			data = append(data, hllOpZero|byte(run-1))
+		case value > hllSparseMaxValue:
+			return nil, false
+		default:
+			for run > 0 {
+				n := min(run, 4)
+				data = append(data, hllOpVal|(value-1)<<2|byte(n-1))
+				run -= n
+			}
+		}
+		if len(data) > hllSparseMaxBytes {
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+func (h *HyperLogLog) writeHeader(data []byte, encoding byte) {
+	copy(data, "HYLL")
+	data[4] = encoding
+	card := uint64(h.cardinality)
+	if !h.cardinalityValid {
+		card = 1 << 63
+	}
+	binary.LittleEndian.PutUint64(data[8:hllHeaderSize], card)
+}
+
+// denseRegister returns the register of the dense encoding, where registers are packed
+// in 6 bits starting from the least significant bit of the first byte.
+func denseRegister(registers []byte, index int) uint8 {
+	b := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	value := registers[b] >> fb
+	if b+1 < len(registers) {
+		value |= registers[b+1] << (8 - fb)
+	}
+	return value & hllRegisterMax
+}
+
+func setDenseRegister(registers []byte, index int, value uint8) {
+	b := index * hllBits / 8
+	fb := uint(index * hllBits & 7)
+	registers[b] &^= hllRegisterMax << fb
+	registers[b] |= value << fb
+	if b+1 < len(registers) {
+		registers[b+1] &^= hllRegisterMax >> (8 - fb)
+		registers[b+1] |= value >> (8 - fb)
+	}
+}
+
+func hllSigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y, z := 1.0, x
+	for {
+		x *= x
+		previous := z
+		z += x * y
+		y += y
+		if previous == z {
+			return z
+		}
+	}
+}
+
+func hllTau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y, z := 1.0, 1-x
+	for {
+		x = math.Sqrt(x)
+		previous := z
+		y *= 0.5
+		z -= (1 - x) * (1 - x) * y
+		if previous == z {
+			return z / 3
+		}
+	}
+}
+
+// murmurHash64A is the 64 bit version of MurmurHash2 by Austin Appleby, the hash used by
+// the HyperLogLog of Redis, reading the blocks as little endian.
+func murmurHash64A(data []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+	h := seed ^ uint64(len(data))*m
+	for len(data) >= 8 {
+		k := binary.LittleEndian.Uint64(data)
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+		data = data[8:]
+	}
+	if len(data) > 0 {
+		for i := len(data) - 1; i >= 0; i-- {
+			h ^= uint64(data[i]) << (8 * i)
+		}
+		h *= m
+	}
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
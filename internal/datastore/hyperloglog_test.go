@@ -0,0 +1,83 @@
+package datastore
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHyperLogLogCount(t *testing.T) {
+	for _, size := range []int{0, 10, 1000, 100000} {
+		t.Run(fmt.Sprintf("%d elements", size), func(t *testing.T) {
+			hll := NewHyperLogLog()
+			for i := 0; i < size; i++ {
+				hll.Add([]byte(fmt.Sprintf("element-%d", i)))
+			}
+			count := hll.Count()
+			// The standard error is 0.81%, so the estimate should be well within 3%
+			if math.Abs(float64(count)-float64(size)) > float64(size)*0.03 {
+				t.Fatalf("estimate %d too far from %d", count, size)
+			}
+			parsed, err := ParseHyperLogLog(hll.Bytes())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed.registers != hll.registers || parsed.sparse != hll.sparse {
+				t.Fatalf("the parsed HyperLogLog doesn't match the encoded one")
+			}
+			if !parsed.IsCountCached() || parsed.Count() != count {
+				t.Fatalf("the estimate should be cached in the encoded HyperLogLog")
+			}
+		})
+	}
+}
+
+func TestHyperLogLogEncodings(t *testing.T) {
+	hll := NewHyperLogLog()
+	data := hll.Bytes()
+	// An empty sparse HyperLogLog is a single XZERO opcode covering all the registers
+	if string(data[:5]) != "HYLL\x01" || len(data) != hllHeaderSize+2 || data[16] != 0x7f || data[17] != 0xff {
+		t.Fatalf("unexpected empty HyperLogLog %q", data)
+	}
+	if !hll.Add([]byte("a")) || hll.Add([]byte("a")) {
+		t.Fatalf("only the first addition should change a register")
+	}
+	if data := hll.Bytes(); data[15]&0x80 == 0 {
+		t.Fatalf("the cached cardinality should be invalidated")
+	}
+	for i := 0; i < 5000; i++ {
+		hll.Add([]byte(fmt.Sprintf("element-%d", i)))
+	}
+	if data := hll.Bytes(); hll.IsSparse() || len(data) != hllDenseSize || data[4] != hllDense {
+		t.Fatalf("the HyperLogLog should have been converted to dense")
+	}
+
+	merged := NewHyperLogLog()
+	merged.Add([]byte("b"))
+	merged.Merge(hll)
+	if merged.IsSparse() || merged.Count() < hll.Count() {
+		t.Fatalf("unexpected merged HyperLogLog with count %d", merged.Count())
+	}
+}
+
+func TestHyperLogLogInvalid(t *testing.T) {
+	sparse := NewHyperLogLog().Bytes()
+	tcs := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{"not a HyperLogLog", []byte("value"), ErrNotHyperLogLog},
+		{"invalid encoding", append([]byte("HYLL\x02"), sparse[5:]...), ErrNotHyperLogLog},
+		{"short dense", append([]byte("HYLL\x00"), sparse[5:]...), ErrNotHyperLogLog},
+		{"sparse with too few registers", append(sparse[:16:16], 0x7f, 0xfe), ErrCorruptedHyperLogLog},
+		{"sparse with too many registers", append(sparse[:16:16], 0x7f, 0xff, 0x00), ErrCorruptedHyperLogLog},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseHyperLogLog(tc.data); err != tc.expected {
+				t.Fatalf("unexpected error. Expected: %v, Actual: %v", tc.expected, err)
+			}
+		})
+	}
+}
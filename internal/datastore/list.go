@@ -0,0 +1,341 @@
+package datastore
+
+import (
+	"bytes"
+)
+
+const (
+	// listNodeSize is the maximum number of entries kept in a single node of a List.
+	listNodeSize = 128
+)
+
+// List is a quicklist: a doubly linked list of nodes where each node holds up to
+// listNodeSize entries in a slice. Compared to a plain linked list it needs far fewer
+// pointers per entry, while pushing and popping from both ends is still O(1).
+//
+// Indexes follow the Redis conventions: negative indexes count from the tail, -1 being
+// the last entry.
+type List struct {
+	changeCounter
+	head, tail *listNode
+	length     int
+}
+
+type listNode struct {
+	entries    [][]byte
+	prev, next *listNode
+}
+
+func NewList() *List {
+	return &List{}
+}
+
+// GetList returns the list stored in the key. It returns ErrWrongType if the key holds
+// a value of another type.
+func GetList(key string) (*List, bool, error) {
+	val, ok, err := lookupType(key, TypeList)
+	if !ok {
+		return nil, false, err
+	}
+	list := val.value.(*List)
+	return list, true, nil
+}
+
+// GetOrCreateList returns the list stored in the key, storing a new empty list if the
+// key doesn't exist. Lists are modified in place, and commands must delete the key once
+// the list is empty, since Redis never keeps empty lists.
+func GetOrCreateList(key string) (*List, error) {
+	list, ok, err := GetList(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		list = NewList()
+		store[key] = Value{value: list, kind: TypeList, version: nextVersion()}
+	}
+	return list, nil
+}
+
+func (l *List) Len() int {
+	return l.length
+}
+
+func (l *List) PushFront(value []byte) {
+	if l.head == nil || len(l.head.entries) >= listNodeSize {
+		l.insertNodeAfter(nil, &listNode{})
+	}
+	l.head.entries = append([][]byte{value}, l.head.entries...)
+	l.length++
+	l.changed()
+}
+
+func (l *List) PushBack(value []byte) {
+	if l.tail == nil || len(l.tail.entries) >= listNodeSize {
+		l.insertNodeAfter(l.tail, &listNode{})
+	}
+	l.tail.entries = append(l.tail.entries, value)
+	l.length++
+	l.changed()
+}
+
+func (l *List) PopFront() ([]byte, bool) {
+	if l.length == 0 {
+		return nil, false
+	}
+	value := l.head.entries[0]
+	l.removeEntry(l.head, 0)
+	return value, true
+}
+
+func (l *List) PopBack() ([]byte, bool) {
+	if l.length == 0 {
+		return nil, false
+	}
+	value := l.tail.entries[len(l.tail.entries)-1]
+	l.removeEntry(l.tail, len(l.tail.entries)-1)
+	return value, true
+}
+
+func (l *List) Index(index int) ([]byte, bool) {
+	node, offset, ok := l.locate(index)
+	if !ok {
+		return nil, false
+	}
+	return node.entries[offset], true
+}
+
+// Set replaces the entry at the index, returning false if the index is out of range.
+func (l *List) Set(index int, value []byte) bool {
+	node, offset, ok := l.locate(index)
+	if !ok {
+		return false
+	}
+	node.entries[offset] = value
+	l.changed()
+	return true
+}
+
+// Range returns the entries between start and stop, both inclusive, with the same
+// semantics as LRANGE.
+func (l *List) Range(start, stop int) [][]byte {
+	start, stop, ok := l.normalizeRange(start, stop)
+	if !ok {
+		return [][]byte{}
+	}
+	values := make([][]byte, 0, stop-start+1)
+	node, offset, _ := l.locate(start)
+	for len(values) < cap(values) {
+		values = append(values, node.entries[offset])
+		offset++
+		if offset == len(node.entries) {
+			node, offset = node.next, 0
+		}
+	}
+	return values
+}
+
+// Trim keeps only the entries between start and stop, both inclusive, with the same
+// semantics as LTRIM.
+func (l *List) Trim(start, stop int) {
+	start, stop, ok := l.normalizeRange(start, stop)
+	if !ok {
+		l.head, l.tail, l.length = nil, nil, 0
+		l.changed()
+		return
+	}
+	l.deleteRange(stop+1, l.length-stop-1)
+	l.deleteRange(0, start)
+}
+
+// Remove removes the entries equal to value with the same semantics as LREM: a positive
+// count removes up to count entries starting from the head, a negative count removes up
+// to -count entries starting from the tail and 0 removes all of them. It returns the
+// number of removed entries.
+func (l *List) Remove(count int, value []byte) int {
+	removed := 0
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	if count >= 0 {
+		for node := l.head; node != nil; {
+			next := node.next
+			for i := 0; i < len(node.entries) && (limit == 0 || removed < limit); {
+				if bytes.Equal(node.entries[i], value) {
+					l.removeEntry(node, i)
+					removed++
+					continue
+				}
+				i++
+			}
+			node = next
+		}
+		return removed
+	}
+	for node := l.tail; node != nil && removed < limit; {
+		prev := node.prev
+		for i := len(node.entries) - 1; i >= 0 && removed < limit; i-- {
+			if bytes.Equal(node.entries[i], value) {
+				l.removeEntry(node, i)
+				removed++
+			}
+		}
+		node = prev
+	}
+	return removed
+}
+
+// Insert adds value before or after the first entry equal to pivot. It returns false if
+// the pivot wasn't found.
+func (l *List) Insert(pivot []byte, value []byte, before bool) bool {
+	for node := l.head; node != nil; node = node.next {
+		for i, entry := range node.entries {
+			if bytes.Equal(entry, pivot) {
+				if !before {
+					i++
+				}
+				l.insertEntry(node, i, value)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeRange converts start and stop to positive indexes inside the list. It
+// returns false if the range is empty.
+func (l *List) normalizeRange(start, stop int) (int, int, bool) {
+	if start < 0 {
+		start = l.length + start
+	}
+	if stop < 0 {
+		stop = l.length + stop
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start > stop || start >= l.length {
+		return 0, 0, false
+	}
+	if stop >= l.length {
+		stop = l.length - 1
+	}
+	return start, stop, true
+}
+
+// locate finds the node and the offset inside the node of the entry at the index,
+// walking from the closest end of the list.
+func (l *List) locate(index int) (*listNode, int, bool) {
+	if index < 0 {
+		index = l.length + index
+	}
+	if index < 0 || index >= l.length {
+		return nil, 0, false
+	}
+	if index < l.length/2 {
+		for node := l.head; node != nil; node = node.next {
+			if index < len(node.entries) {
+				return node, index, true
+			}
+			index -= len(node.entries)
+		}
+	}
+	index = l.length - 1 - index
+	for node := l.tail; node != nil; node = node.prev {
+		if index < len(node.entries) {
+			return node, len(node.entries) - 1 - index, true
+		}
+		index -= len(node.entries)
+	}
+	return nil, 0, false
+}
+
+// deleteRange removes count entries starting at the index, dropping whole nodes when
+// possible.
+func (l *List) deleteRange(index, count int) {
+	if count <= 0 {
+		return
+	}
+	l.changed()
+	node, offset, ok := l.locate(index)
+	for ok && count > 0 {
+		next := node.next
+		available := len(node.entries) - offset
+		if offset == 0 && available <= count {
+			l.length -= len(node.entries)
+			count -= len(node.entries)
+			l.unlinkNode(node)
+		} else {
+			deleted := min(available, count)
+			node.entries = append(node.entries[:offset], node.entries[offset+deleted:]...)
+			l.length -= deleted
+			count -= deleted
+		}
+		node, offset, ok = next, 0, next != nil
+	}
+}
+
+// insertEntry inserts the value at the offset of the node, splitting the node in two if
+// it's full.
+func (l *List) insertEntry(node *listNode, offset int, value []byte) {
+	if len(node.entries) >= listNodeSize {
+		half := len(node.entries) / 2
+		newNode := &listNode{entries: append([][]byte{}, node.entries[half:]...)}
+		node.entries = node.entries[:half]
+		l.insertNodeAfter(node, newNode)
+		if offset > half {
+			node, offset = newNode, offset-half
+		}
+	}
+	node.entries = append(node.entries, nil)
+	copy(node.entries[offset+1:], node.entries[offset:])
+	node.entries[offset] = value
+	l.length++
+	l.changed()
+}
+
+func (l *List) removeEntry(node *listNode, offset int) {
+	node.entries = append(node.entries[:offset], node.entries[offset+1:]...)
+	l.length--
+	l.changed()
+	if len(node.entries) == 0 {
+		l.unlinkNode(node)
+	}
+}
+
+// insertNodeAfter links the new node after the given node, or as the head if node is nil.
+func (l *List) insertNodeAfter(node, newNode *listNode) {
+	if node == nil {
+		newNode.next = l.head
+		if l.head != nil {
+			l.head.prev = newNode
+		}
+		l.head = newNode
+		if l.tail == nil {
+			l.tail = newNode
+		}
+		return
+	}
+	newNode.prev = node
+	newNode.next = node.next
+	if node.next != nil {
+		node.next.prev = newNode
+	} else {
+		l.tail = newNode
+	}
+	node.next = newNode
+}
+
+func (l *List) unlinkNode(node *listNode) {
+	if node.prev != nil {
+		node.prev.next = node.next
+	} else {
+		l.head = node.next
+	}
+	if node.next != nil {
+		node.next.prev = node.prev
+	} else {
+		l.tail = node.prev
+	}
+	node.prev, node.next = nil, nil
+}
@@ -0,0 +1,152 @@
+package datastore
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// newTestList creates a list with the entries 0 to size-1, spanning several nodes.
+func newTestList(size int) *List {
+	list := NewList()
+	for i := 0; i < size; i++ {
+		list.PushBack([]byte(strconv.Itoa(i)))
+	}
+	return list
+}
+
+func toStrings(values [][]byte) []string {
+	result := []string{}
+	for _, value := range values {
+		result = append(result, string(value))
+	}
+	return result
+}
+
+func sequence(start, stop int) []string {
+	result := []string{}
+	for i := start; i <= stop; i++ {
+		result = append(result, strconv.Itoa(i))
+	}
+	return result
+}
+
+func TestListPushPop(t *testing.T) {
+	list := NewList()
+	for i := 0; i < 300; i++ {
+		list.PushFront([]byte(fmt.Sprintf("f%d", i)))
+		list.PushBack([]byte(fmt.Sprintf("b%d", i)))
+	}
+	if list.Len() != 600 {
+		t.Fatalf("unexpected length %d", list.Len())
+	}
+	for i := 299; i >= 0; i-- {
+		value, _ := list.PopFront()
+		if string(value) != fmt.Sprintf("f%d", i) {
+			t.Fatalf("unexpected value popped from the front: %s", value)
+		}
+		value, _ = list.PopBack()
+		if string(value) != fmt.Sprintf("b%d", i) {
+			t.Fatalf("unexpected value popped from the back: %s", value)
+		}
+	}
+	if _, ok := list.PopFront(); ok || list.Len() != 0 || list.head != nil || list.tail != nil {
+		t.Fatalf("list should be empty")
+	}
+}
+
+func TestListIndexAndRange(t *testing.T) {
+	list := newTestList(400)
+	for _, index := range []int{0, 127, 128, 255, 399, -1, -400} {
+		value, ok := list.Index(index)
+		expected := index
+		if index < 0 {
+			expected = 400 + index
+		}
+		if !ok || string(value) != strconv.Itoa(expected) {
+			t.Fatalf("unexpected value at index %d: %s", index, value)
+		}
+	}
+	if _, ok := list.Index(400); ok {
+		t.Fatalf("index 400 should be out of range")
+	}
+	if actual := toStrings(list.Range(120, 135)); !reflect.DeepEqual(actual, sequence(120, 135)) {
+		t.Fatalf("unexpected range %v", actual)
+	}
+	if actual := toStrings(list.Range(-3, 1000)); !reflect.DeepEqual(actual, sequence(397, 399)) {
+		t.Fatalf("unexpected range %v", actual)
+	}
+	if actual := list.Range(5, 2); len(actual) != 0 {
+		t.Fatalf("unexpected range %v", actual)
+	}
+}
+
+func TestListTrim(t *testing.T) {
+	list := newTestList(400)
+	list.Trim(100, -100)
+	if list.Len() != 201 {
+		t.Fatalf("unexpected length %d", list.Len())
+	}
+	if actual := toStrings(list.Range(0, -1)); !reflect.DeepEqual(actual, sequence(100, 300)) {
+		t.Fatalf("unexpected entries %v", actual)
+	}
+	list.Trim(10, 5)
+	if list.Len() != 0 || list.head != nil {
+		t.Fatalf("list should be empty")
+	}
+}
+
+func TestListRemove(t *testing.T) {
+	list := NewList()
+	for i := 0; i < 200; i++ {
+		list.PushBack([]byte("a"))
+		list.PushBack([]byte(strconv.Itoa(i)))
+	}
+	if removed := list.Remove(2, []byte("a")); removed != 2 {
+		t.Fatalf("unexpected number of removed entries %d", removed)
+	}
+	if first, _ := list.Index(0); string(first) != "0" {
+		t.Fatalf("remove should start from the head, first entry is %s", first)
+	}
+	if removed := list.Remove(-3, []byte("a")); removed != 3 {
+		t.Fatalf("unexpected number of removed entries %d", removed)
+	}
+	if last, _ := list.Index(-2); string(last) != "198" {
+		t.Fatalf("remove should start from the tail, second to last entry is %s", last)
+	}
+	if removed := list.Remove(0, []byte("a")); removed != 195 {
+		t.Fatalf("unexpected number of removed entries %d", removed)
+	}
+	if actual := toStrings(list.Range(0, -1)); !reflect.DeepEqual(actual, sequence(0, 199)) {
+		t.Fatalf("unexpected entries %v", actual)
+	}
+}
+
+func TestListInsertAndSet(t *testing.T) {
+	list := newTestList(listNodeSize)
+	if !list.Insert([]byte("10"), []byte("before"), true) {
+		t.Fatalf("pivot should have been found")
+	}
+	if !list.Insert([]byte("100"), []byte("after"), false) {
+		t.Fatalf("pivot should have been found")
+	}
+	if list.Insert([]byte("missing"), []byte("value"), true) {
+		t.Fatalf("pivot shouldn't have been found")
+	}
+	if list.Len() != listNodeSize+2 {
+		t.Fatalf("unexpected length %d", list.Len())
+	}
+	if value, _ := list.Index(10); string(value) != "before" {
+		t.Fatalf("unexpected value %s", value)
+	}
+	if value, _ := list.Index(102); string(value) != "after" {
+		t.Fatalf("unexpected value %s", value)
+	}
+	if !list.Set(-1, []byte("last")) || list.Set(1000, []byte("x")) {
+		t.Fatalf("unexpected result setting values")
+	}
+	if value, _ := list.Index(listNodeSize + 1); string(value) != "last" {
+		t.Fatalf("unexpected value %s", value)
+	}
+}
@@ -0,0 +1,120 @@
+package datastore
+
+import (
+	"strconv"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+// ObjectType is the type of the value stored in a key. Every key records its type when
+// it's stored, so reading a key as another type fails with ErrWrongType instead of
+// depending on how the value happens to be represented.
+type ObjectType int
+
+const (
+	TypeString ObjectType = iota + 1
+	TypeList
+	TypeHash
+	TypeSet
+	TypeSortedSet
+	TypeStream
+)
+
+// embstrMaxSize is the longest string reported with the embstr encoding, like in Redis.
+const embstrMaxSize = 44
+
+// String returns the name of the type as replied by the TYPE command.
+func (t ObjectType) String() string {
+	switch t {
+	case TypeString:
+		return "string"
+	case TypeList:
+		return "list"
+	case TypeHash:
+		return "hash"
+	case TypeSet:
+		return "set"
+	case TypeSortedSet:
+		return "zset"
+	case TypeStream:
+		return "stream"
+	}
+	return "none"
+}
+
+// lookupType returns the value stored in the key, or ErrWrongType if the key holds a
+// value of another type than kind.
+func lookupType(key string, kind ObjectType) (Value, bool, error) {
+	val, ok := lookup(key)
+	if !ok {
+		return Value{}, false, nil
+	}
+	if val.kind != kind {
+		return Value{}, false, ErrWrongType
+	}
+	return val, true, nil
+}
+
+// TypeOf returns the type of the value stored in the key. The bool is false if the key
+// doesn't exist.
+func TypeOf(key string) (ObjectType, bool) {
+	val, ok := lookup(key)
+	if !ok {
+		return 0, false
+	}
+	return val.kind, true
+}
+
+// Encoding returns how the value stored in the key is represented, using the names of
+// the OBJECT ENCODING command of Redis. The bool is false if the key doesn't exist.
+func Encoding(key string) (string, bool) {
+	val, ok := lookup(key)
+	if !ok {
+		return "", false
+	}
+	return val.encoding(), true
+}
+
+func (v Value) encoding() string {
+	switch value := v.value.(type) {
+	case int64:
+		return "int"
+	case protocol.DataType:
+		if len(value.String()) <= embstrMaxSize {
+			return "embstr"
+		}
+		return "raw"
+	case *List:
+		// A list that fits in a single node is the equivalent of the listpack encoding
+		if value.head == value.tail {
+			return "listpack"
+		}
+		return "quicklist"
+	case *Hash:
+		return "hashtable"
+	case *UnorderedSet:
+		if value.IsIntset() {
+			return "intset"
+		}
+		return "hashtable"
+	case *SortedSet:
+		if value.IsCompact() {
+			return "listpack"
+		}
+		return "skiplist"
+	case *Stream:
+		return "stream"
+	}
+	return ""
+}
+
+// parseInteger converts the string to an integer if it can be stored as one, for the
+// int encoding of strings or the intset encoding of sets. It requires the string to be
+// the canonical representation of the integer, so "01" or "+1" are kept as strings.
+func parseInteger(str string) (int64, bool) {
+	value, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || strconv.FormatInt(value, 10) != str {
+		return 0, false
+	}
+	return value, true
+}
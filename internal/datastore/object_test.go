@@ -0,0 +1,58 @@
+package datastore
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func TestStringEncodings(t *testing.T) {
+	tcs := []struct {
+		value    string
+		encoding string
+		integer  bool
+	}{
+		{"12345", "int", true},
+		{"-9223372036854775808", "int", true},
+		{"9223372036854775808", "embstr", false},
+		{"007", "embstr", true},
+		{"+7", "embstr", true},
+		{"text", "embstr", false},
+		{strings.Repeat("a", 45), "raw", false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.value, func(t *testing.T) {
+			key := "object-" + tc.value
+			Set(key, protocol.NewBulkString([]byte(tc.value)))
+			if encoding, _ := Encoding(key); encoding != tc.encoding {
+				t.Fatalf("unexpected encoding. Expected: %s, Actual: %s", tc.encoding, encoding)
+			}
+			if val, _, _ := Get(key); val.String() != tc.value {
+				t.Fatalf("unexpected value %v", val)
+			}
+			_, _, err := GetInteger(key)
+			if tc.integer != (err == nil) {
+				t.Fatalf("unexpected error reading as integer: %v", err)
+			}
+		})
+	}
+}
+
+func TestObjectTypes(t *testing.T) {
+	Set("object-string", protocol.NewBulkString([]byte("value")))
+	GetOrCreateList("object-list")
+	if kind, ok := TypeOf("object-list"); !ok || kind != TypeList {
+		t.Fatalf("unexpected type %v", kind)
+	}
+	if _, _, err := GetList("object-string"); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("reading a string as a list should fail, got %v", err)
+	}
+	if _, _, err := GetInteger("object-list"); !errors.Is(err, ErrWrongType) {
+		t.Fatalf("reading a list as an integer should fail, got %v", err)
+	}
+	if _, ok := TypeOf("object-missing"); ok {
+		t.Fatalf("the key shouldn't exist")
+	}
+}
@@ -0,0 +1,158 @@
+package datastore
+
+import (
+	"slices"
+	"strconv"
+)
+
+// maxIntsetEntries is the largest set kept with the intset encoding, the same default
+// as the set-max-intset-entries option of Redis.
+const maxIntsetEntries = 512
+
+// UnorderedSet is a set of unique members. Sets whose members are all integers are kept
+// as a sorted slice of integers, the intset encoding, which uses much less memory than a
+// map. The set is converted to a map once a member isn't an integer or it grows past
+// maxIntsetEntries, and never converted back.
+type UnorderedSet struct {
+	changeCounter
+	intset  []int64
+	members map[string]struct{}
+}
+
+// NewUnorderedSet creates an empty set with the intset encoding.
+func NewUnorderedSet() *UnorderedSet {
+	return &UnorderedSet{intset: []int64{}}
+}
+
+// GetSet returns the set stored in the key. It returns ErrWrongType if the key holds a
+// value of another type.
+func GetSet(key string) (*UnorderedSet, bool, error) {
+	val, ok, err := lookupType(key, TypeSet)
+	if !ok {
+		return nil, false, err
+	}
+	set := val.value.(*UnorderedSet)
+	return set, true, nil
+}
+
+// GetOrCreateSet returns the set stored in the key, storing a new empty set if the key
+// doesn't exist. Sets are modified in place, and commands must delete the key once the
+// set is empty.
+func GetOrCreateSet(key string) (*UnorderedSet, error) {
+	set, ok, err := GetSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		set = NewUnorderedSet()
+		store[key] = Value{value: set, kind: TypeSet, version: nextVersion()}
+	}
+	return set, nil
+}
+
+// StoreSet replaces whatever the key holds with the set, deleting the key if the set is
+// empty. It's used by commands that store their result, like SINTERSTORE.
+func StoreSet(key string, set *UnorderedSet) {
+	if set.Len() == 0 {
+		Delete(key)
+		return
+	}
+	snapshotKey(key)
+	store[key] = Value{value: set, kind: TypeSet, version: nextVersion()}
+	delete(expires, key)
+}
+
+// IsIntset tells if the set is using the intset encoding.
+func (s *UnorderedSet) IsIntset() bool {
+	return s.members == nil
+}
+
+func (s *UnorderedSet) Len() int {
+	if s.IsIntset() {
+		return len(s.intset)
+	}
+	return len(s.members)
+}
+
+// Add adds the member to the set, returning false if it was already a member.
+func (s *UnorderedSet) Add(member string) bool {
+	if s.IsIntset() {
+		if value, ok := parseInteger(member); ok {
+			i, found := slices.BinarySearch(s.intset, value)
+			if found {
+				return false
+			}
+			if len(s.intset) < maxIntsetEntries {
+				s.intset = slices.Insert(s.intset, i, value)
+				s.changed()
+				return true
+			}
+		}
+		s.convertToMap()
+	}
+	if _, ok := s.members[member]; ok {
+		return false
+	}
+	s.members[member] = struct{}{}
+	s.changed()
+	return true
+}
+
+// Remove removes the member from the set, returning false if it wasn't a member.
+func (s *UnorderedSet) Remove(member string) bool {
+	if s.IsIntset() {
+		value, ok := parseInteger(member)
+		if !ok {
+			return false
+		}
+		i, found := slices.BinarySearch(s.intset, value)
+		if found {
+			s.intset = slices.Delete(s.intset, i, i+1)
+			s.changed()
+		}
+		return found
+	}
+	if _, ok := s.members[member]; !ok {
+		return false
+	}
+	delete(s.members, member)
+	s.changed()
+	return true
+}
+
+func (s *UnorderedSet) Contains(member string) bool {
+	if s.IsIntset() {
+		value, ok := parseInteger(member)
+		if !ok {
+			return false
+		}
+		_, found := slices.BinarySearch(s.intset, value)
+		return found
+	}
+	_, ok := s.members[member]
+	return ok
+}
+
+// Members returns all the members of the set. Intsets return them in ascending order,
+// the other sets in no particular order.
+func (s *UnorderedSet) Members() []string {
+	members := make([]string, 0, s.Len())
+	if s.IsIntset() {
+		for _, value := range s.intset {
+			members = append(members, strconv.FormatInt(value, 10))
+		}
+		return members
+	}
+	for member := range s.members {
+		members = append(members, member)
+	}
+	return members
+}
+
+func (s *UnorderedSet) convertToMap() {
+	s.members = make(map[string]struct{}, len(s.intset)+1)
+	for _, value := range s.intset {
+		s.members[strconv.FormatInt(value, 10)] = struct{}{}
+	}
+	s.intset = nil
+}
@@ -0,0 +1,43 @@
+package datastore
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestSetIntsetEncoding(t *testing.T) {
+	set := NewUnorderedSet()
+	for _, member := range []string{"3", "-1", "2", "3"} {
+		set.Add(member)
+	}
+	if !set.IsIntset() || set.Len() != 3 {
+		t.Fatalf("set of integers should use the intset encoding")
+	}
+	if members := set.Members(); !slices.Equal(members, []string{"-1", "2", "3"}) {
+		t.Fatalf("unexpected members %v", members)
+	}
+	if set.Contains("02") || set.Add("+2") == false || set.IsIntset() {
+		t.Fatalf("non canonical integers should convert the set to a map")
+	}
+	if !set.Contains("2") || !set.Contains("+2") || set.Len() != 4 {
+		t.Fatalf("members should be kept after the conversion")
+	}
+}
+
+func TestSetIntsetMaxEntries(t *testing.T) {
+	set := NewUnorderedSet()
+	for i := 0; i < maxIntsetEntries; i++ {
+		set.Add(strconv.Itoa(i))
+	}
+	if !set.IsIntset() {
+		t.Fatalf("set should still use the intset encoding")
+	}
+	set.Add(strconv.Itoa(maxIntsetEntries))
+	if set.IsIntset() || set.Len() != maxIntsetEntries+1 {
+		t.Fatalf("set should be converted once it grows past the intset limit")
+	}
+	if !set.Remove("0") || set.Remove("0") || set.Contains("0") {
+		t.Fatalf("unexpected result removing a member")
+	}
+}
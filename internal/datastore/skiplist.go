@@ -0,0 +1,156 @@
+package datastore
+
+import (
+	"math/rand/v2"
+)
+
+// The same parameters Redis uses: each level has a quarter of the nodes of the level
+// below it, up to 32 levels.
+const (
+	skiplistMaxLevel    = 32
+	skiplistProbability = 0.25
+)
+
+// skiplist keeps the entries of a sorted set in order. Each link stores its span, the
+// number of nodes it skips, so the rank of a node is found while searching for it.
+type skiplist struct {
+	header *skiplistNode
+	tail   *skiplistNode
+	length int
+	level  int
+}
+
+type skiplistNode struct {
+	entry    ZEntry
+	backward *skiplistNode
+	level    []skiplistLevel
+}
+
+type skiplistLevel struct {
+	forward *skiplistNode
+	span    int
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{
+		header: &skiplistNode{level: make([]skiplistLevel, skiplistMaxLevel)},
+		level:  1,
+	}
+}
+
+func randomLevel() int {
+	level := 1
+	for level < skiplistMaxLevel && rand.Float64() < skiplistProbability {
+		level++
+	}
+	return level
+}
+
+// insert adds the entry, which must not be in the skiplist yet.
+func (zsl *skiplist) insert(entry ZEntry) {
+	var update [skiplistMaxLevel]*skiplistNode
+	var rank [skiplistMaxLevel]int
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		if i < zsl.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && compareEntries(x.level[i].forward.entry, entry) < 0 {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+	level := randomLevel()
+	if level > zsl.level {
+		for i := zsl.level; i < level; i++ {
+			update[i] = zsl.header
+			update[i].level[i].span = zsl.length
+		}
+		zsl.level = level
+	}
+	node := &skiplistNode{entry: entry, level: make([]skiplistLevel, level)}
+	for i := 0; i < level; i++ {
+		node.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = node
+		node.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+	// The levels above the new node now skip one more node
+	for i := level; i < zsl.level; i++ {
+		update[i].level[i].span++
+	}
+	if update[0] != zsl.header {
+		node.backward = update[0]
+	}
+	if node.level[0].forward != nil {
+		node.level[0].forward.backward = node
+	} else {
+		zsl.tail = node
+	}
+	zsl.length++
+}
+
+// delete removes the entry, returning false if it isn't in the skiplist.
+func (zsl *skiplist) delete(entry ZEntry) bool {
+	var update [skiplistMaxLevel]*skiplistNode
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && compareEntries(x.level[i].forward.entry, entry) < 0 {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+	x = x.level[0].forward
+	if x == nil || compareEntries(x.entry, entry) != 0 {
+		return false
+	}
+	for i := 0; i < zsl.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		zsl.tail = x.backward
+	}
+	for zsl.level > 1 && zsl.header.level[zsl.level-1].forward == nil {
+		zsl.level--
+	}
+	zsl.length--
+	return true
+}
+
+// nodeByRank returns the node with the 1 based rank.
+func (zsl *skiplist) nodeByRank(rank int) *skiplistNode {
+	traversed := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+	return nil
+}
+
+// countWhile returns how many entries, from the lowest, match the predicate, which
+// must hold for a prefix of the entries and not for the rest.
+func (zsl *skiplist) countWhile(predicate func(e ZEntry) bool) int {
+	traversed := 0
+	x := zsl.header
+	for i := zsl.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && predicate(x.level[i].forward.entry) {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+	}
+	return traversed
+}
@@ -0,0 +1,1056 @@
+package datastore
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+	"github.com/mhsantos/redis-server/internal/rdb"
+)
+
+// Snapshots are written in the RDB format of Redis, so the files can be loaded by Redis
+// and inspected with the tools written for it. Each type is written in a format every
+// recent Redis can load: lists as quicklists of listpacks, sets as intsets or plain sets,
+// sorted sets with binary scores and streams as listpacks. Loading also accepts the
+// listpack encodings Redis uses for small hashes, sets and sorted sets.
+
+const (
+	// redisVersion is the version of Redis written in the snapshots, the first one that
+	// writes rdb.Version.
+	redisVersion = "7.4.0"
+	// snapshotTimeCheckInterval is how many keys are written between checks of the time
+	// budget of a background save cycle.
+	snapshotTimeCheckInterval = 64
+	// snapshotChunksQueued is the most chunks of a background save waiting to be written
+	// to the file. Once it's full the chunks are kept in memory until there's room.
+	snapshotChunksQueued = 16
+)
+
+// The flags of the entries of a stream listpack.
+const (
+	streamItemDeleted    = 1
+	streamItemSameFields = 2
+)
+
+var (
+	// lastSave is the Unix time in seconds of the last successful save.
+	lastSave = time.Now().Unix()
+	// savedDirty is the value of the dirty counter when the last successful save started,
+	// so the writes done since then are the changes not saved yet.
+	savedDirty uint64
+	// bgsave is the background save in progress, if any.
+	bgsave *backgroundSave
+)
+
+// ErrBackgroundSaveInProgress is returned when a save is started while a background
+// save is in progress.
+var ErrBackgroundSaveInProgress = errors.New("Background save already in progress")
+
+// LastSave returns the Unix time in seconds of the last successful save, or the time the
+// server started if there was none.
+func LastSave() int64 {
+	return lastSave
+}
+
+// ChangesSinceSave returns the number of writes to the datastore since the last
+// successful save started.
+func ChangesSinceSave() uint64 {
+	return dirty - savedDirty
+}
+
+// Save writes a snapshot of the datastore and the code of the function libraries to the
+// file at path, blocking until it's done. The snapshot is written to a temporary file
+// first, which replaces the file only once it's complete, so a failed save never leaves
+// a broken snapshot behind.
+func Save(path string, functions [][]byte) error {
+	if bgsave != nil {
+		return ErrBackgroundSaveInProgress
+	}
+	start := dirty
+	file, err := os.CreateTemp(filepath.Dir(path), "temp-*.rdb")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	encoder := rdb.NewEncoder(w)
+	writeSnapshotHeader(encoder, functions, len(store), len(expires))
+	for key, val := range store {
+		writeKey(encoder, key, val)
+	}
+	encoder.WriteFooter()
+	err = encoder.Err()
+	if err == nil {
+		err = w.Flush()
+	}
+	if err = finishSnapshotFile(file, path, err); err != nil {
+		return err
+	}
+	saved(start)
+	return nil
+}
+
+// finishSnapshotFile syncs and closes the temporary file of a snapshot, renaming it to
+// path if the snapshot was written without errors and removing it otherwise.
+func finishSnapshotFile(file *os.File, path string, err error) error {
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		// Temporary files are only readable by their owner, unlike the snapshots of Redis
+		err = file.Chmod(0o644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+func saved(start uint64) {
+	lastSave = time.Now().Unix()
+	savedDirty = start
+}
+
+// backgroundSave is a snapshot written while the task loop keeps serving commands. Go
+// can't fork the process like Redis does, so the snapshot is taken with copy on write
+// at the level of the keys: pending holds the keys that weren't written yet, with their
+// values when the save started. BackgroundSaveCycle writes them a few at a time, and a
+// key is written right away when it's accessed, before the command accessing it can
+// modify it. The keys are serialized in the task loop and the chunks of the file are
+// written by another goroutine, so the task loop never waits for the disk.
+type backgroundSave struct {
+	pending map[string]Value
+	buf     bytes.Buffer
+	encoder *rdb.Encoder
+	dirty   uint64
+	// footer is set once all the keys and the footer were serialized.
+	footer bool
+	// closed is set once the last chunk was handed to the writer.
+	closed bool
+	chunks chan []byte
+	result chan error
+}
+
+// StartBackgroundSave starts saving a snapshot of the datastore and the code of the
+// function libraries to the file at path. The save moves forward on each call to
+// BackgroundSaveCycle, which tells when it's over.
+func StartBackgroundSave(path string, functions [][]byte) error {
+	if bgsave != nil {
+		return ErrBackgroundSaveInProgress
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "temp-*.rdb")
+	if err != nil {
+		return err
+	}
+	bgsave = &backgroundSave{
+		pending: make(map[string]Value, len(store)),
+		dirty:   dirty,
+		chunks:  make(chan []byte, snapshotChunksQueued),
+		result:  make(chan error, 1),
+	}
+	for key, val := range store {
+		bgsave.pending[key] = val
+	}
+	bgsave.encoder = rdb.NewEncoder(&bgsave.buf)
+	writeSnapshotHeader(bgsave.encoder, functions, len(store), len(expires))
+	go writeChunks(file, path, bgsave.chunks, bgsave.result)
+	return nil
+}
+
+// writeChunks writes the chunks of a background save to the file until the channel is
+// closed, sending the result to result. After an error it keeps receiving the chunks,
+// so the task loop is never blocked by a failed save.
+func writeChunks(file *os.File, path string, chunks <-chan []byte, result chan<- error) {
+	var err error
+	for chunk := range chunks {
+		if err == nil {
+			_, err = file.Write(chunk)
+		}
+	}
+	result <- finishSnapshotFile(file, path, err)
+}
+
+// BackgroundSaveInProgress tells if a background save is in progress.
+func BackgroundSaveInProgress() bool {
+	return bgsave != nil
+}
+
+// BackgroundSaveCycle writes pending keys of the background save in progress until the
+// time budget is used. done is set once the save is over, with the error that made it
+// fail, if any. Like the rest of the datastore, it must be called from the task loop.
+func BackgroundSaveCycle(budget time.Duration) (done bool, err error) {
+	if bgsave == nil {
+		return false, nil
+	}
+	start := time.Now()
+	if !bgsave.footer {
+		written := 0
+		for key, val := range bgsave.pending {
+			delete(bgsave.pending, key)
+			writeKey(bgsave.encoder, key, val)
+			written++
+			if written%snapshotTimeCheckInterval == 0 && time.Since(start) > budget {
+				break
+			}
+		}
+		if len(bgsave.pending) == 0 {
+			bgsave.encoder.WriteFooter()
+			bgsave.footer = true
+		}
+	}
+	if bgsave.buf.Len() > 0 {
+		select {
+		case bgsave.chunks <- bytes.Clone(bgsave.buf.Bytes()):
+			bgsave.buf.Reset()
+		default:
+		}
+	}
+	if bgsave.footer && bgsave.buf.Len() == 0 && !bgsave.closed {
+		close(bgsave.chunks)
+		bgsave.closed = true
+	}
+	select {
+	case err := <-bgsave.result:
+		if err == nil {
+			saved(bgsave.dirty)
+		}
+		bgsave = nil
+		return true, err
+	default:
+	}
+	return false, nil
+}
+
+// snapshotKey writes the key to the background save in progress if it wasn't written
+// yet, so it's saved with the value it had when the save started. It must be called
+// before the key is modified or replaced.
+func snapshotKey(key string) {
+	if bgsave == nil {
+		return
+	}
+	if val, ok := bgsave.pending[key]; ok {
+		delete(bgsave.pending, key)
+		writeKey(bgsave.encoder, key, val)
+	}
+}
+
+func writeSnapshotHeader(e *rdb.Encoder, functions [][]byte, keys, expiring int) {
+	e.WriteHeader()
+	e.WriteAux("redis-ver", redisVersion)
+	e.WriteAux("redis-bits", strconv.Itoa(strconv.IntSize))
+	e.WriteAux("ctime", strconv.FormatInt(time.Now().Unix(), 10))
+	e.WriteAux("aof-base", "0")
+	for _, code := range functions {
+		e.WriteOpcode(rdb.OpcodeFunction)
+		e.WriteString(code)
+	}
+	e.WriteOpcode(rdb.OpcodeSelectDB)
+	e.WriteLength(0)
+	e.WriteOpcode(rdb.OpcodeResizeDB)
+	e.WriteLength(uint64(keys))
+	e.WriteLength(uint64(expiring))
+}
+
+// writeKey writes the key with its value and expiration. Expired keys are skipped.
+func writeKey(e *rdb.Encoder, key string, val Value) {
+	if val.IsExpired() {
+		return
+	}
+	if val.expire > 0 {
+		e.WriteOpcode(rdb.OpcodeExpireTimeMs)
+		e.WriteMillis(val.expire)
+	}
+	switch value := val.value.(type) {
+	case int64:
+		e.WriteOpcode(rdb.TypeString)
+		e.WriteString([]byte(key))
+		e.WriteString(strconv.AppendInt(nil, value, 10))
+	case protocol.DataType:
+		e.WriteOpcode(rdb.TypeString)
+		e.WriteString([]byte(key))
+		e.WriteString([]byte(value.String()))
+	case *List:
+		e.WriteOpcode(rdb.TypeListQuicklist2)
+		e.WriteString([]byte(key))
+		value.writeTo(e)
+	case *Hash:
+		if len(value.expiring) > 0 {
+			e.WriteOpcode(rdb.TypeHashMetadata)
+		} else {
+			e.WriteOpcode(rdb.TypeHash)
+		}
+		e.WriteString([]byte(key))
+		value.writeTo(e)
+	case *UnorderedSet:
+		if value.IsIntset() {
+			e.WriteOpcode(rdb.TypeSetIntset)
+		} else {
+			e.WriteOpcode(rdb.TypeSet)
+		}
+		e.WriteString([]byte(key))
+		value.writeTo(e)
+	case *SortedSet:
+		e.WriteOpcode(rdb.TypeZSet2)
+		e.WriteString([]byte(key))
+		value.writeTo(e)
+	case *Stream:
+		e.WriteOpcode(rdb.TypeStreamListpacks3)
+		e.WriteString([]byte(key))
+		value.writeTo(e)
+	}
+}
+
+// writeTo writes each node of the list as a listpack.
+func (l *List) writeTo(e *rdb.Encoder) {
+	nodes := 0
+	for node := l.head; node != nil; node = node.next {
+		nodes++
+	}
+	e.WriteLength(uint64(nodes))
+	for node := l.head; node != nil; node = node.next {
+		var lp rdb.Listpack
+		for _, entry := range node.entries {
+			lp.AppendString(entry)
+		}
+		e.WriteLength(rdb.QuicklistPacked)
+		e.WriteString(lp.Bytes())
+	}
+}
+
+// writeTo writes the fields and their values. Hashes with fields that expire are
+// written with the expiration of each field, relative to the one that expires first.
+func (h *Hash) writeTo(e *rdb.Encoder) {
+	var minExpire int64
+	for field := range h.expiring {
+		if expire := h.fields[field].expire; minExpire == 0 || expire < minExpire {
+			minExpire = expire
+		}
+	}
+	if minExpire > 0 {
+		e.WriteMillis(minExpire)
+	}
+	e.WriteLength(uint64(len(h.fields)))
+	for field, f := range h.fields {
+		if minExpire > 0 {
+			if f.expire > 0 {
+				e.WriteLength(uint64(f.expire - minExpire + 1))
+			} else {
+				e.WriteLength(0)
+			}
+		}
+		e.WriteString([]byte(field))
+		e.WriteString(f.value)
+	}
+}
+
+// writeTo writes the members, as an intset if the set uses that encoding.
+func (s *UnorderedSet) writeTo(e *rdb.Encoder) {
+	if s.IsIntset() {
+		e.WriteString(rdb.Intset(s.intset))
+		return
+	}
+	e.WriteLength(uint64(len(s.members)))
+	for member := range s.members {
+		e.WriteString([]byte(member))
+	}
+}
+
+// writeTo writes the members with their scores.
+func (z *SortedSet) writeTo(e *rdb.Encoder) {
+	e.WriteLength(uint64(z.Len()))
+	for _, entry := range z.Range(0, z.Len()) {
+		e.WriteString([]byte(entry.Member))
+		e.WriteDouble(entry.Score)
+	}
+}
+
+// writeTo writes each chunk of the stream as a listpack, followed by the metadata of
+// the stream and its consumer groups. The entries don't use the fields of the master
+// entry, so the master entry of every listpack has no fields.
+func (s *Stream) writeTo(e *rdb.Encoder) {
+	e.WriteLength(uint64(len(s.chunks)))
+	for _, chunk := range s.chunks {
+		master := chunk.entries[0].ID
+		var lp rdb.Listpack
+		lp.AppendInteger(int64(len(chunk.entries)))
+		// The deleted entries and the fields of the master entry, followed by its end
+		lp.AppendInteger(0)
+		lp.AppendInteger(0)
+		lp.AppendInteger(0)
+		for _, entry := range chunk.entries {
+			pairs := len(entry.Fields) / 2
+			lp.AppendInteger(0)
+			lp.AppendInteger(int64(entry.ID.Ms - master.Ms))
+			lp.AppendInteger(int64(entry.ID.Seq - master.Seq))
+			lp.AppendInteger(int64(pairs))
+			for _, field := range entry.Fields {
+				lp.AppendString(field)
+			}
+			lp.AppendInteger(int64(2*pairs + 4))
+		}
+		e.WriteString(rawStreamID(master))
+		e.WriteString(lp.Bytes())
+	}
+	first, _ := s.First()
+	e.WriteLength(uint64(s.length))
+	writeStreamID(e, s.lastID)
+	writeStreamID(e, first.ID)
+	writeStreamID(e, s.maxDeletedID)
+	e.WriteLength(uint64(s.entriesAdded))
+	groups := s.Groups()
+	e.WriteLength(uint64(len(groups)))
+	for _, group := range groups {
+		e.WriteString([]byte(group.name))
+		writeStreamID(e, group.lastID)
+		e.WriteLength(uint64(group.entriesRead))
+		pending := group.PendingRange(StreamID{}, MaxStreamID)
+		e.WriteLength(uint64(len(pending)))
+		for _, entry := range pending {
+			e.WriteRaw(rawStreamID(entry.ID))
+			e.WriteMillis(entry.DeliveryTime)
+			e.WriteLength(uint64(entry.DeliveryCount))
+		}
+		consumers := group.Consumers()
+		e.WriteLength(uint64(len(consumers)))
+		for _, consumer := range consumers {
+			e.WriteString([]byte(consumer.name))
+			e.WriteMillis(consumer.seenTime)
+			e.WriteMillis(consumer.activeTime)
+			pending := consumer.PendingRange(StreamID{}, MaxStreamID)
+			e.WriteLength(uint64(len(pending)))
+			for _, entry := range pending {
+				e.WriteRaw(rawStreamID(entry.ID))
+			}
+		}
+	}
+}
+
+func writeStreamID(e *rdb.Encoder, id StreamID) {
+	e.WriteLength(id.Ms)
+	e.WriteLength(id.Seq)
+}
+
+// rawStreamID returns the ID as 16 bytes in big endian, which is how the IDs of the
+// pending entries and of the first entry of each listpack are written.
+func rawStreamID(id StreamID) []byte {
+	raw := make([]byte, 16)
+	for i := 0; i < 8; i++ {
+		raw[i] = byte(id.Ms >> (56 - 8*i))
+		raw[8+i] = byte(id.Seq >> (56 - 8*i))
+	}
+	return raw
+}
+
+func parseRawStreamID(raw []byte) (StreamID, error) {
+	if len(raw) != 16 {
+		return StreamID{}, fmt.Errorf("%w: invalid stream ID", rdb.ErrFormat)
+	}
+	var id StreamID
+	for i := 0; i < 8; i++ {
+		id.Ms = id.Ms<<8 | uint64(raw[i])
+		id.Seq = id.Seq<<8 | uint64(raw[8+i])
+	}
+	return id, nil
+}
+
+// Load replaces the contents of the datastore with the snapshot in the file at path,
+// returning the code of the function libraries in it. Expired keys aren't loaded, and
+// only the keys of the first database are, since the datastore has a single one. It
+// must be called before the task loop starts.
+func Load(path string) ([][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	d := rdb.NewDecoder(file)
+	version, err := d.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+	loaded := make(map[string]Value)
+	loadedExpires := make(map[string]struct{})
+	functions := [][]byte{}
+	db := uint64(0)
+	var expire int64
+	now := time.Now().UnixMilli()
+	for {
+		opcode, err := d.ReadOpcode()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case rdb.OpcodeEOF:
+			if version >= 5 {
+				if err := d.ReadFooter(); err != nil {
+					return nil, err
+				}
+			}
+			store, expires = loaded, loadedExpires
+			for key, val := range store {
+				val.version = nextVersion()
+				store[key] = val
+			}
+			savedDirty = dirty
+			lastSave = time.Now().Unix()
+			return functions, nil
+		case rdb.OpcodeAux:
+			if _, err := d.ReadString(); err != nil {
+				return nil, err
+			}
+			if _, err := d.ReadString(); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeFunction:
+			code, err := d.ReadString()
+			if err != nil {
+				return nil, err
+			}
+			functions = append(functions, code)
+		case rdb.OpcodeSelectDB:
+			if db, err = d.ReadLength(); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeResizeDB:
+			if err := readLengths(d, 2); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeSlotInfo:
+			if err := readLengths(d, 3); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeIdle:
+			if err := readLengths(d, 1); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeFreq:
+			if _, err := d.ReadOpcode(); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeExpireTimeMs:
+			if expire, err = d.ReadMillis(); err != nil {
+				return nil, err
+			}
+		case rdb.OpcodeExpireTime:
+			seconds, err := d.ReadSeconds()
+			if err != nil {
+				return nil, err
+			}
+			expire = seconds * 1000
+		default:
+			key, err := d.ReadString()
+			if err != nil {
+				return nil, err
+			}
+			val, err := readValue(d, opcode, now)
+			if err != nil {
+				return nil, fmt.Errorf("loading key %q: %w", key, err)
+			}
+			if db == 0 && val.value != nil && (expire == 0 || expire > now) {
+				val.expire = expire
+				loaded[string(key)] = val
+				if expire > 0 {
+					loadedExpires[string(key)] = struct{}{}
+				}
+			}
+			expire = 0
+		}
+	}
+}
+
+func readLengths(d *rdb.Decoder, n int) error {
+	for i := 0; i < n; i++ {
+		if _, err := d.ReadLength(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStrings reads a length followed by that many strings.
+func readStrings(d *rdb.Decoder, perItem int) ([][]byte, error) {
+	n, err := d.ReadLength()
+	if err != nil {
+		return nil, err
+	}
+	strs := make([][]byte, 0, min(n*uint64(perItem), 1024))
+	for i := uint64(0); i < n*uint64(perItem); i++ {
+		s, err := d.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+	return strs, nil
+}
+
+// readListpack reads a listpack, checking it has a multiple of perItem entries.
+func readListpack(d *rdb.Decoder, perItem int) ([][]byte, error) {
+	data, err := d.ReadString()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := rdb.ListpackEntries(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries)%perItem != 0 {
+		return nil, fmt.Errorf("%w: invalid listpack length", rdb.ErrFormat)
+	}
+	return entries, nil
+}
+
+// readValue reads a value of the type. The value is nil if it's empty once the fields
+// already expired at now are removed.
+func readValue(d *rdb.Decoder, kind byte, now int64) (Value, error) {
+	switch kind {
+	case rdb.TypeString:
+		s, err := d.ReadString()
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{value: stringValue(protocol.NewBulkString(s)), kind: TypeString}, nil
+	case rdb.TypeList, rdb.TypeListQuicklist2:
+		list, err := readList(d, kind)
+		return collectionValue(list, TypeList, list.Len(), err)
+	case rdb.TypeSet, rdb.TypeSetIntset, rdb.TypeSetListpack:
+		set, err := readSet(d, kind)
+		return collectionValue(set, TypeSet, set.Len(), err)
+	case rdb.TypeZSet, rdb.TypeZSet2, rdb.TypeZSetListpack:
+		zset, err := readSortedSet(d, kind)
+		return collectionValue(zset, TypeSortedSet, zset.Len(), err)
+	case rdb.TypeHash, rdb.TypeHashListpack, rdb.TypeHashMetadata, rdb.TypeHashListpackEx:
+		hash, err := readHash(d, kind, now)
+		return collectionValue(hash, TypeHash, hash.Len(), err)
+	case rdb.TypeStreamListpacks, rdb.TypeStreamListpacks2, rdb.TypeStreamListpacks3:
+		stream, err := readStream(d, kind)
+		if err != nil {
+			return Value{}, err
+		}
+		return Value{value: stream, kind: TypeStream}, nil
+	}
+	return Value{}, fmt.Errorf("%w: unsupported value type %d", rdb.ErrFormat, kind)
+}
+
+// collectionValue returns the value of a collection, or an empty Value if the
+// collection is empty, since empty collections aren't kept in a key.
+func collectionValue(collection any, kind ObjectType, length int, err error) (Value, error) {
+	if err != nil || length == 0 {
+		return Value{}, err
+	}
+	return Value{value: collection, kind: kind}, nil
+}
+
+func readList(d *rdb.Decoder, kind byte) (*List, error) {
+	list := NewList()
+	if kind == rdb.TypeList {
+		entries, err := readStrings(d, 1)
+		for _, entry := range entries {
+			list.PushBack(entry)
+		}
+		return list, err
+	}
+	nodes, err := d.ReadLength()
+	if err != nil {
+		return list, err
+	}
+	for i := uint64(0); i < nodes; i++ {
+		container, err := d.ReadLength()
+		if err != nil {
+			return list, err
+		}
+		if container == rdb.QuicklistPlain {
+			entry, err := d.ReadString()
+			if err != nil {
+				return list, err
+			}
+			list.PushBack(entry)
+			continue
+		}
+		entries, err := readListpack(d, 1)
+		if err != nil {
+			return list, err
+		}
+		for _, entry := range entries {
+			list.PushBack(entry)
+		}
+	}
+	return list, nil
+}
+
+func readSet(d *rdb.Decoder, kind byte) (*UnorderedSet, error) {
+	set := NewUnorderedSet()
+	var members [][]byte
+	var err error
+	switch kind {
+	case rdb.TypeSet:
+		members, err = readStrings(d, 1)
+	case rdb.TypeSetListpack:
+		members, err = readListpack(d, 1)
+	case rdb.TypeSetIntset:
+		var data []byte
+		if data, err = d.ReadString(); err != nil {
+			return set, err
+		}
+		values, err := rdb.IntsetEntries(data)
+		for _, value := range values {
+			set.Add(strconv.FormatInt(value, 10))
+		}
+		return set, err
+	}
+	for _, member := range members {
+		set.Add(string(member))
+	}
+	return set, err
+}
+
+func readSortedSet(d *rdb.Decoder, kind byte) (*SortedSet, error) {
+	zset := NewSortedSet()
+	if kind == rdb.TypeZSetListpack {
+		entries, err := readListpack(d, 2)
+		if err != nil {
+			return zset, err
+		}
+		for i := 0; i < len(entries); i += 2 {
+			score, err := strconv.ParseFloat(string(entries[i+1]), 64)
+			if err != nil {
+				return zset, fmt.Errorf("%w: invalid score %q", rdb.ErrFormat, entries[i+1])
+			}
+			zset.Add(string(entries[i]), score)
+		}
+		return zset, nil
+	}
+	n, err := d.ReadLength()
+	if err != nil {
+		return zset, err
+	}
+	for i := uint64(0); i < n; i++ {
+		member, err := d.ReadString()
+		if err != nil {
+			return zset, err
+		}
+		var score float64
+		if kind == rdb.TypeZSet2 {
+			score, err = d.ReadDouble()
+		} else {
+			score, err = d.ReadStringDouble()
+		}
+		if err != nil {
+			return zset, err
+		}
+		zset.Add(string(member), score)
+	}
+	return zset, nil
+}
+
+// readHash reads a hash, dropping the fields already expired at now.
+func readHash(d *rdb.Decoder, kind byte, now int64) (*Hash, error) {
+	hash := NewHash()
+	set := func(field, value []byte, expire int64) {
+		if expire > 0 && expire <= now {
+			return
+		}
+		hash.fields[string(field)] = hashField{value: value, expire: expire}
+		if expire > 0 {
+			hash.expiring[string(field)] = struct{}{}
+		}
+	}
+	switch kind {
+	case rdb.TypeHash:
+		entries, err := readStrings(d, 2)
+		for i := 0; i+1 < len(entries); i += 2 {
+			set(entries[i], entries[i+1], 0)
+		}
+		return hash, err
+	case rdb.TypeHashListpack:
+		entries, err := readListpack(d, 2)
+		for i := 0; i < len(entries); i += 2 {
+			set(entries[i], entries[i+1], 0)
+		}
+		return hash, err
+	case rdb.TypeHashListpackEx:
+		// The expiration of the first field to expire, followed by a listpack with the
+		// fields, their values and their expirations
+		if _, err := d.ReadMillis(); err != nil {
+			return hash, err
+		}
+		entries, err := readListpack(d, 3)
+		if err != nil {
+			return hash, err
+		}
+		for i := 0; i < len(entries); i += 3 {
+			expire, err := strconv.ParseInt(string(entries[i+2]), 10, 64)
+			if err != nil {
+				return hash, fmt.Errorf("%w: invalid field expiration", rdb.ErrFormat)
+			}
+			set(entries[i], entries[i+1], expire)
+		}
+		return hash, nil
+	}
+	minExpire, err := d.ReadMillis()
+	if err != nil {
+		return hash, err
+	}
+	n, err := d.ReadLength()
+	if err != nil {
+		return hash, err
+	}
+	for i := uint64(0); i < n; i++ {
+		ttl, err := d.ReadLength()
+		if err != nil {
+			return hash, err
+		}
+		field, err := d.ReadString()
+		if err != nil {
+			return hash, err
+		}
+		value, err := d.ReadString()
+		if err != nil {
+			return hash, err
+		}
+		var expire int64
+		if ttl > 0 {
+			expire = minExpire + int64(ttl) - 1
+		}
+		set(field, value, expire)
+	}
+	return hash, nil
+}
+
+func readStream(d *rdb.Decoder, kind byte) (*Stream, error) {
+	stream := NewStream()
+	nodes, err := d.ReadLength()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < nodes; i++ {
+		raw, err := d.ReadString()
+		if err != nil {
+			return nil, err
+		}
+		master, err := parseRawStreamID(raw)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := readListpack(d, 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := readStreamListpack(stream, master, entries); err != nil {
+			return nil, err
+		}
+	}
+	length, err := d.ReadLength()
+	if err != nil {
+		return nil, err
+	}
+	if length != uint64(stream.length) {
+		return nil, fmt.Errorf("%w: wrong stream length", rdb.ErrFormat)
+	}
+	if stream.lastID, err = readStreamID(d); err != nil {
+		return nil, err
+	}
+	stream.entriesAdded = int64(length)
+	if kind >= rdb.TypeStreamListpacks2 {
+		// The ID of the first entry, which is known from the entries
+		if _, err := readStreamID(d); err != nil {
+			return nil, err
+		}
+		if stream.maxDeletedID, err = readStreamID(d); err != nil {
+			return nil, err
+		}
+		added, err := d.ReadLength()
+		if err != nil {
+			return nil, err
+		}
+		stream.entriesAdded = int64(added)
+	}
+	groups, err := d.ReadLength()
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < groups; i++ {
+		if err := readConsumerGroup(d, stream, kind); err != nil {
+			return nil, err
+		}
+	}
+	return stream, nil
+}
+
+// readStreamListpack appends the entries of a listpack of a stream, whose IDs are
+// relative to the master ID.
+func readStreamListpack(stream *Stream, master StreamID, entries [][]byte) error {
+	invalid := fmt.Errorf("%w: invalid stream listpack", rdb.ErrFormat)
+	i := 0
+	next := func() (int64, bool) {
+		if i >= len(entries) {
+			return 0, false
+		}
+		value, err := strconv.ParseInt(string(entries[i]), 10, 64)
+		i++
+		return value, err == nil
+	}
+	// The master entry: the valid and deleted entries and the master fields
+	next()
+	next()
+	masterFields, ok := next()
+	if !ok || masterFields < 0 || int(masterFields) > len(entries)-i-1 {
+		return invalid
+	}
+	fields := entries[i : i+int(masterFields)]
+	i += int(masterFields) + 1
+	for i < len(entries) {
+		flags, ok1 := next()
+		msDiff, ok2 := next()
+		seqDiff, ok3 := next()
+		if !ok1 || !ok2 || !ok3 {
+			return invalid
+		}
+		var values [][]byte
+		if flags&streamItemSameFields != 0 {
+			if i+len(fields) > len(entries) {
+				return invalid
+			}
+			values = make([][]byte, 0, 2*len(fields))
+			for j, field := range fields {
+				values = append(values, field, entries[i+j])
+			}
+			i += len(fields)
+		} else {
+			pairs, ok := next()
+			if !ok || pairs < 0 || int(2*pairs) > len(entries)-i {
+				return invalid
+			}
+			values = slices.Clone(entries[i : i+int(2*pairs)])
+			i += int(2 * pairs)
+		}
+		// The number of elements of the entry, used to walk the listpack backwards
+		if _, ok := next(); !ok {
+			return invalid
+		}
+		if flags&streamItemDeleted != 0 {
+			continue
+		}
+		id := StreamID{master.Ms + uint64(msDiff), master.Seq + uint64(seqDiff)}
+		if stream.length > 0 && id.Compare(stream.lastID) <= 0 {
+			return invalid
+		}
+		stream.Append(id, values)
+	}
+	return nil
+}
+
+func readStreamID(d *rdb.Decoder) (StreamID, error) {
+	ms, err := d.ReadLength()
+	if err != nil {
+		return StreamID{}, err
+	}
+	seq, err := d.ReadLength()
+	return StreamID{ms, seq}, err
+}
+
+func readConsumerGroup(d *rdb.Decoder, stream *Stream, kind byte) error {
+	name, err := d.ReadString()
+	if err != nil {
+		return err
+	}
+	lastID, err := readStreamID(d)
+	if err != nil {
+		return err
+	}
+	entriesRead := int64(-1)
+	if kind >= rdb.TypeStreamListpacks2 {
+		read, err := d.ReadLength()
+		if err != nil {
+			return err
+		}
+		entriesRead = int64(read)
+	}
+	group, ok := stream.CreateGroup(string(name), lastID, entriesRead)
+	if !ok {
+		return fmt.Errorf("%w: duplicated consumer group", rdb.ErrFormat)
+	}
+	n, err := d.ReadLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < n; i++ {
+		id, err := readRawStreamID(d)
+		if err != nil {
+			return err
+		}
+		deliveryTime, err := d.ReadMillis()
+		if err != nil {
+			return err
+		}
+		deliveryCount, err := d.ReadLength()
+		if err != nil {
+			return err
+		}
+		group.pending[id] = &PendingEntry{ID: id, DeliveryTime: deliveryTime, DeliveryCount: int(deliveryCount)}
+	}
+	consumers, err := d.ReadLength()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < consumers; i++ {
+		name, err := d.ReadString()
+		if err != nil {
+			return err
+		}
+		seenTime, err := d.ReadMillis()
+		if err != nil {
+			return err
+		}
+		activeTime := seenTime
+		if kind >= rdb.TypeStreamListpacks3 {
+			if activeTime, err = d.ReadMillis(); err != nil {
+				return err
+			}
+		}
+		consumer, _ := group.CreateConsumer(string(name), seenTime)
+		consumer.activeTime = activeTime
+		n, err := d.ReadLength()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < n; j++ {
+			id, err := readRawStreamID(d)
+			if err != nil {
+				return err
+			}
+			entry, ok := group.pending[id]
+			if !ok || entry.consumer != nil {
+				return fmt.Errorf("%w: invalid pending entry of a consumer", rdb.ErrFormat)
+			}
+			entry.consumer = consumer
+			consumer.pending[id] = entry
+		}
+	}
+	for _, entry := range group.pending {
+		if entry.consumer == nil {
+			return fmt.Errorf("%w: pending entry without a consumer", rdb.ErrFormat)
+		}
+	}
+	return nil
+}
+
+func readRawStreamID(d *rdb.Decoder) (StreamID, error) {
+	raw, err := d.ReadRaw(16)
+	if err != nil {
+		return StreamID{}, err
+	}
+	return parseRawStreamID(raw)
+}
@@ -0,0 +1,207 @@
+package datastore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+	"github.com/mhsantos/redis-server/internal/rdb"
+)
+
+func getString(t *testing.T, key string) string {
+	t.Helper()
+	val, ok, err := Get(key)
+	if !ok || err != nil {
+		t.Fatalf("missing string %s, error: %v", key, err)
+	}
+	return val.String()
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	now := time.Now().UnixMilli()
+	Set("snap-string", protocol.NewBulkString([]byte("value")))
+	SetWithExpire("snap-int", protocol.NewBulkString([]byte("42")), now+60000)
+	SetWithExpire("snap-expired", protocol.NewBulkString([]byte("gone")), now-1000)
+	list, _ := GetOrCreateList("snap-list")
+	for i := 0; i < 300; i++ {
+		list.PushBack([]byte("entry-" + strconv.Itoa(i)))
+	}
+	intset, _ := GetOrCreateSet("snap-intset")
+	intset.Add("3")
+	intset.Add("-70000")
+	set, _ := GetOrCreateSet("snap-set")
+	set.Add("a")
+	set.Add("b")
+	small, _ := GetOrCreateSortedSet("snap-zset")
+	small.Add("a", 1.5)
+	small.Add("b", -2)
+	big, _ := GetOrCreateSortedSet("snap-zset-big")
+	for i := 0; i < 200; i++ {
+		big.Add("member-"+strconv.Itoa(i), float64(i%7))
+	}
+	hash, _ := GetOrCreateHash("snap-hash")
+	hash.Set("field", []byte("value"))
+	hash.Set("expiring", []byte("soon"))
+	hash.SetExpire("expiring", now+30000)
+	stream, _ := GetOrCreateStream("snap-stream")
+	for i := 1; i <= 150; i++ {
+		stream.Append(StreamID{uint64(i), 1}, [][]byte{[]byte("f"), []byte(strconv.Itoa(i))})
+	}
+	stream.Delete(StreamID{150, 1})
+	group, _ := stream.CreateGroup("group", StreamID{2, 1}, 2)
+	consumer, _ := group.CreateConsumer("consumer", now)
+	group.Deliver(consumer, StreamID{1, 1}, now)
+	group.Deliver(consumer, StreamID{2, 1}, now)
+	group.CreateConsumer("idle", now)
+
+	expected := map[string]any{
+		"list":     list.Range(0, -1),
+		"zset":     small.Range(0, small.Len()),
+		"zset-big": big.Range(0, big.Len()),
+		"stream":   stream.Range(StreamID{}, MaxStreamID, 0, false),
+	}
+	path := filepath.Join(t.TempDir(), "dump.rdb")
+	functions := [][]byte{[]byte("#!lua name=lib\nredis.register_function('f', function() return 1 end)")}
+	if err := Save(path, functions); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	if ChangesSinceSave() != 0 {
+		t.Fatalf("there should be no changes since the save, got %d", ChangesSinceSave())
+	}
+	loadedFunctions, err := Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if !reflect.DeepEqual(loadedFunctions, functions) {
+		t.Fatalf("unexpected functions %q", loadedFunctions)
+	}
+
+	if getString(t, "snap-string") != "value" || getString(t, "snap-int") != "42" {
+		t.Fatalf("unexpected strings")
+	}
+	if expire, _ := GetExpire("snap-int"); expire != now+60000 {
+		t.Fatalf("unexpected expire %d", expire)
+	}
+	if Exists("snap-expired") {
+		t.Fatalf("expired keys shouldn't be saved")
+	}
+	list, _, _ = GetList("snap-list")
+	if !reflect.DeepEqual(list.Range(0, -1), expected["list"]) {
+		t.Fatalf("unexpected list entries")
+	}
+	intset, _, _ = GetSet("snap-intset")
+	if members := intset.Members(); !intset.IsIntset() || !slices.Equal(members, []string{"-70000", "3"}) {
+		t.Fatalf("unexpected intset %v", members)
+	}
+	set, _, _ = GetSet("snap-set")
+	members := set.Members()
+	slices.Sort(members)
+	if !slices.Equal(members, []string{"a", "b"}) {
+		t.Fatalf("unexpected set %v", members)
+	}
+	small, _, _ = GetSortedSet("snap-zset")
+	big, _, _ = GetSortedSet("snap-zset-big")
+	if !reflect.DeepEqual(small.Range(0, small.Len()), expected["zset"]) || !reflect.DeepEqual(big.Range(0, big.Len()), expected["zset-big"]) {
+		t.Fatalf("unexpected sorted set entries")
+	}
+	hash, _, _ = GetHash("snap-hash")
+	if value, _ := hash.Get("field"); string(value) != "value" {
+		t.Fatalf("unexpected hash value %q", value)
+	}
+	if expire, _ := hash.GetExpire("expiring"); expire != now+30000 {
+		t.Fatalf("unexpected field expire %d", expire)
+	}
+	stream, _, _ = GetStream("snap-stream")
+	if !reflect.DeepEqual(stream.Range(StreamID{}, MaxStreamID, 0, false), expected["stream"]) {
+		t.Fatalf("unexpected stream entries")
+	}
+	if stream.LastID() != (StreamID{150, 1}) || stream.MaxDeletedID() != (StreamID{150, 1}) || stream.EntriesAdded() != 150 {
+		t.Fatalf("unexpected stream metadata %v %v %d", stream.LastID(), stream.MaxDeletedID(), stream.EntriesAdded())
+	}
+	group, _ = stream.Group("group")
+	if group.LastID() != (StreamID{2, 1}) || group.EntriesRead() != 2 || group.PendingCount() != 2 || len(group.Consumers()) != 2 {
+		t.Fatalf("unexpected consumer group")
+	}
+	consumer, _ = group.Consumer("consumer")
+	pending := consumer.PendingRange(StreamID{}, MaxStreamID)
+	if len(pending) != 2 || pending[1].DeliveryTime != now || pending[1].DeliveryCount != 1 || pending[1].Consumer() != consumer {
+		t.Fatalf("unexpected pending entries %v", pending)
+	}
+}
+
+func TestBackgroundSave(t *testing.T) {
+	Set("bgsave-string", protocol.NewBulkString([]byte("before")))
+	list, _ := GetOrCreateList("bgsave-list")
+	list.PushBack([]byte("before"))
+	path := filepath.Join(t.TempDir(), "dump.rdb")
+	if err := StartBackgroundSave(path, nil); err != nil {
+		t.Fatalf("unexpected error starting the save: %v", err)
+	}
+	if err := StartBackgroundSave(path, nil); err != ErrBackgroundSaveInProgress {
+		t.Fatalf("expected an error starting a second save, got %v", err)
+	}
+	if err := Save(path, nil); err != ErrBackgroundSaveInProgress {
+		t.Fatalf("expected an error saving during a background save, got %v", err)
+	}
+
+	// The writes after the save started aren't in the snapshot
+	Set("bgsave-string", protocol.NewBulkString([]byte("after")))
+	list, _ = GetOrCreateList("bgsave-list")
+	list.PushBack([]byte("after"))
+	Set("bgsave-new", protocol.NewBulkString([]byte("after")))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		done, err := BackgroundSaveCycle(time.Millisecond)
+		if done {
+			if err != nil {
+				t.Fatalf("unexpected error saving: %v", err)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the background save didn't finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if BackgroundSaveInProgress() || ChangesSinceSave() < 3 {
+		t.Fatalf("unexpected state after the save. Changes since the save: %d", ChangesSinceSave())
+	}
+
+	if _, err := Load(path); err != nil {
+		t.Fatalf("unexpected error loading: %v", err)
+	}
+	if getString(t, "bgsave-string") != "before" || Exists("bgsave-new") {
+		t.Fatalf("the snapshot should have the keys as they were when it started")
+	}
+	list, _, _ = GetList("bgsave-list")
+	if entries := list.Range(0, -1); len(entries) != 1 || string(entries[0]) != "before" {
+		t.Fatalf("unexpected list entries %q", entries)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.rdb")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected a missing file error, got %v", err)
+	}
+	Set("load-errors", protocol.NewBulkString([]byte("a value long enough to corrupt")))
+	path := filepath.Join(dir, "dump.rdb")
+	if err := Save(path, nil); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	data[len(data)-20]++
+	os.WriteFile(path, data, 0o644)
+	if _, err := Load(path); !errors.Is(err, rdb.ErrChecksum) && !errors.Is(err, rdb.ErrFormat) {
+		t.Fatalf("expected an error loading a corrupted file, got %v", err)
+	}
+	if getString(t, "load-errors") != "a value long enough to corrupt" {
+		t.Fatalf("a failed load shouldn't change the datastore")
+	}
+}
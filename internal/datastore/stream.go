@@ -0,0 +1,573 @@
+package datastore
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// streamChunkSize is the most entries kept in a chunk of a stream. Trimming with the ~
+// option only removes whole chunks, like Redis only removes whole listpack nodes.
+const streamChunkSize = 100
+
+// StreamID identifies an entry of a stream: the Unix time in milliseconds the entry was
+// added at and a sequence number for the entries added in the same millisecond.
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// MaxStreamID is the highest possible stream ID.
+var MaxStreamID = StreamID{math.MaxUint64, math.MaxUint64}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Compare(other StreamID) int {
+	if c := cmp.Compare(id.Ms, other.Ms); c != 0 {
+		return c
+	}
+	return cmp.Compare(id.Seq, other.Seq)
+}
+
+// Next returns the ID right after this one. The bool is false if this is MaxStreamID.
+func (id StreamID) Next() (StreamID, bool) {
+	switch {
+	case id.Seq < math.MaxUint64:
+		return StreamID{id.Ms, id.Seq + 1}, true
+	case id.Ms < math.MaxUint64:
+		return StreamID{id.Ms + 1, 0}, true
+	}
+	return id, false
+}
+
+// Prev returns the ID right before this one. The bool is false if this is 0-0.
+func (id StreamID) Prev() (StreamID, bool) {
+	switch {
+	case id.Seq > 0:
+		return StreamID{id.Ms, id.Seq - 1}, true
+	case id.Ms > 0:
+		return StreamID{id.Ms - 1, math.MaxUint64}, true
+	}
+	return id, false
+}
+
+// StreamEntry is an entry of a stream, with its fields and values interleaved.
+type StreamEntry struct {
+	ID     StreamID
+	Fields [][]byte
+}
+
+// Stream is an append only log of entries ordered by ID. The entries are kept in
+// chunks of up to streamChunkSize entries, so appending and trimming from the start
+// don't move the whole log and a range is found with a binary search over the chunks.
+// Unlike the other types, an empty stream is kept in its key, since it still holds its
+// last ID and its consumer groups.
+type Stream struct {
+	changeCounter
+	chunks       []*streamChunk
+	length       int
+	lastID       StreamID
+	maxDeletedID StreamID
+	entriesAdded int64
+	groups       map[string]*ConsumerGroup
+}
+
+type streamChunk struct {
+	entries []StreamEntry
+}
+
+func NewStream() *Stream {
+	return &Stream{groups: make(map[string]*ConsumerGroup)}
+}
+
+// GetStream returns the stream stored in the key. It returns ErrWrongType if the key
+// holds a value of another type.
+func GetStream(key string) (*Stream, bool, error) {
+	val, ok, err := lookupType(key, TypeStream)
+	if !ok {
+		return nil, false, err
+	}
+	stream := val.value.(*Stream)
+	return stream, true, nil
+}
+
+// GetOrCreateStream returns the stream stored in the key, storing a new empty stream if
+// the key doesn't exist.
+func GetOrCreateStream(key string) (*Stream, error) {
+	stream, ok, err := GetStream(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		stream = NewStream()
+		store[key] = Value{value: stream, kind: TypeStream, version: nextVersion()}
+	}
+	return stream, nil
+}
+
+func (s *Stream) Len() int {
+	return s.length
+}
+
+// LastID returns the ID of the last entry ever added, even if it was deleted since.
+func (s *Stream) LastID() StreamID {
+	return s.lastID
+}
+
+// SetLastID changes the last ID, like XSETID does. It must not be lower than the ID of
+// the last entry in the stream.
+func (s *Stream) SetLastID(id StreamID) {
+	s.lastID = id
+	s.changed()
+}
+
+func (s *Stream) MaxDeletedID() StreamID {
+	return s.maxDeletedID
+}
+
+// EntriesAdded returns how many entries were ever added to the stream.
+func (s *Stream) EntriesAdded() int64 {
+	return s.entriesAdded
+}
+
+// NextID returns the ID for a new entry added at the Unix time in milliseconds. The
+// bool is false if the stream can't have more entries.
+func (s *Stream) NextID(now int64) (StreamID, bool) {
+	if uint64(now) > s.lastID.Ms {
+		return StreamID{uint64(now), 0}, true
+	}
+	return s.lastID.Next()
+}
+
+// Append adds an entry at the end of the stream. The ID must be greater than LastID.
+func (s *Stream) Append(id StreamID, fields [][]byte) {
+	if len(s.chunks) == 0 || len(s.chunks[len(s.chunks)-1].entries) == streamChunkSize {
+		s.chunks = append(s.chunks, &streamChunk{entries: make([]StreamEntry, 0, streamChunkSize)})
+	}
+	last := s.chunks[len(s.chunks)-1]
+	last.entries = append(last.entries, StreamEntry{id, fields})
+	s.length++
+	s.lastID = id
+	s.entriesAdded++
+	s.changed()
+}
+
+// First returns the first entry of the stream. The bool is false if it's empty.
+func (s *Stream) First() (StreamEntry, bool) {
+	if s.length == 0 {
+		return StreamEntry{}, false
+	}
+	return s.chunks[0].entries[0], true
+}
+
+// Last returns the last entry of the stream. The bool is false if it's empty.
+func (s *Stream) Last() (StreamEntry, bool) {
+	if s.length == 0 {
+		return StreamEntry{}, false
+	}
+	last := s.chunks[len(s.chunks)-1]
+	return last.entries[len(last.entries)-1], true
+}
+
+// Get returns the entry with the ID.
+func (s *Stream) Get(id StreamID) (StreamEntry, bool) {
+	ci, ei, ok := s.find(id)
+	if !ok {
+		return StreamEntry{}, false
+	}
+	return s.chunks[ci].entries[ei], true
+}
+
+// Range returns up to count entries with IDs from start to end, both inclusive, in
+// ascending order, or in descending order if rev is set. A count of 0 or lower means
+// no limit.
+func (s *Stream) Range(start, end StreamID, count int, rev bool) []StreamEntry {
+	entries := []StreamEntry{}
+	if start.Compare(end) > 0 {
+		return entries
+	}
+	full := func() bool { return count > 0 && len(entries) == count }
+	if !rev {
+		ci, ei, _ := s.find(start)
+		for ; ci < len(s.chunks); ci, ei = ci+1, 0 {
+			for _, entry := range s.chunks[ci].entries[ei:] {
+				if entry.ID.Compare(end) > 0 || full() {
+					return entries
+				}
+				entries = append(entries, entry)
+			}
+		}
+		return entries
+	}
+	ci, ei, found := s.find(end)
+	if !found {
+		// find returns the position of the first entry after end
+		ci, ei = s.previous(ci, ei)
+	}
+	for ; ci >= 0; ci, ei = s.previous(ci, ei) {
+		entry := s.chunks[ci].entries[ei]
+		if entry.ID.Compare(start) < 0 || full() {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// previous returns the position of the entry before the one at chunk ci and index ei.
+// The chunk is -1 if there is no previous entry.
+func (s *Stream) previous(ci, ei int) (int, int) {
+	if ei > 0 {
+		return ci, ei - 1
+	}
+	if ci == 0 {
+		return -1, 0
+	}
+	return ci - 1, len(s.chunks[ci-1].entries) - 1
+}
+
+// Delete removes the entry with the ID, returning false if it doesn't exist.
+func (s *Stream) Delete(id StreamID) bool {
+	ci, ei, ok := s.find(id)
+	if !ok {
+		return false
+	}
+	chunk := s.chunks[ci]
+	chunk.entries = slices.Delete(chunk.entries, ei, ei+1)
+	if len(chunk.entries) == 0 {
+		s.chunks = slices.Delete(s.chunks, ci, ci+1)
+	}
+	s.length--
+	if id.Compare(s.maxDeletedID) > 0 {
+		s.maxDeletedID = id
+	}
+	s.changed()
+	return true
+}
+
+// TrimMaxLen removes the oldest entries until the stream has at most maxLen entries,
+// returning how many were removed. If approx is set only whole chunks are removed,
+// which may leave a few more entries than maxLen, and at most limit entries are
+// removed unless limit is 0.
+func (s *Stream) TrimMaxLen(maxLen int, approx bool, limit int) int {
+	return s.trim(func(entries []StreamEntry, remaining int) int {
+		return min(len(entries), max(remaining-maxLen, 0))
+	}, approx, limit)
+}
+
+// TrimMinID removes the entries with IDs lower than minID, returning how many were
+// removed. approx and limit work like in TrimMaxLen.
+func (s *Stream) TrimMinID(minID StreamID, approx bool, limit int) int {
+	return s.trim(func(entries []StreamEntry, _ int) int {
+		return sort.Search(len(entries), func(i int) bool { return entries[i].ID.Compare(minID) >= 0 })
+	}, approx, limit)
+}
+
+// trim removes entries from the start of the stream. toRemove returns how many entries
+// of the first chunk should be removed, given the entries left in the stream.
+func (s *Stream) trim(toRemove func(entries []StreamEntry, remaining int) int, approx bool, limit int) int {
+	removed := 0
+	for len(s.chunks) > 0 {
+		chunk := s.chunks[0]
+		n := toRemove(chunk.entries, s.length)
+		if approx && n < len(chunk.entries) {
+			break
+		}
+		if limit > 0 && removed+n > limit {
+			if approx {
+				break
+			}
+			n = limit - removed
+		}
+		if n == 0 {
+			break
+		}
+		lastRemoved := chunk.entries[n-1].ID
+		if lastRemoved.Compare(s.maxDeletedID) > 0 {
+			s.maxDeletedID = lastRemoved
+		}
+		if n == len(chunk.entries) {
+			s.chunks = s.chunks[1:]
+		} else {
+			chunk.entries = slices.Clone(chunk.entries[n:])
+		}
+		s.length -= n
+		removed += n
+		s.changed()
+	}
+	return removed
+}
+
+// find returns the position of the entry with the ID, or of the first entry after it
+// if it doesn't exist, which is one past the last chunk when all the entries are lower.
+func (s *Stream) find(id StreamID) (int, int, bool) {
+	ci := sort.Search(len(s.chunks), func(i int) bool {
+		entries := s.chunks[i].entries
+		return entries[len(entries)-1].ID.Compare(id) >= 0
+	})
+	if ci == len(s.chunks) {
+		return ci, 0, false
+	}
+	entries := s.chunks[ci].entries
+	ei := sort.Search(len(entries), func(i int) bool { return entries[i].ID.Compare(id) >= 0 })
+	return ci, ei, entries[ei].ID == id
+}
+
+// ConsumerGroup tracks the entries of a stream delivered to a group of consumers. The
+// entries delivered and not yet acknowledged are kept in the pending entries list of
+// the group, and also in the one of the consumer they were delivered to. The changes to
+// a group are changes to its stream.
+type ConsumerGroup struct {
+	stream *Stream
+	name   string
+	lastID StreamID
+	// entriesRead is how many entries the group read, or -1 if it's not known, which
+	// happens when the group is created or moved to an arbitrary ID.
+	entriesRead int64
+	pending     map[StreamID]*PendingEntry
+	consumers   map[string]*Consumer
+}
+
+// Consumer is a consumer of a group. The times are Unix times in milliseconds: seen is
+// the last time it attempted an interaction and active the last time it read or claimed
+// entries.
+type Consumer struct {
+	name       string
+	seenTime   int64
+	activeTime int64
+	pending    map[StreamID]*PendingEntry
+}
+
+// PendingEntry is an entry delivered to a consumer and not acknowledged yet.
+type PendingEntry struct {
+	ID            StreamID
+	DeliveryTime  int64
+	DeliveryCount int
+	consumer      *Consumer
+}
+
+func (p *PendingEntry) Consumer() *Consumer {
+	return p.consumer
+}
+
+// CreateGroup creates a consumer group that will deliver the entries after lastID. It
+// returns false if the group already exists.
+func (s *Stream) CreateGroup(name string, lastID StreamID, entriesRead int64) (*ConsumerGroup, bool) {
+	if _, ok := s.groups[name]; ok {
+		return nil, false
+	}
+	group := &ConsumerGroup{
+		stream:      s,
+		name:        name,
+		lastID:      lastID,
+		entriesRead: entriesRead,
+		pending:     make(map[StreamID]*PendingEntry),
+		consumers:   make(map[string]*Consumer),
+	}
+	s.groups[name] = group
+	s.changed()
+	return group, true
+}
+
+func (s *Stream) Group(name string) (*ConsumerGroup, bool) {
+	group, ok := s.groups[name]
+	return group, ok
+}
+
+func (s *Stream) DestroyGroup(name string) bool {
+	if _, ok := s.groups[name]; !ok {
+		return false
+	}
+	delete(s.groups, name)
+	s.changed()
+	return true
+}
+
+// Groups returns the consumer groups sorted by name.
+func (s *Stream) Groups() []*ConsumerGroup {
+	groups := make([]*ConsumerGroup, 0, len(s.groups))
+	for _, group := range s.groups {
+		groups = append(groups, group)
+	}
+	slices.SortFunc(groups, func(a, b *ConsumerGroup) int { return cmp.Compare(a.name, b.name) })
+	return groups
+}
+
+func (g *ConsumerGroup) Name() string {
+	return g.name
+}
+
+func (g *ConsumerGroup) LastID() StreamID {
+	return g.lastID
+}
+
+func (g *ConsumerGroup) EntriesRead() int64 {
+	return g.entriesRead
+}
+
+// SetLastID moves the group, which will deliver the entries after lastID.
+func (g *ConsumerGroup) SetLastID(lastID StreamID, entriesRead int64) {
+	g.lastID = lastID
+	g.entriesRead = entriesRead
+	g.stream.changed()
+}
+
+// MarkRead records that the entry was read by the group, which delivers the entries
+// after it from now on.
+func (g *ConsumerGroup) MarkRead(id StreamID) {
+	g.lastID = id
+	if g.entriesRead >= 0 {
+		g.entriesRead++
+	}
+	g.stream.changed()
+}
+
+func (g *ConsumerGroup) Consumer(name string) (*Consumer, bool) {
+	consumer, ok := g.consumers[name]
+	return consumer, ok
+}
+
+// CreateConsumer creates the consumer if it doesn't exist yet. The bool tells if it
+// was created.
+func (g *ConsumerGroup) CreateConsumer(name string, now int64) (*Consumer, bool) {
+	if consumer, ok := g.consumers[name]; ok {
+		return consumer, false
+	}
+	consumer := &Consumer{
+		name:       name,
+		seenTime:   now,
+		activeTime: -1,
+		pending:    make(map[StreamID]*PendingEntry),
+	}
+	g.consumers[name] = consumer
+	g.stream.changed()
+	return consumer, true
+}
+
+// DeleteConsumer removes the consumer and its pending entries, returning how many
+// entries were pending. The bool is false if the consumer doesn't exist.
+func (g *ConsumerGroup) DeleteConsumer(name string) (int, bool) {
+	consumer, ok := g.consumers[name]
+	if !ok {
+		return 0, false
+	}
+	for id := range consumer.pending {
+		delete(g.pending, id)
+	}
+	delete(g.consumers, name)
+	g.stream.changed()
+	return len(consumer.pending), true
+}
+
+// Consumers returns the consumers sorted by name.
+func (g *ConsumerGroup) Consumers() []*Consumer {
+	consumers := make([]*Consumer, 0, len(g.consumers))
+	for _, consumer := range g.consumers {
+		consumers = append(consumers, consumer)
+	}
+	slices.SortFunc(consumers, func(a, b *Consumer) int { return cmp.Compare(a.name, b.name) })
+	return consumers
+}
+
+// Deliver records that the entry was delivered to the consumer at the Unix time in
+// milliseconds now, adding it to the pending entries list or moving it from the
+// consumer it was delivered to before. It returns the pending entry.
+func (g *ConsumerGroup) Deliver(consumer *Consumer, id StreamID, now int64) *PendingEntry {
+	entry := g.Claim(consumer, id)
+	entry.DeliveryTime = now
+	entry.DeliveryCount++
+	return entry
+}
+
+// Claim moves the pending entry to the consumer, adding it to the pending entries list
+// if needed, without changing its delivery time or count. It returns the pending entry.
+func (g *ConsumerGroup) Claim(consumer *Consumer, id StreamID) *PendingEntry {
+	entry, ok := g.pending[id]
+	if !ok {
+		entry = &PendingEntry{ID: id}
+		g.pending[id] = entry
+	} else {
+		delete(entry.consumer.pending, id)
+	}
+	entry.consumer = consumer
+	consumer.pending[id] = entry
+	g.stream.changed()
+	return entry
+}
+
+// Pending returns the pending entry with the ID.
+func (g *ConsumerGroup) Pending(id StreamID) (*PendingEntry, bool) {
+	entry, ok := g.pending[id]
+	return entry, ok
+}
+
+func (g *ConsumerGroup) PendingCount() int {
+	return len(g.pending)
+}
+
+// PendingRange returns the pending entries of the group with IDs from start to end,
+// both inclusive, in ascending order.
+func (g *ConsumerGroup) PendingRange(start, end StreamID) []*PendingEntry {
+	return pendingRange(g.pending, start, end)
+}
+
+// Ack removes the entry from the pending entries list, returning false if it wasn't
+// pending.
+func (g *ConsumerGroup) Ack(id StreamID) bool {
+	entry, ok := g.pending[id]
+	if !ok {
+		return false
+	}
+	delete(g.pending, id)
+	delete(entry.consumer.pending, id)
+	g.stream.changed()
+	return true
+}
+
+func (c *Consumer) Name() string {
+	return c.name
+}
+
+func (c *Consumer) SeenTime() int64 {
+	return c.seenTime
+}
+
+// ActiveTime returns the last time the consumer read or claimed entries, or -1 if it
+// never did.
+func (c *Consumer) ActiveTime() int64 {
+	return c.activeTime
+}
+
+// Touch records an interaction of the consumer at the Unix time in milliseconds now.
+// active tells if entries were read or claimed.
+func (c *Consumer) Touch(now int64, active bool) {
+	c.seenTime = now
+	if active {
+		c.activeTime = now
+	}
+}
+
+func (c *Consumer) PendingCount() int {
+	return len(c.pending)
+}
+
+// PendingRange returns the pending entries of the consumer with IDs from start to end,
+// both inclusive, in ascending order.
+func (c *Consumer) PendingRange(start, end StreamID) []*PendingEntry {
+	return pendingRange(c.pending, start, end)
+}
+
+func pendingRange(pending map[StreamID]*PendingEntry, start, end StreamID) []*PendingEntry {
+	entries := []*PendingEntry{}
+	for id, entry := range pending {
+		if id.Compare(start) >= 0 && id.Compare(end) <= 0 {
+			entries = append(entries, entry)
+		}
+	}
+	slices.SortFunc(entries, func(a, b *PendingEntry) int { return a.ID.Compare(b.ID) })
+	return entries
+}
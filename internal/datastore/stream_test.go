@@ -0,0 +1,156 @@
+package datastore
+
+import (
+	"slices"
+	"testing"
+)
+
+// streamWithEntries creates a stream with the entries 1-0 to n-0, each with a single
+// field and value.
+func streamWithEntries(n int) *Stream {
+	stream := NewStream()
+	for i := 1; i <= n; i++ {
+		stream.Append(StreamID{Ms: uint64(i)}, [][]byte{[]byte("f"), []byte("v")})
+	}
+	return stream
+}
+
+func entryIDs(entries []StreamEntry) []uint64 {
+	ids := []uint64{}
+	for _, entry := range entries {
+		ids = append(ids, entry.ID.Ms)
+	}
+	return ids
+}
+
+func TestStreamRange(t *testing.T) {
+	stream := streamWithEntries(250)
+	if stream.Len() != 250 || len(stream.chunks) != 3 {
+		t.Fatalf("unexpected length %d with %d chunks", stream.Len(), len(stream.chunks))
+	}
+	tcs := []struct {
+		name       string
+		start, end uint64
+		count      int
+		rev        bool
+		expected   []uint64
+	}{
+		{"within a chunk", 10, 12, 0, false, []uint64{10, 11, 12}},
+		{"across chunks", 99, 102, 0, false, []uint64{99, 100, 101, 102}},
+		{"with count", 150, 250, 2, false, []uint64{150, 151}},
+		{"reversed across chunks", 99, 102, 0, true, []uint64{102, 101, 100, 99}},
+		{"reversed with count", 1, 250, 3, true, []uint64{250, 249, 248}},
+		{"out of the stream", 300, 400, 0, false, []uint64{}},
+		{"empty range", 20, 10, 0, false, []uint64{}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := entryIDs(stream.Range(StreamID{Ms: tc.start}, StreamID{Ms: tc.end}, tc.count, tc.rev))
+			if !slices.Equal(actual, tc.expected) {
+				t.Fatalf("unexpected entries. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestStreamDelete(t *testing.T) {
+	stream := streamWithEntries(150)
+	for _, ms := range []uint64{1, 100, 101, 150} {
+		if !stream.Delete(StreamID{Ms: ms}) {
+			t.Fatalf("entry %d should have been deleted", ms)
+		}
+	}
+	if stream.Delete(StreamID{Ms: 100}) {
+		t.Fatalf("entry 100 was already deleted")
+	}
+	if stream.Len() != 146 || stream.MaxDeletedID() != (StreamID{Ms: 150}) {
+		t.Fatalf("unexpected length %d or max deleted ID %v", stream.Len(), stream.MaxDeletedID())
+	}
+	if first, _ := stream.First(); first.ID != (StreamID{Ms: 2}) {
+		t.Fatalf("unexpected first entry %v", first.ID)
+	}
+	if last, _ := stream.Last(); last.ID != (StreamID{Ms: 149}) {
+		t.Fatalf("unexpected last entry %v", last.ID)
+	}
+	if stream.LastID() != (StreamID{Ms: 150}) {
+		t.Fatalf("deleting entries shouldn't change the last ID, got %v", stream.LastID())
+	}
+	actual := entryIDs(stream.Range(StreamID{Ms: 98}, StreamID{Ms: 103}, 0, false))
+	if expected := []uint64{98, 99, 102, 103}; !slices.Equal(actual, expected) {
+		t.Fatalf("unexpected entries. Expected: %v, Actual: %v", expected, actual)
+	}
+}
+
+func TestStreamTrim(t *testing.T) {
+	tcs := []struct {
+		name     string
+		trim     func(stream *Stream) int
+		removed  int
+		expected int
+	}{
+		{"max length", func(s *Stream) int { return s.TrimMaxLen(120, false, 0) }, 130, 120},
+		{"approximate max length", func(s *Stream) int { return s.TrimMaxLen(120, true, 0) }, 100, 150},
+		{"max length with limit", func(s *Stream) int { return s.TrimMaxLen(10, false, 50) }, 50, 200},
+		{"approximate with limit", func(s *Stream) int { return s.TrimMaxLen(10, true, 50) }, 0, 250},
+		{"min ID", func(s *Stream) int { return s.TrimMinID(StreamID{Ms: 111}, false, 0) }, 110, 140},
+		{"approximate min ID", func(s *Stream) int { return s.TrimMinID(StreamID{Ms: 111}, true, 0) }, 100, 150},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			stream := streamWithEntries(250)
+			if removed := tc.trim(stream); removed != tc.removed {
+				t.Fatalf("unexpected removed entries. Expected: %d, Actual: %d", tc.removed, removed)
+			}
+			if stream.Len() != tc.expected {
+				t.Fatalf("unexpected length. Expected: %d, Actual: %d", tc.expected, stream.Len())
+			}
+			first, _ := stream.First()
+			if first.ID.Ms != uint64(250-tc.expected+1) {
+				t.Fatalf("unexpected first entry %v", first.ID)
+			}
+		})
+	}
+}
+
+func TestStreamConsumerGroup(t *testing.T) {
+	stream := streamWithEntries(3)
+	group, ok := stream.CreateGroup("g", StreamID{}, 0)
+	if !ok {
+		t.Fatalf("the group should have been created")
+	}
+	if _, ok := stream.CreateGroup("g", StreamID{}, 0); ok {
+		t.Fatalf("the group already exists")
+	}
+	alice, _ := group.CreateConsumer("alice", 1000)
+	bob, _ := group.CreateConsumer("bob", 1000)
+	for ms := uint64(1); ms <= 3; ms++ {
+		group.Deliver(alice, StreamID{Ms: ms}, 2000)
+		group.MarkRead(StreamID{Ms: ms})
+	}
+	if group.LastID() != (StreamID{Ms: 3}) || group.EntriesRead() != 3 {
+		t.Fatalf("unexpected last ID %v with %d entries read", group.LastID(), group.EntriesRead())
+	}
+	claimed := group.Claim(bob, StreamID{Ms: 2})
+	if claimed.Consumer() != bob || claimed.DeliveryCount != 1 {
+		t.Fatalf("unexpected claimed entry %+v", claimed)
+	}
+	if alice.PendingCount() != 2 || bob.PendingCount() != 1 || group.PendingCount() != 3 {
+		t.Fatalf("unexpected pending counts %d, %d and %d", alice.PendingCount(), bob.PendingCount(), group.PendingCount())
+	}
+	if !group.Ack(StreamID{Ms: 1}) || group.Ack(StreamID{Ms: 1}) {
+		t.Fatalf("an entry should only be acknowledged once")
+	}
+	pending := group.PendingRange(StreamID{}, MaxStreamID)
+	if len(pending) != 2 || pending[0].ID != (StreamID{Ms: 2}) || pending[1].ID != (StreamID{Ms: 3}) {
+		t.Fatalf("unexpected pending entries %v", pending)
+	}
+	if count, ok := group.DeleteConsumer("alice"); !ok || count != 1 {
+		t.Fatalf("unexpected pending count %d of the deleted consumer", count)
+	}
+	if group.PendingCount() != 1 {
+		t.Fatalf("the entries of the deleted consumer should be removed from the group")
+	}
+	if !stream.DestroyGroup("g") || len(stream.Groups()) != 0 {
+		t.Fatalf("the group should have been destroyed")
+	}
+}
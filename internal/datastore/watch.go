@@ -0,0 +1,91 @@
+package datastore
+
+// Every write to a key gets a new version from lastVersion, which only increases, so
+// WATCH can tell if a key was modified between two points in time even if it was
+// deleted and created again.
+var lastVersion uint64
+
+// dirty counts the writes to the datastore, including the changes in place and the
+// deletions, which don't get a new version.
+var dirty uint64
+
+func nextVersion() uint64 {
+	lastVersion++
+	dirty++
+	return lastVersion
+}
+
+// Dirty returns the number of writes to the datastore so far. Comparing it at two points
+// in time tells if anything was written in between.
+func Dirty() uint64 {
+	return dirty
+}
+
+// watchers counts the clients watching each key. deletedVersions holds a version given
+// to each watched key when it's deleted, so a missing key that was created and deleted
+// after WATCH doesn't look untouched. It's released once no client watches the key.
+var (
+	watchers        = make(map[string]int)
+	deletedVersions = make(map[string]uint64)
+)
+
+// KeyVersion identifies the state of a key at some point. Two versions of the same key
+// are equal if the key wasn't modified, deleted or expired between the points they
+// were taken.
+type KeyVersion struct {
+	version uint64
+	changes uint64
+}
+
+// changeCounter counts the changes to a structure modified in place, like a list, since
+// those changes don't store a new Value in the key.
+type changeCounter struct {
+	changes uint64
+}
+
+func (c *changeCounter) changed() {
+	c.changes++
+	dirty++
+}
+
+func (c *changeCounter) changeCount() uint64 {
+	return c.changes
+}
+
+// Watch starts tracking the deletions of the key for a client watching it, returning
+// the current version of the key. Every call must be matched by a call to Unwatch.
+func Watch(key string) KeyVersion {
+	watchers[key]++
+	return Version(key)
+}
+
+// Unwatch stops tracking the key for a client that watched it.
+func Unwatch(key string) {
+	watchers[key]--
+	if watchers[key] <= 0 {
+		delete(watchers, key)
+		delete(deletedVersions, key)
+	}
+}
+
+// keyDeleted gives a new version to a watched key that was deleted or expired.
+func keyDeleted(key string) {
+	if watchers[key] > 0 {
+		lastVersion++
+		deletedVersions[key] = lastVersion
+	}
+}
+
+// Version returns the current version of the key. A missing key has the version of its
+// last deletion while watched, or the zero value.
+func Version(key string) KeyVersion {
+	val, ok := lookup(key)
+	if !ok {
+		return KeyVersion{version: deletedVersions[key]}
+	}
+	version := KeyVersion{version: val.version}
+	if counter, ok := val.value.(interface{ changeCount() uint64 }); ok {
+		version.changes = counter.changeCount()
+	}
+	return version
+}
@@ -0,0 +1,43 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/protocol"
+)
+
+func TestVersion(t *testing.T) {
+	if Version("watch-missing") != (KeyVersion{}) {
+		t.Fatalf("a missing key should have the zero version")
+	}
+
+	Set("watch-string", protocol.NewBulkString([]byte("a")))
+	version := Version("watch-string")
+	if Version("watch-string") != version {
+		t.Fatalf("reading a key shouldn't change its version")
+	}
+	Set("watch-string", protocol.NewBulkString([]byte("a")))
+	if Version("watch-string") == version {
+		t.Fatalf("storing the same value should change the version")
+	}
+
+	list, _ := GetOrCreateList("watch-list")
+	list.PushBack([]byte("a"))
+	version = Version("watch-list")
+	list.Range(0, -1)
+	if Version("watch-list") != version {
+		t.Fatalf("reading a list shouldn't change its version")
+	}
+	list.PopFront()
+	if Version("watch-list") == version {
+		t.Fatalf("changing a list in place should change its version")
+	}
+
+	// A key deleted and created again with the same contents has a new version
+	version = Version("watch-list")
+	Delete("watch-list")
+	list, _ = GetOrCreateList("watch-list")
+	if Version("watch-list") == version {
+		t.Fatalf("a new list should have a new version")
+	}
+}
@@ -0,0 +1,268 @@
+package datastore
+
+import (
+	"slices"
+	"strings"
+)
+
+// The largest sorted set kept with the compact encoding, the same defaults as the
+// zset-max-listpack-entries and zset-max-listpack-value options of Redis.
+const (
+	maxCompactEntries    = 128
+	maxCompactMemberSize = 64
+)
+
+// ZEntry is a member of a sorted set with its score.
+type ZEntry struct {
+	Member string
+	Score  float64
+}
+
+// SortedSet is a set of unique members ordered by score, and by member for equal
+// scores. Small sorted sets are kept as a slice of entries in order, the compact
+// encoding. Once the set grows past maxCompactEntries or gets a member longer than
+// maxCompactMemberSize it's converted to a skiplist, for the ordered operations, and a
+// map from member to score, for the lookups by member. It's never converted back.
+type SortedSet struct {
+	changeCounter
+	compact []ZEntry
+	scores  map[string]float64
+	zsl     *skiplist
+}
+
+// NewSortedSet creates an empty sorted set with the compact encoding.
+func NewSortedSet() *SortedSet {
+	return &SortedSet{compact: []ZEntry{}}
+}
+
+// GetSortedSet returns the sorted set stored in the key. It returns ErrWrongType if the
+// key holds a value of another type.
+func GetSortedSet(key string) (*SortedSet, bool, error) {
+	val, ok, err := lookupType(key, TypeSortedSet)
+	if !ok {
+		return nil, false, err
+	}
+	zset := val.value.(*SortedSet)
+	return zset, true, nil
+}
+
+// GetOrCreateSortedSet returns the sorted set stored in the key, storing a new empty
+// sorted set if the key doesn't exist. Sorted sets are modified in place, and commands
+// must delete the key once the sorted set is empty.
+func GetOrCreateSortedSet(key string) (*SortedSet, error) {
+	zset, ok, err := GetSortedSet(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		zset = NewSortedSet()
+		store[key] = Value{value: zset, kind: TypeSortedSet, version: nextVersion()}
+	}
+	return zset, nil
+}
+
+// StoreSortedSet replaces whatever the key holds with the sorted set, deleting the key
+// if the sorted set is empty. It's used by commands that store their result, like
+// ZUNIONSTORE.
+func StoreSortedSet(key string, zset *SortedSet) {
+	if zset.Len() == 0 {
+		Delete(key)
+		return
+	}
+	snapshotKey(key)
+	store[key] = Value{value: zset, kind: TypeSortedSet, version: nextVersion()}
+	delete(expires, key)
+}
+
+// IsCompact tells if the sorted set is using the compact encoding.
+func (z *SortedSet) IsCompact() bool {
+	return z.zsl == nil
+}
+
+func (z *SortedSet) Len() int {
+	if z.IsCompact() {
+		return len(z.compact)
+	}
+	return z.zsl.length
+}
+
+func (z *SortedSet) Score(member string) (float64, bool) {
+	if z.IsCompact() {
+		i := slices.IndexFunc(z.compact, func(e ZEntry) bool { return e.Member == member })
+		if i == -1 {
+			return 0, false
+		}
+		return z.compact[i].Score, true
+	}
+	score, ok := z.scores[member]
+	return score, ok
+}
+
+// Add stores the member with the score, updating the score if it's already a member.
+// It returns true if the member is new.
+func (z *SortedSet) Add(member string, score float64) bool {
+	current, exists := z.Score(member)
+	if exists && current == score {
+		return false
+	}
+	if exists {
+		z.Remove(member)
+	}
+	if z.IsCompact() && (len(z.compact) >= maxCompactEntries || len(member) > maxCompactMemberSize) {
+		z.convertToSkiplist()
+	}
+	entry := ZEntry{member, score}
+	if z.IsCompact() {
+		i, _ := slices.BinarySearchFunc(z.compact, entry, compareEntries)
+		z.compact = slices.Insert(z.compact, i, entry)
+	} else {
+		z.zsl.insert(entry)
+		z.scores[member] = score
+	}
+	z.changed()
+	return !exists
+}
+
+// Remove removes the member, returning false if it wasn't a member.
+func (z *SortedSet) Remove(member string) bool {
+	score, ok := z.Score(member)
+	if !ok {
+		return false
+	}
+	entry := ZEntry{member, score}
+	if z.IsCompact() {
+		i, _ := slices.BinarySearchFunc(z.compact, entry, compareEntries)
+		z.compact = slices.Delete(z.compact, i, i+1)
+	} else {
+		z.zsl.delete(entry)
+		delete(z.scores, member)
+	}
+	z.changed()
+	return true
+}
+
+// Rank returns the 0 based position of the member in ascending order.
+func (z *SortedSet) Rank(member string) (int, bool) {
+	score, ok := z.Score(member)
+	if !ok {
+		return 0, false
+	}
+	entry := ZEntry{member, score}
+	return z.countWhile(func(e ZEntry) bool { return compareEntries(e, entry) < 0 }), true
+}
+
+// Range returns the entries with ranks from start, inclusive, to end, exclusive, in
+// ascending order.
+func (z *SortedSet) Range(start, end int) []ZEntry {
+	start = max(start, 0)
+	end = min(end, z.Len())
+	if start >= end {
+		return []ZEntry{}
+	}
+	if z.IsCompact() {
+		return slices.Clone(z.compact[start:end])
+	}
+	entries := make([]ZEntry, 0, end-start)
+	for node := z.zsl.nodeByRank(start + 1); len(entries) < end-start; node = node.level[0].forward {
+		entries = append(entries, node.entry)
+	}
+	return entries
+}
+
+// RemoveRange removes the entries with ranks from start, inclusive, to end, exclusive,
+// returning how many were removed.
+func (z *SortedSet) RemoveRange(start, end int) int {
+	entries := z.Range(start, end)
+	for _, entry := range entries {
+		z.Remove(entry.Member)
+	}
+	return len(entries)
+}
+
+// ScoreRange is a range of scores, like the min and max arguments of ZRANGEBYSCORE.
+type ScoreRange struct {
+	Min, Max                   float64
+	MinExclusive, MaxExclusive bool
+}
+
+// LexRange is a range of members, like the min and max arguments of ZRANGEBYLEX.
+type LexRange struct {
+	Min, Max LexBound
+}
+
+// LexBound is one of the ends of a LexRange. Infinity is -1 for the - bound, which is
+// lower than any member, 1 for the + bound, which is higher than any member, and 0 for
+// the bounds given by Value.
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Infinity  int
+}
+
+// ScoreRanks returns the ranks of the entries in the range, from start, inclusive, to
+// end, exclusive.
+func (z *SortedSet) ScoreRanks(r ScoreRange) (int, int) {
+	start := z.countWhile(func(e ZEntry) bool {
+		return e.Score < r.Min || (r.MinExclusive && e.Score == r.Min)
+	})
+	end := z.countWhile(func(e ZEntry) bool {
+		return e.Score < r.Max || (!r.MaxExclusive && e.Score == r.Max)
+	})
+	return start, max(start, end)
+}
+
+// LexRanks returns the ranks of the entries in the range, from start, inclusive, to end,
+// exclusive. Like in Redis, the result is only meaningful when all the members have the
+// same score.
+func (z *SortedSet) LexRanks(r LexRange) (int, int) {
+	start := z.countWhile(func(e ZEntry) bool {
+		if r.Min.Infinity != 0 {
+			return r.Min.Infinity > 0
+		}
+		c := strings.Compare(e.Member, r.Min.Value)
+		return c < 0 || (r.Min.Exclusive && c == 0)
+	})
+	end := z.countWhile(func(e ZEntry) bool {
+		if r.Max.Infinity != 0 {
+			return r.Max.Infinity > 0
+		}
+		c := strings.Compare(e.Member, r.Max.Value)
+		return c < 0 || (!r.Max.Exclusive && c == 0)
+	})
+	return start, max(start, end)
+}
+
+// countWhile returns how many entries, from the lowest, match the predicate. The
+// predicate must hold for a prefix of the entries and not for the rest.
+func (z *SortedSet) countWhile(predicate func(e ZEntry) bool) int {
+	if z.IsCompact() {
+		i, _ := slices.BinarySearchFunc(z.compact, true, func(e ZEntry, _ bool) int {
+			if predicate(e) {
+				return -1
+			}
+			return 1
+		})
+		return i
+	}
+	return z.zsl.countWhile(predicate)
+}
+
+func (z *SortedSet) convertToSkiplist() {
+	z.zsl = newSkiplist()
+	z.scores = make(map[string]float64, len(z.compact))
+	for _, entry := range z.compact {
+		z.zsl.insert(entry)
+		z.scores[entry.Member] = entry.Score
+	}
+	z.compact = nil
+}
+
+func compareEntries(a, b ZEntry) int {
+	switch {
+	case a.Score < b.Score:
+		return -1
+	case a.Score > b.Score:
+		return 1
+	}
+	return strings.Compare(a.Member, b.Member)
+}
@@ -0,0 +1,110 @@
+package datastore
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkSortedSet compares the sorted set with the entries it should hold, in order.
+func checkSortedSet(t *testing.T, zset *SortedSet, expected []ZEntry) {
+	t.Helper()
+	if zset.Len() != len(expected) {
+		t.Fatalf("unexpected length %d, expected %d", zset.Len(), len(expected))
+	}
+	if actual := zset.Range(0, zset.Len()); !slices.Equal(actual, expected) {
+		t.Fatalf("unexpected entries %v", actual)
+	}
+	for i, entry := range expected {
+		if rank, ok := zset.Rank(entry.Member); !ok || rank != i {
+			t.Fatalf("unexpected rank %d for %s, expected %d", rank, entry.Member, i)
+		}
+		if actual := zset.Range(i, i+1); len(actual) != 1 || actual[0] != entry {
+			t.Fatalf("unexpected entry at rank %d: %v", i, actual)
+		}
+	}
+}
+
+func TestSortedSetEncodings(t *testing.T) {
+	for _, size := range []int{50, 1000} {
+		t.Run(fmt.Sprintf("%d entries", size), func(t *testing.T) {
+			zset := NewSortedSet()
+			scores := make(map[string]float64)
+			for i := 0; i < size*2; i++ {
+				member := fmt.Sprintf("m%d", rand.IntN(size))
+				score := float64(rand.IntN(size / 5))
+				zset.Add(member, score)
+				scores[member] = score
+			}
+			for i := 0; i < size/2; i++ {
+				member := fmt.Sprintf("m%d", rand.IntN(size))
+				_, exists := scores[member]
+				if zset.Remove(member) != exists {
+					t.Fatalf("unexpected result removing %s", member)
+				}
+				delete(scores, member)
+			}
+			expected := []ZEntry{}
+			for member, score := range scores {
+				expected = append(expected, ZEntry{member, score})
+			}
+			slices.SortFunc(expected, compareEntries)
+			if zset.IsCompact() != (size <= maxCompactEntries) {
+				t.Fatalf("unexpected encoding for %d entries", size)
+			}
+			checkSortedSet(t, zset, expected)
+		})
+	}
+}
+
+func TestSortedSetLongMember(t *testing.T) {
+	zset := NewSortedSet()
+	zset.Add("a", 1)
+	zset.Add(string(make([]byte, maxCompactMemberSize+1)), 2)
+	if zset.IsCompact() {
+		t.Fatalf("a long member should convert the sorted set to a skiplist")
+	}
+	zset.Add("a", 3)
+	checkSortedSet(t, zset, []ZEntry{{string(make([]byte, maxCompactMemberSize+1)), 2}, {"a", 3}})
+}
+
+func TestSortedSetRanks(t *testing.T) {
+	for _, size := range []int{10, 300} {
+		zset := NewSortedSet()
+		for i := 0; i < size; i++ {
+			zset.Add(fmt.Sprintf("m%04d", i), float64(i/2))
+		}
+		start, end := zset.ScoreRanks(ScoreRange{Min: 1, Max: 3})
+		if start != 2 || end != 8 {
+			t.Fatalf("unexpected score ranks %d %d", start, end)
+		}
+		start, end = zset.ScoreRanks(ScoreRange{Min: 1, Max: 3, MinExclusive: true, MaxExclusive: true})
+		if start != 4 || end != 6 {
+			t.Fatalf("unexpected exclusive score ranks %d %d", start, end)
+		}
+		start, end = zset.ScoreRanks(ScoreRange{Min: math.Inf(-1), Max: math.Inf(1)})
+		if start != 0 || end != size {
+			t.Fatalf("unexpected infinite score ranks %d %d", start, end)
+		}
+		start, end = zset.ScoreRanks(ScoreRange{Min: 3, Max: 1})
+		if start != end {
+			t.Fatalf("inverted range should be empty")
+		}
+		start, end = zset.LexRanks(LexRange{Min: LexBound{Value: "m0002", Exclusive: true}, Max: LexBound{Infinity: 1}})
+		if start != 3 || end != size {
+			t.Fatalf("unexpected lex ranks %d %d", start, end)
+		}
+		start, end = zset.LexRanks(LexRange{Min: LexBound{Infinity: -1}, Max: LexBound{Value: "m0002"}})
+		if start != 0 || end != 3 {
+			t.Fatalf("unexpected lex ranks %d %d", start, end)
+		}
+		if removed := zset.RemoveRange(0, 4); removed != 4 || zset.Len() != size-4 {
+			t.Fatalf("unexpected result removing a range")
+		}
+		if rank, _ := zset.Rank("m0004"); rank != 0 {
+			t.Fatalf("unexpected rank after removing a range %d", rank)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+const (
+	// maxInlineSize is the longest inline command accepted without a line break, the
+	// same limit Redis uses to protect itself from clients that never send one.
+	maxInlineSize = 64 * 1024
+)
+
+var (
+	errInlineTooBig           = errors.New("Protocol error: too big inline request")
+	errInlineUnbalancedQuotes = errors.New("Protocol error: unbalanced quotes in request")
+)
+
+// isTypePrefix reports whether b is the first byte of a RESP2 or RESP3 value. Any
+// other first byte is treated as the start of an inline command.
+func isTypePrefix(b byte) bool {
+	switch b {
+	case '+', '-', ':', '$', '*', '_', '#', ',', '(', '!', '=', '%', '~', '>':
+		return true
+	default:
+		return false
+	}
+}
+
+/* ParseInline parses an inline command, the plain text format used by telnet or netcat,
+ * for example "SET key value\r\n". The line can be terminated by either CRLF or LF and its
+ * arguments are separated by spaces. Arguments can be quoted following the same rules
+ * as redis-cli: double quotes support escape sequences like \n or \x41 while single
+ * quotes only support \'. The result is an Array of BulkStrings, the same that would be
+ * produced by parsing the command sent in the RESP format.
+ */
+func ParseInline(buffer []byte) (ValidRead, error) {
+	lineBreakIndex := bytes.IndexByte(buffer, '\n')
+	if lineBreakIndex == -1 {
+		if len(buffer) > maxInlineSize {
+			return ValidRead{}, errInlineTooBig
+		}
+		return ValidRead{nil, -1}, nil
+	}
+	line := buffer[:lineBreakIndex]
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	arguments, err := splitInlineArguments(line)
+	if err != nil {
+		return ValidRead{}, err
+	}
+	elements := make([]DataType, 0, len(arguments))
+	for _, argument := range arguments {
+		elements = append(elements, BulkString{argument})
+	}
+	return ValidRead{Array{elements}, lineBreakIndex + 1}, nil
+}
+
+func splitInlineArguments(line []byte) ([][]byte, error) {
+	var arguments [][]byte
+	i := 0
+	for {
+		for i < len(line) && isInlineSpace(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return arguments, nil
+		}
+		var argument []byte
+		inDoubleQuotes, inSingleQuotes, done := false, false, false
+		for !done {
+			switch {
+			case inDoubleQuotes:
+				if i >= len(line) {
+					return nil, errInlineUnbalancedQuotes
+				}
+				switch {
+				case line[i] == '\\' && i+3 < len(line) && line[i+1] == 'x' && isHexDigit(line[i+2]) && isHexDigit(line[i+3]):
+					value, _ := strconv.ParseUint(string(line[i+2:i+4]), 16, 8)
+					argument = append(argument, byte(value))
+					i += 3
+				case line[i] == '\\' && i+1 < len(line):
+					i++
+					argument = append(argument, unescapeInline(line[i]))
+				case line[i] == '"':
+					// The closing quote must be followed by a space or the end of the line
+					if i+1 < len(line) && !isInlineSpace(line[i+1]) {
+						return nil, errInlineUnbalancedQuotes
+					}
+					done = true
+				default:
+					argument = append(argument, line[i])
+				}
+			case inSingleQuotes:
+				if i >= len(line) {
+					return nil, errInlineUnbalancedQuotes
+				}
+				switch {
+				case line[i] == '\\' && i+1 < len(line) && line[i+1] == '\'':
+					i++
+					argument = append(argument, '\'')
+				case line[i] == '\'':
+					if i+1 < len(line) && !isInlineSpace(line[i+1]) {
+						return nil, errInlineUnbalancedQuotes
+					}
+					done = true
+				default:
+					argument = append(argument, line[i])
+				}
+			default:
+				if i >= len(line) {
+					done = true
+					break
+				}
+				switch {
+				case isInlineSpace(line[i]):
+					done = true
+				case line[i] == '"':
+					inDoubleQuotes = true
+				case line[i] == '\'':
+					inSingleQuotes = true
+				default:
+					argument = append(argument, line[i])
+				}
+			}
+			if i < len(line) {
+				i++
+			}
+		}
+		if argument == nil {
+			argument = []byte{}
+		}
+		arguments = append(arguments, argument)
+	}
+}
+
+func unescapeInline(b byte) byte {
+	switch b {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return b
+	}
+}
+
+func isInlineSpace(b byte) bool {
+	switch b {
+	case ' ', '\t', '\r', '\n', '\v', '\f':
+		return true
+	default:
+		return false
+	}
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
@@ -38,6 +38,12 @@ type Array struct {
 	elements []DataType
 }
 
+// NullBulkString is the RESP2 way of representing a missing value: $-1\r\n
+type NullBulkString struct{}
+
+// NullArray is the RESP2 way of representing a missing aggregate value: *-1\r\n
+type NullArray struct{}
+
 func (s SimpleString) String() string {
 	return s.data
 }
@@ -76,6 +82,10 @@ func (i Integer) Encode() []byte {
 	return buffer
 }
 
+func (i Integer) Value() int {
+	return i.value
+}
+
 func (s Error) String() string {
 	return s.msg
 }
@@ -116,6 +126,22 @@ func (a Array) GetElements() []DataType {
 	return a.elements
 }
 
+func (n NullBulkString) String() string {
+	return "(nil)"
+}
+
+func (n NullBulkString) Encode() []byte {
+	return []byte("$-1\r\n")
+}
+
+func (n NullArray) String() string {
+	return "(nil)"
+}
+
+func (n NullArray) Encode() []byte {
+	return []byte("*-1\r\n")
+}
+
 func NewBulkString(value []byte) BulkString {
 	return BulkString{value}
 }
@@ -136,15 +162,27 @@ func NewArray(elements ...DataType) Array {
 	return Array{elements}
 }
 
+func NewNullBulkString() NullBulkString {
+	return NullBulkString{}
+}
+
+func NewNullArray() NullArray {
+	return NullArray{}
+}
+
 func (v ValidRead) Unwrap() (DataType, int) {
 	return v.Data, v.BytesRead
 }
 
 /* ParseFrame parses the buffer input. It it has a complete message, it returs the appropriate
  * DataType implementation with the number of bytes read. If it doesn't have a complete
- * input message, returns nil and -1
+ * input message, returns nil and -1. Input that doesn't start with a RESP type prefix is
+ * parsed as an inline command.
  */
 func ParseFrame(buffer []byte) (ValidRead, error) {
+	if len(buffer) > 0 && !isTypePrefix(buffer[0]) {
+		return ParseInline(buffer)
+	}
 	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
 	if lineBreakIndex == -1 {
 		return ValidRead{nil, -1}, nil
@@ -164,6 +202,24 @@ func parseElement(buffer []byte) (ValidRead, error) {
 		return ParseBulkString(buffer[1:])
 	case '*':
 		return ParseArray(buffer[1:])
+	case '_':
+		return ParseNull(buffer[1:])
+	case '#':
+		return ParseBoolean(buffer[1:])
+	case ',':
+		return ParseDouble(buffer[1:])
+	case '(':
+		return ParseBigNumber(buffer[1:])
+	case '!':
+		return ParseBulkError(buffer[1:])
+	case '=':
+		return ParseVerbatimString(buffer[1:])
+	case '%':
+		return ParseMap(buffer[1:])
+	case '~':
+		return ParseSet(buffer[1:])
+	case '>':
+		return ParsePush(buffer[1:])
 	default:
 		return ValidRead{}, errors.New("invalid input type")
 	}
@@ -208,13 +264,20 @@ func ParseBulkString(buffer []byte) (ValidRead, error) {
 	if err != nil {
 		return ValidRead{}, fmt.Errorf("invalid bulk string length %s: %w", length, err)
 	}
+	if bulkStringLength == -1 {
+		return ValidRead{NullBulkString{}, 1 + lineBreakIndex + 2}, nil
+	}
+	if bulkStringLength < 0 {
+		return ValidRead{}, fmt.Errorf("invalid bulk string length %s", length)
+	}
 	// To account for: the initial bulk string size, the CRLF after that and the CRLF after the bulkstring
 	// For example 5\r\nHello\r\n would have 5 delimiter characters: 1 + 2 + 2
 	delimitersSize := lineBreakIndex + 2 + 2
-	if bulkStringLength > 0 && len(buffer) >= bulkStringLength+delimitersSize {
+	if len(buffer) >= bulkStringLength+delimitersSize {
 		start := lineBreakIndex + 2
 		end := start + bulkStringLength
-		return ValidRead{BulkString{buffer[start:end]}, 1 + bulkStringLength + delimitersSize}, nil
+		// Copy the data so values kept by the server don't hold on to the whole input buffer
+		return ValidRead{BulkString{bytes.Clone(buffer[start:end])}, 1 + bulkStringLength + delimitersSize}, nil
 	}
 	return ValidRead{BulkString{}, -1}, nil
 }
@@ -229,6 +292,9 @@ func ParseArray(buffer []byte) (ValidRead, error) {
 	if err != nil {
 		panic(fmt.Errorf("invalid array length: %s", input))
 	}
+	if elements == -1 {
+		return ValidRead{NullArray{}, 1 + lineBreakIndex + 2}, nil
+	}
 
 	bytesRead := lineBreakIndex + 2
 	var arrayValues []DataType
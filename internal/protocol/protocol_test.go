@@ -19,6 +19,8 @@
 package protocol
 
 import (
+	"math"
+	"math/big"
 	"reflect"
 	"testing"
 )
@@ -41,7 +43,11 @@ func TestPartial(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, length := ParseFrame([]byte(tc.input))
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
 			if !reflect.DeepEqual(actual, tc.expected) {
 				t.Fatalf("Unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
 			}
@@ -65,11 +71,18 @@ func TestFull(t *testing.T) {
 				BulkString{[]byte("Folks")},
 			},
 		}, 38},
+		{"P6", "$0\r\n\r\n", BulkString{[]byte{}}, 6},
+		{"P7", "$-1\r\n", NullBulkString{}, 5},
+		{"P8", "*-1\r\n", NullArray{}, 5},
 	}
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, length := ParseFrame([]byte(tc.input))
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
 			if !reflect.DeepEqual(actual, tc.expected) {
 				t.Fatalf("Unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
 			}
@@ -97,7 +110,11 @@ func TestFullThenPartial(t *testing.T) {
 
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			actual, length := ParseFrame([]byte(tc.input))
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
 			if !reflect.DeepEqual(actual, tc.expected) {
 				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
 			}
@@ -107,3 +124,204 @@ func TestFullThenPartial(t *testing.T) {
 		})
 	}
 }
+
+func TestRESP3Full(t *testing.T) {
+	tcs := []testCase{
+		{"Null", "_\r\n", Null{}, 3},
+		{"True", "#t\r\n", Boolean{true}, 4},
+		{"False", "#f\r\n", Boolean{false}, 4},
+		{"Double", ",3.25\r\n", Double{3.25}, 7},
+		{"Big number", "(3492890328409238509324850943850943825024385\r\n", BigNumber{bigNumber("3492890328409238509324850943850943825024385")}, 46},
+		{"Bulk error", "!21\r\nSYNTAX invalid syntax\r\n", BulkError{"SYNTAX invalid syntax"}, 28},
+		{"Verbatim string", "=15\r\ntxt:Some string\r\n", VerbatimString{"txt", []byte("Some string")}, 22},
+		{"Map", "%2\r\n+first\r\n:1\r\n+second\r\n:2\r\n", Map{
+			elements: []DataType{
+				SimpleString{"first"}, Integer{1},
+				SimpleString{"second"}, Integer{2},
+			},
+		}, 29},
+		{"Set", "~2\r\n+orange\r\n+apple\r\n", Set{
+			elements: []DataType{SimpleString{"orange"}, SimpleString{"apple"}},
+		}, 21},
+		{"Push", ">2\r\n$7\r\nmessage\r\n$2\r\nhi\r\n", Push{
+			elements: []DataType{BulkString{[]byte("message")}, BulkString{[]byte("hi")}},
+		}, 25},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("Unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+			if length != tc.length {
+				t.Fatalf("Unexpected number of bytes read. Expected: %d, actual: %d", tc.length, length)
+			}
+			if encoded := string(actual.Encode()); encoded != tc.input {
+				t.Fatalf("Unexpected encoding. Expected: %q, actual: %q", tc.input, encoded)
+			}
+		})
+	}
+}
+
+func TestRESP3Partial(t *testing.T) {
+	tcs := []testCase{
+		{"Boolean", "#t", nil, -1},
+		{"Bulk error", "!21\r\nSYNTAX inva", BulkError{}, -1},
+		{"Map", "%2\r\n+first\r\n:1\r\n+second\r\n", Map{}, -1},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("Unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+			if length != tc.length {
+				t.Fatalf("Shouldn't have read a full value. Expected: %d, actual: %d", tc.length, length)
+			}
+		})
+	}
+}
+
+func TestConvertToRESP2(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    DataType
+		expected string
+	}{
+		{"Boolean", Boolean{true}, ":1\r\n"},
+		{"Double", Double{1.5}, "$3\r\n1.5\r\n"},
+		{"Bulk error", BulkError{"ERR failed"}, "-ERR failed\r\n"},
+		{"Verbatim string", VerbatimString{"txt", []byte("hi")}, "$2\r\nhi\r\n"},
+		{"Map", NewMap(SimpleString{"a"}, Double{2}), "*2\r\n+a\r\n$1\r\n2\r\n"},
+		{"Nested set", NewArray(NewSet(Boolean{false})), "*1\r\n*1\r\n:0\r\n"},
+		{"Null", Null{}, "$-1\r\n"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(Convert(tc.input, RESP2).Encode())
+			if actual != tc.expected {
+				t.Fatalf("Unexpected encoding. Expected: %q, actual: %q", tc.expected, actual)
+			}
+			if resp3 := Convert(tc.input, RESP3); !reflect.DeepEqual(resp3, tc.input) {
+				t.Fatalf("RESP3 replies shouldn't be converted. Expected: %v, actual: %v", tc.input, resp3)
+			}
+		})
+	}
+}
+
+func TestFormatDouble(t *testing.T) {
+	tcs := []struct {
+		value    float64
+		expected string
+	}{
+		{1.5, "1.5"},
+		{0.1, "0.1"},
+		{-2, "-2"},
+		{1234567, "1234567"},
+		{1e16, "10000000000000000"},
+		{1e17, "1e+17"},
+		{1e300, "1e+300"},
+		{-1.25e-300, "-1.25e-300"},
+		{0.0001, "0.0001"},
+		{0.00001, "1e-05"},
+		{math.Inf(-1), "-inf"},
+	}
+	for _, tc := range tcs {
+		if actual := FormatDouble(tc.value); actual != tc.expected {
+			t.Fatalf("unexpected format of %v. Expected: %q, Actual: %q", tc.value, tc.expected, actual)
+		}
+	}
+}
+
+func TestConvertPairs(t *testing.T) {
+	pairs := NewPairs(BulkString{[]byte("a")}, Double{1.5}, BulkString{[]byte("b")}, Double{2})
+	if actual := string(Convert(pairs, RESP2).Encode()); actual != "*4\r\n$1\r\na\r\n$3\r\n1.5\r\n$1\r\nb\r\n$1\r\n2\r\n" {
+		t.Fatalf("unexpected RESP2 encoding %q", actual)
+	}
+	if actual := string(Convert(pairs, RESP3).Encode()); actual != "*2\r\n*2\r\n$1\r\na\r\n,1.5\r\n*2\r\n$1\r\nb\r\n,2\r\n" {
+		t.Fatalf("unexpected RESP3 encoding %q", actual)
+	}
+}
+
+func TestConvertToRESP3(t *testing.T) {
+	tcs := []struct {
+		name     string
+		input    DataType
+		expected string
+	}{
+		{"Null bulk string", NullBulkString{}, "_\r\n"},
+		{"Null array", NullArray{}, "_\r\n"},
+		{"Nested null", NewArray(BulkString{[]byte("a")}, NullBulkString{}), "*2\r\n$1\r\na\r\n_\r\n"},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := string(Convert(tc.input, RESP3).Encode())
+			if actual != tc.expected {
+				t.Fatalf("Unexpected encoding. Expected: %q, actual: %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func bigNumber(value string) *big.Int {
+	number, _ := new(big.Int).SetString(value, 10)
+	return number
+}
+
+func TestInline(t *testing.T) {
+	tcs := []testCase{
+		{"CRLF", "SET key value\r\n", NewArray(
+			BulkString{[]byte("SET")}, BulkString{[]byte("key")}, BulkString{[]byte("value")},
+		), 15},
+		{"LF and extra spaces", "  GET   key \n", NewArray(
+			BulkString{[]byte("GET")}, BulkString{[]byte("key")},
+		), 13},
+		{"Double quotes", "SET \"my key\" \"a\\tb\\x41\"\r\n", NewArray(
+			BulkString{[]byte("SET")}, BulkString{[]byte("my key")}, BulkString{[]byte("a\tbA")},
+		), 25},
+		{"Single quotes", "SET k 'it\\'s \"here\"'\n", NewArray(
+			BulkString{[]byte("SET")}, BulkString{[]byte("k")}, BulkString{[]byte("it's \"here\"")},
+		), 21},
+		{"Empty quoted argument", "SET k \"\"\n", NewArray(
+			BulkString{[]byte("SET")}, BulkString{[]byte("k")}, BulkString{[]byte{}},
+		), 9},
+		{"Partial", "SET key va", nil, -1},
+		{"Full then RESP", "PING\r\n*1\r\n$4\r\nPING\r\n", NewArray(BulkString{[]byte("PING")}), 6},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			validRead, err := ParseFrame([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual, length := validRead.Unwrap()
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("Unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+			if length != tc.length {
+				t.Fatalf("Unexpected number of bytes read. Expected: %d, actual: %d", tc.length, length)
+			}
+		})
+	}
+}
+
+func TestInlineUnbalancedQuotes(t *testing.T) {
+	for _, input := range []string{"SET k \"value\r\n", "SET k 'value\n", "SET k \"a\"b\n"} {
+		if _, err := ParseFrame([]byte(input)); err != errInlineUnbalancedQuotes {
+			t.Fatalf("Expected unbalanced quotes error for %q, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Protocol versions that can be negotiated by a client with the HELLO command.
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+type Null struct{}
+
+type Boolean struct {
+	value bool
+}
+
+type Double struct {
+	value float64
+}
+
+type BigNumber struct {
+	value *big.Int
+}
+
+type BulkError struct {
+	msg string
+}
+
+type VerbatimString struct {
+	format string
+	data   []byte
+}
+
+// Map holds its keys and values interleaved, in the same order they are sent over
+// the wire: key1, value1, key2, value2, ...
+type Map struct {
+	elements []DataType
+}
+
+// Pairs holds pairs of values interleaved, like the members of a sorted set and their
+// scores. It's sent as an array of two element arrays, which is how Redis replies to
+// RESP3 clients, while RESP2 clients receive a flat array like the one of a Map.
+type Pairs struct {
+	elements []DataType
+}
+
+type Set struct {
+	elements []DataType
+}
+
+type Push struct {
+	elements []DataType
+}
+
+func (n Null) String() string {
+	return "(nil)"
+}
+
+func (n Null) Encode() []byte {
+	return []byte("_\r\n")
+}
+
+func (b Boolean) String() string {
+	return strconv.FormatBool(b.value)
+}
+
+func (b Boolean) Encode() []byte {
+	if b.value {
+		return []byte("#t\r\n")
+	}
+	return []byte("#f\r\n")
+}
+
+func (b Boolean) Value() bool {
+	return b.value
+}
+
+func (d Double) String() string {
+	return FormatDouble(d.value)
+}
+
+func (d Double) Encode() []byte {
+	var buffer []byte
+	buffer = append(buffer, []byte(",")...)
+	buffer = append(buffer, []byte(FormatDouble(d.value))...)
+	buffer = append(buffer, []byte("\r\n")...)
+	return buffer
+}
+
+func (d Double) Value() float64 {
+	return d.value
+}
+
+func (b BigNumber) String() string {
+	return b.value.String()
+}
+
+func (b BigNumber) Encode() []byte {
+	var buffer []byte
+	buffer = append(buffer, []byte("(")...)
+	buffer = append(buffer, []byte(b.value.String())...)
+	buffer = append(buffer, []byte("\r\n")...)
+	return buffer
+}
+
+func (b BulkError) String() string {
+	return b.msg
+}
+
+func (b BulkError) Encode() []byte {
+	var buffer []byte
+	buffer = append(buffer, []byte("!")...)
+	buffer = append(buffer, []byte(strconv.Itoa(len(b.msg)))...)
+	buffer = append(buffer, []byte("\r\n")...)
+	buffer = append(buffer, []byte(b.msg)...)
+	buffer = append(buffer, []byte("\r\n")...)
+	return buffer
+}
+
+func (v VerbatimString) String() string {
+	return string(v.data)
+}
+
+func (v VerbatimString) Encode() []byte {
+	var buffer []byte
+	buffer = append(buffer, []byte("=")...)
+	buffer = append(buffer, []byte(strconv.Itoa(len(v.format)+1+len(v.data)))...)
+	buffer = append(buffer, []byte("\r\n")...)
+	buffer = append(buffer, []byte(v.format)...)
+	buffer = append(buffer, []byte(":")...)
+	buffer = append(buffer, v.data...)
+	buffer = append(buffer, []byte("\r\n")...)
+	return buffer
+}
+
+func (v VerbatimString) Format() string {
+	return v.format
+}
+
+func (m Map) String() string {
+	pairs := []string{}
+	for i := 0; i+1 < len(m.elements); i += 2 {
+		pairs = append(pairs, m.elements[i].String()+":"+m.elements[i+1].String())
+	}
+	return "Map[" + strings.Join(pairs, ",") + "]"
+}
+
+func (m Map) Encode() []byte {
+	return encodeAggregate('%', len(m.elements)/2, m.elements)
+}
+
+// GetElements returns the keys and values of the map interleaved.
+func (m Map) GetElements() []DataType {
+	return m.elements
+}
+
+func (p Pairs) String() string {
+	pairs := []string{}
+	for i := 0; i+1 < len(p.elements); i += 2 {
+		pairs = append(pairs, "["+p.elements[i].String()+","+p.elements[i+1].String()+"]")
+	}
+	return "Pairs[" + strings.Join(pairs, ",") + "]"
+}
+
+func (p Pairs) Encode() []byte {
+	return p.nested().Encode()
+}
+
+// GetElements returns the values of the pairs interleaved.
+func (p Pairs) GetElements() []DataType {
+	return p.elements
+}
+
+// nested returns the pairs as an array of two element arrays.
+func (p Pairs) nested() Array {
+	pairs := make([]DataType, 0, len(p.elements)/2)
+	for i := 0; i+1 < len(p.elements); i += 2 {
+		pairs = append(pairs, Array{[]DataType{p.elements[i], p.elements[i+1]}})
+	}
+	return Array{pairs}
+}
+
+func (s Set) String() string {
+	return "Set[" + joinElements(s.elements) + "]"
+}
+
+func (s Set) Encode() []byte {
+	return encodeAggregate('~', len(s.elements), s.elements)
+}
+
+func (s Set) GetElements() []DataType {
+	return s.elements
+}
+
+func (p Push) String() string {
+	return "Push[" + joinElements(p.elements) + "]"
+}
+
+func (p Push) Encode() []byte {
+	return encodeAggregate('>', len(p.elements), p.elements)
+}
+
+func (p Push) GetElements() []DataType {
+	return p.elements
+}
+
+func NewNull() Null {
+	return Null{}
+}
+
+func NewBoolean(value bool) Boolean {
+	return Boolean{value}
+}
+
+func NewDouble(value float64) Double {
+	return Double{value}
+}
+
+func NewBigNumber(value *big.Int) BigNumber {
+	return BigNumber{value}
+}
+
+func NewBulkError(msg string) BulkError {
+	return BulkError{msg}
+}
+
+// NewVerbatimString creates a verbatim string. The format must be exactly 3 characters
+// long, for example "txt" or "mkd".
+func NewVerbatimString(format string, data []byte) VerbatimString {
+	return VerbatimString{format, data}
+}
+
+// NewMap creates a Map out of interleaved keys and values.
+func NewMap(elements ...DataType) Map {
+	return Map{elements}
+}
+
+// NewPairs creates Pairs out of interleaved values.
+func NewPairs(elements ...DataType) Pairs {
+	return Pairs{elements}
+}
+
+func NewSet(elements ...DataType) Set {
+	return Set{elements}
+}
+
+func NewPush(elements ...DataType) Push {
+	return Push{elements}
+}
+
+// FormatDouble formats a float the way Redis replies with doubles: the shortest
+// decimal representation, with inf, -inf and nan for the special values. Like the %.17g
+// format of C, the exponent notation is used when the exponent is less than -4 or at
+// least 17, so large and tiny doubles don't print hundreds of digits.
+func FormatDouble(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	case math.IsNaN(value):
+		return "nan"
+	}
+	formatted := strconv.FormatFloat(value, 'e', -1, 64)
+	exponent, _ := strconv.Atoi(formatted[strings.IndexByte(formatted, 'e')+1:])
+	if exponent < -4 || exponent >= 17 {
+		return formatted
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// Convert adapts a reply to the protocol version negotiated by the client. RESP2
+// connections receive the closest RESP2 equivalent of every RESP3 type, the same way
+// Redis downgrades its replies, and RESP3 connections receive the RESP3 Null in place
+// of the RESP2 null bulk string and null array. Pairs are flattened for RESP2 and
+// nested for RESP3.
+func Convert(data DataType, version int) DataType {
+	if version >= RESP3 {
+		return convertToRESP3(data)
+	}
+	switch data := data.(type) {
+	case Null:
+		return NullBulkString{}
+	case Boolean:
+		if data.value {
+			return Integer{1}
+		}
+		return Integer{0}
+	case Double:
+		return BulkString{[]byte(data.String())}
+	case BigNumber:
+		return BulkString{[]byte(data.String())}
+	case BulkError:
+		return Error{data.msg}
+	case VerbatimString:
+		return BulkString{data.data}
+	case Map:
+		return Array{convertElements(data.elements, version)}
+	case Pairs:
+		return Array{convertElements(data.elements, version)}
+	case Set:
+		return Array{convertElements(data.elements, version)}
+	case Push:
+		return Array{convertElements(data.elements, version)}
+	case Array:
+		return Array{convertElements(data.elements, version)}
+	default:
+		return data
+	}
+}
+
+func convertToRESP3(data DataType) DataType {
+	switch data := data.(type) {
+	case NullBulkString, NullArray:
+		return Null{}
+	case Array:
+		return Array{convertElements(data.elements, RESP3)}
+	case Map:
+		return Map{convertElements(data.elements, RESP3)}
+	case Pairs:
+		return Pairs{convertElements(data.elements, RESP3)}.nested()
+	case Set:
+		return Set{convertElements(data.elements, RESP3)}
+	case Push:
+		return Push{convertElements(data.elements, RESP3)}
+	default:
+		return data
+	}
+}
+
+func convertElements(elements []DataType, version int) []DataType {
+	if elements == nil {
+		return nil
+	}
+	converted := make([]DataType, len(elements))
+	for i, element := range elements {
+		converted[i] = Convert(element, version)
+	}
+	return converted
+}
+
+func joinElements(elements []DataType) string {
+	values := []string{}
+	for _, val := range elements {
+		values = append(values, val.String())
+	}
+	return strings.Join(values, ",")
+}
+
+func encodeAggregate(prefix byte, size int, elements []DataType) []byte {
+	buffer := []byte{prefix}
+	buffer = append(buffer, []byte(strconv.Itoa(size))...)
+	buffer = append(buffer, []byte("\r\n")...)
+	for _, val := range elements {
+		buffer = append(buffer, val.Encode()...)
+	}
+	return buffer
+}
+
+func ParseNull(buffer []byte) (ValidRead, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return ValidRead{Null{}, -1}, nil
+	}
+	if lineBreakIndex != 0 {
+		return ValidRead{}, errors.New("invalid null value")
+	}
+	return ValidRead{Null{}, 1 + 2}, nil
+}
+
+func ParseBoolean(buffer []byte) (ValidRead, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return ValidRead{Boolean{}, -1}, nil
+	}
+	switch string(buffer[0:lineBreakIndex]) {
+	case "t":
+		return ValidRead{Boolean{true}, 1 + lineBreakIndex + 2}, nil
+	case "f":
+		return ValidRead{Boolean{false}, 1 + lineBreakIndex + 2}, nil
+	default:
+		return ValidRead{}, fmt.Errorf("invalid boolean value %s", buffer[0:lineBreakIndex])
+	}
+}
+
+func ParseDouble(buffer []byte) (ValidRead, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return ValidRead{Double{}, -1}, nil
+	}
+	input := string(buffer[0:lineBreakIndex])
+	var value float64
+	switch strings.ToLower(input) {
+	case "inf", "+inf":
+		value = math.Inf(1)
+	case "-inf":
+		value = math.Inf(-1)
+	case "nan":
+		value = math.NaN()
+	default:
+		fval, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return ValidRead{}, fmt.Errorf("error reading double %s: %w", input, err)
+		}
+		value = fval
+	}
+	return ValidRead{Double{value}, 1 + lineBreakIndex + 2}, nil
+}
+
+func ParseBigNumber(buffer []byte) (ValidRead, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return ValidRead{BigNumber{}, -1}, nil
+	}
+	input := string(buffer[0:lineBreakIndex])
+	value, ok := new(big.Int).SetString(input, 10)
+	if !ok {
+		return ValidRead{}, fmt.Errorf("error reading big number %s", input)
+	}
+	return ValidRead{BigNumber{value}, 1 + lineBreakIndex + 2}, nil
+}
+
+func ParseBulkError(buffer []byte) (ValidRead, error) {
+	data, size, err := parseBlob(buffer)
+	if err != nil || size == -1 {
+		return ValidRead{BulkError{}, size}, err
+	}
+	return ValidRead{BulkError{string(data)}, size}, nil
+}
+
+func ParseVerbatimString(buffer []byte) (ValidRead, error) {
+	data, size, err := parseBlob(buffer)
+	if err != nil || size == -1 {
+		return ValidRead{VerbatimString{}, size}, err
+	}
+	if len(data) < 4 || data[3] != ':' {
+		return ValidRead{}, errors.New("invalid verbatim string format")
+	}
+	return ValidRead{VerbatimString{string(data[:3]), data[4:]}, size}, nil
+}
+
+func ParseMap(buffer []byte) (ValidRead, error) {
+	elements, size, err := parseAggregate(buffer, 2)
+	if err != nil || size == -1 {
+		return ValidRead{Map{}, size}, err
+	}
+	return ValidRead{Map{elements}, size}, nil
+}
+
+func ParseSet(buffer []byte) (ValidRead, error) {
+	elements, size, err := parseAggregate(buffer, 1)
+	if err != nil || size == -1 {
+		return ValidRead{Set{}, size}, err
+	}
+	return ValidRead{Set{elements}, size}, nil
+}
+
+func ParsePush(buffer []byte) (ValidRead, error) {
+	elements, size, err := parseAggregate(buffer, 1)
+	if err != nil || size == -1 {
+		return ValidRead{Push{}, size}, err
+	}
+	return ValidRead{Push{elements}, size}, nil
+}
+
+// parseBlob reads a length prefixed payload like the ones used by bulk errors and
+// verbatim strings. It returns the payload and the number of bytes read, including
+// the type prefix, or -1 if the buffer doesn't contain the full payload yet.
+func parseBlob(buffer []byte) ([]byte, int, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return nil, -1, nil
+	}
+	length := string(buffer[0:lineBreakIndex])
+	blobLength, err := strconv.Atoi(length)
+	if err != nil || blobLength < 0 {
+		return nil, -1, fmt.Errorf("invalid length %s", length)
+	}
+	start := lineBreakIndex + 2
+	end := start + blobLength
+	if len(buffer) < end+2 {
+		return nil, -1, nil
+	}
+	return buffer[start:end], 1 + end + 2, nil
+}
+
+// parseAggregate reads the elements of an aggregate type. The declared size is
+// multiplied by elementsPerEntry so maps can read both keys and values.
+func parseAggregate(buffer []byte, elementsPerEntry int) ([]DataType, int, error) {
+	lineBreakIndex := bytes.Index(buffer, []byte("\r\n"))
+	if lineBreakIndex == -1 {
+		return nil, -1, nil
+	}
+	input := string(buffer[0:lineBreakIndex])
+	entries, err := strconv.Atoi(input)
+	if err != nil || entries < 0 {
+		return nil, -1, fmt.Errorf("invalid aggregate length: %s", input)
+	}
+
+	bytesRead := lineBreakIndex + 2
+	var values []DataType
+
+	for i := 0; i < entries*elementsPerEntry; i++ {
+		if len(buffer) <= bytesRead {
+			return nil, -1, nil
+		}
+		validRead, err := parseElement(buffer[bytesRead:])
+		if err != nil {
+			return nil, -1, err
+		}
+		element, byteSize := validRead.Unwrap()
+		if byteSize < 0 {
+			return nil, -1, nil
+		}
+		bytesRead += byteSize
+		values = append(values, element)
+	}
+	return values, 1 + bytesRead, nil
+}
@@ -0,0 +1,18 @@
+package rdb
+
+import (
+	"hash/crc64"
+)
+
+// jonesPolynomial is the polynomial of the CRC64 used by Redis, the Jones variant, in
+// the reversed form expected by hash/crc64.
+const jonesPolynomial = 0x95ac9329ac4bc9b5
+
+var jonesTable = crc64.MakeTable(jonesPolynomial)
+
+// Checksum adds the data to the CRC64 crc, which is 0 for no data. Unlike the CRC64 of
+// hash/crc64, the one of Redis doesn't invert the bits before and after the update, so
+// it's undone here.
+func Checksum(crc uint64, data []byte) uint64 {
+	return ^crc64.Update(^crc, jonesTable, data)
+}
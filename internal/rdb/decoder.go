@@ -0,0 +1,249 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	// maxPrealloc is the largest buffer allocated up front for a string, so a corrupted
+	// length fails reading the data instead of allocating a huge buffer.
+	maxPrealloc = 1 << 20
+	// maxStringSize is the longest string Redis can store, 512MB.
+	maxStringSize = 512 << 20
+)
+
+// Decoder reads values in the RDB format, keeping the checksum of everything read.
+type Decoder struct {
+	r   *bufio.Reader
+	crc uint64
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// ReadRaw reads n bytes as they are.
+func (d *Decoder) ReadRaw(n int) ([]byte, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("%w: invalid length", ErrFormat)
+	}
+	buf := make([]byte, min(n, maxPrealloc))
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return nil, d.readError(err)
+	}
+	for len(buf) < n {
+		chunk := make([]byte, min(n-len(buf), maxPrealloc))
+		if _, err := io.ReadFull(d.r, chunk); err != nil {
+			return nil, d.readError(err)
+		}
+		buf = append(buf, chunk...)
+	}
+	d.crc = Checksum(d.crc, buf)
+	return buf, nil
+}
+
+func (d *Decoder) readError(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: unexpected end of data", ErrFormat)
+	}
+	return err
+}
+
+// More tells if there's more data to read.
+func (d *Decoder) More() bool {
+	_, err := d.r.Peek(1)
+	return err == nil
+}
+
+// ReadHeader reads the magic string and the version that start an RDB file, returning
+// the version. Files written by a newer version of Redis aren't supported.
+func (d *Decoder) ReadHeader() (int, error) {
+	header, err := d.ReadRaw(9)
+	if err != nil {
+		return 0, err
+	}
+	if string(header[:5]) != "REDIS" {
+		return 0, fmt.Errorf("%w: wrong signature", ErrFormat)
+	}
+	version, err := strconv.Atoi(string(header[5:]))
+	if err != nil || version < 1 || version > Version {
+		return 0, fmt.Errorf("%w: can't handle RDB format version %s", ErrFormat, header[5:])
+	}
+	return version, nil
+}
+
+// ReadOpcode reads an opcode or the type of a key.
+func (d *Decoder) ReadOpcode() (byte, error) {
+	b, err := d.ReadRaw(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// ReadFooter reads the checksum that ends the file, after the EOF opcode, and checks it
+// against the data read. A checksum of 0 means the file was written without one.
+func (d *Decoder) ReadFooter() error {
+	expected := d.crc
+	footer, err := d.ReadRaw(8)
+	if err != nil {
+		return err
+	}
+	if crc := binary.LittleEndian.Uint64(footer); crc != 0 && crc != expected {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// ReadLength reads a length, or any unsigned integer.
+func (d *Decoder) ReadLength() (uint64, error) {
+	n, special, err := d.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if special {
+		return 0, fmt.Errorf("%w: unexpected string encoding", ErrFormat)
+	}
+	return n, nil
+}
+
+// readLength reads a length. If special is set it's not a length but the encoding of
+// a string.
+func (d *Decoder) readLength() (n uint64, special bool, err error) {
+	first, err := d.ReadOpcode()
+	if err != nil {
+		return 0, false, err
+	}
+	switch {
+	case first>>6 == len6Bit:
+		return uint64(first & 0x3f), false, nil
+	case first>>6 == len14Bit:
+		next, err := d.ReadOpcode()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(first&0x3f)<<8 | uint64(next), false, nil
+	case first == len32Bit:
+		buf, err := d.ReadRaw(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(buf)), false, nil
+	case first == len64Bit:
+		buf, err := d.ReadRaw(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(buf), false, nil
+	case first>>6 == lenSpecial:
+		return uint64(first & 0x3f), true, nil
+	}
+	return 0, false, fmt.Errorf("%w: unknown length encoding %d", ErrFormat, first)
+}
+
+// ReadString reads a string, whether it was written as is, as an integer or compressed.
+func (d *Decoder) ReadString() ([]byte, error) {
+	n, special, err := d.readLength()
+	if err != nil {
+		return nil, err
+	}
+	if !special {
+		return d.ReadRaw(int(n))
+	}
+	switch n {
+	case encodingInt8:
+		buf, err := d.ReadRaw(1)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int8(buf[0])), 10), nil
+	case encodingInt16:
+		buf, err := d.ReadRaw(2)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int16(binary.LittleEndian.Uint16(buf))), 10), nil
+	case encodingInt32:
+		buf, err := d.ReadRaw(4)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.AppendInt(nil, int64(int32(binary.LittleEndian.Uint32(buf))), 10), nil
+	case encodingLZF:
+		compressedLen, err := d.ReadLength()
+		if err != nil {
+			return nil, err
+		}
+		uncompressedLen, err := d.ReadLength()
+		if err != nil {
+			return nil, err
+		}
+		if uncompressedLen > maxStringSize {
+			return nil, fmt.Errorf("%w: invalid length", ErrFormat)
+		}
+		compressed, err := d.ReadRaw(int(compressedLen))
+		if err != nil {
+			return nil, err
+		}
+		return decompressLZF(compressed, int(uncompressedLen))
+	}
+	return nil, fmt.Errorf("%w: unknown string encoding %d", ErrFormat, n)
+}
+
+// ReadMillis reads a Unix time in milliseconds.
+func (d *Decoder) ReadMillis() (int64, error) {
+	buf, err := d.ReadRaw(8)
+	if err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint64(buf)), nil
+}
+
+// ReadSeconds reads a Unix time in seconds, used by the oldest versions of the format.
+func (d *Decoder) ReadSeconds() (int64, error) {
+	buf, err := d.ReadRaw(4)
+	if err != nil {
+		return 0, err
+	}
+	return int64(int32(binary.LittleEndian.Uint32(buf))), nil
+}
+
+// ReadDouble reads a float64 in its binary form.
+func (d *Decoder) ReadDouble() (float64, error) {
+	buf, err := d.ReadRaw(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(buf)), nil
+}
+
+// ReadStringDouble reads a float64 written as a string, which is how the scores of
+// TypeZSet sorted sets are stored.
+func (d *Decoder) ReadStringDouble() (float64, error) {
+	n, err := d.ReadOpcode()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	buf, err := d.ReadRaw(int(n))
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseFloat(string(buf), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid double %q", ErrFormat, buf)
+	}
+	return value, nil
+}
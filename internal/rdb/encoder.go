@@ -0,0 +1,127 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Encoder writes values in the RDB format, keeping the checksum of everything written.
+// Like bufio.Writer, the first error stops the writes and is returned by Err, so a
+// sequence of writes only needs to be checked once.
+type Encoder struct {
+	w   io.Writer
+	crc uint64
+	err error
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Err returns the first error writing the values, if any.
+func (e *Encoder) Err() error {
+	return e.err
+}
+
+// Checksum returns the CRC64 of everything written so far.
+func (e *Encoder) Checksum() uint64 {
+	return e.crc
+}
+
+// WriteRaw writes the bytes as they are.
+func (e *Encoder) WriteRaw(p []byte) {
+	if e.err != nil {
+		return
+	}
+	e.crc = Checksum(e.crc, p)
+	_, e.err = e.w.Write(p)
+}
+
+// WriteHeader writes the magic string and the version that start an RDB file.
+func (e *Encoder) WriteHeader() {
+	e.WriteRaw([]byte(fmt.Sprintf("REDIS%04d", Version)))
+}
+
+// WriteOpcode writes an opcode or the type of a key.
+func (e *Encoder) WriteOpcode(opcode byte) {
+	e.WriteRaw([]byte{opcode})
+}
+
+// WriteAux writes an auxiliary field, which holds information about the file.
+func (e *Encoder) WriteAux(key, value string) {
+	e.WriteOpcode(OpcodeAux)
+	e.WriteString([]byte(key))
+	e.WriteString([]byte(value))
+}
+
+// WriteFooter writes the end of the file: the EOF opcode and the checksum.
+func (e *Encoder) WriteFooter() {
+	e.WriteOpcode(OpcodeEOF)
+	var footer [8]byte
+	binary.LittleEndian.PutUint64(footer[:], e.crc)
+	e.WriteRaw(footer[:])
+}
+
+// WriteLength writes a length, or any unsigned integer, using 1, 2, 5 or 9 bytes
+// depending on its value.
+func (e *Encoder) WriteLength(n uint64) {
+	switch {
+	case n < 1<<6:
+		e.WriteRaw([]byte{byte(n)})
+	case n < 1<<14:
+		e.WriteRaw([]byte{len14Bit<<6 | byte(n>>8), byte(n)})
+	case n <= math.MaxUint32:
+		buf := []byte{len32Bit, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(buf[1:], uint32(n))
+		e.WriteRaw(buf)
+	default:
+		buf := []byte{len64Bit, 0, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint64(buf[1:], n)
+		e.WriteRaw(buf)
+	}
+}
+
+// WriteString writes a string. Strings holding a small integer are written as the
+// integer, which takes less space, like Redis does.
+func (e *Encoder) WriteString(s []byte) {
+	if len(s) <= 11 {
+		if value, err := strconv.ParseInt(string(s), 10, 32); err == nil && strconv.FormatInt(value, 10) == string(s) {
+			e.writeInteger(value)
+			return
+		}
+	}
+	e.WriteLength(uint64(len(s)))
+	e.WriteRaw(s)
+}
+
+func (e *Encoder) writeInteger(value int64) {
+	switch {
+	case value >= math.MinInt8 && value <= math.MaxInt8:
+		e.WriteRaw([]byte{lenSpecial<<6 | encodingInt8, byte(value)})
+	case value >= math.MinInt16 && value <= math.MaxInt16:
+		buf := []byte{lenSpecial<<6 | encodingInt16, 0, 0}
+		binary.LittleEndian.PutUint16(buf[1:], uint16(value))
+		e.WriteRaw(buf)
+	default:
+		buf := []byte{lenSpecial<<6 | encodingInt32, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(buf[1:], uint32(value))
+		e.WriteRaw(buf)
+	}
+}
+
+// WriteMillis writes a Unix time in milliseconds.
+func (e *Encoder) WriteMillis(ms int64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(ms))
+	e.WriteRaw(buf[:])
+}
+
+// WriteDouble writes a float64 in its binary form.
+func (e *Encoder) WriteDouble(f float64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], math.Float64bits(f))
+	e.WriteRaw(buf[:])
+}
@@ -0,0 +1,267 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// A listpack is a sequence of strings and integers serialized in a single buffer: a
+// header with the total size and the number of entries, the entries and an end byte.
+// Each entry is its encoding, its data and its length written backwards, so the
+// listpack can be walked in both directions.
+const (
+	listpackHeaderSize = 6
+	listpackEnd        = 0xff
+	// listpackUnknownCount is the number of entries in the header of listpacks with
+	// more entries than fit in it.
+	listpackUnknownCount = math.MaxUint16
+)
+
+// Listpack builds a listpack by appending entries to it.
+type Listpack struct {
+	entries []byte
+	count   int
+}
+
+// Len returns the number of entries in the listpack.
+func (lp *Listpack) Len() int {
+	return lp.count
+}
+
+// AppendString appends a string, which is stored as an integer if it's the canonical
+// representation of one, like Redis does.
+func (lp *Listpack) AppendString(s []byte) {
+	if len(s) <= 20 {
+		if value, err := strconv.ParseInt(string(s), 10, 64); err == nil && strconv.FormatInt(value, 10) == string(s) {
+			lp.AppendInteger(value)
+			return
+		}
+	}
+	start := len(lp.entries)
+	switch {
+	case len(s) < 1<<6:
+		lp.entries = append(lp.entries, 0x80|byte(len(s)))
+	case len(s) < 1<<12:
+		lp.entries = append(lp.entries, 0xe0|byte(len(s)>>8), byte(len(s)))
+	default:
+		lp.entries = append(lp.entries, 0xf0)
+		lp.entries = binary.LittleEndian.AppendUint32(lp.entries, uint32(len(s)))
+	}
+	lp.entries = append(lp.entries, s...)
+	lp.appendBacklen(len(lp.entries) - start)
+}
+
+// AppendInteger appends an integer, using the smallest encoding it fits in.
+func (lp *Listpack) AppendInteger(value int64) {
+	start := len(lp.entries)
+	switch {
+	case value >= 0 && value < 1<<7:
+		lp.entries = append(lp.entries, byte(value))
+	case value >= -1<<12 && value < 1<<12:
+		encoded := uint64(value) & (1<<13 - 1)
+		lp.entries = append(lp.entries, 0xc0|byte(encoded>>8), byte(encoded))
+	case value >= math.MinInt16 && value <= math.MaxInt16:
+		lp.entries = append(lp.entries, 0xf1)
+		lp.entries = binary.LittleEndian.AppendUint16(lp.entries, uint16(value))
+	case value >= -1<<23 && value < 1<<23:
+		encoded := uint32(value)
+		lp.entries = append(lp.entries, 0xf2, byte(encoded), byte(encoded>>8), byte(encoded>>16))
+	case value >= math.MinInt32 && value <= math.MaxInt32:
+		lp.entries = append(lp.entries, 0xf3)
+		lp.entries = binary.LittleEndian.AppendUint32(lp.entries, uint32(value))
+	default:
+		lp.entries = append(lp.entries, 0xf4)
+		lp.entries = binary.LittleEndian.AppendUint64(lp.entries, uint64(value))
+	}
+	lp.appendBacklen(len(lp.entries) - start)
+}
+
+// appendBacklen appends the size of the entry, encoding and data, written backwards in
+// groups of 7 bits.
+func (lp *Listpack) appendBacklen(size int) {
+	switch {
+	case size < 1<<7:
+		lp.entries = append(lp.entries, byte(size))
+	case size < 1<<14:
+		lp.entries = append(lp.entries, byte(size>>7), byte(size&127)|128)
+	case size < 1<<21:
+		lp.entries = append(lp.entries, byte(size>>14), byte(size>>7&127)|128, byte(size&127)|128)
+	case size < 1<<28:
+		lp.entries = append(lp.entries, byte(size>>21), byte(size>>14&127)|128, byte(size>>7&127)|128, byte(size&127)|128)
+	default:
+		lp.entries = append(lp.entries, byte(size>>28), byte(size>>21&127)|128, byte(size>>14&127)|128, byte(size>>7&127)|128, byte(size&127)|128)
+	}
+	lp.count++
+}
+
+// Bytes returns the serialized listpack.
+func (lp *Listpack) Bytes() []byte {
+	buf := make([]byte, listpackHeaderSize, listpackHeaderSize+len(lp.entries)+1)
+	binary.LittleEndian.PutUint32(buf, uint32(cap(buf)))
+	binary.LittleEndian.PutUint16(buf[4:], uint16(min(lp.count, listpackUnknownCount)))
+	buf = append(buf, lp.entries...)
+	return append(buf, listpackEnd)
+}
+
+// ListpackEntries returns the entries of the serialized listpack. Integers are returned
+// in their decimal representation.
+func ListpackEntries(data []byte) ([][]byte, error) {
+	invalid := fmt.Errorf("%w: invalid listpack", ErrFormat)
+	if len(data) < listpackHeaderSize+1 || int(binary.LittleEndian.Uint32(data)) != len(data) {
+		return nil, invalid
+	}
+	entries := [][]byte{}
+	for i := listpackHeaderSize; ; {
+		if i >= len(data) {
+			return nil, invalid
+		}
+		b := data[i]
+		if b == listpackEnd {
+			break
+		}
+		var entry []byte
+		var size int
+		var value int64
+		isInteger := true
+		switch {
+		case b&0x80 == 0:
+			value, size = int64(b), 1
+		case b&0xc0 == 0x80:
+			isInteger = false
+			entry, size = slice(data, i+1, int(b&0x3f)), 1+int(b&0x3f)
+		case b&0xe0 == 0xc0:
+			if i+1 >= len(data) {
+				return nil, invalid
+			}
+			encoded := int64(b&0x1f)<<8 | int64(data[i+1])
+			if encoded >= 1<<12 {
+				encoded -= 1 << 13
+			}
+			value, size = encoded, 2
+		case b&0xf0 == 0xe0:
+			if i+1 >= len(data) {
+				return nil, invalid
+			}
+			length := int(b&0x0f)<<8 | int(data[i+1])
+			isInteger = false
+			entry, size = slice(data, i+2, length), 2+length
+		case b == 0xf0:
+			header := slice(data, i+1, 4)
+			if header == nil {
+				return nil, invalid
+			}
+			length := int(binary.LittleEndian.Uint32(header))
+			isInteger = false
+			entry, size = slice(data, i+5, length), 5+length
+		case b == 0xf1:
+			if buf := slice(data, i+1, 2); buf != nil {
+				value = int64(int16(binary.LittleEndian.Uint16(buf)))
+			}
+			size = 3
+		case b == 0xf2:
+			if buf := slice(data, i+1, 3); buf != nil {
+				value = int64(int32(uint32(buf[0])<<8|uint32(buf[1])<<16|uint32(buf[2])<<24) >> 8)
+			}
+			size = 4
+		case b == 0xf3:
+			if buf := slice(data, i+1, 4); buf != nil {
+				value = int64(int32(binary.LittleEndian.Uint32(buf)))
+			}
+			size = 5
+		case b == 0xf4:
+			if buf := slice(data, i+1, 8); buf != nil {
+				value = int64(binary.LittleEndian.Uint64(buf))
+			}
+			size = 9
+		default:
+			return nil, invalid
+		}
+		if i+size > len(data) || (!isInteger && entry == nil) {
+			return nil, invalid
+		}
+		if isInteger {
+			entry = strconv.AppendInt(nil, value, 10)
+		}
+		entries = append(entries, entry)
+		i += size + backlenSize(size)
+	}
+	return entries, nil
+}
+
+// slice returns the n bytes of data at the offset, or nil if they're out of bounds.
+func slice(data []byte, offset, n int) []byte {
+	if offset+n > len(data) || n < 0 {
+		return nil
+	}
+	return data[offset : offset+n]
+}
+
+func backlenSize(size int) int {
+	switch {
+	case size < 1<<7:
+		return 1
+	case size < 1<<14:
+		return 2
+	case size < 1<<21:
+		return 3
+	case size < 1<<28:
+		return 4
+	}
+	return 5
+}
+
+// IntsetEntries returns the integers of a serialized intset, the encoding Redis uses for
+// small sets of integers: the size of the integers, the number of integers and the
+// integers in ascending order.
+func IntsetEntries(data []byte) ([]int64, error) {
+	invalid := fmt.Errorf("%w: invalid intset", ErrFormat)
+	if len(data) < 8 {
+		return nil, invalid
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	if (size != 2 && size != 4 && size != 8) || len(data) != 8+size*count {
+		return nil, invalid
+	}
+	values := make([]int64, count)
+	for i := range values {
+		buf := data[8+i*size:]
+		switch size {
+		case 2:
+			values[i] = int64(int16(binary.LittleEndian.Uint16(buf)))
+		case 4:
+			values[i] = int64(int32(binary.LittleEndian.Uint32(buf)))
+		case 8:
+			values[i] = int64(binary.LittleEndian.Uint64(buf))
+		}
+	}
+	return values, nil
+}
+
+// Intset serializes the integers, which must be in ascending order, as an intset.
+func Intset(values []int64) []byte {
+	size := 2
+	for _, value := range values {
+		switch {
+		case value < math.MinInt32 || value > math.MaxInt32:
+			size = 8
+		case (value < math.MinInt16 || value > math.MaxInt16) && size < 4:
+			size = 4
+		}
+	}
+	buf := binary.LittleEndian.AppendUint32(nil, uint32(size))
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(values)))
+	for _, value := range values {
+		switch size {
+		case 2:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(value))
+		case 4:
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(value))
+		case 8:
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(value))
+		}
+	}
+	return buf
+}
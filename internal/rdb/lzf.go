@@ -0,0 +1,51 @@
+package rdb
+
+import (
+	"fmt"
+)
+
+// decompressLZF decompresses the LZF data Redis writes for long strings. The data is a
+// sequence of literal runs, copied as they are, and back references, which copy bytes
+// already decompressed.
+func decompressLZF(in []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 1<<5 {
+			// A literal run of ctrl+1 bytes
+			run := ctrl + 1
+			if i+run > len(in) || len(out)+run > size {
+				return nil, fmt.Errorf("%w: invalid LZF data", ErrFormat)
+			}
+			out = append(out, in[i:i+run]...)
+			i += run
+			continue
+		}
+		length := ctrl >> 5
+		if length == 7 {
+			if i >= len(in) {
+				return nil, fmt.Errorf("%w: invalid LZF data", ErrFormat)
+			}
+			length += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, fmt.Errorf("%w: invalid LZF data", ErrFormat)
+		}
+		ref := len(out) - (ctrl&0x1f)<<8 - int(in[i]) - 1
+		i++
+		length += 2
+		if ref < 0 || len(out)+length > size {
+			return nil, fmt.Errorf("%w: invalid LZF data", ErrFormat)
+		}
+		// The reference can overlap the bytes being written, so they're copied one by one
+		for j := 0; j < length; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != size {
+		return nil, fmt.Errorf("%w: invalid LZF data", ErrFormat)
+	}
+	return out, nil
+}
@@ -0,0 +1,79 @@
+// Package rdb implements the building blocks of the RDB format, the binary format Redis
+// uses for its snapshots, so the files can be read by Redis and by the tools written
+// for it. It covers the encoding of lengths, strings, numbers and times, the listpacks
+// some types are stored in, the LZF compression of strings and the CRC64 checksum. How
+// each type is laid out using these is up to the datastore.
+package rdb
+
+import (
+	"errors"
+)
+
+// Version is the version of the RDB format written, the one of Redis 7.4, which is the
+// first one with the expiration of hash fields.
+const Version = 12
+
+// The types of the values, written before each key.
+const (
+	TypeString           byte = 0
+	TypeList             byte = 1
+	TypeSet              byte = 2
+	TypeZSet             byte = 3
+	TypeHash             byte = 4
+	TypeZSet2            byte = 5
+	TypeSetIntset        byte = 11
+	TypeListQuicklist    byte = 14
+	TypeStreamListpacks  byte = 15
+	TypeHashListpack     byte = 16
+	TypeZSetListpack     byte = 17
+	TypeListQuicklist2   byte = 18
+	TypeStreamListpacks2 byte = 19
+	TypeSetListpack      byte = 20
+	TypeStreamListpacks3 byte = 21
+	TypeHashMetadata     byte = 24
+	TypeHashListpackEx   byte = 25
+)
+
+// The opcodes, which share the byte of the types. Every entry of the file starts with
+// either an opcode or the type of a key.
+const (
+	OpcodeSlotInfo     byte = 244
+	OpcodeFunction     byte = 245
+	OpcodeModuleAux    byte = 247
+	OpcodeIdle         byte = 248
+	OpcodeFreq         byte = 249
+	OpcodeAux          byte = 250
+	OpcodeResizeDB     byte = 251
+	OpcodeExpireTimeMs byte = 252
+	OpcodeExpireTime   byte = 253
+	OpcodeSelectDB     byte = 254
+	OpcodeEOF          byte = 255
+)
+
+// The containers of the nodes of a TypeListQuicklist2 list.
+const (
+	QuicklistPlain  = 1
+	QuicklistPacked = 2
+)
+
+// The first two bits of a length tell how it's encoded. A length with the special
+// encoding is actually the encoding of a string, as an integer or compressed.
+const (
+	len6Bit    = 0
+	len14Bit   = 1
+	len32Bit   = 0x80
+	len64Bit   = 0x81
+	lenSpecial = 3
+
+	encodingInt8  = 0
+	encodingInt16 = 1
+	encodingInt32 = 2
+	encodingLZF   = 3
+)
+
+var (
+	// ErrFormat is returned when the data isn't valid RDB.
+	ErrFormat = errors.New("bad RDB format")
+	// ErrChecksum is returned when the checksum at the end of the data doesn't match.
+	ErrChecksum = errors.New("wrong RDB checksum")
+)
@@ -0,0 +1,150 @@
+package rdb
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	// The test vector of the CRC64 of Redis
+	if crc := Checksum(0, []byte("123456789")); crc != 0xe9c6d914c4b8d9ca {
+		t.Fatalf("unexpected checksum %x", crc)
+	}
+	if crc := Checksum(Checksum(0, []byte("1234")), []byte("56789")); crc != 0xe9c6d914c4b8d9ca {
+		t.Fatalf("unexpected checksum of the data in two parts %x", crc)
+	}
+}
+
+func TestEncoderAndDecoder(t *testing.T) {
+	lengths := []uint64{0, 63, 64, 16383, 16384, math.MaxUint32, math.MaxUint32 + 1}
+	strs := []string{"", "value", "0", "-128", "32767", "-2147483648", "2147483648", "007", strings.Repeat("x", 300)}
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteHeader()
+	for _, n := range lengths {
+		e.WriteLength(n)
+	}
+	for _, s := range strs {
+		e.WriteString([]byte(s))
+	}
+	e.WriteMillis(1700000000123)
+	e.WriteDouble(-1.5)
+	e.WriteFooter()
+	if e.Err() != nil {
+		t.Fatalf("unexpected error writing: %v", e.Err())
+	}
+
+	d := NewDecoder(bytes.NewReader(buf.Bytes()))
+	if version, err := d.ReadHeader(); err != nil || version != Version {
+		t.Fatalf("unexpected header. Version: %d, error: %v", version, err)
+	}
+	for _, expected := range lengths {
+		if n, err := d.ReadLength(); err != nil || n != expected {
+			t.Fatalf("unexpected length. Expected: %d, Actual: %d, error: %v", expected, n, err)
+		}
+	}
+	for _, expected := range strs {
+		if s, err := d.ReadString(); err != nil || string(s) != expected {
+			t.Fatalf("unexpected string. Expected: %q, Actual: %q, error: %v", expected, s, err)
+		}
+	}
+	if ms, err := d.ReadMillis(); err != nil || ms != 1700000000123 {
+		t.Fatalf("unexpected time %d, error: %v", ms, err)
+	}
+	if f, err := d.ReadDouble(); err != nil || f != -1.5 {
+		t.Fatalf("unexpected double %v, error: %v", f, err)
+	}
+	if opcode, err := d.ReadOpcode(); err != nil || opcode != OpcodeEOF {
+		t.Fatalf("unexpected opcode %d, error: %v", opcode, err)
+	}
+	if err := d.ReadFooter(); err != nil {
+		t.Fatalf("unexpected error reading the footer: %v", err)
+	}
+}
+
+func TestSmallIntegersAreEncoded(t *testing.T) {
+	var buf bytes.Buffer
+	NewEncoder(&buf).WriteString([]byte("-1"))
+	if !bytes.Equal(buf.Bytes(), []byte{0xc0, 0xff}) {
+		t.Fatalf("unexpected encoding %x", buf.Bytes())
+	}
+}
+
+func TestCorruptedData(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.WriteHeader()
+	e.WriteString([]byte("value"))
+	e.WriteFooter()
+	data := buf.Bytes()
+	data[len(data)-10] = 'V'
+	d := NewDecoder(bytes.NewReader(data))
+	d.ReadHeader()
+	d.ReadString()
+	d.ReadOpcode()
+	if err := d.ReadFooter(); err != ErrChecksum {
+		t.Fatalf("expected a checksum error, got %v", err)
+	}
+
+	d = NewDecoder(bytes.NewReader([]byte("REDIS0099")))
+	if _, err := d.ReadHeader(); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected a format error for an unknown version, got %v", err)
+	}
+	d = NewDecoder(bytes.NewReader([]byte{0x85, 'a'}))
+	if _, err := d.ReadString(); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected a format error for truncated data, got %v", err)
+	}
+}
+
+func TestDecompressLZF(t *testing.T) {
+	// A literal "a" followed by a reference copying it 9 times
+	compressed := []byte{0xc3, 0x05, 0x0a, 0x00, 'a', 0xe0, 0x00, 0x00}
+	d := NewDecoder(bytes.NewReader(compressed))
+	s, err := d.ReadString()
+	if err != nil || string(s) != "aaaaaaaaaa" {
+		t.Fatalf("unexpected decompressed string %q, error: %v", s, err)
+	}
+	if _, err := decompressLZF([]byte{0x00, 'a', 0xe0, 0x00, 0x05}, 10); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected an error for a reference out of the data, got %v", err)
+	}
+}
+
+func TestListpack(t *testing.T) {
+	entries := []string{"", "a", "0", "127", "128", "-4096", "4095", "-32768", "32767", "-8388608", "8388607",
+		"-2147483648", "2147483647", "-9223372036854775808", "9223372036854775807", "01", strings.Repeat("x", 100), strings.Repeat("y", 5000)}
+	var lp Listpack
+	for _, entry := range entries {
+		lp.AppendString([]byte(entry))
+	}
+	if lp.Len() != len(entries) {
+		t.Fatalf("unexpected length %d", lp.Len())
+	}
+	decoded, err := ListpackEntries(lp.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	actual := make([]string, len(decoded))
+	for i, entry := range decoded {
+		actual[i] = string(entry)
+	}
+	if !reflect.DeepEqual(actual, entries) {
+		t.Fatalf("unexpected entries. Expected: %q, Actual: %q", entries, actual)
+	}
+	data := lp.Bytes()
+	if _, err := ListpackEntries(data[:len(data)-1]); !errors.Is(err, ErrFormat) {
+		t.Fatalf("expected an error for a truncated listpack, got %v", err)
+	}
+}
+
+func TestIntset(t *testing.T) {
+	for _, values := range [][]int64{{}, {-3, 1, 2}, {1, 70000}, {-1, math.MaxInt64}} {
+		decoded, err := IntsetEntries(Intset(values))
+		if err != nil || !reflect.DeepEqual(decoded, values) {
+			t.Fatalf("unexpected intset. Expected: %v, Actual: %v, error: %v", values, decoded, err)
+		}
+	}
+}
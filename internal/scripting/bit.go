@@ -0,0 +1,98 @@
+package scripting
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// openBit adds the bit library of LuaBitOp, whose functions work on the 32 bits
+// integers numbers are converted to.
+func openBit(L *lua.LState) {
+	library := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"tobit": func(L *lua.LState) int {
+			return pushBits(L, checkBits(L, 1))
+		},
+		"tohex": bitToHex,
+		"bnot": func(L *lua.LState) int {
+			return pushBits(L, ^checkBits(L, 1))
+		},
+		"band": bitOperator(func(a, b uint32) uint32 { return a & b }),
+		"bor":  bitOperator(func(a, b uint32) uint32 { return a | b }),
+		"bxor": bitOperator(func(a, b uint32) uint32 { return a ^ b }),
+		"lshift": bitShift(func(n uint32, shift int) uint32 {
+			return n << shift
+		}),
+		"rshift": bitShift(func(n uint32, shift int) uint32 {
+			return n >> shift
+		}),
+		"arshift": bitShift(func(n uint32, shift int) uint32 {
+			return uint32(int32(n) >> shift)
+		}),
+		"rol": bitShift(func(n uint32, shift int) uint32 {
+			return bits.RotateLeft32(n, shift)
+		}),
+		"ror": bitShift(func(n uint32, shift int) uint32 {
+			return bits.RotateLeft32(n, -shift)
+		}),
+		"bswap": func(L *lua.LState) int {
+			return pushBits(L, bits.ReverseBytes32(checkBits(L, 1)))
+		},
+	})
+	L.SetGlobal("bit", library)
+}
+
+// checkBits converts the argument to 32 bits, rounding it to an integer and keeping the
+// lowest 32 bits, like LuaBitOp does.
+func checkBits(L *lua.LState, n int) uint32 {
+	f := math.RoundToEven(float64(L.CheckNumber(n)))
+	return uint32(int64(math.Mod(f, 1<<32)))
+}
+
+// pushBits pushes the result of an operation, which is a signed 32 bits integer.
+func pushBits(L *lua.LState, n uint32) int {
+	L.Push(lua.LNumber(int32(n)))
+	return 1
+}
+
+// bitOperator creates a function that combines all of its arguments with the operator.
+func bitOperator(operator func(a, b uint32) uint32) lua.LGFunction {
+	return func(L *lua.LState) int {
+		result := checkBits(L, 1)
+		for i := 2; i <= L.GetTop(); i++ {
+			result = operator(result, checkBits(L, i))
+		}
+		return pushBits(L, result)
+	}
+}
+
+// bitShift creates a function that shifts its first argument by the number of bits of
+// the second one. Only the lowest 5 bits of the shift count.
+func bitShift(shift func(n uint32, shift int) uint32) lua.LGFunction {
+	return func(L *lua.LState) int {
+		return pushBits(L, shift(checkBits(L, 1), int(checkBits(L, 2)&31)))
+	}
+}
+
+// bitToHex converts the first argument to a hex string with the number of digits of
+// the second argument, 8 by default. Negative numbers of digits use upper case letters.
+func bitToHex(L *lua.LState) int {
+	n := checkBits(L, 1)
+	digits := int32(8)
+	if L.GetTop() >= 2 {
+		digits = int32(checkBits(L, 2))
+	}
+	format := "%0*x"
+	if digits < 0 {
+		format = "%0*X"
+		digits = -digits
+	}
+	if digits > 8 {
+		digits = 8
+	}
+	hex := fmt.Sprintf(format, digits, n)
+	L.Push(lua.LString(hex[len(hex)-int(digits):]))
+	return 1
+}
@@ -0,0 +1,397 @@
+package scripting
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// jsonMaxDepth is the deepest nesting of tables and JSON arrays or objects cjson
+// encodes and decodes.
+const jsonMaxDepth = 1000
+
+// openCJSON adds the cjson library, which encodes values to JSON and decodes them back.
+// JSON nulls are decoded to cjson.null, since nil can't be stored in tables.
+func openCJSON(L *lua.LState) {
+	null := L.NewUserData()
+	library := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"encode": func(L *lua.LState) int {
+			e := &jsonEncoder{null: null}
+			if err := e.encode(L.CheckAny(1), 0); err != nil {
+				raiseError(L, "%s", err)
+			}
+			L.Push(lua.LString(e.buffer.String()))
+			return 1
+		},
+		"decode": func(L *lua.LState) int {
+			d := &jsonDecoder{L: L, null: null, text: L.CheckString(1)}
+			value, err := d.decode()
+			if err != nil {
+				raiseError(L, "%s", err)
+			}
+			L.Push(value)
+			return 1
+		},
+	})
+	library.RawSetString("null", null)
+	L.SetGlobal("cjson", library)
+}
+
+type jsonEncoder struct {
+	null   *lua.LUserData
+	buffer strings.Builder
+}
+
+func (e *jsonEncoder) encode(value lua.LValue, depth int) error {
+	switch value := value.(type) {
+	case *lua.LNilType:
+		e.buffer.WriteString("null")
+	case lua.LBool:
+		e.buffer.WriteString(strconv.FormatBool(bool(value)))
+	case lua.LNumber:
+		f := float64(value)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("Cannot serialise number: must not be NaN or Inf")
+		}
+		e.buffer.WriteString(formatNumber(f))
+	case lua.LString:
+		e.encodeString(string(value))
+	case *lua.LTable:
+		if depth >= jsonMaxDepth {
+			return fmt.Errorf("Cannot serialise, excessive nesting (%d)", depth+1)
+		}
+		return e.encodeTable(value, depth+1)
+	case *lua.LUserData:
+		if value != e.null {
+			return fmt.Errorf("Cannot serialise userdata: type not supported")
+		}
+		e.buffer.WriteString("null")
+	default:
+		return fmt.Errorf("Cannot serialise %s: type not supported", value.Type())
+	}
+	return nil
+}
+
+// encodeTable encodes tables whose keys are all positive integers as arrays, and the
+// others as objects, whose keys must be strings or numbers. Arrays with a lot more
+// missing values than values are rejected.
+func (e *jsonEncoder) encodeTable(t *lua.LTable, depth int) error {
+	size, err := arraySize(t)
+	if err != nil {
+		return err
+	}
+	if size > 0 {
+		e.buffer.WriteByte('[')
+		for i := 1; i <= size; i++ {
+			if i > 1 {
+				e.buffer.WriteByte(',')
+			}
+			if err := e.encode(t.RawGetInt(i), depth); err != nil {
+				return err
+			}
+		}
+		e.buffer.WriteByte(']')
+		return nil
+	}
+	e.buffer.WriteByte('{')
+	first := true
+	for key, value := t.Next(lua.LNil); key != lua.LNil; key, value = t.Next(key) {
+		if !first {
+			e.buffer.WriteByte(',')
+		}
+		first = false
+		switch key := key.(type) {
+		case lua.LString:
+			e.encodeString(string(key))
+		case lua.LNumber:
+			e.encodeString(formatNumber(float64(key)))
+		default:
+			return fmt.Errorf("Cannot serialise table: table key must be a number or string")
+		}
+		e.buffer.WriteByte(':')
+		if err := e.encode(value, depth); err != nil {
+			return err
+		}
+	}
+	e.buffer.WriteByte('}')
+	return nil
+}
+
+// arraySize returns the largest key of a table whose keys are all positive integers, or
+// 0 for other tables.
+func arraySize(t *lua.LTable) (int, error) {
+	size, count := 0, 0
+	for key, _ := t.Next(lua.LNil); key != lua.LNil; key, _ = t.Next(key) {
+		n, ok := key.(lua.LNumber)
+		if !ok || float64(n) < 1 || float64(n) != math.Floor(float64(n)) {
+			return 0, nil
+		}
+		size = max(size, int(n))
+		count++
+	}
+	if size > 10 && size > count*2 {
+		return 0, fmt.Errorf("Cannot serialise table: excessively sparse array")
+	}
+	return size, nil
+}
+
+func (e *jsonEncoder) encodeString(s string) {
+	e.buffer.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', '/':
+			e.buffer.WriteByte('\\')
+			e.buffer.WriteByte(c)
+		case '\b':
+			e.buffer.WriteString(`\b`)
+		case '\f':
+			e.buffer.WriteString(`\f`)
+		case '\n':
+			e.buffer.WriteString(`\n`)
+		case '\r':
+			e.buffer.WriteString(`\r`)
+		case '\t':
+			e.buffer.WriteString(`\t`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&e.buffer, `\u%04x`, c)
+			} else {
+				e.buffer.WriteByte(c)
+			}
+		}
+	}
+	e.buffer.WriteByte('"')
+}
+
+// formatNumber formats numbers like Lua does, with up to 14 significant digits.
+func formatNumber(f float64) string {
+	if f == math.Trunc(f) && math.Abs(f) < 1e15 {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return strconv.FormatFloat(f, 'g', 14, 64)
+}
+
+// jsonDecoder decodes JSON text. Strings are kept as they are, without checking that
+// they are valid UTF-8, since Lua strings are bytes.
+type jsonDecoder struct {
+	L     *lua.LState
+	null  *lua.LUserData
+	text  string
+	pos   int
+	depth int
+}
+
+func (d *jsonDecoder) decode() (lua.LValue, error) {
+	value, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	d.skipSpaces()
+	if d.pos < len(d.text) {
+		return nil, d.unexpected("the end")
+	}
+	return value, nil
+}
+
+func (d *jsonDecoder) skipSpaces() {
+	for d.pos < len(d.text) && strings.IndexByte(" \t\n\r", d.text[d.pos]) >= 0 {
+		d.pos++
+	}
+}
+
+// unexpected creates the error for an invalid token where the expected one should be.
+// Positions are counted from 1.
+func (d *jsonDecoder) unexpected(expected string) error {
+	return fmt.Errorf("Expected %s but found invalid token at character %d", expected, d.pos+1)
+}
+
+func (d *jsonDecoder) value() (lua.LValue, error) {
+	d.skipSpaces()
+	if d.pos >= len(d.text) {
+		return nil, fmt.Errorf("Expected value but found T_END at character %d", d.pos+1)
+	}
+	switch c := d.text[d.pos]; {
+	case c == '{' || c == '[':
+		if d.depth >= jsonMaxDepth {
+			return nil, fmt.Errorf("Found too many nested data structures (%d) at character %d", d.depth+1, d.pos+1)
+		}
+		d.depth++
+		defer func() { d.depth-- }()
+		if c == '{' {
+			return d.object()
+		}
+		return d.array()
+	case c == '"':
+		s, err := d.string()
+		return lua.LString(s), err
+	case c == '-' || (c >= '0' && c <= '9'):
+		return d.number()
+	}
+	switch rest := d.text[d.pos:]; {
+	case strings.HasPrefix(rest, "true"):
+		d.pos += 4
+		return lua.LTrue, nil
+	case strings.HasPrefix(rest, "false"):
+		d.pos += 5
+		return lua.LFalse, nil
+	case strings.HasPrefix(rest, "null"):
+		d.pos += 4
+		return d.null, nil
+	}
+	return nil, d.unexpected("value")
+}
+
+func (d *jsonDecoder) object() (lua.LValue, error) {
+	t := d.L.NewTable()
+	d.pos++
+	d.skipSpaces()
+	if d.pos < len(d.text) && d.text[d.pos] == '}' {
+		d.pos++
+		return t, nil
+	}
+	for {
+		d.skipSpaces()
+		if d.pos >= len(d.text) || d.text[d.pos] != '"' {
+			return nil, d.unexpected("object key string")
+		}
+		key, err := d.string()
+		if err != nil {
+			return nil, err
+		}
+		d.skipSpaces()
+		if d.pos >= len(d.text) || d.text[d.pos] != ':' {
+			return nil, d.unexpected("colon")
+		}
+		d.pos++
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		t.RawSetString(key, value)
+		d.skipSpaces()
+		if d.pos < len(d.text) && d.text[d.pos] == '}' {
+			d.pos++
+			return t, nil
+		}
+		if d.pos >= len(d.text) || d.text[d.pos] != ',' {
+			return nil, d.unexpected("comma or object end")
+		}
+		d.pos++
+	}
+}
+
+func (d *jsonDecoder) array() (lua.LValue, error) {
+	t := d.L.NewTable()
+	d.pos++
+	d.skipSpaces()
+	if d.pos < len(d.text) && d.text[d.pos] == ']' {
+		d.pos++
+		return t, nil
+	}
+	for i := 1; ; i++ {
+		value, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		t.RawSetInt(i, value)
+		d.skipSpaces()
+		if d.pos < len(d.text) && d.text[d.pos] == ']' {
+			d.pos++
+			return t, nil
+		}
+		if d.pos >= len(d.text) || d.text[d.pos] != ',' {
+			return nil, d.unexpected("comma or array end")
+		}
+		d.pos++
+	}
+}
+
+func (d *jsonDecoder) number() (lua.LValue, error) {
+	start := d.pos
+	for d.pos < len(d.text) && strings.IndexByte("+-0123456789.eE", d.text[d.pos]) >= 0 {
+		d.pos++
+	}
+	f, err := strconv.ParseFloat(d.text[start:d.pos], 64)
+	if err != nil {
+		d.pos = start
+		return nil, d.unexpected("value")
+	}
+	return lua.LNumber(f), nil
+}
+
+// string decodes the string that starts at the current position, which is a quote.
+func (d *jsonDecoder) string() (string, error) {
+	var s strings.Builder
+	d.pos++
+	for d.pos < len(d.text) {
+		c := d.text[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return s.String(), nil
+		case c == '\\':
+			if err := d.escape(&s); err != nil {
+				return "", err
+			}
+		case c < 0x20:
+			return "", d.unexpected("string end")
+		default:
+			s.WriteByte(c)
+			d.pos++
+		}
+	}
+	return "", d.unexpected("string end")
+}
+
+// escape decodes the escape sequence at the current position, a backslash.
+func (d *jsonDecoder) escape(s *strings.Builder) error {
+	start := d.pos
+	if d.pos+1 >= len(d.text) {
+		return d.unexpected("string end")
+	}
+	c := d.text[d.pos+1]
+	d.pos += 2
+	if i := strings.IndexByte(`"\/bfnrt`, c); i >= 0 {
+		s.WriteByte("\"\\/\b\f\n\r\t"[i])
+		return nil
+	}
+	if c != 'u' {
+		d.pos = start
+		return d.unexpected("valid escape")
+	}
+	r, ok := d.hex()
+	if ok && utf16.IsSurrogate(r) {
+		// Characters outside the basic plane are encoded as a pair of surrogates
+		if !strings.HasPrefix(d.text[d.pos:], `\u`) {
+			ok = false
+		} else {
+			d.pos += 2
+			low, lowOk := d.hex()
+			r, ok = utf16.DecodeRune(r, low), lowOk
+			ok = ok && r != utf8.RuneError
+		}
+	}
+	if !ok {
+		d.pos = start
+		return d.unexpected("valid unicode escape")
+	}
+	s.WriteRune(r)
+	return nil
+}
+
+func (d *jsonDecoder) hex() (rune, bool) {
+	if d.pos+4 > len(d.text) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(d.text[d.pos:d.pos+4], 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	d.pos += 4
+	return rune(n), true
+}
@@ -0,0 +1,296 @@
+package scripting
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// msgpackMaxNesting is the deepest nesting of tables cmsgpack encodes, deeper tables
+// are encoded as nil.
+const msgpackMaxNesting = 16
+
+var (
+	errMsgpackMissingBytes = errors.New("Missing bytes in input.")
+	errMsgpackBadFormat    = errors.New("Bad data format in input.")
+)
+
+// openCMsgpack adds the cmsgpack library, which encodes values to MessagePack and
+// decodes them back.
+func openCMsgpack(L *lua.LState) {
+	library := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"pack": func(L *lua.LState) int {
+			if L.GetTop() == 0 {
+				raiseError(L, "MessagePack pack needs input.")
+			}
+			var buffer []byte
+			for i := 1; i <= L.GetTop(); i++ {
+				buffer = msgpackEncode(buffer, L.Get(i), 0)
+			}
+			L.Push(lua.LString(buffer))
+			return 1
+		},
+		"unpack": func(L *lua.LState) int {
+			d := &msgpackDecoder{L: L, data: L.CheckString(1)}
+			var values []lua.LValue
+			for d.pos < len(d.data) {
+				value, err := d.decode()
+				if err != nil {
+					raiseError(L, "%s", err)
+				}
+				values = append(values, value)
+			}
+			for _, value := range values {
+				L.Push(value)
+			}
+			return len(values)
+		},
+	})
+	L.SetGlobal("cmsgpack", library)
+}
+
+// msgpackEncode appends the encoding of the value to the buffer. Numbers are encoded as
+// integers when they have no fractional part, and as floats otherwise, using 32 bits
+// if that doesn't lose precision. Values of other types are encoded as nil.
+func msgpackEncode(buffer []byte, value lua.LValue, depth int) []byte {
+	switch value := value.(type) {
+	case lua.LBool:
+		if value {
+			return append(buffer, 0xc3)
+		}
+		return append(buffer, 0xc2)
+	case lua.LNumber:
+		f := float64(value)
+		if f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64 {
+			return msgpackEncodeInt(buffer, int64(f))
+		}
+		if float64(float32(f)) == f || math.IsNaN(f) {
+			return binary.BigEndian.AppendUint32(append(buffer, 0xca), math.Float32bits(float32(f)))
+		}
+		return binary.BigEndian.AppendUint64(append(buffer, 0xcb), math.Float64bits(f))
+	case lua.LString:
+		n := len(value)
+		switch {
+		case n < 32:
+			buffer = append(buffer, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			buffer = append(buffer, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			buffer = binary.BigEndian.AppendUint16(append(buffer, 0xda), uint16(n))
+		default:
+			buffer = binary.BigEndian.AppendUint32(append(buffer, 0xdb), uint32(n))
+		}
+		return append(buffer, value...)
+	case *lua.LTable:
+		if depth < msgpackMaxNesting {
+			return msgpackEncodeTable(buffer, value, depth+1)
+		}
+	}
+	return append(buffer, 0xc0)
+}
+
+func msgpackEncodeInt(buffer []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= 0x7f:
+		return append(buffer, byte(n))
+	case n >= 0 && n <= math.MaxUint8:
+		return append(buffer, 0xcc, byte(n))
+	case n >= 0 && n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buffer, 0xcd), uint16(n))
+	case n >= 0 && n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buffer, 0xce), uint32(n))
+	case n >= 0:
+		return binary.BigEndian.AppendUint64(append(buffer, 0xcf), uint64(n))
+	case n >= -32:
+		return append(buffer, byte(n))
+	case n >= math.MinInt8:
+		return append(buffer, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buffer, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buffer, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buffer, 0xd3), uint64(n))
+}
+
+// msgpackEncodeTable encodes tables whose keys are the integers from 1 to their length
+// as arrays, and the others as maps.
+func msgpackEncodeTable(buffer []byte, t *lua.LTable, depth int) []byte {
+	count, isArray := 0, true
+	for key, _ := t.Next(lua.LNil); key != lua.LNil; key, _ = t.Next(key) {
+		count++
+		if n, ok := key.(lua.LNumber); !ok || float64(n) < 1 || float64(n) != math.Trunc(float64(n)) {
+			isArray = false
+		}
+	}
+	if isArray {
+		for i := 1; i <= count; i++ {
+			if t.RawGetInt(i) == lua.LNil {
+				isArray = false
+				break
+			}
+		}
+	}
+	header := [3]byte{0x80, 0xde, 0xdf}
+	if isArray {
+		header = [3]byte{0x90, 0xdc, 0xdd}
+	}
+	switch {
+	case count < 16:
+		buffer = append(buffer, header[0]|byte(count))
+	case count <= math.MaxUint16:
+		buffer = binary.BigEndian.AppendUint16(append(buffer, header[1]), uint16(count))
+	default:
+		buffer = binary.BigEndian.AppendUint32(append(buffer, header[2]), uint32(count))
+	}
+	if isArray {
+		for i := 1; i <= count; i++ {
+			buffer = msgpackEncode(buffer, t.RawGetInt(i), depth)
+		}
+		return buffer
+	}
+	for key, value := t.Next(lua.LNil); key != lua.LNil; key, value = t.Next(key) {
+		buffer = msgpackEncode(buffer, key, depth)
+		buffer = msgpackEncode(buffer, value, depth)
+	}
+	return buffer
+}
+
+type msgpackDecoder struct {
+	L    *lua.LState
+	data string
+	pos  int
+}
+
+// next returns the following n bytes.
+func (d *msgpackDecoder) next(n int) (string, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return "", errMsgpackMissingBytes
+	}
+	d.pos += n
+	return d.data[d.pos-n : d.pos], nil
+}
+
+// length reads a big endian unsigned integer of n bytes.
+func (d *msgpackDecoder) length(n int) (int, error) {
+	b, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	length := 0
+	for i := 0; i < n; i++ {
+		length = length<<8 | int(b[i])
+	}
+	return length, nil
+}
+
+func (d *msgpackDecoder) decode() (lua.LValue, error) {
+	b, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return lua.LNumber(c), nil
+	case c >= 0xe0:
+		return lua.LNumber(int8(c)), nil
+	case c >= 0xa0 && c <= 0xbf:
+		return d.string(int(c & 0x1f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.array(int(c & 0x0f))
+	case c >= 0x80 && c <= 0x8f:
+		return d.table(int(c & 0x0f))
+	}
+	switch c {
+	case 0xc0:
+		return lua.LNil, nil
+	case 0xc2:
+		return lua.LFalse, nil
+	case 0xc3:
+		return lua.LTrue, nil
+	case 0xca, 0xcb:
+		size := 4
+		if c == 0xcb {
+			size = 8
+		}
+		n, err := d.length(size)
+		if err != nil {
+			return nil, err
+		}
+		if size == 4 {
+			return lua.LNumber(math.Float32frombits(uint32(n))), nil
+		}
+		return lua.LNumber(math.Float64frombits(uint64(n))), nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := d.length(1 << (c - 0xcc))
+		return lua.LNumber(uint64(n)), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := d.length(size)
+		// Sign extend from the size of the integer
+		shift := 64 - 8*size
+		return lua.LNumber(int64(n) << shift >> shift), err
+	case 0xc4, 0xc5, 0xc6, 0xd9, 0xda, 0xdb:
+		size := 1 << ((c - 0xc4) % 3)
+		if c >= 0xd9 {
+			size = 1 << (c - 0xd9)
+		}
+		n, err := d.length(size)
+		if err != nil {
+			return nil, err
+		}
+		return d.string(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.table(n)
+	}
+	return nil, errMsgpackBadFormat
+}
+
+func (d *msgpackDecoder) string(n int) (lua.LValue, error) {
+	s, err := d.next(n)
+	return lua.LString(s), err
+}
+
+func (d *msgpackDecoder) array(n int) (lua.LValue, error) {
+	t := d.L.NewTable()
+	for i := 1; i <= n; i++ {
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		t.RawSetInt(i, value)
+	}
+	return t, nil
+}
+
+func (d *msgpackDecoder) table(n int) (lua.LValue, error) {
+	t := d.L.NewTable()
+	for i := 0; i < n; i++ {
+		key, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decode()
+		if err != nil {
+			return nil, err
+		}
+		if key == lua.LNil {
+			return nil, errMsgpackBadFormat
+		}
+		t.RawSet(key, value)
+	}
+	return t, nil
+}
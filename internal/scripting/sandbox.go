@@ -0,0 +1,147 @@
+// Package scripting creates the Lua 5.1 states Redis scripts run in. The language and
+// its standard library come from gopher-lua, and the package adds what Redis gives
+// scripts on top of it: the cjson, cmsgpack, bit and struct libraries, globals that
+// can't be created by scripts, and a way to interrupt scripts that run for too long.
+package scripting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// interruptInterval is the number of instructions between two calls to the interrupt
+// function of a state.
+const interruptInterval = 1000
+
+// unsafeFunctions are the base functions removed from the sandbox, since they access
+// files, write to the standard output of the server or load modules.
+var unsafeFunctions = []string{"dofile", "loadfile", "print", "module", "require", "_printregs", "newproxy"}
+
+// NewState creates a state with the standard libraries that are safe to use in a
+// sandbox, base, table, string and math, and the libraries Redis adds.
+func NewState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, open := range []lua.LGFunction{lua.OpenBase, lua.OpenTable, lua.OpenString, lua.OpenMath} {
+		L.Push(L.NewFunction(open))
+		L.Call(0, 0)
+	}
+	for _, name := range unsafeFunctions {
+		L.SetGlobal(name, lua.LNil)
+	}
+	openCJSON(L)
+	openCMsgpack(L)
+	openBit(L)
+	openStruct(L)
+	return L
+}
+
+// ProtectGlobals makes reading a global that doesn't exist, or creating one, an error,
+// so scripts can't keep state in globals. Globals set before are still writable.
+func ProtectGlobals(L *lua.LState) {
+	metatable := L.NewTable()
+	metatable.RawSetString("__index", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("Script attempted to access nonexistent global variable '%s'", L.ToString(2))
+		return 0
+	}))
+	metatable.RawSetString("__newindex", L.NewFunction(func(L *lua.LState) int {
+		L.RaiseError("Script attempted to create global variable '%s'", L.ToString(2))
+		return 0
+	}))
+	L.SetMetatable(L.G.Global, metatable)
+}
+
+// Compile compiles the source of a script. The name is used in the error messages to
+// tell where an error happened, like name:1.
+func Compile(source, name string) (*lua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), name)
+	if err != nil {
+		if parseErr, ok := err.(*parse.Error); ok {
+			// The position isn't known for errors at the end of the source
+			line, token := parseErr.Pos.Line, fmt.Sprintf("'%s'", parseErr.Token)
+			if line < 0 {
+				line, token = strings.Count(source, "\n")+1, "<eof>"
+			}
+			return nil, fmt.Errorf("%s:%d: %s near %s", name, line, parseErr.Message, token)
+		}
+		return nil, err
+	}
+	return lua.Compile(chunk, name)
+}
+
+// Call calls the function with the arguments, returning the values it returns. Errors
+// raised by the function are returned as a *lua.ApiError, whose Object is the value
+// given to error or the message of a runtime error. If the state was interrupted, the
+// error of the interrupt function is returned instead.
+func Call(L *lua.LState, function lua.LValue, arguments ...lua.LValue) ([]lua.LValue, error) {
+	top := L.GetTop()
+	L.Push(function)
+	for _, argument := range arguments {
+		L.Push(argument)
+	}
+	err := L.PCall(len(arguments), lua.MultRet, nil)
+	if interrupt, ok := L.Context().(*interruptContext); ok && interrupt.err != nil {
+		L.SetTop(top)
+		return nil, interrupt.err
+	}
+	if err != nil {
+		L.SetTop(top)
+		return nil, err
+	}
+	values := make([]lua.LValue, L.GetTop()-top)
+	for i := range values {
+		values[i] = L.Get(top + i + 1)
+	}
+	L.SetTop(top)
+	return values, nil
+}
+
+// SetInterrupt makes the state call interrupt periodically while it runs. Once it
+// returns an error, the state stops with that error. The error can't be caught by
+// pcall, since the instructions that follow keep raising it.
+func SetInterrupt(L *lua.LState, interrupt func() error) {
+	L.SetContext(&interruptContext{Context: context.Background(), interrupt: interrupt})
+}
+
+// closedChannel is returned by interruptContext once the state is interrupted.
+var closedChannel = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// interruptContext is the context of interrupted states. gopher-lua checks if the
+// context is done before running each instruction, which is when the interrupt
+// function is called.
+type interruptContext struct {
+	context.Context
+	interrupt func() error
+	steps     int
+	err       error
+}
+
+func (c *interruptContext) Done() <-chan struct{} {
+	if c.err == nil {
+		c.steps++
+		if c.steps%interruptInterval != 0 {
+			return nil
+		}
+		if c.err = c.interrupt(); c.err == nil {
+			return nil
+		}
+	}
+	return closedChannel
+}
+
+func (c *interruptContext) Err() error {
+	return c.err
+}
+
+// raiseError raises an error whose message doesn't start with the position in the
+// script, like the errors of the libraries Redis adds, which are written in C.
+func raiseError(L *lua.LState, format string, args ...any) {
+	L.Error(lua.LString(fmt.Sprintf(format, args...)), 0)
+}
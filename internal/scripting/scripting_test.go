@@ -0,0 +1,160 @@
+package scripting
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+type scriptingTestCase struct {
+	name     string
+	source   string
+	expected []lua.LValue
+}
+
+// run runs the source in a new state, with protected globals.
+func run(t *testing.T, source string) ([]lua.LValue, error) {
+	t.Helper()
+	proto, err := Compile(source, "test")
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	L := NewState()
+	defer L.Close()
+	ProtectGlobals(L)
+	return Call(L, L.NewFunctionFromProto(proto))
+}
+
+func TestLibraries(t *testing.T) {
+	stcs := []scriptingTestCase{
+		{
+			name:     "metatables",
+			source:   "local t = setmetatable({}, {__index = function(t, k) return k .. '!' end}) return t.x, getmetatable(t) ~= nil",
+			expected: []lua.LValue{lua.LString("x!"), lua.LTrue},
+		},
+		{
+			name:     "xpcall",
+			source:   "return xpcall(function() error('failed', 0) end, function(err) return 'handled ' .. err end)",
+			expected: []lua.LValue{lua.LFalse, lua.LString("handled failed")},
+		},
+		{
+			name:     "math.random",
+			source:   "local n = math.random(10) return n >= 1 and n <= 10 and n == math.floor(n)",
+			expected: []lua.LValue{lua.LTrue},
+		},
+		{
+			name:     "Unsafe functions are removed",
+			source:   "return rawget(_G, 'loadfile'), rawget(_G, 'dofile'), rawget(_G, 'print'), rawget(_G, 'require')",
+			expected: []lua.LValue{lua.LNil, lua.LNil, lua.LNil, lua.LNil},
+		},
+		{
+			name:     "cjson.encode",
+			source:   `return cjson.encode({1, 'a/b', {x = true}, cjson.null}), cjson.encode({}), cjson.encode(0.5), cjson.encode('"\n')`,
+			expected: []lua.LValue{lua.LString(`[1,"a\/b",{"x":true},null]`), lua.LString("{}"), lua.LString("0.5"), lua.LString(`"\"\n"`)},
+		},
+		{
+			name:     "cjson.decode",
+			source:   `local v = cjson.decode('{"a": [1, 2.5, null], "b": "\\u00e9\\ud83d\\ude00", "c": false}') return v.a[2], #v.a, v.a[3] == cjson.null, v.b, v.c`,
+			expected: []lua.LValue{lua.LNumber(2.5), lua.LNumber(3), lua.LTrue, lua.LString("é😀"), lua.LFalse},
+		},
+		{
+			name:     "cjson errors",
+			source:   "local ok1, err1 = pcall(cjson.decode, '[1,') local ok2, err2 = pcall(cjson.encode, {[1] = 1, [100] = 2}) return ok1, ok2, err2",
+			expected: []lua.LValue{lua.LFalse, lua.LFalse, lua.LString("Cannot serialise table: excessively sparse array")},
+		},
+		{
+			name:     "cmsgpack",
+			source:   "local packed = cmsgpack.pack({1, 2, 3}, 'abc', -1, 300, 1.5, {a = true}) local t, s, neg, n, f, m = cmsgpack.unpack(packed) return #packed, t[3], s, neg, n, f, m.a",
+			expected: []lua.LValue{lua.LNumber(21), lua.LNumber(3), lua.LString("abc"), lua.LNumber(-1), lua.LNumber(300), lua.LNumber(1.5), lua.LTrue},
+		},
+		{
+			name:     "cmsgpack encoding",
+			source:   "return cmsgpack.pack({1, 2}), cmsgpack.pack(-33), cmsgpack.pack(nil, false)",
+			expected: []lua.LValue{lua.LString("\x92\x01\x02"), lua.LString("\xd0\xdf"), lua.LString("\xc0\xc2")},
+		},
+		{
+			name:     "bit",
+			source:   "return bit.band(0xff, 0x0f, 0x3), bit.bor(1, 2, 4), bit.bxor(3, 1), bit.bnot(0), bit.lshift(1, 33), bit.rshift(-1, 28), bit.arshift(-16, 2), bit.tobit(2^32 + 5), bit.tohex(255), bit.tohex(-1, -4)",
+			expected: []lua.LValue{lua.LNumber(3), lua.LNumber(7), lua.LNumber(2), lua.LNumber(-1), lua.LNumber(2), lua.LNumber(15), lua.LNumber(-4), lua.LNumber(5), lua.LString("000000ff"), lua.LString("FFFF")},
+		},
+		{
+			name:     "struct",
+			source:   "local packed = struct.pack('>Hi2sc3', 258, -2, 'ab', 'xyz') local a, b, c, d, pos = struct.unpack('>Hi2sc3', packed) return packed, a, b, c, d, pos, struct.size('<!4bi')",
+			expected: []lua.LValue{lua.LString("\x01\x02\xff\xfeab\x00xyz"), lua.LNumber(258), lua.LNumber(-2), lua.LString("ab"), lua.LString("xyz"), lua.LNumber(11), lua.LNumber(8)},
+		},
+		{
+			name:     "struct with a previous size",
+			source:   "return struct.unpack('bc0', struct.pack('bc0', 3, 'abc'))",
+			expected: []lua.LValue{lua.LString("abc"), lua.LNumber(5)},
+		},
+	}
+	for _, tc := range stcs {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := run(t, tc.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("unexpected return value. Expected: %v, Actual: %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tcs := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{"reading a missing global", "return x", "test:1: Script attempted to access nonexistent global variable 'x'"},
+		{"creating a global", "\nx = 1", "test:2: Script attempted to create global variable 'x'"},
+		{"error", "error('failed')", "test:1: failed"},
+		{"struct errors", "struct.pack('q', 1)", "invalid format option 'q'"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := run(t, tc.source)
+			var luaErr *lua.ApiError
+			if !errors.As(err, &luaErr) {
+				t.Fatalf("expected a Lua error, got %v", err)
+			}
+			if luaErr.Object.String() != tc.expected {
+				t.Fatalf("unexpected error. Expected: %s, Actual: %s", tc.expected, luaErr.Object)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	if _, err := Compile("return +", "test"); err == nil || err.Error() != "test:1: syntax error near '+'" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Compile("local x = 1\nreturn 'a", "test"); err == nil || err.Error() != "test:2: unterminated string near <eof>" {
+		t.Fatalf("unexpected error at the end of the source: %v", err)
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	proto, err := Compile("while true do pcall(function() while true do end end) end", "test")
+	if err != nil {
+		t.Fatalf("unexpected compile error: %v", err)
+	}
+	L := NewState()
+	defer L.Close()
+	stop := errors.New("stopped")
+	calls := 0
+	SetInterrupt(L, func() error {
+		calls++
+		if calls == 10 {
+			return stop
+		}
+		return nil
+	})
+	// pcall can't catch the interrupt
+	if _, err := Call(L, L.NewFunctionFromProto(proto)); err != stop {
+		t.Fatalf("the script should have been interrupted, got %v", err)
+	}
+}
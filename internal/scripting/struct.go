@@ -0,0 +1,292 @@
+package scripting
+
+import (
+	"encoding/binary"
+	"math"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	// structMaxIntSize is the largest size of the integers of the i and I options
+	structMaxIntSize = 32
+	// structMaxAlign is the alignment of the ! option when it has no size
+	structMaxAlign = 8
+)
+
+// openStruct adds the struct library, which converts numbers and strings to binary
+// structures and back. The format describes each field with an option, like i for an
+// integer of 4 bytes, and may change the endianness and alignment of the fields that
+// follow.
+func openStruct(L *lua.LState) {
+	library := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"pack":   structPack,
+		"unpack": structUnpack,
+		"size":   structSize,
+	})
+	L.SetGlobal("struct", library)
+}
+
+// structFormat reads the options of a format.
+type structFormat struct {
+	L         *lua.LState
+	format    string
+	pos       int
+	bigEndian bool
+	align     int
+}
+
+func newStructFormat(L *lua.LState) *structFormat {
+	return &structFormat{L: L, format: L.CheckString(1), align: 1}
+}
+
+func (f *structFormat) more() bool {
+	return f.pos < len(f.format)
+}
+
+// number reads the number that follows an option, returning def if there is none.
+func (f *structFormat) number(def int) int {
+	if !f.more() || f.format[f.pos] < '0' || f.format[f.pos] > '9' {
+		return def
+	}
+	n := 0
+	for f.more() && f.format[f.pos] >= '0' && f.format[f.pos] <= '9' {
+		n = min(n*10+int(f.format[f.pos]-'0'), math.MaxInt32)
+		f.pos++
+	}
+	return n
+}
+
+// next reads the next option and returns it with its size, which is 0 for the options
+// that have no data. Options that change the endianness or alignment are applied.
+func (f *structFormat) next() (byte, int) {
+	option := f.format[f.pos]
+	f.pos++
+	switch option {
+	case 'b', 'B', 'x':
+		return option, 1
+	case 'h', 'H':
+		return option, 2
+	case 'f':
+		return option, 4
+	case 'l', 'L', 'T', 'd':
+		return option, 8
+	case 'c':
+		return option, f.number(1)
+	case 's':
+		return option, 0
+	case 'i', 'I':
+		size := f.number(4)
+		if size > structMaxIntSize {
+			raiseError(f.L, "integral size %d is larger than limit of %d", size, structMaxIntSize)
+		}
+		return option, size
+	case ' ':
+	case '>':
+		f.bigEndian = true
+	case '<', '=':
+		f.bigEndian = false
+	case '!':
+		align := f.number(structMaxAlign)
+		if align == 0 || align&(align-1) != 0 {
+			raiseError(f.L, "alignment %d is not a power of 2", align)
+		}
+		f.align = align
+	default:
+		raiseError(f.L, "invalid format option '%c'", option)
+	}
+	return option, 0
+}
+
+// padding returns the number of bytes that align an option of the size at the offset.
+func (f *structFormat) padding(offset int, option byte, size int) int {
+	if size == 0 || option == 'c' {
+		return 0
+	}
+	size = min(size, f.align)
+	if size&(size-1) != 0 {
+		f.L.ArgError(1, "alignment must be power of 2")
+	}
+	return (size - offset&(size-1)) & (size - 1)
+}
+
+// byteOrder reads and appends the bytes of floats in the endianness of the format.
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+func (f *structFormat) order() byteOrder {
+	if f.bigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+func isIntegerOption(option byte) bool {
+	return strings.IndexByte("bBhHlLTiI", option) >= 0
+}
+
+// structPack implements struct.pack(format, ...), which returns the string with the
+// values of the arguments.
+func structPack(L *lua.LState) int {
+	f := newStructFormat(L)
+	var data []byte
+	argument := 2
+	for f.more() {
+		option, size := f.next()
+		data = append(data, make([]byte, f.padding(len(data), option, size))...)
+		switch {
+		case isIntegerOption(option):
+			n := float64(L.CheckNumber(argument))
+			argument++
+			var bits uint64
+			if option >= 'a' || n < 0 {
+				bits = uint64(int64(n))
+			} else {
+				bits = uint64(n)
+			}
+			data = appendInteger(data, bits, size, f.bigEndian, n < 0)
+		case option == 'x':
+			data = append(data, 0)
+		case option == 'f':
+			data = f.order().AppendUint32(data, math.Float32bits(float32(L.CheckNumber(argument))))
+			argument++
+		case option == 'd':
+			data = f.order().AppendUint64(data, math.Float64bits(float64(L.CheckNumber(argument))))
+			argument++
+		case option == 'c' || option == 's':
+			s := L.CheckString(argument)
+			if size == 0 {
+				size = len(s)
+			}
+			if len(s) < size {
+				L.ArgError(argument, "string too short")
+			}
+			argument++
+			data = append(data, s[:size]...)
+			if option == 's' {
+				data = append(data, 0)
+			}
+		}
+	}
+	L.Push(lua.LString(data))
+	return 1
+}
+
+// appendInteger appends the lowest size bytes of the integer. Integers of more than 8
+// bytes are extended with the sign.
+func appendInteger(data []byte, n uint64, size int, bigEndian, negative bool) []byte {
+	b := make([]byte, size)
+	for i := 0; i < size; i++ {
+		switch {
+		case i < 8:
+			b[i] = byte(n >> (8 * i))
+		case negative:
+			b[i] = 0xff
+		}
+	}
+	if bigEndian {
+		for i, j := 0, size-1; i < j; i, j = i+1, j-1 {
+			b[i], b[j] = b[j], b[i]
+		}
+	}
+	return append(data, b...)
+}
+
+// structUnpack implements struct.unpack(format, data [, position]), which returns the
+// values of the data, followed by the position that follows them.
+func structUnpack(L *lua.LState) int {
+	f := newStructFormat(L)
+	data := L.CheckString(2)
+	pos := L.OptInt(3, 1) - 1
+	if pos < 0 {
+		L.ArgError(3, "offset must be 1 or greater")
+	}
+	var values []lua.LValue
+	for f.more() {
+		option, size := f.next()
+		pos += f.padding(pos, option, size)
+		if pos+size > len(data) {
+			L.ArgError(2, "data string too short")
+		}
+		switch {
+		case isIntegerOption(option):
+			values = append(values, readInteger(data[pos:pos+size], f.bigEndian, option >= 'a'))
+		case option == 'f':
+			values = append(values, lua.LNumber(math.Float32frombits(f.order().Uint32([]byte(data[pos:])))))
+		case option == 'd':
+			values = append(values, lua.LNumber(math.Float64frombits(f.order().Uint64([]byte(data[pos:])))))
+		case option == 'c':
+			if size == 0 {
+				// c0 takes its size from the value that precedes it
+				var previous lua.LNumber
+				var ok bool
+				if len(values) > 0 {
+					previous, ok = values[len(values)-1].(lua.LNumber)
+				}
+				if !ok {
+					raiseError(L, "format 'c0' needs a previous size")
+				}
+				values = values[:len(values)-1]
+				size = int(previous)
+				if size < 0 || pos+size > len(data) {
+					L.ArgError(2, "data string too short")
+				}
+			}
+			values = append(values, lua.LString(data[pos:pos+size]))
+		case option == 's':
+			end := strings.IndexByte(data[pos:], 0)
+			if end < 0 {
+				raiseError(L, "unfinished string in data")
+			}
+			values = append(values, lua.LString(data[pos:pos+end]))
+			size = end + 1
+		}
+		pos += size
+	}
+	for _, value := range values {
+		L.Push(value)
+	}
+	L.Push(lua.LNumber(pos + 1))
+	return len(values) + 1
+}
+
+// readInteger reads an integer from its bytes, extending the sign of signed integers.
+func readInteger(b string, bigEndian, signed bool) lua.LValue {
+	var n uint64
+	size := len(b)
+	for i := 0; i < size && i < 8; i++ {
+		j := i
+		if bigEndian {
+			j = size - 1 - i
+		}
+		n |= uint64(b[j]) << (8 * i)
+	}
+	if !signed {
+		return lua.LNumber(n)
+	}
+	if size < 8 {
+		shift := 64 - 8*size
+		return lua.LNumber(int64(n<<shift) >> shift)
+	}
+	return lua.LNumber(int64(n))
+}
+
+// structSize implements struct.size(format), which returns the size of the data of the
+// format. The size of the options c0 and s depends on the data, so they aren't allowed.
+func structSize(L *lua.LState) int {
+	f := newStructFormat(L)
+	size := 0
+	for f.more() {
+		option, optionSize := f.next()
+		size += f.padding(size, option, optionSize)
+		if option == 's' || (option == 'c' && optionSize == 0) {
+			L.ArgError(1, "options 'c0' - 's' have undefined sizes")
+		}
+		size += optionSize
+	}
+	L.Push(lua.LNumber(size))
+	return 1
+}
@@ -1,30 +1,87 @@
 package taskmanager
 
 import (
+	"time"
+
 	"github.com/mhsantos/redis-server/internal/commands"
+	"github.com/mhsantos/redis-server/internal/datastore"
 	"github.com/mhsantos/redis-server/internal/protocol"
 )
 
+// Task is a command sent by a client. The reply is written to the output of the client,
+// and Done receives a value once it's there, so the connection can send the next command.
 type Task struct {
-	Command         protocol.Array
-	ResponseChannel chan protocol.DataType
-	ErrorChannel    chan error
+	Client       *commands.Client
+	Command      protocol.Array
+	Done         chan struct{}
+	ErrorChannel chan error
+	// disconnect frees the client instead of running a command
+	disconnect bool
 }
 
 const (
 	taskQueueSize = 1000
+	// The active expire cycle runs 10 times per second using at most 25% of the time,
+	// the same defaults Redis uses.
+	activeExpireInterval = 100 * time.Millisecond
+	activeExpireBudget   = 25 * time.Millisecond
 )
 
-var tasks chan Task
+var tasks = make(chan Task, taskQueueSize)
 
 func AppendTask(task Task) {
 	tasks <- task
 }
 
+// RemoveClient must be called when the connection of a client is closed, so the task
+// loop releases its state, like its subscriptions. It goes through the same queue as the
+// commands, so the client is only freed after the commands it sent before closing the
+// connection ran, and none of them can block it or subscribe it once it's gone.
+func RemoveClient(client *commands.Client) {
+	tasks <- Task{Client: client, disconnect: true}
+}
+
+// Start processes the tasks one at a time, which is what keeps the datastore free of
+// data races. Background jobs like the active expire cycle and the background saves run
+// from this same loop, between tasks.
+//
+// A task with a blocking command, like BLPOP, doesn't block the loop: its Done channel is
+// kept aside until the command is served by a later task or times out.
 func Start() {
-	tasks = make(chan Task, taskQueueSize)
-	for task := range tasks {
-		response := commands.ProcessCommand(task.Command)
-		task.ResponseChannel <- response
+	ticker := time.NewTicker(activeExpireInterval)
+	defer ticker.Stop()
+	blockedTimer := time.NewTimer(time.Hour)
+	blockedTimer.Stop()
+	blockedTasks := make(map[*commands.Client]chan struct{})
+	for {
+		select {
+		case task := <-tasks:
+			if task.disconnect {
+				commands.FreeClient(task.Client)
+				delete(blockedTasks, task.Client)
+				break
+			}
+			response := commands.ProcessClientCommand(task.Client, task.Command)
+			if response == nil {
+				blockedTasks[task.Client] = task.Done
+			} else {
+				task.Client.Write(response)
+				task.Done <- struct{}{}
+			}
+		case now := <-ticker.C:
+			datastore.ActiveExpireCycle(activeExpireBudget)
+			commands.SaveCron(now)
+		case <-blockedTimer.C:
+		}
+		for _, blocked := range commands.ServeBlockedClients(time.Now()) {
+			blocked.Client.Write(blocked.Reply)
+			blockedTasks[blocked.Client] <- struct{}{}
+			delete(blockedTasks, blocked.Client)
+		}
+		if deadline, ok := commands.NextBlockedDeadline(); ok {
+			blockedTimer.Reset(time.Until(deadline))
+		} else {
+			blockedTimer.Stop()
+		}
 	}
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"time"
 
 	"github.com/mhsantos/redis-server/internal/commands"
 	"github.com/mhsantos/redis-server/internal/protocol"
@@ -16,6 +18,24 @@ const (
 )
 
 func main() {
+	dir := flag.String("dir", ".", "directory where the snapshot is saved")
+	dbfilename := flag.String("dbfilename", "dump.rdb", "file name of the snapshot")
+	save := flag.String("save", "3600 1 300 100 60 10000", `save a snapshot after the given seconds if the given number of writes happened, as pairs of seconds and writes, or "" to disable`)
+	flag.Parse()
+	rules, err := commands.ParseSaveRules(*save)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	commands.ConfigurePersistence(*dir, *dbfilename, rules)
+	// The snapshot is loaded before the task loop starts, since it replaces the datastore
+	start := time.Now()
+	if err := commands.LoadSnapshot(); err != nil {
+		fmt.Printf("Error loading the snapshot: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("DB loaded from disk: %.3f seconds\n", time.Since(start).Seconds())
+
 	// Listen for incoming connections on port 6379
 	listener, err := net.Listen("tcp", ":6379")
 
@@ -43,57 +63,130 @@ func main() {
 }
 
 func handleConnection(conn net.Conn) {
+	client := commands.NewClient()
+	closed := make(chan struct{})
 	defer func() {
 		if r := recover(); r != nil {
-			fmt.Printf("error parsing input closing the connection %s\n", r.(error))
+			fmt.Printf("error parsing input closing the connection %v\n", r)
 			// Close the connection when we're done
 		}
+		close(closed)
 		conn.Close()
+		taskmanager.RemoveClient(client)
 	}()
+	go writeOutput(conn, client)
+	reads := make(chan []byte)
+	go readInput(conn, reads, closed)
 
-	// Read incoming data
-	inBuf := make([]byte, bufferSize)
 	protocolBuf := make([]byte, 0)
-	responseQueue := make(chan protocol.DataType)
+	// The task loop may send to done after the client disconnected, so it never blocks
+	done := make(chan struct{}, 1)
+	for input := range reads {
+		protocolBuf = append(protocolBuf, input...)
+		var connected bool
+		if protocolBuf, connected = processFrames(client, protocolBuf, reads, done); !connected {
+			return
+		}
+	}
+}
 
+// readInput sends what's read from the connection to reads, closing it once the client
+// disconnects. Reading goes on while a command is blocked, so a client that disconnects
+// is freed right away instead of being served data nobody will read.
+func readInput(conn net.Conn, reads chan<- []byte, closed <-chan struct{}) {
+	defer close(reads)
 	for {
+		inBuf := make([]byte, bufferSize)
 		size, err := conn.Read(inBuf)
 		if err != nil {
 			if err == io.EOF {
 				fmt.Println("Client disconnected")
-				return
 			}
+			return
+		}
+		select {
+		case reads <- inBuf[:size]:
+		case <-closed:
+			return
 		}
-		protocolBuf = append(protocolBuf, inBuf[:size]...)
+	}
+}
+
+// writeOutput writes the output of the client to the connection as it's produced, which
+// besides the replies includes the messages published to the client's subscriptions. It
+// returns when the client is freed, or closes the connection if the client went over
+// its output limits.
+func writeOutput(conn net.Conn, client *commands.Client) {
+	for range client.OutputReady() {
+		output, disconnect := client.TakeOutput()
+		if disconnect {
+			fmt.Printf("Closing client %d for going over the output buffer limits\n", client.ID())
+			conn.Close()
+			return
+		}
+		if _, err := conn.Write(output); err != nil {
+			conn.Close()
+			return
+		}
+	}
+}
+
+// processFrames processes every complete frame in the buffer, in the order they were
+// received, so pipelined commands don't wait for another read from the connection.
+// Empty commands are skipped without a reply.
+// Each command is sent to the task loop, which writes its reply to the output of the
+// client, and the next one is only sent once done receives a value. What's read in the
+// meantime is appended to the buffer, and connected is false if the client disconnected.
+// While a script is busy the task loop can't run commands, so they are answered right
+// away instead. The unprocessed bytes of the buffer are returned.
+func processFrames(client *commands.Client, protocolBuf []byte, reads <-chan []byte, done chan struct{}) (unprocessed []byte, connected bool) {
+	for len(protocolBuf) > 0 {
 		validRead, err := commands.ParseCommand(protocolBuf)
 		if err != nil {
-			clear(inBuf)
-			protocolBuf = make([]byte, 0)
-			conn.Write([]byte(protocol.NewError(err.Error()).Encode()))
-			continue
+			client.Write(protocol.NewError(err.Error()))
+			return make([]byte, 0), true
 		}
 		data, dataSize := validRead.Unwrap()
-		if dataSize > 0 {
-			// Processed a full frame
-			switch data := data.(type) {
-			case protocol.Error:
-				conn.Write([]byte(data.Encode()))
-				clear(inBuf)
-			case protocol.Array:
-				task := taskmanager.Task{
-					Command:         data,
-					ResponseChannel: responseQueue,
-				}
-				taskmanager.AppendTask(task)
+		if dataSize < 0 {
+			// The rest of the buffer is a partial frame
+			return protocolBuf, true
+		}
+		switch data := data.(type) {
+		case protocol.Error:
+			client.Write(data)
+		case protocol.Array:
+			if reply := commands.ProcessBusyCommand(data); reply != nil {
+				client.Write(reply)
+				break
+			}
+			task := taskmanager.Task{
+				Client:  client,
+				Command: data,
+				Done:    done,
+			}
+			taskmanager.AppendTask(task)
+			if protocolBuf, connected = waitTask(protocolBuf, reads, done); !connected {
+				return nil, false
 			}
-			response := <-responseQueue
-			conn.Write([]byte(response.Encode()))
-			clear(inBuf)
-			processedBufferSize := dataSize
-			protocolBuf = protocolBuf[processedBufferSize:]
-			continue
 		}
-		clear(inBuf)
+		protocolBuf = protocolBuf[dataSize:]
 	}
+	return protocolBuf, true
+}
 
+// waitTask waits for the task loop to be done with the task of the client, appending
+// what's read in the meantime to the buffer. connected is false if the client
+// disconnected before the task was done, which happens while the command is blocked.
+func waitTask(protocolBuf []byte, reads <-chan []byte, done <-chan struct{}) ([]byte, bool) {
+	for {
+		select {
+		case <-done:
+			return protocolBuf, true
+		case input, ok := <-reads:
+			if !ok {
+				return protocolBuf, false
+			}
+			protocolBuf = append(protocolBuf, input...)
+		}
+	}
 }
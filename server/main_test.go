@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mhsantos/redis-server/internal/commands"
+	"github.com/mhsantos/redis-server/internal/protocol"
+	"github.com/mhsantos/redis-server/internal/taskmanager"
+)
+
+func init() {
+	go taskmanager.Start()
+}
+
+// pipeline sends all the commands in a single write and reads the expected number of
+// response lines back from the server.
+func pipeline(t *testing.T, payload string, lines int) []string {
+	t.Helper()
+	server, client := net.Pipe()
+	defer client.Close()
+	go handleConnection(server)
+
+	go func() {
+		client.Write([]byte(payload))
+	}()
+	reader := bufio.NewReader(client)
+	responses := make([]string, 0, lines)
+	for i := 0; i < lines; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response %d: %v", i, err)
+		}
+		responses = append(responses, line)
+	}
+	return responses
+}
+
+func TestPipelinedCommands(t *testing.T) {
+	const commandCount = 100
+	var payload strings.Builder
+	for i := 0; i < commandCount; i++ {
+		payload.Write(protocol.NewArray(
+			protocol.NewBulkString([]byte("INCR")),
+			protocol.NewBulkString([]byte("pipelined")),
+		).Encode())
+	}
+	responses := pipeline(t, payload.String(), commandCount)
+	for i, response := range responses {
+		expected := fmt.Sprintf(":%d\r\n", i+1)
+		if response != expected {
+			t.Fatalf("unexpected response %d. Expected: %q, Actual: %q", i, expected, response)
+		}
+	}
+}
+
+func TestPipelinedMixedFrames(t *testing.T) {
+	payload := "*3\r\n$3\r\nSET\r\n$5\r\nmixed\r\n$5\r\nvalue\r\n" +
+		"GET mixed\r\n" +
+		"FOO bar\r\n" +
+		"*2\r\n$6\r\nEXISTS\r\n$5\r\nmixed\r\n"
+	expected := []string{"+OK\r\n", "$5\r\n", "value\r\n", "-invalid command FOO\r\n", ":1\r\n"}
+	responses := pipeline(t, payload, len(expected))
+	for i, response := range responses {
+		if response != expected[i] {
+			t.Fatalf("unexpected response %d. Expected: %q, Actual: %q", i, expected[i], response)
+		}
+	}
+}
+
+func TestEmptyInlineCommandsAreIgnored(t *testing.T) {
+	responses := pipeline(t, "\r\nPING\r\n\r\n*0\r\nPING\r\n", 2)
+	for _, response := range responses {
+		if response != "+PONG\r\n" {
+			t.Fatalf("unexpected response %q", response)
+		}
+	}
+}
+
+func TestBlockedClientDoesNotBlockOthers(t *testing.T) {
+	blockedServer, blockedClient := net.Pipe()
+	defer blockedClient.Close()
+	go handleConnection(blockedServer)
+	go blockedClient.Write([]byte("BLPOP blocking-queue 0\r\n"))
+
+	// Another client can still run commands, and its push wakes up the blocked one
+	responses := pipeline(t, "PING-UNKNOWN\r\nRPUSH blocking-queue job\r\n", 2)
+	if responses[1] != ":1\r\n" {
+		t.Fatalf("unexpected response %q", responses[1])
+	}
+	reader := bufio.NewReader(blockedClient)
+	expected := []string{"*2\r\n", "$14\r\n", "blocking-queue\r\n", "$3\r\n", "job\r\n"}
+	for i := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		if line != expected[i] {
+			t.Fatalf("unexpected response. Expected: %q, Actual: %q", expected[i], line)
+		}
+	}
+}
+
+func TestDisconnectedBlockedClientIsNotServed(t *testing.T) {
+	blockedServer, blockedClient := net.Pipe()
+	returned := make(chan struct{})
+	go func() {
+		handleConnection(blockedServer)
+		close(returned)
+	}()
+	blockedClient.Write([]byte("BLPOP disconnected-queue 0\r\n"))
+	blockedClient.Close()
+	<-returned
+
+	// The client was freed once it disconnected, so the element stays in the list
+	responses := pipeline(t, "RPUSH disconnected-queue job\r\nLLEN disconnected-queue\r\n", 2)
+	if responses[0] != ":1\r\n" || responses[1] != ":1\r\n" {
+		t.Fatalf("unexpected responses %q", responses)
+	}
+}
+
+func TestClientIsFreedAfterItsQueuedCommands(t *testing.T) {
+	client := commands.NewClient()
+	for _, command := range []string{"BLPOP queued-disconnect 0", "SUBSCRIBE queued-disconnect-channel"} {
+		validRead, err := commands.ParseCommand([]byte(command + "\r\n"))
+		if err != nil {
+			t.Fatalf("unexpected error parsing %s: %v", command, err)
+		}
+		data, _ := validRead.Unwrap()
+		taskmanager.AppendTask(taskmanager.Task{Client: client, Command: data.(protocol.Array), Done: make(chan struct{}, 1)})
+	}
+	taskmanager.RemoveClient(client)
+
+	// The commands queued before the disconnect don't leave the client blocked or subscribed
+	responses := pipeline(t, "RPUSH queued-disconnect job\r\nLLEN queued-disconnect\r\nPUBLISH queued-disconnect-channel message\r\n", 3)
+	if !reflect.DeepEqual(responses, []string{":1\r\n", ":1\r\n", ":0\r\n"}) {
+		t.Fatalf("unexpected responses %q", responses)
+	}
+}
+
+func TestSubscriberReceivesMessages(t *testing.T) {
+	subscriberServer, subscriberClient := net.Pipe()
+	defer subscriberClient.Close()
+	go handleConnection(subscriberServer)
+	go subscriberClient.Write([]byte("SUBSCRIBE news\r\n"))
+	reader := bufio.NewReader(subscriberClient)
+	expected := []string{"*3\r\n", "$9\r\n", "subscribe\r\n", "$4\r\n", "news\r\n", ":1\r\n"}
+	for i := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		if line != expected[i] {
+			t.Fatalf("unexpected response. Expected: %q, Actual: %q", expected[i], line)
+		}
+	}
+
+	// The message is pushed to the subscriber without it sending any command
+	responses := pipeline(t, "PUBLISH news hello\r\n", 1)
+	if responses[0] != ":1\r\n" {
+		t.Fatalf("unexpected response %q", responses[0])
+	}
+	expected = []string{"*3\r\n", "$7\r\n", "message\r\n", "$4\r\n", "news\r\n", "$5\r\n", "hello\r\n"}
+	for i := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		if line != expected[i] {
+			t.Fatalf("unexpected response. Expected: %q, Actual: %q", expected[i], line)
+		}
+	}
+}
+
+func TestShardedMessagesArePushedToRESP3Clients(t *testing.T) {
+	subscriberServer, subscriberClient := net.Pipe()
+	defer subscriberClient.Close()
+	go handleConnection(subscriberServer)
+	go subscriberClient.Write([]byte("HELLO 3\r\nSSUBSCRIBE orders\r\n"))
+	reader := bufio.NewReader(subscriberClient)
+	// Skip the reply to HELLO, up to the push confirming the subscription
+	for line := ""; line != ">3\r\n"; {
+		var err error
+		if line, err = reader.ReadString('\n'); err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+	}
+	expected := []string{"$10\r\n", "ssubscribe\r\n", "$6\r\n", "orders\r\n", ":1\r\n"}
+	for i := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		if line != expected[i] {
+			t.Fatalf("unexpected response. Expected: %q, Actual: %q", expected[i], line)
+		}
+	}
+
+	responses := pipeline(t, "SPUBLISH orders created\r\n", 1)
+	if responses[0] != ":1\r\n" {
+		t.Fatalf("unexpected response %q", responses[0])
+	}
+	expected = []string{">3\r\n", "$8\r\n", "smessage\r\n", "$6\r\n", "orders\r\n", "$7\r\n", "created\r\n"}
+	for i := range expected {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("error reading response: %v", err)
+		}
+		if line != expected[i] {
+			t.Fatalf("unexpected response. Expected: %q, Actual: %q", expected[i], line)
+		}
+	}
+}